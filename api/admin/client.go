@@ -7,6 +7,7 @@ import (
 	"context"
 
 	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/database/snapshot"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/rpc"
@@ -28,6 +29,8 @@ type Client interface {
 	SetLoggerLevel(ctx context.Context, loggerName, logLevel, displayLevel string, options ...rpc.Option) error
 	GetLoggerLevel(ctx context.Context, loggerName string, options ...rpc.Option) (map[string]LogAndDisplayLevels, error)
 	GetConfig(ctx context.Context, options ...rpc.Option) (interface{}, error)
+	CreateSnapshot(ctx context.Context, name string, options ...rpc.Option) (*snapshot.Manifest, error)
+	ResyncChain(ctx context.Context, chain string, options ...rpc.Option) (uint64, error)
 }
 
 // Client implementation for the Avalanche Platform Info API Endpoint
@@ -138,3 +141,19 @@ func (c *client) GetConfig(ctx context.Context, options ...rpc.Option) (interfac
 	err := c.requester.SendRequest(ctx, "admin.getConfig", struct{}{}, &res, options...)
 	return res, err
 }
+
+func (c *client) CreateSnapshot(ctx context.Context, name string, options ...rpc.Option) (*snapshot.Manifest, error) {
+	res := &CreateSnapshotReply{}
+	err := c.requester.SendRequest(ctx, "admin.createSnapshot", &CreateSnapshotArgs{
+		Name: name,
+	}, res, options...)
+	return res.Manifest, err
+}
+
+func (c *client) ResyncChain(ctx context.Context, chain string, options ...rpc.Option) (uint64, error) {
+	res := &ResyncChainReply{}
+	err := c.requester.SendRequest(ctx, "admin.resyncChain", &ResyncChainArgs{
+		Chain: chain,
+	}, res, options...)
+	return uint64(res.Generation), err
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/database/snapshot"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/rpc"
@@ -66,6 +67,12 @@ func (mc *mockClient) SendRequest(_ context.Context, _ string, _ interface{}, re
 	case *GetLoggerLevelReply:
 		response := mc.response.(*GetLoggerLevelReply)
 		*p = *response
+	case *CreateSnapshotReply:
+		response := mc.response.(*CreateSnapshotReply)
+		*p = *response
+	case *ResyncChainReply:
+		response := mc.response.(*ResyncChainReply)
+		*p = *response
 	case *interface{}:
 		response := mc.response.(*interface{})
 		*p = *response
@@ -210,6 +217,57 @@ func TestReloadInstalledVMs(t *testing.T) {
 	})
 }
 
+func TestCreateSnapshot(t *testing.T) {
+	t.Run("successful", func(t *testing.T) {
+		require := require.New(t)
+
+		expectedManifest := &snapshot.Manifest{
+			DBType: "leveldb",
+			Chains: []snapshot.ChainManifest{
+				{
+					ChainID:      ids.GenerateTestID(),
+					Name:         "P",
+					LastAccepted: ids.GenerateTestID(),
+					Height:       1,
+				},
+			},
+		}
+		mockClient := client{requester: NewMockClient(&CreateSnapshotReply{
+			Manifest: expectedManifest,
+		}, nil)}
+
+		manifest, err := mockClient.CreateSnapshot(context.Background(), "snapshot")
+		require.NoError(err)
+		require.Equal(expectedManifest, manifest)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockClient := client{requester: NewMockClient(&CreateSnapshotReply{}, errTest)}
+		_, err := mockClient.CreateSnapshot(context.Background(), "snapshot")
+		require.ErrorIs(t, err, errTest)
+	})
+}
+
+func TestResyncChain(t *testing.T) {
+	t.Run("successful", func(t *testing.T) {
+		require := require.New(t)
+
+		mockClient := client{requester: NewMockClient(&ResyncChainReply{
+			Generation: 2,
+		}, nil)}
+
+		generation, err := mockClient.ResyncChain(context.Background(), "X")
+		require.NoError(err)
+		require.Equal(uint64(2), generation)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockClient := client{requester: NewMockClient(&ResyncChainReply{}, errTest)}
+		_, err := mockClient.ResyncChain(context.Background(), "X")
+		require.ErrorIs(t, err, errTest)
+	})
+}
+
 func TestSetLoggerLevel(t *testing.T) {
 	type test struct {
 		name         string
@@ -15,6 +15,7 @@ import (
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/api/server"
 	"github.com/ava-labs/avalanchego/chains"
+	"github.com/ava-labs/avalanchego/database/snapshot"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils"
@@ -48,6 +49,7 @@ type Config struct {
 	HTTPServer   server.PathAdderWithReadLock
 	VMRegistry   registry.VMRegistry
 	VMManager    vms.Manager
+	Snapshotter  snapshot.Creator
 }
 
 // Admin is the API service for node admin management
@@ -345,3 +347,71 @@ func (a *Admin) LoadVMs(r *http.Request, _ *struct{}, reply *LoadVMsReply) error
 	reply.NewVMs, err = ids.GetRelevantAliases(a.VMManager, loadedVMs)
 	return err
 }
+
+// CreateSnapshotArgs are the arguments for calling CreateSnapshot
+type CreateSnapshotArgs struct {
+	// Name of the directory, inside the configured snapshot directory, that
+	// the snapshot is written into
+	Name string `json:"name"`
+}
+
+// CreateSnapshotReply is the manifest of the created snapshot
+type CreateSnapshotReply struct {
+	Manifest *snapshot.Manifest `json:"manifest"`
+}
+
+// CreateSnapshot writes a consistent snapshot of the node's databases
+func (a *Admin) CreateSnapshot(r *http.Request, args *CreateSnapshotArgs, reply *CreateSnapshotReply) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "createSnapshot"),
+		logging.UserString("name", args.Name),
+	)
+
+	manifest, err := a.Snapshotter.Create(r.Context(), args.Name)
+	reply.Manifest = manifest
+	return err
+}
+
+// ResyncChainArgs are the arguments for calling ResyncChain
+type ResyncChainArgs struct {
+	Chain string `json:"chain"`
+}
+
+// ResyncChainReply is the generation of the chain's new database partition
+type ResyncChainReply struct {
+	Generation json.Uint64 `json:"generation"`
+	// Warning describes the keys of the chain's previous partition that won't
+	// be deleted
+	Warning string `json:"warning"`
+}
+
+// ResyncChain gives the chain a new, empty, database partition the next time
+// the node is started, so that only the chain syncs from scratch. Chains of
+// the primary network, and chains that may have written to shared memory,
+// can't be re-synced.
+func (a *Admin) ResyncChain(_ *http.Request, args *ResyncChainArgs, reply *ResyncChainReply) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "resyncChain"),
+		logging.UserString("chain", args.Chain),
+	)
+
+	chainID, err := a.ChainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	generation, err := a.ChainManager.ScheduleResync(chainID)
+	if err != nil {
+		return err
+	}
+
+	a.Log.Warn("chain will be re-synced once the node is restarted",
+		zap.Stringer("chainID", chainID),
+		zap.Uint64("generation", generation),
+		zap.String("warning", chains.StalePartitionsWarning),
+	)
+	reply.Generation = json.Uint64(generation)
+	reply.Warning = chains.StalePartitionsWarning
+	return nil
+}
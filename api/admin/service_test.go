@@ -11,7 +11,9 @@ import (
 
 	"go.uber.org/mock/gomock"
 
+	"github.com/ava-labs/avalanchego/chains"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/json"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/vms"
 	"github.com/ava-labs/avalanchego/vms/registry"
@@ -117,3 +119,61 @@ func TestLoadVMsGetAliasesFails(t *testing.T) {
 	err := resources.admin.LoadVMs(&http.Request{}, nil, &reply)
 	require.ErrorIs(err, errTest)
 }
+
+// resyncManager is a chains.Manager that records the chains it's asked to
+// re-sync.
+type resyncManager struct {
+	chains.Manager
+
+	resynced   []ids.ID
+	generation uint64
+	err        error
+}
+
+func (m *resyncManager) ScheduleResync(chainID ids.ID) (uint64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.resynced = append(m.resynced, chainID)
+	return m.generation, nil
+}
+
+func TestResyncChainSchedulesResync(t *testing.T) {
+	require := require.New(t)
+
+	chainManager := &resyncManager{
+		Manager:    chains.TestManager,
+		generation: 1,
+	}
+	admin := &Admin{Config: Config{
+		Log:          logging.NoLog{},
+		ChainManager: chainManager,
+	}}
+
+	chainID := ids.GenerateTestID()
+	reply := ResyncChainReply{}
+	require.NoError(admin.ResyncChain(&http.Request{}, &ResyncChainArgs{
+		Chain: chainID.String(),
+	}, &reply))
+	require.Equal(json.Uint64(1), reply.Generation)
+	require.Equal(chains.StalePartitionsWarning, reply.Warning)
+	require.Equal([]ids.ID{chainID}, chainManager.resynced)
+}
+
+func TestResyncChainSharedMemoryChain(t *testing.T) {
+	require := require.New(t)
+
+	admin := &Admin{Config: Config{
+		Log: logging.NoLog{},
+		ChainManager: &resyncManager{
+			Manager: chains.TestManager,
+			err:     chains.ErrUsesSharedMemory,
+		},
+	}}
+
+	reply := ResyncChainReply{}
+	err := admin.ResyncChain(&http.Request{}, &ResyncChainArgs{
+		Chain: ids.GenerateTestID().String(),
+	}, &reply)
+	require.ErrorIs(err, chains.ErrUsesSharedMemory)
+}
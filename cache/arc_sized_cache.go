@@ -0,0 +1,273 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cache
+
+import (
+	"sync"
+
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/linkedhashmap"
+	"github.com/ava-labs/avalanchego/utils/math"
+)
+
+var _ Cacher[struct{}, any] = (*sizedARC[struct{}, any])(nil)
+
+// sizedARC is a key value store with bounded size. If the size is attempted to
+// be exceeded, then elements are removed from the cache until the bound is
+// honored, based on the Adaptive Replacement Cache (ARC) policy.
+//
+// Elements that have been used once since they were added are kept separately
+// from elements that have been used more than once. The keys of recently
+// evicted elements are remembered, and are used to adapt how much of the cache
+// is given to each group. This makes the cache scan resistant: a scan over many
+// elements that are each used once evicts elements that were used once, rather
+// than the frequently used working set.
+type sizedARC[K comparable, V any] struct {
+	lock sync.Mutex
+
+	// Elements used once since they were added to the cache.
+	recent     linkedhashmap.LinkedHashmap[K, V]
+	recentSize int
+	// Elements used more than once since they were added to the cache.
+	frequent     linkedhashmap.LinkedHashmap[K, V]
+	frequentSize int
+
+	// Keys, and the sizes of their values, that were recently evicted from
+	// [recent] and [frequent].
+	recentGhosts       linkedhashmap.LinkedHashmap[K, int]
+	recentGhostsSize   int
+	frequentGhosts     linkedhashmap.LinkedHashmap[K, int]
+	frequentGhostsSize int
+
+	// The size [recent] is adapted towards.
+	targetRecentSize int
+	maxSize          int
+	size             func(K, V) int
+}
+
+// NewARC returns a cache that holds at most [size] elements and evicts them
+// based on the Adaptive Replacement Cache policy. If [size] is <= 0, it's
+// treated as 1.
+func NewARC[K comparable, V any](size int) Cacher[K, V] {
+	return NewSizedARC[K, V](
+		math.Max(size, 1),
+		func(K, V) int {
+			return 1
+		},
+	)
+}
+
+// NewSizedARC returns a cache that holds elements with a total size of at most
+// [maxSize] and evicts them based on the Adaptive Replacement Cache policy.
+func NewSizedARC[K comparable, V any](maxSize int, size func(K, V) int) Cacher[K, V] {
+	c := &sizedARC[K, V]{
+		maxSize: maxSize,
+		size:    size,
+	}
+	c.flush()
+	return c
+}
+
+func (c *sizedARC[K, V]) Put(key K, value V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.put(key, value)
+}
+
+func (c *sizedARC[K, V]) Get(key K) (V, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.get(key)
+}
+
+func (c *sizedARC[K, V]) Evict(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.evict(key)
+}
+
+func (c *sizedARC[K, V]) Flush() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.flush()
+}
+
+func (c *sizedARC[_, _]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.len()
+}
+
+func (c *sizedARC[_, _]) PortionFilled() float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.portionFilled()
+}
+
+func (c *sizedARC[K, V]) put(key K, value V) {
+	newEntrySize := c.size(key, value)
+	if newEntrySize > c.maxSize {
+		c.flush()
+		return
+	}
+
+	// If [key] is in the cache, it has now been used more than once.
+	if oldValue, ok := c.recent.Get(key); ok {
+		c.recent.Delete(key)
+		c.recentSize -= c.size(key, oldValue)
+		c.putFrequent(key, value, newEntrySize, false)
+		return
+	}
+	if oldValue, ok := c.frequent.Get(key); ok {
+		c.frequent.Delete(key)
+		c.frequentSize -= c.size(key, oldValue)
+		c.putFrequent(key, value, newEntrySize, false)
+		return
+	}
+
+	// If [key] was recently evicted, it would have been a hit if the part of
+	// the cache it was evicted from was larger.
+	if ghostSize, ok := c.recentGhosts.Get(key); ok {
+		delta := ghostSize
+		if c.frequentGhostsSize > c.recentGhostsSize && c.recentGhostsSize > 0 {
+			delta = ghostSize * c.frequentGhostsSize / c.recentGhostsSize
+		}
+		c.targetRecentSize = math.Min(c.targetRecentSize+delta, c.maxSize)
+
+		c.recentGhosts.Delete(key)
+		c.recentGhostsSize -= ghostSize
+		c.putFrequent(key, value, newEntrySize, false)
+		return
+	}
+	if ghostSize, ok := c.frequentGhosts.Get(key); ok {
+		delta := ghostSize
+		if c.recentGhostsSize > c.frequentGhostsSize && c.frequentGhostsSize > 0 {
+			delta = ghostSize * c.recentGhostsSize / c.frequentGhostsSize
+		}
+		c.targetRecentSize = math.Max(c.targetRecentSize-delta, 0)
+
+		c.frequentGhosts.Delete(key)
+		c.frequentGhostsSize -= ghostSize
+		c.putFrequent(key, value, newEntrySize, true)
+		return
+	}
+
+	c.makeRoom(newEntrySize, false)
+	c.recent.Put(key, value)
+	c.recentSize += newEntrySize
+	c.trimGhosts()
+}
+
+// Adds [key] to [c.frequent], evicting elements to make room for it.
+func (c *sizedARC[K, V]) putFrequent(key K, value V, size int, isFrequentGhost bool) {
+	c.makeRoom(size, isFrequentGhost)
+	c.frequent.Put(key, value)
+	c.frequentSize += size
+	c.trimGhosts()
+}
+
+// Evicts elements until an element of [size] fits in the cache.
+// [isFrequentGhost] is true if the element being added was recently evicted
+// from [c.frequent].
+func (c *sizedARC[K, V]) makeRoom(size int, isFrequentGhost bool) {
+	for c.recentSize+c.frequentSize > c.maxSize-size {
+		evictRecent := c.recent.Len() > 0 &&
+			(c.frequent.Len() == 0 ||
+				c.recentSize > c.targetRecentSize ||
+				(c.recentSize == c.targetRecentSize && isFrequentGhost))
+		if evictRecent {
+			key, value, _ := c.recent.Oldest()
+			c.recent.Delete(key)
+			valueSize := c.size(key, value)
+			c.recentSize -= valueSize
+			c.recentGhosts.Put(key, valueSize)
+			c.recentGhostsSize += valueSize
+		} else {
+			key, value, _ := c.frequent.Oldest()
+			c.frequent.Delete(key)
+			valueSize := c.size(key, value)
+			c.frequentSize -= valueSize
+			c.frequentGhosts.Put(key, valueSize)
+			c.frequentGhostsSize += valueSize
+		}
+	}
+}
+
+// Forgets the oldest evicted keys so that the recently used elements and
+// their evicted keys are at most [c.maxSize], and all elements and evicted
+// keys are at most twice [c.maxSize].
+func (c *sizedARC[K, V]) trimGhosts() {
+	for c.recentSize+c.recentGhostsSize > c.maxSize && c.recentGhosts.Len() > 0 {
+		key, size, _ := c.recentGhosts.Oldest()
+		c.recentGhosts.Delete(key)
+		c.recentGhostsSize -= size
+	}
+	for c.recentSize+c.frequentSize+c.recentGhostsSize+c.frequentGhostsSize > 2*c.maxSize && c.frequentGhosts.Len() > 0 {
+		key, size, _ := c.frequentGhosts.Oldest()
+		c.frequentGhosts.Delete(key)
+		c.frequentGhostsSize -= size
+	}
+}
+
+func (c *sizedARC[K, V]) get(key K) (V, bool) {
+	if value, ok := c.recent.Get(key); ok {
+		// [key] has now been used more than once.
+		size := c.size(key, value)
+		c.recent.Delete(key)
+		c.recentSize -= size
+		c.frequent.Put(key, value)
+		c.frequentSize += size
+		return value, true
+	}
+	if value, ok := c.frequent.Get(key); ok {
+		c.frequent.Put(key, value) // Mark [k] as MRU.
+		return value, true
+	}
+	return utils.Zero[V](), false
+}
+
+func (c *sizedARC[K, _]) evict(key K) {
+	if value, ok := c.recent.Get(key); ok {
+		c.recent.Delete(key)
+		c.recentSize -= c.size(key, value)
+	}
+	if value, ok := c.frequent.Get(key); ok {
+		c.frequent.Delete(key)
+		c.frequentSize -= c.size(key, value)
+	}
+	if size, ok := c.recentGhosts.Get(key); ok {
+		c.recentGhosts.Delete(key)
+		c.recentGhostsSize -= size
+	}
+	if size, ok := c.frequentGhosts.Get(key); ok {
+		c.frequentGhosts.Delete(key)
+		c.frequentGhostsSize -= size
+	}
+}
+
+func (c *sizedARC[K, V]) flush() {
+	c.recent = linkedhashmap.New[K, V]()
+	c.recentSize = 0
+	c.frequent = linkedhashmap.New[K, V]()
+	c.frequentSize = 0
+	c.recentGhosts = linkedhashmap.New[K, int]()
+	c.recentGhostsSize = 0
+	c.frequentGhosts = linkedhashmap.New[K, int]()
+	c.frequentGhostsSize = 0
+	c.targetRecentSize = 0
+}
+
+func (c *sizedARC[_, _]) len() int {
+	return c.recent.Len() + c.frequent.Len()
+}
+
+func (c *sizedARC[_, _]) portionFilled() float64 {
+	return float64(c.recentSize+c.frequentSize) / float64(c.maxSize)
+}
@@ -0,0 +1,165 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cache
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestARC(t *testing.T) {
+	cache := NewARC[ids.ID, int64](1)
+
+	TestBasic(t, cache)
+}
+
+func TestARCEviction(t *testing.T) {
+	cache := NewARC[ids.ID, int64](2)
+
+	TestEviction(t, cache)
+}
+
+func TestSizedARC(t *testing.T) {
+	cache := NewSizedARC[ids.ID, int64](TestIntSize, TestIntSizeFunc)
+
+	TestBasic(t, cache)
+}
+
+func TestSizedARCEviction(t *testing.T) {
+	cache := NewSizedARC[ids.ID, int64](2*TestIntSize, TestIntSizeFunc)
+
+	TestEviction(t, cache)
+}
+
+// Test that a scan over many elements that are each used once doesn't evict
+// the elements that are used frequently.
+func TestARCScanResistance(t *testing.T) {
+	require := require.New(t)
+
+	const (
+		size        = 100
+		workingSize = size / 2
+	)
+	cache := NewARC[int, int](size)
+
+	// Use the working set more than once so it's known to be frequently used.
+	for i := 0; i < workingSize; i++ {
+		cache.Put(i, i)
+	}
+	for i := 0; i < workingSize; i++ {
+		_, ok := cache.Get(i)
+		require.True(ok)
+	}
+
+	// Scan over many more elements than fit in the cache.
+	for i := workingSize; i < 100*size; i++ {
+		if _, ok := cache.Get(i); !ok {
+			cache.Put(i, i)
+		}
+	}
+
+	for i := 0; i < workingSize; i++ {
+		value, ok := cache.Get(i)
+		require.True(ok)
+		require.Equal(i, value)
+	}
+	require.Equal(size, cache.Len())
+
+	// An LRU cache loses the working set.
+	lru := &LRU[int, int]{Size: size}
+	for i := 0; i < workingSize; i++ {
+		lru.Put(i, i)
+	}
+	for i := workingSize; i < 100*size; i++ {
+		lru.Put(i, i)
+	}
+	for i := 0; i < workingSize; i++ {
+		_, ok := lru.Get(i)
+		require.False(ok)
+	}
+}
+
+func TestSizedARCSizes(t *testing.T) {
+	require := require.New(t)
+
+	cache := NewSizedARC[string, struct{}](
+		3,
+		func(key string, _ struct{}) int {
+			return len(key)
+		},
+	)
+
+	cache.Put("a", struct{}{})
+	cache.Put("b", struct{}{})
+	cache.Put("c", struct{}{})
+	require.Equal(3, cache.Len())
+	require.Equal(float64(1), cache.PortionFilled())
+
+	cache.Put("dd", struct{}{})
+	require.Equal(2, cache.Len())
+	require.Equal(float64(1), cache.PortionFilled())
+	_, ok := cache.Get("a")
+	require.False(ok)
+	_, ok = cache.Get("b")
+	require.False(ok)
+	_, ok = cache.Get("c")
+	require.True(ok)
+	_, ok = cache.Get("dd")
+	require.True(ok)
+
+	// Elements larger than the cache flush it.
+	cache.Put("eeee", struct{}{})
+	require.Zero(cache.Len())
+	require.Zero(cache.PortionFilled())
+}
+
+// Test that the cache never exceeds its size and keeps track of the sizes of
+// its elements under random operations.
+func TestSizedARCRandom(t *testing.T) {
+	require := require.New(t)
+
+	const maxSize = 20
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	c := NewSizedARC[int, int](
+		maxSize,
+		func(_ int, value int) int {
+			return value
+		},
+	).(*sizedARC[int, int])
+
+	for i := 0; i < 10_000; i++ {
+		key := r.Intn(50)
+		switch r.Intn(10) {
+		case 0:
+			c.Evict(key)
+		case 1, 2, 3, 4:
+			value := r.Intn(5)
+			c.Put(key, value)
+			got, ok := c.Get(key)
+			require.True(ok)
+			require.Equal(value, got)
+		default:
+			c.Get(key)
+		}
+
+		recentSize := 0
+		for it := c.recent.NewIterator(); it.Next(); {
+			recentSize += it.Value()
+		}
+		frequentSize := 0
+		for it := c.frequent.NewIterator(); it.Next(); {
+			frequentSize += it.Value()
+		}
+		require.Equal(recentSize, c.recentSize)
+		require.Equal(frequentSize, c.frequentSize)
+		require.LessOrEqual(c.recentSize+c.frequentSize, maxSize)
+		require.LessOrEqual(c.recentSize+c.frequentSize+c.recentGhostsSize+c.frequentGhostsSize, 2*maxSize)
+		require.GreaterOrEqual(c.targetRecentSize, 0)
+		require.LessOrEqual(c.targetRecentSize, maxSize)
+	}
+}
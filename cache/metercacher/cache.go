@@ -25,7 +25,7 @@ func New[K comparable, V any](
 	cache cache.Cacher[K, V],
 ) (cache.Cacher[K, V], error) {
 	meterCache := &Cache[K, V]{Cacher: cache}
-	return meterCache, meterCache.metrics.Initialize(namespace, registerer)
+	return meterCache, meterCache.metrics.Initialize(namespace, registerer, cache)
 }
 
 func (c *Cache[K, V]) Put(key K, value V) {
@@ -47,6 +47,11 @@ func (c *Cache[K, V]) Get(key K) (V, bool) {
 	} else {
 		c.miss.Inc()
 	}
+	if _, ok := c.Cacher.(cache.Expirer); ok {
+		// Expired elements may have been removed from the cache.
+		c.len.Set(float64(c.Cacher.Len()))
+		c.portionFilled.Set(c.Cacher.PortionFilled())
+	}
 
 	return value, has
 }
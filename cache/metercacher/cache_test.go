@@ -5,13 +5,16 @@ package metercacher
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 )
 
 func TestInterface(t *testing.T) {
@@ -33,6 +36,24 @@ func TestInterface(t *testing.T) {
 				return cache.NewSizedLRU[ids.ID, int64](size*cache.TestIntSize, cache.TestIntSizeFunc)
 			},
 		},
+		{
+			description: "cache ARC",
+			setup: func(size int) cache.Cacher[ids.ID, int64] {
+				return cache.NewARC[ids.ID, int64](size)
+			},
+		},
+		{
+			description: "sized cache ARC",
+			setup: func(size int) cache.Cacher[ids.ID, int64] {
+				return cache.NewSizedARC[ids.ID, int64](size*cache.TestIntSize, cache.TestIntSizeFunc)
+			},
+		},
+		{
+			description: "cache TTL",
+			setup: func(size int) cache.Cacher[ids.ID, int64] {
+				return cache.NewTTL[ids.ID, int64](&mockable.Clock{}, size, time.Hour)
+			},
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -44,3 +65,53 @@ func TestInterface(t *testing.T) {
 		}
 	}
 }
+
+func TestExpiredMetric(t *testing.T) {
+	require := require.New(t)
+
+	clock := &mockable.Clock{}
+	now := time.Unix(0, 0)
+	clock.Set(now)
+
+	registry := prometheus.NewRegistry()
+	c, err := New[ids.ID, int64](
+		"",
+		registry,
+		cache.NewTTL[ids.ID, int64](clock, 2, time.Minute),
+	)
+	require.NoError(err)
+
+	c.Put(ids.ID{1}, 1)
+	c.Put(ids.ID{2}, 2)
+	require.Equal(2, c.Len())
+
+	clock.Set(now.Add(time.Minute))
+	_, ok := c.Get(ids.ID{1})
+	require.False(ok)
+
+	metrics, err := registry.Gather()
+	require.NoError(err)
+	values := make(map[string]float64)
+	for _, metric := range metrics {
+		m := metric.GetMetric()[0]
+		values[metric.GetName()] = m.GetGauge().GetValue() + m.GetCounter().GetValue()
+	}
+	require.Equal(float64(2), values["expired"])
+	require.Zero(values["len"])
+}
+
+func TestNoExpiredMetric(t *testing.T) {
+	require := require.New(t)
+
+	registry := prometheus.NewRegistry()
+	_, err := New[ids.ID, int64](
+		"",
+		registry,
+		&cache.LRU[ids.ID, int64]{Size: 1},
+	)
+	require.NoError(err)
+
+	count, err := testutil.GatherAndCount(registry, "expired")
+	require.NoError(err)
+	require.Zero(count)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/utils/metric"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
@@ -44,6 +45,7 @@ type metrics struct {
 func (m *metrics) Initialize(
 	namespace string,
 	reg prometheus.Registerer,
+	cacher any,
 ) error {
 	errs := wrappers.Errs{}
 	m.get = newAveragerMetric(namespace, "get", reg, &errs)
@@ -66,5 +68,17 @@ func (m *metrics) Initialize(
 	errs.Add(reg.Register(m.portionFilled))
 	m.hit = newCounterMetric(namespace, "hit", reg, &errs)
 	m.miss = newCounterMetric(namespace, "miss", reg, &errs)
+	if expirer, ok := cacher.(cache.Expirer); ok {
+		errs.Add(reg.Register(prometheus.NewCounterFunc(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "expired",
+				Help:      "number of entries that expired",
+			},
+			func() float64 {
+				return float64(expirer.Expired())
+			},
+		)))
+	}
 	return errs.Err
 }
@@ -0,0 +1,60 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownPolicy = errors.New("unknown cache policy")
+
+// Policy determines which elements a cache evicts when it's full.
+type Policy string
+
+const (
+	// LRUPolicy evicts the least recently used element.
+	LRUPolicy Policy = "lru"
+	// ARCPolicy evicts elements based on the Adaptive Replacement Cache
+	// policy, which keeps frequently used elements when many elements are
+	// each used once, such as during a scan.
+	ARCPolicy Policy = "arc"
+)
+
+// Verify returns an error if [p] isn't a known policy.
+// The empty policy is treated as [LRUPolicy].
+func (p Policy) Verify() error {
+	switch p {
+	case "", LRUPolicy, ARCPolicy:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownPolicy, p)
+	}
+}
+
+// New returns a cache that holds at most [size] elements and evicts them
+// based on [policy].
+func New[K comparable, V any](policy Policy, size int) (Cacher[K, V], error) {
+	switch policy {
+	case "", LRUPolicy:
+		return &LRU[K, V]{Size: size}, nil
+	case ARCPolicy:
+		return NewARC[K, V](size), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPolicy, policy)
+	}
+}
+
+// NewSized returns a cache that holds elements with a total size of at most
+// [maxSize] and evicts them based on [policy].
+func NewSized[K comparable, V any](policy Policy, maxSize int, size func(K, V) int) (Cacher[K, V], error) {
+	switch policy {
+	case "", LRUPolicy:
+		return NewSizedLRU[K, V](maxSize, size), nil
+	case ARCPolicy:
+		return NewSizedARC[K, V](maxSize, size), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPolicy, policy)
+	}
+}
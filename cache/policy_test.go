@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestPolicy(t *testing.T) {
+	tests := []struct {
+		policy      Policy
+		expectedErr error
+	}{
+		{
+			policy: "",
+		},
+		{
+			policy: LRUPolicy,
+		},
+		{
+			policy: ARCPolicy,
+		},
+		{
+			policy:      "lfu",
+			expectedErr: ErrUnknownPolicy,
+		},
+	}
+	for _, test := range tests {
+		t.Run(string(test.policy), func(t *testing.T) {
+			require := require.New(t)
+
+			require.ErrorIs(test.policy.Verify(), test.expectedErr)
+
+			cache, err := New[ids.ID, int64](test.policy, 2)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr == nil {
+				TestEviction(t, cache)
+			}
+
+			cache, err = NewSized[ids.ID, int64](test.policy, 2*TestIntSize, TestIntSizeFunc)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr == nil {
+				TestEviction(t, cache)
+			}
+		})
+	}
+}
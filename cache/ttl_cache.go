@@ -0,0 +1,186 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/linkedhashmap"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+)
+
+var (
+	_ Cacher[struct{}, struct{}] = (*TTL[struct{}, struct{}])(nil)
+	_ Expirer                    = (*TTL[struct{}, struct{}])(nil)
+)
+
+// Expirer is implemented by caches whose elements expire.
+type Expirer interface {
+	// Expired returns the number of elements that have expired from the cache
+	// since it was created.
+	Expired() uint64
+}
+
+// TTL is a key value store with bounded size whose elements expire [ttl] after
+// they were last put into the cache. If the size is attempted to be exceeded,
+// then an element is removed from the cache before the insertion is done,
+// based on evicting the least recently used value.
+//
+// Expired elements are removed from the cache when it's used, and by
+// [ExpirePeriodically].
+type TTL[K comparable, V any] struct {
+	lock  sync.Mutex
+	clock *mockable.Clock
+	ttl   time.Duration
+	size  int
+
+	// Elements in the cache, ordered by when they were last used.
+	elements linkedhashmap.LinkedHashmap[K, V]
+	// Expiry times of the elements in the cache, ordered by when they were
+	// last put into the cache. Because every element lives for [ttl], this is
+	// also the order in which they expire.
+	expiries linkedhashmap.LinkedHashmap[K, time.Time]
+	expired  uint64
+}
+
+// NewTTL returns a cache that holds at most [size] elements, each of which
+// expires [ttl] after it was put into the cache, according to [clock]. If
+// [size] is <= 0, it's treated as 1.
+func NewTTL[K comparable, V any](clock *mockable.Clock, size int, ttl time.Duration) *TTL[K, V] {
+	if size <= 0 {
+		size = 1
+	}
+	c := &TTL[K, V]{
+		clock: clock,
+		ttl:   ttl,
+		size:  size,
+	}
+	c.flush()
+	return c
+}
+
+func (c *TTL[K, V]) Put(key K, value V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.expire()
+	c.put(key, value)
+}
+
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.expire()
+	return c.get(key)
+}
+
+func (c *TTL[K, _]) Evict(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.expire()
+	c.evict(key)
+}
+
+func (c *TTL[_, _]) Flush() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.flush()
+}
+
+func (c *TTL[_, _]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.expire()
+	return c.elements.Len()
+}
+
+func (c *TTL[_, _]) PortionFilled() float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.expire()
+	return float64(c.elements.Len()) / float64(c.size)
+}
+
+func (c *TTL[_, _]) Expired() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.expired
+}
+
+// Expire removes all the expired elements from the cache.
+func (c *TTL[_, _]) Expire() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.expire()
+}
+
+// ExpirePeriodically removes the expired elements from the cache every
+// [frequency] until [ctx] is done.
+//
+// This ensures that the memory held by expired elements is released even if
+// the cache isn't used.
+func (c *TTL[_, _]) ExpirePeriodically(ctx context.Context, frequency time.Duration) {
+	ticker := time.NewTicker(frequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.Expire()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *TTL[K, V]) put(key K, value V) {
+	if _, ok := c.elements.Get(key); !ok && c.elements.Len() == c.size {
+		oldestKey, _, _ := c.elements.Oldest()
+		c.evict(oldestKey)
+	}
+	c.elements.Put(key, value)
+	c.expiries.Put(key, c.clock.Time().Add(c.ttl))
+}
+
+func (c *TTL[K, V]) get(key K) (V, bool) {
+	value, ok := c.elements.Get(key)
+	if !ok {
+		return utils.Zero[V](), false
+	}
+	c.elements.Put(key, value) // Mark [k] as MRU.
+	return value, true
+}
+
+func (c *TTL[K, _]) evict(key K) {
+	c.elements.Delete(key)
+	c.expiries.Delete(key)
+}
+
+func (c *TTL[K, V]) flush() {
+	c.elements = linkedhashmap.New[K, V]()
+	c.expiries = linkedhashmap.New[K, time.Time]()
+}
+
+// Removes the elements whose expiry time isn't after the current time.
+func (c *TTL[_, _]) expire() {
+	now := c.clock.Time()
+	for {
+		key, expiry, ok := c.expiries.Oldest()
+		if !ok || expiry.After(now) {
+			return
+		}
+		c.evict(key)
+		c.expired++
+	}
+}
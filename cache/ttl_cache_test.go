@@ -0,0 +1,107 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+)
+
+func TestTTL(t *testing.T) {
+	cache := NewTTL[ids.ID, int64](&mockable.Clock{}, 1, time.Hour)
+
+	TestBasic(t, cache)
+}
+
+func TestTTLEviction(t *testing.T) {
+	cache := NewTTL[ids.ID, int64](&mockable.Clock{}, 2, time.Hour)
+
+	TestEviction(t, cache)
+}
+
+func TestTTLExpiry(t *testing.T) {
+	require := require.New(t)
+
+	clock := &mockable.Clock{}
+	now := time.Unix(0, 0)
+	clock.Set(now)
+
+	cache := NewTTL[int, int](clock, 10, time.Minute)
+	cache.Put(1, 1)
+
+	clock.Set(now.Add(30 * time.Second))
+	cache.Put(2, 2)
+
+	// Getting an element doesn't extend its lifetime.
+	value, ok := cache.Get(1)
+	require.True(ok)
+	require.Equal(1, value)
+
+	clock.Set(now.Add(time.Minute))
+	_, ok = cache.Get(1)
+	require.False(ok)
+	value, ok = cache.Get(2)
+	require.True(ok)
+	require.Equal(2, value)
+	require.Equal(1, cache.Len())
+	require.Equal(uint64(1), cache.Expired())
+
+	// Putting an element again extends its lifetime.
+	cache.Put(2, 3)
+	clock.Set(now.Add(90 * time.Second))
+	value, ok = cache.Get(2)
+	require.True(ok)
+	require.Equal(3, value)
+	require.Equal(uint64(1), cache.Expired())
+
+	clock.Set(now.Add(3 * time.Minute))
+	cache.Expire()
+	require.Zero(cache.Len())
+	require.Zero(cache.PortionFilled())
+	require.Equal(uint64(2), cache.Expired())
+
+	// Evicted and flushed elements aren't counted as expired.
+	cache.Put(1, 1)
+	cache.Put(2, 2)
+	cache.Evict(1)
+	cache.Flush()
+	clock.Set(now.Add(time.Hour))
+	cache.Expire()
+	require.Equal(uint64(2), cache.Expired())
+}
+
+func TestTTLExpirePeriodically(t *testing.T) {
+	require := require.New(t)
+
+	clock := &mockable.Clock{}
+	now := time.Unix(0, 0)
+	clock.Set(now)
+
+	cache := NewTTL[int, int](clock, 10, time.Minute)
+	cache.Put(1, 1)
+	clock.Set(now.Add(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.ExpirePeriodically(ctx, time.Millisecond)
+	}()
+
+	require.Eventually(
+		func() bool {
+			return cache.Expired() == 1
+		},
+		time.Second,
+		time.Millisecond,
+	)
+	cancel()
+	<-done
+}
@@ -128,6 +128,11 @@ type Manager interface {
 	// Returns true iff the chain with the given ID exists and is finished bootstrapping
 	IsBootstrapped(ids.ID) bool
 
+	// Schedules the chain with the given ID to be re-synced from scratch the
+	// next time the node is started. Returns the generation of the chain's new
+	// database partition.
+	ScheduleResync(ids.ID) (uint64, error)
+
 	// Starts the chain creator with the initial platform chain parameters, must
 	// be called once.
 	StartChainCreator(platformChain ChainParameters) error
@@ -157,6 +162,9 @@ type chain struct {
 	VM      common.VM
 	Handler handler.Handler
 	Beacons validators.Set
+	// Generation is a lower bound on the generation of the chain's partition
+	// of the database.
+	Generation uint64
 }
 
 // ChainConfig is configuration settings for the current execution.
@@ -256,6 +264,9 @@ type manager struct {
 	// Key: Chain's ID
 	// Value: The chain
 	chains map[ids.ID]handler.Handler
+	// Key: Chain's ID
+	// Value: The parameters the chain was attempted to be created with
+	chainParams map[ids.ID]ChainParameters
 
 	// snowman++ related interface to allow validators retrieval
 	validatorState validators.State
@@ -270,6 +281,7 @@ func New(config *ManagerConfig) Manager {
 		stakingCert:            staking.CertificateFromX509(config.StakingTLSCert.Leaf),
 		subnets:                make(map[ids.ID]subnets.Subnet),
 		chains:                 make(map[ids.ID]handler.Handler),
+		chainParams:            make(map[ids.ID]ChainParameters),
 		chainsQueue:            buffer.NewUnboundedBlockingDeque[ChainParameters](initialQueueSize),
 		unblockChainCreatorCh:  make(chan struct{}),
 		chainCreatorShutdownCh: make(chan struct{}),
@@ -334,6 +346,12 @@ func (m *manager) createChain(chainParams ChainParameters) {
 	sb := m.subnets[chainParams.SubnetID]
 	m.subnetsLock.RUnlock()
 
+	// The parameters are recorded even if the chain fails to be built, so that
+	// a chain whose partition is corrupted can be re-synced.
+	m.chainsLock.Lock()
+	m.chainParams[chainParams.ID] = chainParams
+	m.chainsLock.Unlock()
+
 	// Note: buildChain builds all chain's relevant objects (notably engine and handler)
 	// but does not start their operations. Starting of the handler (which could potentially
 	// issue some internal messages), is delayed until chain dispatching is started and
@@ -423,6 +441,29 @@ func (m *manager) createChain(chainParams ChainParameters) {
 	// Tell the chain to start processing messages.
 	// If the X, P, or C Chain panics, do not attempt to recover
 	chain.Handler.Start(context.TODO(), !m.CriticalChains.Contains(chainParams.ID))
+
+	// The chain no longer uses the partitions it used before it was re-synced.
+	if chain.Generation > 0 {
+		go m.deleteStalePartitions(chainParams.ID, chain.Generation)
+	}
+}
+
+// deleteStalePartitions deletes the partitions of the database that the chain
+// [chainID] used before its partition of generation [generation].
+func (m *manager) deleteStalePartitions(chainID ids.ID, generation uint64) {
+	if err := deleteStalePartitions(m.DBManager.Current().Database, chainID, generation); err != nil {
+		m.Log.Warn("failed to delete stale partitions of re-synced chain",
+			zap.Stringer("chainID", chainID),
+			zap.Uint64("generation", generation),
+			zap.Error(err),
+		)
+		return
+	}
+	m.Log.Info("deleted stale partitions of re-synced chain",
+		zap.Stringer("chainID", chainID),
+		zap.Uint64("generation", generation),
+		zap.String("warning", StalePartitionsWarning),
+	)
 }
 
 // Create a chain
@@ -432,6 +473,13 @@ func (m *manager) buildChain(chainParams ChainParameters, sb subnets.Subnet) (*c
 	}
 	primaryAlias := m.PrimaryAliasOrDefault(chainParams.ID)
 
+	// The generation is read before the chain's partition is created, so that
+	// it is never newer than the generation of the partition.
+	generation, err := chainGeneration(m.DBManager.Current().Database, chainParams.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create this chain's data directory
 	chainDataDir := filepath.Join(m.ChainDataDir, chainParams.ID.String())
 	if err := os.MkdirAll(chainDataDir, perms.ReadWriteExecute); err != nil {
@@ -477,11 +525,15 @@ func (m *manager) buildChain(chainParams ChainParameters, sb subnets.Subnet) (*c
 			CChainID:    m.CChainID,
 			AVAXAssetID: m.AVAXAssetID,
 
-			Log:          chainLog,
-			Keystore:     m.Keystore.NewBlockchainKeyStore(chainParams.ID),
-			SharedMemory: m.AtomicMemory.NewSharedMemory(chainParams.ID),
-			BCLookup:     m,
-			Metrics:      vmMetrics,
+			Log:      chainLog,
+			Keystore: m.Keystore.NewBlockchainKeyStore(chainParams.ID),
+			SharedMemory: newSharedMemory(
+				m.AtomicMemory.NewSharedMemory(chainParams.ID),
+				m.DBManager.Current().Database,
+				chainParams.ID,
+			),
+			BCLookup: m,
+			Metrics:  vmMetrics,
 
 			WarpSigner: warp.NewSigner(m.StakingBLSKey, m.NetworkID, chainParams.ID),
 
@@ -580,6 +632,7 @@ func (m *manager) buildChain(chainParams ChainParameters, sb subnets.Subnet) (*c
 		return nil, err
 	}
 
+	chain.Generation = generation
 	return chain, nil
 }
 
@@ -608,7 +661,11 @@ func (m *manager) createAvalancheChain(
 	if err != nil {
 		return nil, err
 	}
-	prefixDBManager := meterDBManager.NewPrefixDBManager(ctx.ChainID[:])
+	chainDBPrefix, err := ChainDBPrefix(m.DBManager.Current().Database, ctx.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	prefixDBManager := meterDBManager.NewPrefixDBManager(chainDBPrefix)
 	vmDBManager := prefixDBManager.NewPrefixDBManager(vmDBPrefix)
 
 	db := prefixDBManager.Current()
@@ -754,16 +811,19 @@ func (m *manager) createAvalancheChain(
 	var (
 		minBlockDelay       = proposervm.DefaultMinBlockDelay
 		numHistoricalBlocks = proposervm.DefaultNumHistoricalBlocks
+		innerBlkCachePolicy = proposervm.DefaultInnerBlkCachePolicy
 	)
 	if subnetCfg, ok := m.SubnetConfigs[ctx.SubnetID]; ok {
 		minBlockDelay = subnetCfg.ProposerMinBlockDelay
 		numHistoricalBlocks = subnetCfg.ProposerNumHistoricalBlocks
+		innerBlkCachePolicy = subnetCfg.ProposerCachePolicy
 	}
 	m.Log.Info("creating proposervm wrapper",
 		zap.Time("activationTime", m.ApricotPhase4Time),
 		zap.Uint64("minPChainHeight", m.ApricotPhase4MinPChainHeight),
 		zap.Duration("minBlockDelay", minBlockDelay),
 		zap.Uint64("numHistoricalBlocks", numHistoricalBlocks),
+		zap.String("innerBlkCachePolicy", string(innerBlkCachePolicy)),
 	)
 
 	chainAlias := m.PrimaryAliasOrDefault(ctx.ChainID)
@@ -784,6 +844,7 @@ func (m *manager) createAvalancheChain(
 		m.ApricotPhase4MinPChainHeight,
 		minBlockDelay,
 		numHistoricalBlocks,
+		innerBlkCachePolicy,
 		m.stakingSigner,
 		m.stakingCert,
 	)
@@ -1013,7 +1074,11 @@ func (m *manager) createSnowmanChain(
 	if err != nil {
 		return nil, err
 	}
-	prefixDBManager := meterDBManager.NewPrefixDBManager(ctx.ChainID[:])
+	chainDBPrefix, err := ChainDBPrefix(m.DBManager.Current().Database, ctx.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	prefixDBManager := meterDBManager.NewPrefixDBManager(chainDBPrefix)
 	vmDBManager := prefixDBManager.NewPrefixDBManager(vmDBPrefix)
 
 	db := prefixDBManager.Current()
@@ -1109,16 +1174,19 @@ func (m *manager) createSnowmanChain(
 	var (
 		minBlockDelay       = proposervm.DefaultMinBlockDelay
 		numHistoricalBlocks = proposervm.DefaultNumHistoricalBlocks
+		innerBlkCachePolicy = proposervm.DefaultInnerBlkCachePolicy
 	)
 	if subnetCfg, ok := m.SubnetConfigs[ctx.SubnetID]; ok {
 		minBlockDelay = subnetCfg.ProposerMinBlockDelay
 		numHistoricalBlocks = subnetCfg.ProposerNumHistoricalBlocks
+		innerBlkCachePolicy = subnetCfg.ProposerCachePolicy
 	}
 	m.Log.Info("creating proposervm wrapper",
 		zap.Time("activationTime", m.ApricotPhase4Time),
 		zap.Uint64("minPChainHeight", m.ApricotPhase4MinPChainHeight),
 		zap.Duration("minBlockDelay", minBlockDelay),
 		zap.Uint64("numHistoricalBlocks", numHistoricalBlocks),
+		zap.String("innerBlkCachePolicy", string(innerBlkCachePolicy)),
 	)
 
 	chainAlias := m.PrimaryAliasOrDefault(ctx.ChainID)
@@ -1132,6 +1200,7 @@ func (m *manager) createSnowmanChain(
 		m.ApricotPhase4MinPChainHeight,
 		minBlockDelay,
 		numHistoricalBlocks,
+		innerBlkCachePolicy,
 		m.stakingSigner,
 		m.stakingCert,
 	)
@@ -1315,6 +1384,17 @@ func (m *manager) IsBootstrapped(id ids.ID) bool {
 	return chain.Context().State.Get().State == snow.NormalOp
 }
 
+func (m *manager) ScheduleResync(id ids.ID) (uint64, error) {
+	m.chainsLock.Lock()
+	chainParams, exists := m.chainParams[id]
+	m.chainsLock.Unlock()
+	if !exists {
+		return 0, fmt.Errorf("couldn't re-sync chain %s: %w", id, errChainNotCreated)
+	}
+
+	return scheduleResync(m.DBManager.Current().Database, chainParams)
+}
+
 func (m *manager) subnetsNotBootstrapped() []ids.ID {
 	m.subnetsLock.RLock()
 	defer m.subnetsLock.RUnlock()
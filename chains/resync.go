@@ -0,0 +1,233 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/chains/atomic"
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/inspect"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	// deleteBatchSize is the number of bytes deleted from a stale partition
+	// per batch.
+	deleteBatchSize = 1024 * 1024
+
+	// StalePartitionsWarning describes the keys of a re-synced chain that are
+	// left in the node's database.
+	StalePartitionsWarning = "keys that the chain's VM wrote under prefixes it created itself can't be enumerated, " +
+		"so they aren't deleted with the chain's previous partition"
+)
+
+var (
+	// ErrUsesSharedMemory is returned when re-syncing a chain that may have
+	// written to shared memory.
+	ErrUsesSharedMemory = errors.New("chain may have written to shared memory")
+
+	errChainNotCreated = errors.New("chain hasn't been created")
+
+	// sharedMemoryVMs are the VMs that write to shared memory. Their chains
+	// may have written to shared memory before the chains that write to it
+	// were recorded, so they are never re-synced.
+	sharedMemoryVMs = set.Of(
+		constants.PlatformVMID,
+		constants.AVMID,
+		constants.EVMID,
+	)
+
+	// chainGenerationsDBPrefix is the prefix of the partition of the node's
+	// database that stores the generation of each chain's partition.
+	chainGenerationsDBPrefix = []byte("chain generations")
+	// deletedGenerationsDBPrefix is the prefix of the partition of the node's
+	// database that stores, for each chain, the first generation whose
+	// partition hasn't been deleted.
+	deletedGenerationsDBPrefix = []byte("deleted chain generations")
+	// sharedMemoryChainsDBPrefix is the prefix of the partition of the node's
+	// database that stores the chains that have written to shared memory.
+	sharedMemoryChainsDBPrefix = []byte("shared memory chains")
+
+	_ atomic.SharedMemory = (*sharedMemory)(nil)
+)
+
+// ChainDBPrefix returns the prefix of the partition of the node's database
+// [db] that is given to the chain [chainID].
+//
+// Every key of a chain is derived from this prefix, so re-syncing a chain
+// only requires giving it a new prefix.
+func ChainDBPrefix(db database.Database, chainID ids.ID) ([]byte, error) {
+	generation, err := chainGeneration(db, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return chainDBPrefix(chainID, generation)
+}
+
+// scheduleResync gives the chain [chainParams] a new, empty, partition of the
+// node's database [db] the next time the chain is created. Once the node is
+// restarted, the chain syncs from scratch without affecting any other chain.
+// The previous partition of the chain is deleted once the chain is using the
+// new partition.
+//
+// Chains that may have written to shared memory can't be re-synced. Their
+// state in shared memory is shared with other chains, so it can't be reset,
+// and re-executing their blocks would apply their atomic operations twice.
+// This includes every chain of the primary network and of a VM that writes to
+// shared memory, whether or not it has been recorded as writing to it.
+//
+// Returns the generation of the new partition.
+func scheduleResync(db database.Database, chainParams ChainParameters) (uint64, error) {
+	chainID := chainParams.ID
+	if chainParams.SubnetID == constants.PrimaryNetworkID || sharedMemoryVMs.Contains(chainParams.VMID) {
+		return 0, fmt.Errorf("couldn't re-sync chain %s of VM %s: %w", chainID, chainParams.VMID, ErrUsesSharedMemory)
+	}
+
+	usesSharedMemory, err := UsesSharedMemory(db, chainID)
+	if err != nil {
+		return 0, err
+	}
+	if usesSharedMemory {
+		return 0, fmt.Errorf("couldn't re-sync chain %s: %w", chainID, ErrUsesSharedMemory)
+	}
+
+	generation, err := chainGeneration(db, chainID)
+	if err != nil {
+		return 0, err
+	}
+	generation++
+
+	generationsDB := prefixdb.New(chainGenerationsDBPrefix, db)
+	if err := database.PutUInt64(generationsDB, chainID[:], generation); err != nil {
+		return 0, fmt.Errorf("couldn't write generation of chain %s: %w", chainID, err)
+	}
+	return generation, nil
+}
+
+// MarkUsesSharedMemory records in the node's database [db] that the chain
+// [chainID] has written to shared memory, so that it is never re-synced.
+func MarkUsesSharedMemory(db database.Database, chainID ids.ID) error {
+	sharedMemoryChainsDB := prefixdb.New(sharedMemoryChainsDBPrefix, db)
+	if err := database.PutBool(sharedMemoryChainsDB, chainID[:], true); err != nil {
+		return fmt.Errorf("couldn't record that chain %s uses shared memory: %w", chainID, err)
+	}
+	return nil
+}
+
+// UsesSharedMemory returns true if the chain [chainID] has been recorded in
+// the node's database [db] as having written to shared memory.
+func UsesSharedMemory(db database.Database, chainID ids.ID) (bool, error) {
+	sharedMemoryChainsDB := prefixdb.New(sharedMemoryChainsDBPrefix, db)
+	usesSharedMemory, err := database.GetBool(sharedMemoryChainsDB, chainID[:])
+	if errors.Is(err, database.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("couldn't read whether chain %s uses shared memory: %w", chainID, err)
+	}
+	return usesSharedMemory, nil
+}
+
+// deleteStalePartitions deletes the partitions of the node's database [db]
+// that the chain [chainID] used before its partition of generation
+// [generation].
+//
+// Only the keys of the partitions that the chain manager gives to the chain,
+// and of the partitions that are known to be created in them, are deleted.
+// The prefixes of other partitions created by the chain's VM are compressed
+// into hashes, so their keys can't be enumerated and are left in place. See
+// [StalePartitionsWarning].
+func deleteStalePartitions(db database.Database, chainID ids.ID, generation uint64) error {
+	deletedDB := prefixdb.New(deletedGenerationsDBPrefix, db)
+	deleted, err := database.GetUInt64(deletedDB, chainID[:])
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("couldn't read deleted generations of chain %s: %w", chainID, err)
+	}
+
+	for ; deleted < generation; deleted++ {
+		prefix, err := chainDBPrefix(chainID, deleted)
+		if err != nil {
+			return err
+		}
+		for _, partitionPrefix := range inspect.ChainPrefixes(prefix) {
+			if err := database.ClearPrefix(db, partitionPrefix, deleteBatchSize); err != nil {
+				return fmt.Errorf("couldn't delete generation %d of chain %s: %w", deleted, chainID, err)
+			}
+		}
+
+		// The generation is only marked as deleted once all of its keys have
+		// been deleted, so that an interrupted deletion is resumed.
+		if err := database.PutUInt64(deletedDB, chainID[:], deleted+1); err != nil {
+			return fmt.Errorf("couldn't write deleted generations of chain %s: %w", chainID, err)
+		}
+	}
+	return nil
+}
+
+// chainGeneration returns the number of times the chain [chainID] has been
+// scheduled to be re-synced.
+func chainGeneration(db database.Database, chainID ids.ID) (uint64, error) {
+	generationsDB := prefixdb.New(chainGenerationsDBPrefix, db)
+	generation, err := database.GetUInt64(generationsDB, chainID[:])
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("couldn't read generation of chain %s: %w", chainID, err)
+	}
+	return generation, nil
+}
+
+// chainDBPrefix returns the prefix of the partition of generation
+// [generation] of the chain [chainID].
+func chainDBPrefix(chainID ids.ID, generation uint64) ([]byte, error) {
+	if generation == 0 {
+		// The first generation uses the chain ID as the prefix so that the
+		// partitions of chains created before re-syncing was supported are
+		// unchanged.
+		return chainID[:], nil
+	}
+
+	p := wrappers.Packer{Bytes: make([]byte, ids.IDLen+wrappers.LongLen)}
+	p.PackFixedBytes(chainID[:])
+	p.PackLong(generation)
+	return p.Bytes, p.Err
+}
+
+// sharedMemory records that its chain uses shared memory before the chain
+// first writes to it.
+type sharedMemory struct {
+	atomic.SharedMemory
+
+	db      database.Database
+	chainID ids.ID
+	marked  utils.Atomic[bool]
+}
+
+func newSharedMemory(sm atomic.SharedMemory, db database.Database, chainID ids.ID) *sharedMemory {
+	return &sharedMemory{
+		SharedMemory: sm,
+		db:           db,
+		chainID:      chainID,
+	}
+}
+
+func (s *sharedMemory) Apply(requests map[ids.ID]*atomic.Requests, batches ...database.Batch) error {
+	// The chain is marked before writing to shared memory, so that a chain is
+	// never re-synced after it has written to shared memory.
+	if len(requests) > 0 && !s.marked.Get() {
+		if err := MarkUsesSharedMemory(s.db, s.chainID); err != nil {
+			return err
+		}
+		s.marked.Set(true)
+	}
+	return s.SharedMemory.Apply(requests, batches...)
+}
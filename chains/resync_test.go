@@ -0,0 +1,254 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/chains/atomic"
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/version"
+
+	dbManager "github.com/ava-labs/avalanchego/database/manager"
+)
+
+func TestScheduleResync(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	chainID := ids.GenerateTestID()
+	otherChainID := ids.GenerateTestID()
+	chainParams := ChainParameters{
+		ID:       chainID,
+		SubnetID: ids.GenerateTestID(),
+		VMID:     ids.GenerateTestID(),
+	}
+
+	// Chains that have never been re-synced are partitioned by their ID.
+	prefix, err := ChainDBPrefix(db, chainID)
+	require.NoError(err)
+	require.Equal(chainID[:], prefix)
+
+	generation, err := scheduleResync(db, chainParams)
+	require.NoError(err)
+	require.Equal(uint64(1), generation)
+
+	firstPrefix, err := ChainDBPrefix(db, chainID)
+	require.NoError(err)
+	require.NotEqual(prefix, firstPrefix)
+
+	generation, err = scheduleResync(db, chainParams)
+	require.NoError(err)
+	require.Equal(uint64(2), generation)
+
+	secondPrefix, err := ChainDBPrefix(db, chainID)
+	require.NoError(err)
+	require.NotEqual(prefix, secondPrefix)
+	require.NotEqual(firstPrefix, secondPrefix)
+
+	// Other chains are unaffected.
+	prefix, err = ChainDBPrefix(db, otherChainID)
+	require.NoError(err)
+	require.Equal(otherChainID[:], prefix)
+}
+
+func TestScheduleResyncSharedMemoryChain(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	chainID := ids.GenerateTestID()
+	peerChainID := ids.GenerateTestID()
+
+	sm := newSharedMemory(
+		atomic.NewMemory(memdb.New()).NewSharedMemory(chainID),
+		db,
+		chainID,
+	)
+
+	// Reading from shared memory doesn't prevent the chain from being
+	// re-synced.
+	_, err := sm.Get(peerChainID, [][]byte{{1}})
+	require.ErrorIs(err, database.ErrNotFound)
+
+	usesSharedMemory, err := UsesSharedMemory(db, chainID)
+	require.NoError(err)
+	require.False(usesSharedMemory)
+
+	require.NoError(sm.Apply(map[ids.ID]*atomic.Requests{
+		peerChainID: {
+			PutRequests: []*atomic.Element{{
+				Key:   []byte{1},
+				Value: []byte{2},
+			}},
+		},
+	}))
+
+	usesSharedMemory, err = UsesSharedMemory(db, chainID)
+	require.NoError(err)
+	require.True(usesSharedMemory)
+
+	_, err = scheduleResync(db, ChainParameters{
+		ID:       chainID,
+		SubnetID: ids.GenerateTestID(),
+		VMID:     ids.GenerateTestID(),
+	})
+	require.ErrorIs(err, ErrUsesSharedMemory)
+
+	prefix, err := ChainDBPrefix(db, chainID)
+	require.NoError(err)
+	require.Equal(chainID[:], prefix)
+}
+
+func TestScheduleResyncIneligibleChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		subnetID ids.ID
+		vmID     ids.ID
+	}{
+		{
+			name:     "primary network chain",
+			subnetID: constants.PrimaryNetworkID,
+			vmID:     ids.GenerateTestID(),
+		},
+		{
+			name:     "platform vm",
+			subnetID: ids.GenerateTestID(),
+			vmID:     constants.PlatformVMID,
+		},
+		{
+			name:     "avm",
+			subnetID: ids.GenerateTestID(),
+			vmID:     constants.AVMID,
+		},
+		{
+			name:     "evm",
+			subnetID: ids.GenerateTestID(),
+			vmID:     constants.EVMID,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db := memdb.New()
+			chainID := ids.GenerateTestID()
+
+			// The chain is refused even though it was never recorded as
+			// writing to shared memory.
+			_, err := scheduleResync(db, ChainParameters{
+				ID:       chainID,
+				SubnetID: test.subnetID,
+				VMID:     test.vmID,
+			})
+			require.ErrorIs(err, ErrUsesSharedMemory)
+
+			prefix, err := ChainDBPrefix(db, chainID)
+			require.NoError(err)
+			require.Equal(chainID[:], prefix)
+		})
+	}
+}
+
+func TestManagerScheduleResync(t *testing.T) {
+	require := require.New(t)
+
+	m := &manager{
+		ManagerConfig: ManagerConfig{
+			DBManager: dbManager.NewMemDB(version.Semantic1_0_0),
+		},
+		chainParams: make(map[ids.ID]ChainParameters),
+	}
+
+	// Chains that haven't been created can't be re-synced, as it isn't known
+	// whether they use shared memory.
+	chainID := ids.GenerateTestID()
+	_, err := m.ScheduleResync(chainID)
+	require.ErrorIs(err, errChainNotCreated)
+
+	m.chainParams[chainID] = ChainParameters{
+		ID:       chainID,
+		SubnetID: ids.GenerateTestID(),
+		VMID:     ids.GenerateTestID(),
+	}
+	generation, err := m.ScheduleResync(chainID)
+	require.NoError(err)
+	require.Equal(uint64(1), generation)
+
+	m.chainParams[constants.PlatformChainID] = ChainParameters{
+		ID:       constants.PlatformChainID,
+		SubnetID: constants.PrimaryNetworkID,
+		VMID:     constants.PlatformVMID,
+	}
+	_, err = m.ScheduleResync(constants.PlatformChainID)
+	require.ErrorIs(err, ErrUsesSharedMemory)
+}
+
+func TestDeleteStalePartitions(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	chainID := ids.GenerateTestID()
+	otherChainID := ids.GenerateTestID()
+
+	// Writes a key to the chain's partition, and to its VM's partition.
+	writeChain := func(chainID ids.ID) (*prefixdb.Database, *prefixdb.Database) {
+		prefix, err := ChainDBPrefix(db, chainID)
+		require.NoError(err)
+		chainDB := prefixdb.New(prefix, db)
+		vmDB := prefixdb.New(vmDBPrefix, chainDB)
+		require.NoError(chainDB.Put([]byte{1}, []byte{2}))
+		require.NoError(vmDB.Put([]byte{3}, []byte{4}))
+		return chainDB, vmDB
+	}
+
+	chainParams := ChainParameters{
+		ID:       chainID,
+		SubnetID: ids.GenerateTestID(),
+		VMID:     ids.GenerateTestID(),
+	}
+
+	otherChainDB, otherVMDB := writeChain(otherChainID)
+	staleChainDB, staleVMDB := writeChain(chainID)
+
+	_, err := scheduleResync(db, chainParams)
+	require.NoError(err)
+	firstChainDB, firstVMDB := writeChain(chainID)
+
+	_, err = scheduleResync(db, chainParams)
+	require.NoError(err)
+	chainDB, vmDB := writeChain(chainID)
+
+	// Only the generations before the given generation are deleted.
+	require.NoError(deleteStalePartitions(db, chainID, 1))
+	for _, db := range []database.Database{staleChainDB, staleVMDB} {
+		isEmpty, err := database.IsEmpty(db)
+		require.NoError(err)
+		require.True(isEmpty)
+	}
+	for _, db := range []database.Database{firstChainDB, firstVMDB} {
+		isEmpty, err := database.IsEmpty(db)
+		require.NoError(err)
+		require.False(isEmpty)
+	}
+
+	require.NoError(deleteStalePartitions(db, chainID, 2))
+	for _, db := range []database.Database{firstChainDB, firstVMDB} {
+		isEmpty, err := database.IsEmpty(db)
+		require.NoError(err)
+		require.True(isEmpty)
+	}
+
+	// The partitions in use are unaffected.
+	for _, db := range []database.Database{chainDB, vmDB, otherChainDB, otherVMDB} {
+		isEmpty, err := database.IsEmpty(db)
+		require.NoError(err)
+		require.False(isEmpty)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/avalanchego/codec/codecgen"
+)
+
+var errTypeRequired = errors.New("--type is required")
+
+func main() {
+	var (
+		typeNames []string
+		dir       string
+		output    string
+	)
+	rootCmd := &cobra.Command{
+		Use:   "codecgen",
+		Short: "Generates code that marshals structs without reflection",
+		Long: "Generates the codec.Marshaler and codec.Unmarshaler methods of the structs named by --type in the package in --dir. " +
+			"The generated code produces the same bytes as codec/linearcodec. Intended to be run by go:generate.",
+		RunE: func(*cobra.Command, []string) error {
+			if len(typeNames) == 0 {
+				return errTypeRequired
+			}
+			if len(output) == 0 {
+				output = strings.ToLower(typeNames[0]) + "_codec.go"
+			}
+			if !filepath.IsAbs(output) {
+				output = filepath.Join(dir, output)
+			}
+
+			src, err := codecgen.Generate(dir, typeNames, output)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(output, src, 0o644) // #nosec G306
+		},
+	}
+	rootCmd.Flags().StringSliceVar(&typeNames, "type", nil, "comma-separated names of the structs to generate code for")
+	rootCmd.Flags().StringVar(&dir, "dir", ".", "directory of the package that declares the structs")
+	rootCmd.Flags().StringVar(&output, "output", "", "file to write the generated code to, relative to --dir. Defaults to <type>_codec.go")
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "codecgen failed: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,662 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package codecgen generates code that (un)marshals structs the same way as
+// codec/linearcodec does, without using reflection.
+//
+// For each struct, the generated code implements [codec.Marshaler] and
+// [codec.Unmarshaler], which the codec manager prefers over reflection. Fields
+// that the generated code doesn't handle natively, such as interfaces and
+// maps, are (un)marshaled with reflection by the [codec.ValueCodec] passed to
+// the generated code.
+package codecgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/codec/reflectcodec"
+)
+
+const idsPath = "github.com/ava-labs/avalanchego/ids"
+
+var (
+	errNoPackage       = errors.New("no package found")
+	errUnknownType     = errors.New("unknown type")
+	errNotStruct       = errors.New("type isn't a struct")
+	errGenericType     = errors.New("generic types aren't supported")
+	errUnexportedField = errors.New("serialized field is unexported")
+
+	// Fixed size arrays of bytes that are commonly serialized.
+	byteArrays = map[string]map[string]int{
+		idsPath: {
+			"ID":      32,
+			"ShortID": 20,
+			"NodeID":  20,
+		},
+	}
+)
+
+type kind int
+
+const (
+	// bool, string or an integer with a fixed size
+	kindBasic kind = iota
+	// []byte
+	kindBytes
+	// [N]byte
+	kindByteArray
+	// Slice of a type that is handled natively
+	kindSlice
+	// Struct with generated code
+	kindStruct
+	// Pointer to a struct with generated code
+	kindPointer
+	// Any other type, which is handled with reflection
+	kindValue
+)
+
+type basicType struct {
+	size   string
+	pack   string
+	unpack string
+}
+
+var basicTypes = map[string]basicType{
+	"bool":   {size: "wrappers.BoolLen", pack: "PackBool", unpack: "UnpackBool"},
+	"uint8":  {size: "wrappers.ByteLen", pack: "PackByte", unpack: "UnpackByte"},
+	"byte":   {size: "wrappers.ByteLen", pack: "PackByte", unpack: "UnpackByte"},
+	"int8":   {size: "wrappers.ByteLen", pack: "PackByte", unpack: "UnpackByte"},
+	"uint16": {size: "wrappers.ShortLen", pack: "PackShort", unpack: "UnpackShort"},
+	"int16":  {size: "wrappers.ShortLen", pack: "PackShort", unpack: "UnpackShort"},
+	"uint32": {size: "wrappers.IntLen", pack: "PackInt", unpack: "UnpackInt"},
+	"int32":  {size: "wrappers.IntLen", pack: "PackInt", unpack: "UnpackInt"},
+	"uint64": {size: "wrappers.LongLen", pack: "PackLong", unpack: "UnpackLong"},
+	"int64":  {size: "wrappers.LongLen", pack: "PackLong", unpack: "UnpackLong"},
+	"string": {pack: "PackStr", unpack: "UnpackStr"},
+}
+
+// packerTypes are the types the packer reads and writes each basic type as.
+var packerTypes = map[string]string{
+	"PackBool":  "bool",
+	"PackByte":  "uint8",
+	"PackShort": "uint16",
+	"PackInt":   "uint32",
+	"PackLong":  "uint64",
+	"PackStr":   "string",
+}
+
+// fieldType describes how a serialized field is (un)marshaled.
+type fieldType struct {
+	kind kind
+	// Go expression of the type
+	expr string
+	// Underlying basic type of kindBasic
+	basic string
+	// Length of kindByteArray
+	length int
+	// Element type of kindSlice
+	elem *fieldType
+}
+
+// fixedSize returns the size of values of [t] if it's constant.
+func (t *fieldType) fixedSize() (string, bool) {
+	switch t.kind {
+	case kindBasic:
+		if t.basic == "string" {
+			return "", false
+		}
+		return basicTypes[t.basic].size, true
+	case kindByteArray:
+		return strconv.Itoa(t.length), true
+	default:
+		return "", false
+	}
+}
+
+type field struct {
+	name        string
+	typ         *fieldType
+	maxSliceLen string
+}
+
+type generator struct {
+	pkg string
+	// Type name --> type spec of the types declared in the package
+	specs map[string]*ast.TypeSpec
+	// Type name --> file the type is declared in
+	files map[string]*ast.File
+	// Types to generate code for
+	generated map[string]bool
+
+	// Import path --> name of the imports the generated code uses
+	imports map[string]string
+	buf     bytes.Buffer
+	vars    int
+}
+
+// Generate returns the source of a file in the package in [dir] that contains
+// the generated code for [typeNames]. The file [output], if it exists in
+// [dir], is ignored.
+func Generate(dir string, typeNames []string, output string) ([]byte, error) {
+	g := &generator{
+		specs:     make(map[string]*ast.TypeSpec),
+		files:     make(map[string]*ast.File),
+		generated: make(map[string]bool),
+		imports: map[string]string{
+			"fmt":                                   "fmt",
+			"math":                                  "math",
+			"reflect":                               "reflect",
+			"github.com/ava-labs/avalanchego/codec": "codec",
+			"github.com/ava-labs/avalanchego/utils/wrappers": "wrappers",
+		},
+	}
+	if err := g.parse(dir, filepath.Base(output)); err != nil {
+		return nil, err
+	}
+
+	structs := make([]*ast.StructType, len(typeNames))
+	for i, typeName := range typeNames {
+		spec, ok := g.specs[typeName]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownType, typeName)
+		}
+		if spec.TypeParams != nil {
+			return nil, fmt.Errorf("%w: %s", errGenericType, typeName)
+		}
+		structType, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errNotStruct, typeName)
+		}
+		structs[i] = structType
+		g.generated[typeName] = true
+	}
+
+	var body bytes.Buffer
+	for i, typeName := range typeNames {
+		fields, err := g.fields(g.files[typeName], structs[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", typeName, err)
+		}
+		g.buf.Reset()
+		g.generate(typeName, fields)
+		body.Write(g.buf.Bytes())
+	}
+
+	g.buf.Reset()
+	g.printf("// Code generated by codecgen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkg)
+	g.printf("import (\n")
+	for _, importPath := range g.usedImports(body.Bytes()) {
+		name := g.imports[importPath]
+		switch {
+		case importPath == "":
+			g.printf("\n")
+		case name == path.Base(importPath):
+			g.printf("%q\n", importPath)
+		default:
+			g.printf("%s %q\n", name, importPath)
+		}
+	}
+	g.printf(")\n")
+	g.buf.Write(body.Bytes())
+	return format.Source(g.buf.Bytes())
+}
+
+// parse the non-test files in [dir], other than [output].
+func (g *generator) parse(dir string, output string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() ||
+			name == output ||
+			!strings.HasSuffix(name, ".go") ||
+			strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		g.pkg = file.Name.Name
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				g.specs[typeSpec.Name.Name] = typeSpec
+				g.files[typeSpec.Name.Name] = file
+			}
+		}
+	}
+	if g.pkg == "" {
+		return fmt.Errorf("%w in %s", errNoPackage, dir)
+	}
+	return nil
+}
+
+// fields returns the serialized fields of [structType], in the order they are
+// serialized.
+func (g *generator) fields(file *ast.File, structType *ast.StructType) ([]field, error) {
+	var fields []field
+	for _, astField := range structType.Fields.List {
+		if astField.Tag == nil {
+			continue
+		}
+		tagValue, err := strconv.Unquote(astField.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		tag := reflect.StructTag(tagValue)
+		if tag.Get(reflectcodec.DefaultTagName) != reflectcodec.TagValue {
+			continue
+		}
+
+		maxSliceLen := "c.MaxSliceLen()"
+		if newLen, err := strconv.ParseUint(tag.Get(reflectcodec.SliceLenTagName), 10, 31); err == nil {
+			maxSliceLen = strconv.FormatUint(newLen, 10)
+		}
+
+		typ := g.resolve(file, astField.Type)
+		names := astField.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{embeddedName(astField.Type)}
+		}
+		for _, name := range names {
+			if !name.IsExported() {
+				return nil, fmt.Errorf("%w: %s", errUnexportedField, name.Name)
+			}
+			fields = append(fields, field{
+				name:        name.Name,
+				typ:         typ,
+				maxSliceLen: maxSliceLen,
+			})
+		}
+	}
+	return fields, nil
+}
+
+// embeddedName returns the name of an embedded field of type [expr].
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel
+	case *ast.Ident:
+		return expr
+	default:
+		return ast.NewIdent("_")
+	}
+}
+
+// resolve returns how values of the type [expr], used in [file], are
+// (un)marshaled.
+func (g *generator) resolve(file *ast.File, expr ast.Expr) *fieldType {
+	value := &fieldType{
+		kind: kindValue,
+		expr: types.ExprString(expr),
+	}
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if _, ok := basicTypes[expr.Name]; ok {
+			return &fieldType{
+				kind:  kindBasic,
+				expr:  expr.Name,
+				basic: expr.Name,
+			}
+		}
+		if g.generated[expr.Name] {
+			return &fieldType{
+				kind: kindStruct,
+				expr: expr.Name,
+			}
+		}
+		spec, ok := g.specs[expr.Name]
+		if !ok || spec.TypeParams != nil || spec.Assign.IsValid() {
+			return value
+		}
+		underlying, ok := spec.Type.(*ast.Ident)
+		if !ok {
+			return value
+		}
+		if _, ok := basicTypes[underlying.Name]; !ok {
+			return value
+		}
+		return &fieldType{
+			kind:  kindBasic,
+			expr:  expr.Name,
+			basic: underlying.Name,
+		}
+	case *ast.SelectorExpr:
+		pkgName, ok := expr.X.(*ast.Ident)
+		if !ok {
+			return value
+		}
+		importPath, ok := importPath(file, pkgName.Name)
+		if !ok {
+			return value
+		}
+		length, ok := byteArrays[importPath][expr.Sel.Name]
+		if !ok {
+			return value
+		}
+		g.imports[importPath] = pkgName.Name
+		return &fieldType{
+			kind:   kindByteArray,
+			expr:   value.expr,
+			length: length,
+		}
+	case *ast.StarExpr:
+		elem, ok := expr.X.(*ast.Ident)
+		if !ok || !g.generated[elem.Name] {
+			return value
+		}
+		return &fieldType{
+			kind: kindPointer,
+			expr: elem.Name,
+		}
+	case *ast.ArrayType:
+		elem := g.resolve(file, expr.Elt)
+		isByte := elem.kind == kindBasic && (elem.expr == "byte" || elem.expr == "uint8")
+		if expr.Len == nil {
+			switch {
+			case isByte:
+				return &fieldType{
+					kind: kindBytes,
+					expr: value.expr,
+				}
+			case elem.kind == kindValue:
+				return value
+			default:
+				return &fieldType{
+					kind: kindSlice,
+					expr: value.expr,
+					elem: elem,
+				}
+			}
+		}
+		lengthLit, ok := expr.Len.(*ast.BasicLit)
+		if !ok || lengthLit.Kind != token.INT || !isByte {
+			return value
+		}
+		length, err := strconv.ParseInt(lengthLit.Value, 0, 32)
+		if err != nil {
+			return value
+		}
+		return &fieldType{
+			kind:   kindByteArray,
+			expr:   value.expr,
+			length: int(length),
+		}
+	default:
+		return value
+	}
+}
+
+// importPath returns the path of the import named [name] in [file].
+func importPath(file *ast.File, name string) (string, bool) {
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		importName := path.Base(importPath)
+		if imp.Name != nil {
+			importName = imp.Name.Name
+		}
+		if importName == name {
+			return importPath, true
+		}
+	}
+	return "", false
+}
+
+// usedImports returns the imports that are used by [body], in the order
+// gofmt sorts them.
+func (g *generator) usedImports(body []byte) []string {
+	var (
+		std   []string
+		other []string
+	)
+	for importPath, name := range g.imports {
+		used := regexp.MustCompile(`(^|[^\w.])` + regexp.QuoteMeta(name) + `\.`)
+		if !used.Match(body) {
+			continue
+		}
+		if strings.Contains(importPath, ".") {
+			other = append(other, importPath)
+		} else {
+			std = append(std, importPath)
+		}
+	}
+	slices.Sort(std)
+	slices.Sort(other)
+	if len(std) > 0 && len(other) > 0 {
+		// Separate the standard library imports from the other imports.
+		std = append(std, "")
+	}
+	return append(std, other...)
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// newVar returns a variable name that isn't used by the generated code yet.
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+	return fmt.Sprintf("%s%d", prefix, g.vars)
+}
+
+func (g *generator) generate(typeName string, fields []field) {
+	g.printf("\nfunc (*%s) CodecType() reflect.Type {\n", typeName)
+	g.printf("return reflect.TypeOf((*%s)(nil))\n", typeName)
+	g.printf("}\n")
+
+	g.vars = 0
+	g.printf("\nfunc (v *%s) SizeCodec(c codec.ValueCodec) (int, error) {\n", typeName)
+	g.printf("size := 0\n")
+	for _, f := range fields {
+		g.size(f.typ, "v."+f.name)
+	}
+	g.printf("return size, nil\n")
+	g.printf("}\n")
+
+	g.vars = 0
+	g.printf("\nfunc (v *%s) MarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {\n", typeName)
+	for _, f := range fields {
+		g.marshal(f.typ, "v."+f.name, f.maxSliceLen)
+	}
+	g.printf("return p.Err\n")
+	g.printf("}\n")
+
+	g.vars = 0
+	g.printf("\nfunc (v *%s) UnmarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {\n", typeName)
+	for _, f := range fields {
+		g.unmarshal(f.typ, "v."+f.name, f.maxSliceLen)
+	}
+	g.printf("return nil\n")
+	g.printf("}\n")
+}
+
+// size adds the size of [x], of type [t], to the size variable.
+func (g *generator) size(t *fieldType, x string) {
+	switch t.kind {
+	case kindBasic:
+		if t.basic == "string" {
+			g.printf("size += wrappers.StringLen(string(%s))\n", x)
+			return
+		}
+		g.printf("size += %s\n", basicTypes[t.basic].size)
+	case kindBytes:
+		g.printf("size += wrappers.IntLen + len(%s)\n", x)
+	case kindByteArray:
+		g.printf("size += %d\n", t.length)
+	case kindSlice:
+		g.printf("size += wrappers.IntLen\n")
+		if elemSize, ok := t.elem.fixedSize(); ok {
+			g.printf("size += len(%s) * %s\n", x, elemSize)
+			return
+		}
+		i := g.newVar("i")
+		g.printf("for %s := range %s {\n", i, x)
+		g.size(t.elem, fmt.Sprintf("%s[%s]", x, i))
+		g.printf("}\n")
+	case kindPointer:
+		g.printf("if %s == nil {\n", x)
+		g.printf("return 0, codec.ErrMarshalNil\n")
+		g.printf("}\n")
+		fallthrough
+	case kindStruct:
+		s := g.newVar("size")
+		g.printf("%s, err := %s.SizeCodec(c)\n", s, x)
+		g.printf("if err != nil {\n")
+		g.printf("return 0, err\n")
+		g.printf("}\n")
+		g.printf("size += %s\n", s)
+	default:
+		s := g.newVar("size")
+		g.printf("%s, err := c.SizeValue(&%s)\n", s, x)
+		g.printf("if err != nil {\n")
+		g.printf("return 0, err\n")
+		g.printf("}\n")
+		g.printf("size += %s\n", s)
+	}
+}
+
+// marshal writes [x], of type [t], to the packer. Slices may have at most
+// [maxSliceLen] elements.
+func (g *generator) marshal(t *fieldType, x string, maxSliceLen string) {
+	switch t.kind {
+	case kindBasic:
+		pack := basicTypes[t.basic].pack
+		g.printf("p.%s(%s(%s))\n", pack, packerTypes[pack], x)
+	case kindBytes:
+		g.marshalSliceLen(x, maxSliceLen)
+		g.printf("p.PackFixedBytes(%s)\n", x)
+	case kindByteArray:
+		g.printf("if %d > c.MaxSliceLen() {\n", t.length)
+		g.printf("return fmt.Errorf(\"%%w; array length, %%d, exceeds maximum length, %%d\", codec.ErrMaxSliceLenExceeded, %d, c.MaxSliceLen())\n", t.length)
+		g.printf("}\n")
+		g.printf("p.PackFixedBytes(%s[:])\n", x)
+	case kindSlice:
+		g.marshalSliceLen(x, maxSliceLen)
+		i := g.newVar("i")
+		g.printf("for %s := range %s {\n", i, x)
+		g.marshal(t.elem, fmt.Sprintf("%s[%s]", x, i), "c.MaxSliceLen()")
+		g.printf("}\n")
+	case kindPointer:
+		g.printf("if %s == nil {\n", x)
+		g.printf("return codec.ErrMarshalNil\n")
+		g.printf("}\n")
+		fallthrough
+	case kindStruct:
+		g.printf("if err := %s.MarshalCodec(c, p); err != nil {\n", x)
+		g.printf("return err\n")
+		g.printf("}\n")
+	default:
+		g.printf("if err := c.MarshalValue(&%s, p, %s); err != nil {\n", x, maxSliceLen)
+		g.printf("return err\n")
+		g.printf("}\n")
+	}
+}
+
+func (g *generator) marshalSliceLen(x string, maxSliceLen string) {
+	g.printf("if uint32(len(%s)) > %s {\n", x, maxSliceLen)
+	g.printf("return fmt.Errorf(\"%%w; slice length, %%d, exceeds maximum length, %%d\", codec.ErrMaxSliceLenExceeded, len(%s), %s)\n", x, maxSliceLen)
+	g.printf("}\n")
+	g.printf("p.PackInt(uint32(len(%s)))\n", x)
+	g.printf("if p.Err != nil {\n")
+	g.printf("return p.Err\n")
+	g.printf("}\n")
+}
+
+// unmarshal reads [x], of type [t], from the packer. Slices may have at most
+// [maxSliceLen] elements.
+func (g *generator) unmarshal(t *fieldType, x string, maxSliceLen string) {
+	switch t.kind {
+	case kindBasic:
+		g.printf("%s = %s(p.%s())\n", x, t.expr, basicTypes[t.basic].unpack)
+		g.printf("if p.Err != nil {\n")
+		g.printf("return fmt.Errorf(\"couldn't unmarshal %s: %%w\", p.Err)\n", packerTypeName(t.basic))
+		g.printf("}\n")
+	case kindBytes:
+		numElts := g.unmarshalSliceLen(maxSliceLen)
+		g.printf("%s = p.UnpackFixedBytes(int(%s))\n", x, numElts)
+		g.printf("if p.Err != nil {\n")
+		g.printf("return p.Err\n")
+		g.printf("}\n")
+	case kindByteArray:
+		g.printf("copy(%s[:], p.UnpackFixedBytes(%d))\n", x, t.length)
+		g.printf("if p.Err != nil {\n")
+		g.printf("return p.Err\n")
+		g.printf("}\n")
+	case kindSlice:
+		numElts := g.unmarshalSliceLen(maxSliceLen)
+		g.printf("%s = make(%s, %s)\n", x, t.expr, numElts)
+		i := g.newVar("i")
+		g.printf("for %s := range %s {\n", i, x)
+		g.unmarshal(t.elem, fmt.Sprintf("%s[%s]", x, i), "c.MaxSliceLen()")
+		g.printf("}\n")
+	case kindPointer:
+		g.printf("%s = new(%s)\n", x, t.expr)
+		fallthrough
+	case kindStruct:
+		g.printf("if err := %s.UnmarshalCodec(c, p); err != nil {\n", x)
+		g.printf("return fmt.Errorf(\"couldn't unmarshal struct: %%w\", err)\n")
+		g.printf("}\n")
+	default:
+		g.printf("if err := c.UnmarshalValue(p, &%s, %s); err != nil {\n", x, maxSliceLen)
+		g.printf("return err\n")
+		g.printf("}\n")
+	}
+}
+
+// unmarshalSliceLen reads the length of a slice from the packer, and returns
+// the name of the variable it's stored in.
+func (g *generator) unmarshalSliceLen(maxSliceLen string) string {
+	numElts := g.newVar("numElts")
+	g.printf("%s := p.UnpackInt()\n", numElts)
+	g.printf("if p.Err != nil {\n")
+	g.printf("return fmt.Errorf(\"couldn't unmarshal slice: %%w\", p.Err)\n")
+	g.printf("}\n")
+	g.printf("if %s > %s {\n", numElts, maxSliceLen)
+	g.printf("return fmt.Errorf(\"%%w; array length, %%d, exceeds maximum length, %%d\", codec.ErrMaxSliceLenExceeded, %s, %s)\n", numElts, maxSliceLen)
+	g.printf("}\n")
+	g.printf("if %s > math.MaxInt32 {\n", numElts)
+	g.printf("return fmt.Errorf(\"%%w; array length, %%d, exceeds maximum length, %%d\", codec.ErrMaxSliceLenExceeded, %s, math.MaxInt32)\n", numElts)
+	g.printf("}\n")
+	return numElts
+}
+
+// packerTypeName returns the name of [basic] in unmarshaling errors.
+func packerTypeName(basic string) string {
+	if basic == "byte" {
+		return "uint8"
+	}
+	return basic
+}
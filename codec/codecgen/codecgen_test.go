@@ -0,0 +1,75 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codecgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/codec/codecgen/codecgentest"
+)
+
+// Test that the checked in generated code is up to date.
+func TestGenerateUpToDate(t *testing.T) {
+	require := require.New(t)
+
+	output := filepath.Join("codecgentest", "types_codec.go")
+	expected, err := os.ReadFile(output)
+	require.NoError(err)
+
+	src, err := Generate("codecgentest", codecgentest.Types, output)
+	require.NoError(err)
+	require.Equal(string(expected), string(src))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		typeNames   []string
+		expectedErr error
+	}{
+		{
+			name:        "unknown type",
+			src:         "package test\n",
+			typeNames:   []string{"Foo"},
+			expectedErr: errUnknownType,
+		},
+		{
+			name:        "not a struct",
+			src:         "package test\n\ntype Foo uint64\n",
+			typeNames:   []string{"Foo"},
+			expectedErr: errNotStruct,
+		},
+		{
+			name:        "generic type",
+			src:         "package test\n\ntype Foo[T any] struct {\n\tValue T `serialize:\"true\"`\n}\n",
+			typeNames:   []string{"Foo"},
+			expectedErr: errGenericType,
+		},
+		{
+			name:        "unexported field",
+			src:         "package test\n\ntype Foo struct {\n\tvalue uint64 `serialize:\"true\"`\n}\n",
+			typeNames:   []string{"Foo"},
+			expectedErr: errUnexportedField,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			dir := t.TempDir()
+			require.NoError(os.WriteFile(filepath.Join(dir, "types.go"), []byte(test.src), 0o600))
+
+			_, err := Generate(dir, test.typeNames, filepath.Join(dir, "types_codec.go"))
+			require.ErrorIs(err, test.expectedErr)
+		})
+	}
+
+	_, err := Generate(t.TempDir(), []string{"Foo"}, "types_codec.go")
+	require.ErrorIs(t, err, errNoPackage)
+}
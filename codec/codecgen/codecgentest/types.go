@@ -0,0 +1,85 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package codecgentest contains types with code generated by codecgen, which
+// are used to test that the generated code is equivalent to reflection.
+package codecgentest
+
+import "github.com/ava-labs/avalanchego/ids"
+
+//go:generate go run github.com/ava-labs/avalanchego/codec/codecgen/cmd --type Outer,Inner,Embedded,Impl --output types_codec.go
+
+// Types is the types that have generated code.
+var Types = []string{"Outer", "Inner", "Embedded", "Impl"}
+
+var (
+	_ Interface = (*Impl)(nil)
+	_ Interface = (*OtherImpl)(nil)
+)
+
+type Status uint32
+
+type Interface interface {
+	Verify() error
+}
+
+type Impl struct {
+	Value uint64 `serialize:"true"`
+}
+
+func (*Impl) Verify() error {
+	return nil
+}
+
+// OtherImpl doesn't have generated code.
+type OtherImpl struct {
+	Values []string `serialize:"true"`
+}
+
+func (*OtherImpl) Verify() error {
+	return nil
+}
+
+type Embedded struct {
+	Memo []byte `serialize:"true"`
+}
+
+type Inner struct {
+	ID     ids.ID `serialize:"true"`
+	Amount uint64 `serialize:"true"`
+}
+
+type Outer struct {
+	Embedded `serialize:"true"`
+
+	Bool   bool   `serialize:"true"`
+	Uint8  uint8  `serialize:"true"`
+	Int8   int8   `serialize:"true"`
+	Uint16 uint16 `serialize:"true"`
+	Int16  int16  `serialize:"true"`
+	Uint32 uint32 `serialize:"true"`
+	Int32  int32  `serialize:"true"`
+	Uint64 uint64 `serialize:"true"`
+	Int64  int64  `serialize:"true"`
+	String string `serialize:"true"`
+	Status Status `serialize:"true"`
+
+	Bytes      []byte            `serialize:"true"`
+	Array      [4]byte           `serialize:"true"`
+	NodeID     ids.NodeID        `serialize:"true"`
+	ShortBytes []byte            `serialize:"true" len:"4"`
+	Uint32s    []uint32          `serialize:"true"`
+	Strings    []string          `serialize:"true"`
+	IDs        []ids.ID          `serialize:"true"`
+	Nested     [][]byte          `serialize:"true"`
+	Inners     []Inner           `serialize:"true"`
+	Inner      Inner             `serialize:"true"`
+	InnerPtr   *Inner            `serialize:"true"`
+	Interface  Interface         `serialize:"true"`
+	Interfaces []Interface       `serialize:"true" len:"2"`
+	Map        map[uint32]string `serialize:"true"`
+	Uint16s    [2]uint16         `serialize:"true"`
+
+	NotSerialized uint64
+	Other         uint64 `json:"other"`
+}
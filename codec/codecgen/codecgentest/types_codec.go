@@ -0,0 +1,515 @@
+// Code generated by codecgen. DO NOT EDIT.
+
+package codecgentest
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+func (*Outer) CodecType() reflect.Type {
+	return reflect.TypeOf((*Outer)(nil))
+}
+
+func (v *Outer) SizeCodec(c codec.ValueCodec) (int, error) {
+	size := 0
+	size1, err := v.Embedded.SizeCodec(c)
+	if err != nil {
+		return 0, err
+	}
+	size += size1
+	size += wrappers.BoolLen
+	size += wrappers.ByteLen
+	size += wrappers.ByteLen
+	size += wrappers.ShortLen
+	size += wrappers.ShortLen
+	size += wrappers.IntLen
+	size += wrappers.IntLen
+	size += wrappers.LongLen
+	size += wrappers.LongLen
+	size += wrappers.StringLen(string(v.String))
+	size += wrappers.IntLen
+	size += wrappers.IntLen + len(v.Bytes)
+	size += 4
+	size += 20
+	size += wrappers.IntLen + len(v.ShortBytes)
+	size += wrappers.IntLen
+	size += len(v.Uint32s) * wrappers.IntLen
+	size += wrappers.IntLen
+	for i2 := range v.Strings {
+		size += wrappers.StringLen(string(v.Strings[i2]))
+	}
+	size += wrappers.IntLen
+	size += len(v.IDs) * 32
+	size += wrappers.IntLen
+	for i3 := range v.Nested {
+		size += wrappers.IntLen + len(v.Nested[i3])
+	}
+	size += wrappers.IntLen
+	for i4 := range v.Inners {
+		size5, err := v.Inners[i4].SizeCodec(c)
+		if err != nil {
+			return 0, err
+		}
+		size += size5
+	}
+	size6, err := v.Inner.SizeCodec(c)
+	if err != nil {
+		return 0, err
+	}
+	size += size6
+	if v.InnerPtr == nil {
+		return 0, codec.ErrMarshalNil
+	}
+	size7, err := v.InnerPtr.SizeCodec(c)
+	if err != nil {
+		return 0, err
+	}
+	size += size7
+	size8, err := c.SizeValue(&v.Interface)
+	if err != nil {
+		return 0, err
+	}
+	size += size8
+	size9, err := c.SizeValue(&v.Interfaces)
+	if err != nil {
+		return 0, err
+	}
+	size += size9
+	size10, err := c.SizeValue(&v.Map)
+	if err != nil {
+		return 0, err
+	}
+	size += size10
+	size11, err := c.SizeValue(&v.Uint16s)
+	if err != nil {
+		return 0, err
+	}
+	size += size11
+	return size, nil
+}
+
+func (v *Outer) MarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {
+	if err := v.Embedded.MarshalCodec(c, p); err != nil {
+		return err
+	}
+	p.PackBool(bool(v.Bool))
+	p.PackByte(uint8(v.Uint8))
+	p.PackByte(uint8(v.Int8))
+	p.PackShort(uint16(v.Uint16))
+	p.PackShort(uint16(v.Int16))
+	p.PackInt(uint32(v.Uint32))
+	p.PackInt(uint32(v.Int32))
+	p.PackLong(uint64(v.Uint64))
+	p.PackLong(uint64(v.Int64))
+	p.PackStr(string(v.String))
+	p.PackInt(uint32(v.Status))
+	if uint32(len(v.Bytes)) > c.MaxSliceLen() {
+		return fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, len(v.Bytes), c.MaxSliceLen())
+	}
+	p.PackInt(uint32(len(v.Bytes)))
+	if p.Err != nil {
+		return p.Err
+	}
+	p.PackFixedBytes(v.Bytes)
+	if 4 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, 4, c.MaxSliceLen())
+	}
+	p.PackFixedBytes(v.Array[:])
+	if 20 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, 20, c.MaxSliceLen())
+	}
+	p.PackFixedBytes(v.NodeID[:])
+	if uint32(len(v.ShortBytes)) > 4 {
+		return fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, len(v.ShortBytes), 4)
+	}
+	p.PackInt(uint32(len(v.ShortBytes)))
+	if p.Err != nil {
+		return p.Err
+	}
+	p.PackFixedBytes(v.ShortBytes)
+	if uint32(len(v.Uint32s)) > c.MaxSliceLen() {
+		return fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, len(v.Uint32s), c.MaxSliceLen())
+	}
+	p.PackInt(uint32(len(v.Uint32s)))
+	if p.Err != nil {
+		return p.Err
+	}
+	for i1 := range v.Uint32s {
+		p.PackInt(uint32(v.Uint32s[i1]))
+	}
+	if uint32(len(v.Strings)) > c.MaxSliceLen() {
+		return fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, len(v.Strings), c.MaxSliceLen())
+	}
+	p.PackInt(uint32(len(v.Strings)))
+	if p.Err != nil {
+		return p.Err
+	}
+	for i2 := range v.Strings {
+		p.PackStr(string(v.Strings[i2]))
+	}
+	if uint32(len(v.IDs)) > c.MaxSliceLen() {
+		return fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, len(v.IDs), c.MaxSliceLen())
+	}
+	p.PackInt(uint32(len(v.IDs)))
+	if p.Err != nil {
+		return p.Err
+	}
+	for i3 := range v.IDs {
+		if 32 > c.MaxSliceLen() {
+			return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, 32, c.MaxSliceLen())
+		}
+		p.PackFixedBytes(v.IDs[i3][:])
+	}
+	if uint32(len(v.Nested)) > c.MaxSliceLen() {
+		return fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, len(v.Nested), c.MaxSliceLen())
+	}
+	p.PackInt(uint32(len(v.Nested)))
+	if p.Err != nil {
+		return p.Err
+	}
+	for i4 := range v.Nested {
+		if uint32(len(v.Nested[i4])) > c.MaxSliceLen() {
+			return fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, len(v.Nested[i4]), c.MaxSliceLen())
+		}
+		p.PackInt(uint32(len(v.Nested[i4])))
+		if p.Err != nil {
+			return p.Err
+		}
+		p.PackFixedBytes(v.Nested[i4])
+	}
+	if uint32(len(v.Inners)) > c.MaxSliceLen() {
+		return fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, len(v.Inners), c.MaxSliceLen())
+	}
+	p.PackInt(uint32(len(v.Inners)))
+	if p.Err != nil {
+		return p.Err
+	}
+	for i5 := range v.Inners {
+		if err := v.Inners[i5].MarshalCodec(c, p); err != nil {
+			return err
+		}
+	}
+	if err := v.Inner.MarshalCodec(c, p); err != nil {
+		return err
+	}
+	if v.InnerPtr == nil {
+		return codec.ErrMarshalNil
+	}
+	if err := v.InnerPtr.MarshalCodec(c, p); err != nil {
+		return err
+	}
+	if err := c.MarshalValue(&v.Interface, p, c.MaxSliceLen()); err != nil {
+		return err
+	}
+	if err := c.MarshalValue(&v.Interfaces, p, 2); err != nil {
+		return err
+	}
+	if err := c.MarshalValue(&v.Map, p, c.MaxSliceLen()); err != nil {
+		return err
+	}
+	if err := c.MarshalValue(&v.Uint16s, p, c.MaxSliceLen()); err != nil {
+		return err
+	}
+	return p.Err
+}
+
+func (v *Outer) UnmarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {
+	if err := v.Embedded.UnmarshalCodec(c, p); err != nil {
+		return fmt.Errorf("couldn't unmarshal struct: %w", err)
+	}
+	v.Bool = bool(p.UnpackBool())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal bool: %w", p.Err)
+	}
+	v.Uint8 = uint8(p.UnpackByte())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal uint8: %w", p.Err)
+	}
+	v.Int8 = int8(p.UnpackByte())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal int8: %w", p.Err)
+	}
+	v.Uint16 = uint16(p.UnpackShort())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal uint16: %w", p.Err)
+	}
+	v.Int16 = int16(p.UnpackShort())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal int16: %w", p.Err)
+	}
+	v.Uint32 = uint32(p.UnpackInt())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal uint32: %w", p.Err)
+	}
+	v.Int32 = int32(p.UnpackInt())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal int32: %w", p.Err)
+	}
+	v.Uint64 = uint64(p.UnpackLong())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal uint64: %w", p.Err)
+	}
+	v.Int64 = int64(p.UnpackLong())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal int64: %w", p.Err)
+	}
+	v.String = string(p.UnpackStr())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal string: %w", p.Err)
+	}
+	v.Status = Status(p.UnpackInt())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal uint32: %w", p.Err)
+	}
+	numElts1 := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal slice: %w", p.Err)
+	}
+	if numElts1 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts1, c.MaxSliceLen())
+	}
+	if numElts1 > math.MaxInt32 {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts1, math.MaxInt32)
+	}
+	v.Bytes = p.UnpackFixedBytes(int(numElts1))
+	if p.Err != nil {
+		return p.Err
+	}
+	copy(v.Array[:], p.UnpackFixedBytes(4))
+	if p.Err != nil {
+		return p.Err
+	}
+	copy(v.NodeID[:], p.UnpackFixedBytes(20))
+	if p.Err != nil {
+		return p.Err
+	}
+	numElts2 := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal slice: %w", p.Err)
+	}
+	if numElts2 > 4 {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts2, 4)
+	}
+	if numElts2 > math.MaxInt32 {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts2, math.MaxInt32)
+	}
+	v.ShortBytes = p.UnpackFixedBytes(int(numElts2))
+	if p.Err != nil {
+		return p.Err
+	}
+	numElts3 := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal slice: %w", p.Err)
+	}
+	if numElts3 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts3, c.MaxSliceLen())
+	}
+	if numElts3 > math.MaxInt32 {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts3, math.MaxInt32)
+	}
+	v.Uint32s = make([]uint32, numElts3)
+	for i4 := range v.Uint32s {
+		v.Uint32s[i4] = uint32(p.UnpackInt())
+		if p.Err != nil {
+			return fmt.Errorf("couldn't unmarshal uint32: %w", p.Err)
+		}
+	}
+	numElts5 := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal slice: %w", p.Err)
+	}
+	if numElts5 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts5, c.MaxSliceLen())
+	}
+	if numElts5 > math.MaxInt32 {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts5, math.MaxInt32)
+	}
+	v.Strings = make([]string, numElts5)
+	for i6 := range v.Strings {
+		v.Strings[i6] = string(p.UnpackStr())
+		if p.Err != nil {
+			return fmt.Errorf("couldn't unmarshal string: %w", p.Err)
+		}
+	}
+	numElts7 := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal slice: %w", p.Err)
+	}
+	if numElts7 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts7, c.MaxSliceLen())
+	}
+	if numElts7 > math.MaxInt32 {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts7, math.MaxInt32)
+	}
+	v.IDs = make([]ids.ID, numElts7)
+	for i8 := range v.IDs {
+		copy(v.IDs[i8][:], p.UnpackFixedBytes(32))
+		if p.Err != nil {
+			return p.Err
+		}
+	}
+	numElts9 := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal slice: %w", p.Err)
+	}
+	if numElts9 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts9, c.MaxSliceLen())
+	}
+	if numElts9 > math.MaxInt32 {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts9, math.MaxInt32)
+	}
+	v.Nested = make([][]byte, numElts9)
+	for i10 := range v.Nested {
+		numElts11 := p.UnpackInt()
+		if p.Err != nil {
+			return fmt.Errorf("couldn't unmarshal slice: %w", p.Err)
+		}
+		if numElts11 > c.MaxSliceLen() {
+			return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts11, c.MaxSliceLen())
+		}
+		if numElts11 > math.MaxInt32 {
+			return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts11, math.MaxInt32)
+		}
+		v.Nested[i10] = p.UnpackFixedBytes(int(numElts11))
+		if p.Err != nil {
+			return p.Err
+		}
+	}
+	numElts12 := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal slice: %w", p.Err)
+	}
+	if numElts12 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts12, c.MaxSliceLen())
+	}
+	if numElts12 > math.MaxInt32 {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts12, math.MaxInt32)
+	}
+	v.Inners = make([]Inner, numElts12)
+	for i13 := range v.Inners {
+		if err := v.Inners[i13].UnmarshalCodec(c, p); err != nil {
+			return fmt.Errorf("couldn't unmarshal struct: %w", err)
+		}
+	}
+	if err := v.Inner.UnmarshalCodec(c, p); err != nil {
+		return fmt.Errorf("couldn't unmarshal struct: %w", err)
+	}
+	v.InnerPtr = new(Inner)
+	if err := v.InnerPtr.UnmarshalCodec(c, p); err != nil {
+		return fmt.Errorf("couldn't unmarshal struct: %w", err)
+	}
+	if err := c.UnmarshalValue(p, &v.Interface, c.MaxSliceLen()); err != nil {
+		return err
+	}
+	if err := c.UnmarshalValue(p, &v.Interfaces, 2); err != nil {
+		return err
+	}
+	if err := c.UnmarshalValue(p, &v.Map, c.MaxSliceLen()); err != nil {
+		return err
+	}
+	if err := c.UnmarshalValue(p, &v.Uint16s, c.MaxSliceLen()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (*Inner) CodecType() reflect.Type {
+	return reflect.TypeOf((*Inner)(nil))
+}
+
+func (v *Inner) SizeCodec(c codec.ValueCodec) (int, error) {
+	size := 0
+	size += 32
+	size += wrappers.LongLen
+	return size, nil
+}
+
+func (v *Inner) MarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {
+	if 32 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, 32, c.MaxSliceLen())
+	}
+	p.PackFixedBytes(v.ID[:])
+	p.PackLong(uint64(v.Amount))
+	return p.Err
+}
+
+func (v *Inner) UnmarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {
+	copy(v.ID[:], p.UnpackFixedBytes(32))
+	if p.Err != nil {
+		return p.Err
+	}
+	v.Amount = uint64(p.UnpackLong())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal uint64: %w", p.Err)
+	}
+	return nil
+}
+
+func (*Embedded) CodecType() reflect.Type {
+	return reflect.TypeOf((*Embedded)(nil))
+}
+
+func (v *Embedded) SizeCodec(c codec.ValueCodec) (int, error) {
+	size := 0
+	size += wrappers.IntLen + len(v.Memo)
+	return size, nil
+}
+
+func (v *Embedded) MarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {
+	if uint32(len(v.Memo)) > c.MaxSliceLen() {
+		return fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, len(v.Memo), c.MaxSliceLen())
+	}
+	p.PackInt(uint32(len(v.Memo)))
+	if p.Err != nil {
+		return p.Err
+	}
+	p.PackFixedBytes(v.Memo)
+	return p.Err
+}
+
+func (v *Embedded) UnmarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {
+	numElts1 := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal slice: %w", p.Err)
+	}
+	if numElts1 > c.MaxSliceLen() {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts1, c.MaxSliceLen())
+	}
+	if numElts1 > math.MaxInt32 {
+		return fmt.Errorf("%w; array length, %d, exceeds maximum length, %d", codec.ErrMaxSliceLenExceeded, numElts1, math.MaxInt32)
+	}
+	v.Memo = p.UnpackFixedBytes(int(numElts1))
+	if p.Err != nil {
+		return p.Err
+	}
+	return nil
+}
+
+func (*Impl) CodecType() reflect.Type {
+	return reflect.TypeOf((*Impl)(nil))
+}
+
+func (v *Impl) SizeCodec(c codec.ValueCodec) (int, error) {
+	size := 0
+	size += wrappers.LongLen
+	return size, nil
+}
+
+func (v *Impl) MarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {
+	p.PackLong(uint64(v.Value))
+	return p.Err
+}
+
+func (v *Impl) UnmarshalCodec(c codec.ValueCodec, p *wrappers.Packer) error {
+	v.Value = uint64(p.UnpackLong())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal uint64: %w", p.Err)
+	}
+	return nil
+}
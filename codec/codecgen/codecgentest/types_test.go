@@ -0,0 +1,257 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codecgentest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/codec/reflectcodec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const codecVersion = 0
+
+func newCodec(t testing.TB, tagNames []string, maxSliceLen uint32) (linearcodec.Codec, codec.Manager) {
+	require := require.New(t)
+
+	c := linearcodec.New(tagNames, maxSliceLen)
+	require.NoError(c.RegisterType(&Impl{}))
+	require.NoError(c.RegisterType(&OtherImpl{}))
+
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(codecVersion, c))
+	return c, manager
+}
+
+func newOuter() *Outer {
+	return &Outer{
+		Embedded: Embedded{
+			Memo: []byte("memo"),
+		},
+		Bool:       true,
+		Uint8:      1,
+		Int8:       -2,
+		Uint16:     3,
+		Int16:      -4,
+		Uint32:     5,
+		Int32:      -6,
+		Uint64:     7,
+		Int64:      -8,
+		String:     "string",
+		Status:     9,
+		Bytes:      []byte{10, 11},
+		Array:      [4]byte{12, 13, 14, 15},
+		NodeID:     ids.GenerateTestNodeID(),
+		ShortBytes: []byte{16},
+		Uint32s:    []uint32{17, 18},
+		Strings:    []string{"a", "", "b"},
+		IDs:        []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()},
+		Nested:     [][]byte{{19}, {}, {20, 21}},
+		Inners: []Inner{
+			{ID: ids.GenerateTestID(), Amount: 22},
+		},
+		Inner:    Inner{ID: ids.GenerateTestID(), Amount: 23},
+		InnerPtr: &Inner{ID: ids.GenerateTestID(), Amount: 24},
+		Interface: &OtherImpl{
+			Values: []string{"c"},
+		},
+		Interfaces: []Interface{
+			&Impl{Value: 25},
+			&OtherImpl{
+				Values: []string{},
+			},
+		},
+		Map: map[uint32]string{
+			26: "d",
+			27: "e",
+		},
+		Uint16s:       [2]uint16{28, 29},
+		NotSerialized: 30,
+		Other:         31,
+	}
+}
+
+// reflectMarshal marshals [value] with reflection, the same way the codec
+// manager does for types without generated code.
+func reflectMarshal(c codec.Codec, value interface{}) ([]byte, error) {
+	p := wrappers.Packer{
+		MaxSize: 256 * 1024,
+	}
+	p.PackShort(codecVersion)
+	err := c.MarshalInto(value, &p)
+	return p.Bytes, err
+}
+
+func TestGeneratedMarshal(t *testing.T) {
+	require := require.New(t)
+
+	c, manager := newCodec(t, []string{reflectcodec.DefaultTagName}, 1024)
+	outer := newOuter()
+
+	expectedBytes, err := reflectMarshal(c, outer)
+	require.NoError(err)
+	expectedSize, err := c.Size(outer)
+	require.NoError(err)
+
+	generatedBytes, err := manager.Marshal(codecVersion, outer)
+	require.NoError(err)
+	require.Equal(expectedBytes, generatedBytes)
+
+	generatedSize, err := manager.Size(codecVersion, outer)
+	require.NoError(err)
+	require.Equal(wrappers.ShortLen+expectedSize, generatedSize)
+	require.Len(generatedBytes, generatedSize)
+
+	parsed := &Outer{}
+	_, err = manager.Unmarshal(generatedBytes, parsed)
+	require.NoError(err)
+
+	expected := newOuter()
+	*expected = *outer
+	expected.NotSerialized = 0
+	expected.Other = 0
+	require.Equal(expected, parsed)
+
+	// Trailing bytes are rejected.
+	_, err = manager.Unmarshal(append(generatedBytes, 0), &Outer{})
+	require.ErrorIs(err, codec.ErrExtraSpace)
+}
+
+func TestGeneratedMarshalErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxSliceLen uint32
+		modify      func(*Outer)
+		expectedErr error
+	}{
+		{
+			name:        "nil pointer",
+			maxSliceLen: 1024,
+			modify: func(o *Outer) {
+				o.InnerPtr = nil
+			},
+			expectedErr: codec.ErrMarshalNil,
+		},
+		{
+			name:        "slice too long",
+			maxSliceLen: 1024,
+			modify: func(o *Outer) {
+				o.ShortBytes = make([]byte, 5)
+			},
+			expectedErr: codec.ErrMaxSliceLenExceeded,
+		},
+		{
+			name:        "array too long",
+			maxSliceLen: 16,
+			modify:      func(*Outer) {},
+			expectedErr: codec.ErrMaxSliceLenExceeded,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			c, manager := newCodec(t, []string{reflectcodec.DefaultTagName}, test.maxSliceLen)
+			outer := newOuter()
+			test.modify(outer)
+
+			_, err := reflectMarshal(c, outer)
+			require.Error(err) //nolint:forbidigo // reflection returns unexported errors
+
+			_, err = manager.Marshal(codecVersion, outer)
+			require.ErrorIs(err, test.expectedErr)
+		})
+	}
+}
+
+// Types that embed a type with generated code are marshaled with reflection,
+// rather than with the generated code of the embedded type.
+func TestEmbeddedGeneratedType(t *testing.T) {
+	require := require.New(t)
+
+	type wrapper struct {
+		Embedded `serialize:"true"`
+
+		Value uint64 `serialize:"true"`
+	}
+
+	c, manager := newCodec(t, []string{reflectcodec.DefaultTagName}, 1024)
+	value := &wrapper{
+		Embedded: Embedded{
+			Memo: []byte("memo"),
+		},
+		Value: 1,
+	}
+
+	expectedBytes, err := reflectMarshal(c, value)
+	require.NoError(err)
+	bytes, err := manager.Marshal(codecVersion, value)
+	require.NoError(err)
+	require.Equal(expectedBytes, bytes)
+
+	parsed := &wrapper{}
+	_, err = manager.Unmarshal(bytes, parsed)
+	require.NoError(err)
+	require.Equal(value, parsed)
+}
+
+// Codecs with other tags don't use the generated code.
+func TestOtherTagsUseReflection(t *testing.T) {
+	require := require.New(t)
+
+	c, manager := newCodec(t, []string{"other"}, 1024)
+	_, ok := c.(codec.ValueCodec)
+	require.False(ok)
+
+	outer := newOuter()
+	bytes, err := manager.Marshal(codecVersion, outer)
+	require.NoError(err)
+	require.Equal([]byte{0, 0}, bytes)
+}
+
+// FuzzGeneratedUnmarshal checks that the generated code and reflection parse
+// the same bytes into the same values, and marshal them into the same bytes.
+func FuzzGeneratedUnmarshal(f *testing.F) {
+	c, manager := newCodec(f, []string{reflectcodec.DefaultTagName}, ids.IDLen)
+
+	outer := newOuter()
+	outer.Strings = outer.Strings[:2]
+	bytes, err := manager.Marshal(codecVersion, outer)
+	require.NoError(f, err)
+	f.Add(bytes[wrappers.ShortLen:])
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, bytes []byte) {
+		require := require.New(t)
+
+		expected := &Outer{}
+		expectedErr := c.Unmarshal(bytes, expected)
+
+		parsed := &Outer{}
+		_, err := manager.Unmarshal(append([]byte{0, 0}, bytes...), parsed)
+		if expectedErr != nil {
+			require.Error(err) //nolint:forbidigo // reflection returns unexported errors
+			return
+		}
+		require.NoError(err)
+		require.Equal(expected, parsed)
+
+		expectedBytes, err := reflectMarshal(c, expected)
+		require.NoError(err)
+		parsedBytes, err := manager.Marshal(codecVersion, parsed)
+		require.NoError(err)
+		require.Equal(expectedBytes, parsedBytes)
+
+		expectedSize, err := c.Size(expected)
+		require.NoError(err)
+		parsedSize, err := manager.Size(codecVersion, parsed)
+		require.NoError(err)
+		require.Equal(wrappers.ShortLen+expectedSize, parsedSize)
+	})
+}
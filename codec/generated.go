@@ -0,0 +1,88 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"reflect"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// ValueCodec is a Codec that can be used by code generated by codecgen.
+//
+// Generated code (un)marshals the values that it doesn't have generated code
+// for, such as interfaces, with the ValueCodec.
+type ValueCodec interface {
+	Codec
+
+	// MaxSliceLen returns the maximum length of slices and maps whose field
+	// doesn't specify one.
+	MaxSliceLen() uint32
+
+	// SizeValue returns the size, in bytes, of the value [value] points to when
+	// it's marshaled.
+	SizeValue(value interface{}) (int, error)
+
+	// MarshalValue writes the byte representation of the value [value] points
+	// to into [p]. Slices and maps may have at most [maxSliceLen] elements.
+	MarshalValue(value interface{}, p *wrappers.Packer, maxSliceLen uint32) error
+
+	// UnmarshalValue reads a value from [p] into the value [value] points to.
+	// Slices and maps may have at most [maxSliceLen] elements.
+	UnmarshalValue(p *wrappers.Packer, value interface{}, maxSliceLen uint32) error
+}
+
+// Marshaler is implemented by pointers to types that have marshaling code
+// generated by codecgen. The generated code produces the same bytes as the
+// reflection based codec.
+type Marshaler interface {
+	// CodecType returns the type the code was generated for. Types that embed a
+	// generated type have its methods, but the methods only marshal the
+	// embedded value.
+	CodecType() reflect.Type
+
+	// SizeCodec returns the size, in bytes, of the value when it's marshaled.
+	SizeCodec(c ValueCodec) (int, error)
+
+	// MarshalCodec writes the byte representation of the value into [p].
+	MarshalCodec(c ValueCodec, p *wrappers.Packer) error
+}
+
+// Unmarshaler is implemented by pointers to types that have unmarshaling code
+// generated by codecgen.
+type Unmarshaler interface {
+	// CodecType returns the type the code was generated for.
+	CodecType() reflect.Type
+
+	// UnmarshalCodec reads the value from [p].
+	UnmarshalCodec(c ValueCodec, p *wrappers.Packer) error
+}
+
+// generatedMarshaler returns [value] as a Marshaler if [c] can be used by
+// generated code and [value] has generated code.
+func generatedMarshaler(c Codec, value interface{}) (ValueCodec, Marshaler, bool) {
+	vc, ok := c.(ValueCodec)
+	if !ok {
+		return nil, nil, false
+	}
+	m, ok := value.(Marshaler)
+	if !ok || m.CodecType() != reflect.TypeOf(value) {
+		return nil, nil, false
+	}
+	return vc, m, true
+}
+
+// generatedUnmarshaler returns [dest] as an Unmarshaler if [c] can be used by
+// generated code and [dest] has generated code.
+func generatedUnmarshaler(c Codec, dest interface{}) (ValueCodec, Unmarshaler, bool) {
+	vc, ok := c.(ValueCodec)
+	if !ok {
+		return nil, nil, false
+	}
+	u, ok := dest.(Unmarshaler)
+	if !ok || u.CodecType() != reflect.TypeOf(dest) {
+		return nil, nil, false
+	}
+	return vc, u, true
+}
@@ -20,7 +20,7 @@ const (
 
 var (
 	_ Codec              = (*linearCodec)(nil)
-	_ codec.Codec        = (*linearCodec)(nil)
+	_ codec.ValueCodec   = (*linearCodec)(nil)
 	_ codec.Registry     = (*linearCodec)(nil)
 	_ codec.GeneralCodec = (*linearCodec)(nil)
 )
@@ -34,7 +34,7 @@ type Codec interface {
 
 // Codec handles marshaling and unmarshaling of structs
 type linearCodec struct {
-	codec.Codec
+	codec.ValueCodec
 
 	lock         sync.RWMutex
 	nextTypeID   uint32
@@ -50,10 +50,22 @@ func New(tagNames []string, maxSliceLen uint32) Codec {
 		typeIDToType: map[uint32]reflect.Type{},
 		typeToTypeID: map[reflect.Type]uint32{},
 	}
-	hCodec.Codec = reflectcodec.New(hCodec, tagNames, maxSliceLen)
+	hCodec.ValueCodec = reflectcodec.New(hCodec, tagNames, maxSliceLen)
+
+	// Code generated by codecgen only (un)marshals the fields tagged with
+	// [reflectcodec.DefaultTagName], so it can't be used by codecs with other
+	// tags.
+	if len(tagNames) != 1 || tagNames[0] != reflectcodec.DefaultTagName {
+		return &reflectOnlyCodec{Codec: hCodec}
+	}
 	return hCodec
 }
 
+// reflectOnlyCodec is a Codec that doesn't use generated code.
+type reflectOnlyCodec struct {
+	Codec
+}
+
 // NewDefault is a convenience constructor; it returns a new codec with reasonable default values
 func NewDefault() Codec {
 	return New([]string{reflectcodec.DefaultTagName}, defaultMaxSliceLength)
@@ -100,7 +100,15 @@ func (m *manager) Size(version uint16, value interface{}) (int, error) {
 		return 0, ErrUnknownVersion
 	}
 
-	res, err := c.Size(value)
+	var (
+		res int
+		err error
+	)
+	if vc, marshaler, ok := generatedMarshaler(c, value); ok {
+		res, err = marshaler.SizeCodec(vc)
+	} else {
+		res, err = c.Size(value)
+	}
 
 	// Add [wrappers.ShortLen] for the codec version
 	return wrappers.ShortLen + res, err
@@ -127,6 +135,10 @@ func (m *manager) Marshal(version uint16, value interface{}) ([]byte, error) {
 	if p.Errored() {
 		return nil, ErrCantPackVersion // Should never happen
 	}
+	if vc, marshaler, ok := generatedMarshaler(c, value); ok {
+		err := marshaler.MarshalCodec(vc, &p)
+		return p.Bytes, err
+	}
 	return p.Bytes, c.MarshalInto(value, &p)
 }
 
@@ -155,5 +167,26 @@ func (m *manager) Unmarshal(bytes []byte, dest interface{}) (uint16, error) {
 	if !exists {
 		return version, ErrUnknownVersion
 	}
+	if vc, u, ok := generatedUnmarshaler(c, dest); ok {
+		return version, unmarshalGenerated(vc, u, p.Bytes[p.Offset:])
+	}
 	return version, c.Unmarshal(p.Bytes[p.Offset:], dest)
 }
+
+// unmarshalGenerated unmarshals [bytes] into [dest] with its generated code.
+func unmarshalGenerated(c ValueCodec, dest Unmarshaler, bytes []byte) error {
+	p := wrappers.Packer{
+		Bytes: bytes,
+	}
+	if err := dest.UnmarshalCodec(c, &p); err != nil {
+		return err
+	}
+	if p.Offset != len(bytes) {
+		return fmt.Errorf("%w: read %d provided %d",
+			ErrExtraSpace,
+			p.Offset,
+			len(bytes),
+		)
+	}
+	return nil
+}
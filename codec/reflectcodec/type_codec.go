@@ -20,7 +20,7 @@ import (
 const DefaultTagName = "serialize"
 
 var (
-	_ codec.Codec = (*genericCodec)(nil)
+	_ codec.ValueCodec = (*genericCodec)(nil)
 
 	errMarshalNil   = errors.New("can't marshal nil pointer or interface")
 	errUnmarshalNil = errors.New("can't unmarshal nil")
@@ -72,7 +72,7 @@ type genericCodec struct {
 }
 
 // New returns a new, concurrency-safe codec
-func New(typer TypeCodec, tagNames []string, maxSliceLen uint32) codec.Codec {
+func New(typer TypeCodec, tagNames []string, maxSliceLen uint32) codec.ValueCodec {
 	return &genericCodec{
 		typer:       typer,
 		maxSliceLen: maxSliceLen,
@@ -89,6 +89,20 @@ func (c *genericCodec) Size(value interface{}) (int, error) {
 	return size, err
 }
 
+func (c *genericCodec) MaxSliceLen() uint32 {
+	return c.maxSliceLen
+}
+
+func (c *genericCodec) SizeValue(value interface{}) (int, error) {
+	valuePtr := reflect.ValueOf(value)
+	if valuePtr.Kind() != reflect.Ptr || valuePtr.IsNil() {
+		return 0, errNeedPointer
+	}
+
+	size, _, err := c.size(valuePtr.Elem())
+	return size, err
+}
+
 // size returns the size of the value along with whether the value is constant sized.
 func (c *genericCodec) size(value reflect.Value) (int, bool, error) {
 	switch valueKind := value.Kind(); valueKind {
@@ -282,6 +296,15 @@ func (c *genericCodec) MarshalInto(value interface{}, p *wrappers.Packer) error
 	return c.marshal(reflect.ValueOf(value), p, c.maxSliceLen)
 }
 
+func (c *genericCodec) MarshalValue(value interface{}, p *wrappers.Packer, maxSliceLen uint32) error {
+	valuePtr := reflect.ValueOf(value)
+	if valuePtr.Kind() != reflect.Ptr || valuePtr.IsNil() {
+		return errNeedPointer
+	}
+
+	return c.marshal(valuePtr.Elem(), p, maxSliceLen)
+}
+
 // marshal writes the byte representation of [value] to [p]
 // [value]'s underlying value must not be a nil pointer or interface
 // c.lock should be held for the duration of this function
@@ -494,6 +517,15 @@ func (c *genericCodec) Unmarshal(bytes []byte, dest interface{}) error {
 	return nil
 }
 
+func (c *genericCodec) UnmarshalValue(p *wrappers.Packer, value interface{}, maxSliceLen uint32) error {
+	valuePtr := reflect.ValueOf(value)
+	if valuePtr.Kind() != reflect.Ptr || valuePtr.IsNil() {
+		return errNeedPointer
+	}
+
+	return c.unmarshal(p, valuePtr.Elem(), maxSliceLen)
+}
+
 // Unmarshal from p.Bytes into [value]. [value] must be addressable.
 // c.lock should be held for the duration of this function
 func (c *genericCodec) unmarshal(p *wrappers.Packer, value reflect.Value, maxSliceLen uint32) error {
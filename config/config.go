@@ -320,6 +320,24 @@ func getNetworkConfig(
 		return network.Config{}, err
 	}
 
+	var zstdDict []byte
+	if v.IsSet(NetworkCompressionZstdDictContentKey) {
+		zstdDict, err = base64.StdEncoding.DecodeString(v.GetString(NetworkCompressionZstdDictContentKey))
+		if err != nil {
+			return network.Config{}, fmt.Errorf("unable to decode base64 content: %w", err)
+		}
+	} else if v.IsSet(NetworkCompressionZstdDictFileKey) {
+		zstdDict, err = os.ReadFile(GetExpandedArg(v, NetworkCompressionZstdDictFileKey))
+		if err != nil {
+			return network.Config{}, err
+		}
+	}
+	if len(zstdDict) > 0 {
+		if _, err := compression.ZstdDictID(zstdDict); err != nil {
+			return network.Config{}, err
+		}
+	}
+
 	allowPrivateIPs := !constants.ProductionNetworkIDs.Contains(networkID)
 	if v.IsSet(NetworkAllowPrivateIPsKey) {
 		allowPrivateIPs = v.GetBool(NetworkAllowPrivateIPsKey)
@@ -377,6 +395,8 @@ func getNetworkConfig(
 			ConnectionTimeout: v.GetDuration(NetworkOutboundConnectionTimeoutKey),
 		},
 
+		QUICEnabled: v.GetBool(NetworkQUICEnabledKey),
+
 		TLSKeyLogFile: v.GetString(NetworkTLSKeyLogFileKey),
 
 		TimeoutConfig: network.TimeoutConfig{
@@ -399,6 +419,7 @@ func getNetworkConfig(
 
 		MaxClockDifference:           v.GetDuration(NetworkMaxClockDifferenceKey),
 		CompressionType:              compressionType,
+		CompressionZstdDictionary:    zstdDict,
 		PingFrequency:                v.GetDuration(NetworkPingFrequencyKey),
 		AllowPrivateIPs:              allowPrivateIPs,
 		UptimeMetricFreq:             v.GetDuration(UptimeMetricFreqKey),
@@ -916,7 +937,11 @@ func getDatabaseConfig(v *viper.Viper, networkID uint32) (node.DatabaseConfig, e
 			GetExpandedArg(v, DBPathKey),
 			constants.NetworkName(networkID),
 		),
-		Config: configBytes,
+		Config:               configBytes,
+		SnapshotDir:          GetExpandedArg(v, DBSnapshotDirKey),
+		RestoreSnapshot:      GetExpandedArg(v, DBRestoreSnapshotKey),
+		QuarantineEnabled:    v.GetBool(DBQuarantineEnabledKey),
+		PrefixMetricsEnabled: v.GetBool(DBPrefixMetricsEnabledKey),
 	}, nil
 }
 
@@ -1167,6 +1192,7 @@ func getDefaultSubnetConfig(v *viper.Viper) subnets.Config {
 		GossipConfig:                getGossipConfig(v),
 		ProposerMinBlockDelay:       proposervm.DefaultMinBlockDelay,
 		ProposerNumHistoricalBlocks: proposervm.DefaultNumHistoricalBlocks,
+		ProposerCachePolicy:         proposervm.DefaultInnerBlkCachePolicy,
 	}
 }
 
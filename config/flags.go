@@ -15,6 +15,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/database/leveldb"
 	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
 	"github.com/ava-labs/avalanchego/genesis"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/trace"
@@ -38,6 +39,7 @@ var (
 	// [defaultUnexpandedDataDir] will be expanded when reading the flags
 	defaultDataDir              = filepath.Join("$HOME", ".avalanchego")
 	defaultDBDir                = filepath.Join(defaultUnexpandedDataDir, "db")
+	defaultDBSnapshotDir        = filepath.Join(defaultUnexpandedDataDir, "snapshots")
 	defaultLogDir               = filepath.Join(defaultUnexpandedDataDir, "logs")
 	defaultProfileDir           = filepath.Join(defaultUnexpandedDataDir, "profiles")
 	defaultStakingPath          = filepath.Join(defaultUnexpandedDataDir, "staking")
@@ -103,10 +105,14 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	fs.Uint64(AddSubnetDelegatorFeeKey, genesis.LocalParams.AddSubnetDelegatorFee, "Transaction fee, in nAVAX, for transactions that add new subnet delegators")
 
 	// Database
-	fs.String(DBTypeKey, leveldb.Name, fmt.Sprintf("Database type to use. Should be one of {%s, %s}", leveldb.Name, memdb.Name))
+	fs.String(DBTypeKey, leveldb.Name, fmt.Sprintf("Database type to use. Should be one of {%s, %s, %s}", leveldb.Name, memdb.Name, pebbledb.Name))
 	fs.String(DBPathKey, defaultDBDir, "Path to database directory")
 	fs.String(DBConfigFileKey, "", fmt.Sprintf("Path to database config file. Ignored if %s is specified", DBConfigContentKey))
 	fs.String(DBConfigContentKey, "", "Specifies base64 encoded database config content")
+	fs.String(DBSnapshotDirKey, defaultDBSnapshotDir, "Path to the directory database snapshots are written into")
+	fs.String(DBRestoreSnapshotKey, "", "Path to a database snapshot to restore into the database directory before starting. The database directory must be empty")
+	fs.Bool(DBQuarantineEnabledKey, false, "If true, unexpected database errors only disable the database partition of the affected chain, rather than the whole database")
+	fs.Bool(DBPrefixMetricsEnabledKey, false, "If true, the time and size of database reads and writes are also reported for each database partition of the node and of the primary network chains")
 
 	// Logging
 	fs.String(LogsDirKey, defaultLogDir, "Logging directory for Avalanche")
@@ -148,7 +154,9 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	fs.Duration(NetworkPingTimeoutKey, constants.DefaultPingPongTimeout, "Timeout value for Ping-Pong with a peer")
 	fs.Duration(NetworkPingFrequencyKey, constants.DefaultPingFrequency, "Frequency of pinging other peers")
 
-	fs.String(NetworkCompressionTypeKey, constants.DefaultNetworkCompressionType.String(), fmt.Sprintf("Compression type for outbound messages. Must be one of [%s, %s, %s]", compression.TypeGzip, compression.TypeZstd, compression.TypeNone))
+	fs.String(NetworkCompressionTypeKey, constants.DefaultNetworkCompressionType.String(), fmt.Sprintf("Compression type for outbound messages. Must be one of [%s, %s, %s, %s, %s]. Peers that don't support the compression type are sent messages compressed with a type they support", compression.TypeGzip, compression.TypeZstd, compression.TypeLz4, compression.TypeSnappy, compression.TypeNone))
+	fs.String(NetworkCompressionZstdDictFileKey, "", fmt.Sprintf("Path to a zstd dictionary, created with the zstddict tool, to compress messages with when sent to peers that have the same dictionary. Ignored if %s is specified", NetworkCompressionZstdDictContentKey))
+	fs.String(NetworkCompressionZstdDictContentKey, "", "Specifies base64 encoded zstd dictionary content")
 
 	fs.Duration(NetworkMaxClockDifferenceKey, constants.DefaultNetworkMaxClockDifference, "Max allowed clock difference value between this node and peers")
 	// Note: The default value is set to false here because the default
@@ -167,6 +175,8 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	// a timeout of 0 should generally not be provided.
 	fs.Duration(NetworkTCPProxyReadTimeoutKey, constants.DefaultNetworkTCPProxyReadTimeout, "Maximum duration to wait for a TCP proxy header")
 
+	fs.Bool(NetworkQUICEnabledKey, false, "If true, this node will accept QUIC connections on the UDP port with the same number as the staking port, and will connect over QUIC to peers that accept them. Connections fall back to TCP if QUIC fails")
+
 	fs.String(NetworkTLSKeyLogFileKey, "", "TLS key log file path. Should only be specified for debugging")
 
 	// Benchlist
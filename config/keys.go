@@ -37,6 +37,10 @@ const (
 	DBPathKey                                          = "db-dir"
 	DBConfigFileKey                                    = "db-config-file"
 	DBConfigContentKey                                 = "db-config-file-content"
+	DBSnapshotDirKey                                   = "db-snapshot-dir"
+	DBRestoreSnapshotKey                               = "restore-snapshot"
+	DBQuarantineEnabledKey                             = "db-quarantine-enabled"
+	DBPrefixMetricsEnabledKey                          = "db-prefix-metrics-enabled"
 	PublicIPKey                                        = "public-ip"
 	PublicIPResolutionFreqKey                          = "public-ip-resolution-frequency"
 	PublicIPResolutionServiceKey                       = "public-ip-resolution-service"
@@ -97,6 +101,8 @@ const (
 	NetworkPingFrequencyKey                            = "network-ping-frequency"
 	NetworkMaxReconnectDelayKey                        = "network-max-reconnect-delay"
 	NetworkCompressionTypeKey                          = "network-compression-type"
+	NetworkCompressionZstdDictFileKey                  = "network-compression-zstd-dictionary-file"
+	NetworkCompressionZstdDictContentKey               = "network-compression-zstd-dictionary-file-content"
 	NetworkMaxClockDifferenceKey                       = "network-max-clock-difference"
 	NetworkAllowPrivateIPsKey                          = "network-allow-private-ips"
 	NetworkRequireValidatorToConnectKey                = "network-require-validator-to-connect"
@@ -104,6 +110,7 @@ const (
 	NetworkPeerWriteBufferSizeKey                      = "network-peer-write-buffer-size"
 	NetworkTCPProxyEnabledKey                          = "network-tcp-proxy-enabled"
 	NetworkTCPProxyReadTimeoutKey                      = "network-tcp-proxy-read-timeout"
+	NetworkQUICEnabledKey                              = "network-quic-enabled"
 	NetworkTLSKeyLogFileKey                            = "network-tls-key-log-file-unsafe"
 	NetworkInboundConnUpgradeThrottlerCooldownKey      = "network-inbound-connection-throttling-cooldown"
 	NetworkInboundThrottlerMaxConnsPerSecKey           = "network-inbound-connection-throttling-max-conns-per-sec"
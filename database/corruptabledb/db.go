@@ -9,11 +9,13 @@ import (
 	"sync"
 
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/set"
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.RangeIteratee = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
 )
 
 // CorruptableDB is a wrapper around Database
@@ -26,6 +28,9 @@ type Database struct {
 	// writes will fail with initialError.
 	errorLock    sync.RWMutex
 	initialError error
+
+	// quarantine is nil unless quarantining has been enabled.
+	quarantine *quarantine
 }
 
 // New returns a new prefixed database
@@ -35,36 +40,36 @@ func New(db database.Database) *Database {
 
 // Has returns if the key is set in the database
 func (db *Database) Has(key []byte) (bool, error) {
-	if err := db.corrupted(); err != nil {
+	if err := db.corruptedKey(key); err != nil {
 		return false, err
 	}
 	has, err := db.Database.Has(key)
-	return has, db.handleError(err)
+	return has, db.handleKeyError(key, err)
 }
 
 // Get returns the value the key maps to in the database
 func (db *Database) Get(key []byte) ([]byte, error) {
-	if err := db.corrupted(); err != nil {
+	if err := db.corruptedKey(key); err != nil {
 		return nil, err
 	}
 	value, err := db.Database.Get(key)
-	return value, db.handleError(err)
+	return value, db.handleKeyError(key, err)
 }
 
 // Put sets the value of the provided key to the provided value
 func (db *Database) Put(key []byte, value []byte) error {
-	if err := db.corrupted(); err != nil {
+	if err := db.corruptedKey(key); err != nil {
 		return err
 	}
-	return db.handleError(db.Database.Put(key, value))
+	return db.handleKeyError(key, db.Database.Put(key, value))
 }
 
 // Delete removes the key from the database
 func (db *Database) Delete(key []byte) error {
-	if err := db.corrupted(); err != nil {
+	if err := db.corruptedKey(key); err != nil {
 		return err
 	}
-	return db.handleError(db.Database.Delete(key))
+	return db.handleKeyError(key, db.Database.Delete(key))
 }
 
 func (db *Database) Compact(start []byte, limit []byte) error {
@@ -79,6 +84,9 @@ func (db *Database) HealthCheck(ctx context.Context) (interface{}, error) {
 	if err := db.corrupted(); err != nil {
 		return nil, err
 	}
+	if corruptions := db.Corruptions(); len(corruptions) > 0 {
+		return newHealthDetails(corruptions), fmt.Errorf("%w: %d prefixes", ErrQuarantined, len(corruptions))
+	}
 	return db.Database.HealthCheck(ctx)
 }
 
@@ -117,6 +125,13 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	}
 }
 
+func (db *Database) NewIteratorWithOptions(options database.IteratorOptions) database.Iterator {
+	return &iterator{
+		Iterator: database.NewIteratorWithOptions(db.Database, options),
+		db:       db,
+	}
+}
+
 func (db *Database) corrupted() error {
 	db.errorLock.RLock()
 	defer db.errorLock.RUnlock()
@@ -146,27 +161,63 @@ func (db *Database) handleError(err error) error {
 type batch struct {
 	database.Batch
 	db *Database
+
+	// prefixes are the quarantine prefixes of the keys written to the batch.
+	prefixes set.Set[string]
+}
+
+func (b *batch) Put(key, value []byte) error {
+	b.addKey(key)
+	return b.Batch.Put(key, value)
+}
+
+func (b *batch) Delete(key []byte) error {
+	b.addKey(key)
+	return b.Batch.Delete(key)
 }
 
 // Write flushes any accumulated data to disk.
 func (b *batch) Write() error {
-	if err := b.db.corrupted(); err != nil {
+	if err := b.db.corruptedPrefixes(b.prefixes); err != nil {
 		return err
 	}
-	return b.db.handleError(b.Batch.Write())
+	return b.db.handlePrefixesError(b.prefixes, nil, b.Batch.Write())
+}
+
+func (b *batch) Reset() {
+	b.Batch.Reset()
+	b.prefixes.Clear()
+}
+
+// addKey records the quarantine prefix of [key], if quarantining is enabled.
+func (b *batch) addKey(key []byte) {
+	if prefix, ok := b.db.quarantinePrefix(key); ok {
+		b.prefixes.Add(prefix)
+	}
 }
 
 type iterator struct {
 	database.Iterator
 	db *Database
+
+	// err is set if the iterator reached a quarantined key.
+	err error
 }
 
 func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
 	if err := it.db.corrupted(); err != nil {
 		return false
 	}
 	val := it.Iterator.Next()
 	_ = it.db.handleError(it.Iterator.Error())
+	if val {
+		// Don't return keys that have been quarantined.
+		it.err = it.db.corruptedKey(it.Iterator.Key())
+		return it.err == nil
+	}
 	return val
 }
 
@@ -174,5 +225,22 @@ func (it *iterator) Error() error {
 	if err := it.db.corrupted(); err != nil {
 		return err
 	}
+	if it.err != nil {
+		return it.err
+	}
 	return it.db.handleError(it.Iterator.Error())
 }
+
+func (it *iterator) Key() []byte {
+	if it.err != nil {
+		return nil
+	}
+	return it.Iterator.Key()
+}
+
+func (it *iterator) Value() []byte {
+	if it.err != nil {
+		return nil
+	}
+	return it.Iterator.Value()
+}
@@ -0,0 +1,272 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package corruptabledb
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+var ErrQuarantined = errors.New("quarantined to avoid possible corruption")
+
+// Corruption describes the unexpected error that caused a prefix to be
+// quarantined.
+type Corruption struct {
+	// Prefix is the quarantined prefix.
+	Prefix []byte
+	// Key is the key that returned [Err]. Key is nil if [Err] was returned by
+	// a batch write, as it can't be attributed to a single key.
+	Key []byte
+	Err error
+}
+
+type quarantine struct {
+	prefixLen int
+	// corruptions maps each quarantined prefix to the corruption that caused
+	// it to be quarantined.
+	corruptions map[string]*Corruption
+}
+
+// EnableQuarantine changes how the database handles unexpected errors.
+//
+// By default, the first unexpected error causes every subsequent call to fail.
+// Once quarantining is enabled, an unexpected error returned for a key only
+// causes subsequent calls on keys that share the first [prefixLen] bytes of
+// that key to fail. Errors that can't be attributed to any key, such as
+// iteration errors, still cause every subsequent call to fail.
+//
+// For example, every key written through a prefixdb.Database starts with a
+// hash of its prefix, so a [prefixLen] of hashing.HashLen quarantines the
+// prefixdb.Database that wrote the key.
+func (db *Database) EnableQuarantine(prefixLen int) {
+	db.errorLock.Lock()
+	defer db.errorLock.Unlock()
+
+	db.quarantine = &quarantine{
+		prefixLen:   prefixLen,
+		corruptions: make(map[string]*Corruption),
+	}
+}
+
+// Corruptions returns the corruptions that caused prefixes to be quarantined,
+// sorted by prefix.
+func (db *Database) Corruptions() []Corruption {
+	db.errorLock.RLock()
+	defer db.errorLock.RUnlock()
+
+	if db.quarantine == nil {
+		return nil
+	}
+
+	prefixes := maps.Keys(db.quarantine.corruptions)
+	slices.Sort(prefixes)
+	corruptions := make([]Corruption, len(prefixes))
+	for i, prefix := range prefixes {
+		corruptions[i] = *db.quarantine.corruptions[prefix]
+	}
+	return corruptions
+}
+
+// Scan checks the integrity of the database by reading every key/value pair,
+// both by iterating and by looking up each key.
+//
+// If quarantining is enabled, keys that can't be read are quarantined and the
+// scan continues after their prefix. Otherwise, the scan stops at the first
+// unexpected error.
+func (db *Database) Scan(ctx context.Context) error {
+	var start []byte
+	for {
+		next, done, err := db.scanFrom(ctx, start)
+		if err != nil || done {
+			return err
+		}
+		start = next
+	}
+}
+
+// scanFrom scans the database, starting at [start], until it reaches a
+// quarantined key. If the scan reaches a quarantined key, the key to continue
+// the scan at is returned. Otherwise, done is returned as true.
+func (db *Database) scanFrom(ctx context.Context, start []byte) ([]byte, bool, error) {
+	if err := db.corrupted(); err != nil {
+		return nil, false, err
+	}
+
+	it := db.Database.NewIteratorWithStart(start)
+	defer it.Release()
+
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		key := it.Key()
+		if next, ok := db.skipQuarantined(key); ok {
+			return next, next == nil, nil
+		}
+
+		// If quarantining is enabled, an unexpected error quarantines [key],
+		// so the scan continues.
+		_, err := db.Get(key)
+		if err != nil && !errors.Is(err, database.ErrNotFound) && !db.quarantining() {
+			return nil, false, err
+		}
+	}
+	return nil, true, db.handleError(it.Error())
+}
+
+func (db *Database) quarantining() bool {
+	db.errorLock.RLock()
+	defer db.errorLock.RUnlock()
+
+	return db.quarantine != nil
+}
+
+// quarantinePrefix returns the prefix of [key] that would be quarantined and
+// true, if quarantining is enabled.
+func (db *Database) quarantinePrefix(key []byte) (string, bool) {
+	db.errorLock.RLock()
+	defer db.errorLock.RUnlock()
+
+	if db.quarantine == nil {
+		return "", false
+	}
+	return db.quarantine.prefix(key), true
+}
+
+// skipQuarantined returns true if the prefix of [key] has been quarantined,
+// along with the smallest key that is larger than every key in the
+// quarantined prefix. If no such key exists, nil is returned.
+func (db *Database) skipQuarantined(key []byte) ([]byte, bool) {
+	db.errorLock.RLock()
+	defer db.errorLock.RUnlock()
+
+	if db.quarantine == nil {
+		return nil, false
+	}
+	prefix := db.quarantine.prefix(key)
+	if _, ok := db.quarantine.corruptions[prefix]; !ok {
+		return nil, false
+	}
+	if len(prefix) < db.quarantine.prefixLen {
+		// Only [key] has this prefix, as longer keys have longer prefixes.
+		return append(slices.Clone(key), 0), true
+	}
+	return database.PrefixUpperBound([]byte(prefix)), true
+}
+
+// corruptedKey returns the error that calls on [key] should fail with, if
+// any.
+func (db *Database) corruptedKey(key []byte) error {
+	db.errorLock.RLock()
+	defer db.errorLock.RUnlock()
+
+	if db.initialError != nil || db.quarantine == nil {
+		return db.initialError
+	}
+	return db.quarantine.corrupted(db.quarantine.prefix(key))
+}
+
+// corruptedPrefixes returns the error that a batch write of keys with
+// [prefixes] should fail with, if any.
+func (db *Database) corruptedPrefixes(prefixes set.Set[string]) error {
+	db.errorLock.RLock()
+	defer db.errorLock.RUnlock()
+
+	if db.initialError != nil || db.quarantine == nil {
+		return db.initialError
+	}
+	for prefix := range prefixes {
+		if err := db.quarantine.corrupted(prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleKeyError quarantines the prefix of [key] if [err] is unexpected and
+// quarantining is enabled. Otherwise, it behaves like handleError.
+func (db *Database) handleKeyError(key []byte, err error) error {
+	if prefix, ok := db.quarantinePrefix(key); ok {
+		return db.handlePrefixesError(set.Of(prefix), key, err)
+	}
+	return db.handleError(err)
+}
+
+// handlePrefixesError quarantines [prefixes] if [err] is unexpected and
+// quarantining is enabled. Otherwise, it behaves like handleError. [key] is
+// the key that returned [err], or nil if it isn't known.
+func (db *Database) handlePrefixesError(prefixes set.Set[string], key []byte, err error) error {
+	switch err {
+	case nil, database.ErrNotFound, database.ErrClosed:
+		return err
+	}
+
+	db.errorLock.Lock()
+	quarantine := db.quarantine
+	if quarantine == nil || prefixes.Len() == 0 {
+		db.errorLock.Unlock()
+		return db.handleError(err)
+	}
+	defer db.errorLock.Unlock()
+
+	offendingKey := slices.Clone(key)
+	for prefix := range prefixes {
+		// Keep the first corruption of each prefix.
+		if _, ok := quarantine.corruptions[prefix]; ok {
+			continue
+		}
+		quarantine.corruptions[prefix] = &Corruption{
+			Prefix: []byte(prefix),
+			Key:    offendingKey,
+			Err:    err,
+		}
+	}
+	return err
+}
+
+func (q *quarantine) prefix(key []byte) string {
+	return string(key[:math.Min(q.prefixLen, len(key))])
+}
+
+func (q *quarantine) corrupted(prefix string) error {
+	c, ok := q.corruptions[prefix]
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("%w: prefix 0x%x: %w", ErrQuarantined, c.Prefix, c.Err)
+}
+
+type healthDetails struct {
+	Corruptions []corruptionDetails `json:"corruptions"`
+}
+
+type corruptionDetails struct {
+	Prefix string `json:"prefix"`
+	Key    string `json:"key,omitempty"`
+	Error  string `json:"error"`
+}
+
+func newHealthDetails(corruptions []Corruption) healthDetails {
+	details := healthDetails{
+		Corruptions: make([]corruptionDetails, len(corruptions)),
+	}
+	for i, c := range corruptions {
+		details.Corruptions[i] = corruptionDetails{
+			Prefix: hex.EncodeToString(c.Prefix),
+			Key:    hex.EncodeToString(c.Key),
+			Error:  c.Err.Error(),
+		}
+	}
+	return details
+}
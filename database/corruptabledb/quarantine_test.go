@@ -0,0 +1,253 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package corruptabledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+// failingDB returns [errTest] for reads and writes of [failing] keys.
+type failingDB struct {
+	database.Database
+	failing set.Set[string]
+}
+
+func (db *failingDB) Has(key []byte) (bool, error) {
+	if db.failing.Contains(string(key)) {
+		return false, errTest
+	}
+	return db.Database.Has(key)
+}
+
+func (db *failingDB) Get(key []byte) ([]byte, error) {
+	if db.failing.Contains(string(key)) {
+		return nil, errTest
+	}
+	return db.Database.Get(key)
+}
+
+func (db *failingDB) Put(key, value []byte) error {
+	if db.failing.Contains(string(key)) {
+		return errTest
+	}
+	return db.Database.Put(key, value)
+}
+
+func (db *failingDB) NewBatch() database.Batch {
+	return &failingBatch{
+		Batch: db.Database.NewBatch(),
+		db:    db,
+	}
+}
+
+type failingBatch struct {
+	database.Batch
+	db      *failingDB
+	failing bool
+}
+
+func (b *failingBatch) Put(key, value []byte) error {
+	b.failing = b.failing || b.db.failing.Contains(string(key))
+	return b.Batch.Put(key, value)
+}
+
+func (b *failingBatch) Write() error {
+	if b.failing {
+		return errTest
+	}
+	return b.Batch.Write()
+}
+
+func TestQuarantine(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := &failingDB{
+		Database: memdb.New(),
+	}
+	db := New(baseDB)
+	db.EnableQuarantine(1)
+
+	require.NoError(db.Put([]byte{1, 1}, []byte{1}))
+	require.NoError(db.Put([]byte{1, 2}, []byte{2}))
+	require.NoError(db.Put([]byte{2, 1}, []byte{3}))
+
+	_, err := db.HealthCheck(context.Background())
+	require.NoError(err)
+
+	baseDB.failing.Add(string([]byte{1, 2}))
+	_, err = db.Get([]byte{1, 2})
+	require.ErrorIs(err, errTest)
+
+	// Keys with the same prefix are quarantined.
+	_, err = db.Get([]byte{1, 1})
+	require.ErrorIs(err, ErrQuarantined)
+	require.ErrorIs(err, errTest)
+	_, err = db.Has([]byte{1, 1})
+	require.ErrorIs(err, ErrQuarantined)
+	err = db.Put([]byte{1, 3}, nil)
+	require.ErrorIs(err, ErrQuarantined)
+	err = db.Delete([]byte{1})
+	require.ErrorIs(err, ErrQuarantined)
+
+	b := db.NewBatch()
+	require.NoError(b.Put([]byte{2, 2}, nil))
+	require.NoError(b.Put([]byte{1, 3}, nil))
+	require.ErrorIs(b.Write(), ErrQuarantined)
+
+	// Keys with other prefixes aren't quarantined.
+	value, err := db.Get([]byte{2, 1})
+	require.NoError(err)
+	require.Equal([]byte{3}, value)
+	require.NoError(db.Put([]byte{2, 2}, []byte{4}))
+
+	b.Reset()
+	require.NoError(b.Put([]byte{2, 3}, nil))
+	require.NoError(b.Write())
+
+	// Iteration stops at the first quarantined key.
+	it := db.NewIterator()
+	require.False(it.Next())
+	require.Nil(it.Key())
+	require.Nil(it.Value())
+	require.ErrorIs(it.Error(), ErrQuarantined)
+	it.Release()
+
+	it = db.NewIteratorWithPrefix([]byte{2})
+	numKeys := 0
+	for it.Next() {
+		numKeys++
+	}
+	require.NoError(it.Error())
+	require.Equal(3, numKeys)
+	it.Release()
+
+	require.Equal([]Corruption{
+		{
+			Prefix: []byte{1},
+			Key:    []byte{1, 2},
+			Err:    errTest,
+		},
+	}, db.Corruptions())
+
+	details, err := db.HealthCheck(context.Background())
+	require.ErrorIs(err, ErrQuarantined)
+	require.Equal(healthDetails{
+		Corruptions: []corruptionDetails{
+			{
+				Prefix: "01",
+				Key:    "0102",
+				Error:  errTest.Error(),
+			},
+		},
+	}, details)
+}
+
+func TestQuarantineBatch(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := &failingDB{
+		Database: memdb.New(),
+		failing:  set.Of(string([]byte{1, 1})),
+	}
+	db := New(baseDB)
+	db.EnableQuarantine(1)
+
+	b := db.NewBatch()
+	require.NoError(b.Put([]byte{1, 1}, nil))
+	require.NoError(b.Put([]byte{2, 1}, nil))
+	require.ErrorIs(b.Write(), errTest)
+
+	// Every prefix written by the batch is quarantined.
+	require.Equal([]Corruption{
+		{
+			Prefix: []byte{1},
+			Err:    errTest,
+		},
+		{
+			Prefix: []byte{2},
+			Err:    errTest,
+		},
+	}, db.Corruptions())
+
+	require.NoError(db.Put([]byte{3}, nil))
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name                string
+		quarantine          bool
+		expectedErr         error
+		expectedCorruptions []Corruption
+	}{
+		{
+			name:        "without quarantine",
+			quarantine:  false,
+			expectedErr: errTest,
+		},
+		{
+			name:        "with quarantine",
+			quarantine:  true,
+			expectedErr: nil,
+			expectedCorruptions: []Corruption{
+				{
+					Prefix: []byte{1, 1},
+					Key:    []byte{1, 1},
+					Err:    errTest,
+				},
+				{
+					Prefix: []byte{3},
+					Key:    []byte{3},
+					Err:    errTest,
+				},
+				{
+					Prefix: []byte{4, 0xff},
+					Key:    []byte{4, 0xff},
+					Err:    errTest,
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			baseDB := &failingDB{
+				Database: memdb.New(),
+			}
+			for _, key := range [][]byte{
+				{1, 1},
+				{1, 2},
+				{2, 1},
+				{3},
+				{3, 1},
+				{4, 0xff},
+				{4, 0xff, 1},
+				{5},
+			} {
+				require.NoError(baseDB.Put(key, nil))
+			}
+			baseDB.failing = set.Of(
+				string([]byte{1, 1}),
+				string([]byte{3}),
+				string([]byte{4, 0xff}),
+			)
+
+			db := New(baseDB)
+			if test.quarantine {
+				db.EnableQuarantine(2)
+			}
+
+			err := db.Scan(context.Background())
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expectedCorruptions, db.Corruptions())
+		})
+	}
+}
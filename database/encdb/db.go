@@ -21,27 +21,42 @@ import (
 
 const (
 	codecVersion = 0
+
+	// The number of keys re-encrypted at a time by Rotate.
+	rotationBatchSize = 1024
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.RangeIteratee = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.Iterator      = (*iterator)(nil)
 )
 
 // Database encrypts all values that are provided
 type Database struct {
 	lock   sync.RWMutex
 	codec  codec.Manager
-	cipher cipher.AEAD
 	db     database.Database
 	closed bool
+
+	// Ensures only one call to Rotate runs at a time.
+	rotateLock sync.Mutex
+
+	// Protects [cipher], [oldCiphers] and [generation].
+	keyLock sync.RWMutex
+	// Encrypts all values that are written.
+	cipher cipher.AEAD
+	// Ciphers of previous keys that values may still be encrypted with while
+	// the key is being rotated.
+	oldCiphers []cipher.AEAD
+	// Incremented every time [cipher] changes.
+	generation uint64
 }
 
 // New returns a new encrypted database
 func New(password []byte, db database.Database) (*Database, error) {
-	h := hashing.ComputeHash256(password)
-	aead, err := chacha20poly1305.NewX(h)
+	aead, err := newCipher(password)
 	if err != nil {
 		return nil, err
 	}
@@ -54,6 +69,21 @@ func New(password []byte, db database.Database) (*Database, error) {
 	}, manager.RegisterCodec(codecVersion, c)
 }
 
+// NewWithKeyProvider returns a new encrypted database that encrypts values
+// with the key supplied by [provider].
+func NewWithKeyProvider(ctx context.Context, provider KeyProvider, db database.Database) (*Database, error) {
+	key, err := provider.Key(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return New(key, db)
+}
+
+func newCipher(key []byte) (cipher.AEAD, error) {
+	h := hashing.ComputeHash256(key)
+	return chacha20poly1305.NewX(h)
+}
+
 func (db *Database) Has(key []byte) (bool, error) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -86,7 +116,7 @@ func (db *Database) Put(key, value []byte) error {
 		return database.ErrClosed
 	}
 
-	encValue, err := db.encrypt(value)
+	encValue, _, err := db.encrypt(value)
 	if err != nil {
 		return err
 	}
@@ -137,6 +167,21 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	}
 }
 
+func (db *Database) NewIteratorWithOptions(options database.IteratorOptions) database.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	return &iterator{
+		Iterator: database.NewIteratorWithOptions(db.db, options),
+		db:       db,
+	}
+}
+
 func (db *Database) Compact(start, limit []byte) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
@@ -158,6 +203,115 @@ func (db *Database) Close() error {
 	return nil
 }
 
+// Rotate re-encrypts every value in the database with the key supplied by
+// [provider].
+//
+// The database remains readable and writable while the key is rotated. Values
+// written after Rotate is called are encrypted with the new key, and values
+// that haven't been re-encrypted yet are decrypted with the old key.
+//
+// If Rotate returns an error, the old key is still used to decrypt the values
+// that weren't re-encrypted, and Rotate may be called again to finish. If the
+// process stops before Rotate returns, the database must be re-opened with the
+// old key and rotated again.
+func (db *Database) Rotate(ctx context.Context, provider KeyProvider) error {
+	key, err := provider.Key(ctx)
+	if err != nil {
+		return err
+	}
+	newCipher, err := newCipher(key)
+	if err != nil {
+		return err
+	}
+
+	db.rotateLock.Lock()
+	defer db.rotateLock.Unlock()
+
+	// Hold [db.lock] so that no values are written between switching keys and
+	// creating the iterator. Every value written after that uses [newCipher],
+	// so only the values the iterator returns need to be re-encrypted.
+	db.lock.Lock()
+	if db.closed {
+		db.lock.Unlock()
+		return database.ErrClosed
+	}
+	db.keyLock.Lock()
+	db.oldCiphers = append(db.oldCiphers, db.cipher)
+	db.cipher = newCipher
+	db.generation++
+	db.keyLock.Unlock()
+	it := db.db.NewIterator()
+	db.lock.Unlock()
+	defer it.Release()
+
+	keys := make([][]byte, 0, rotationBatchSize)
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		keys = append(keys, slices.Clone(it.Key()))
+		if len(keys) < rotationBatchSize {
+			continue
+		}
+		if err := db.reencrypt(keys); err != nil {
+			return err
+		}
+		keys = keys[:0]
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := db.reencrypt(keys); err != nil {
+		return err
+	}
+
+	db.keyLock.Lock()
+	db.oldCiphers = nil
+	db.keyLock.Unlock()
+	return nil
+}
+
+// reencrypt encrypts the values of [keys] with the current key if they're
+// encrypted with an old key.
+func (db *Database) reencrypt(keys [][]byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	batch := db.db.NewBatch()
+	for _, key := range keys {
+		encVal, err := db.db.Get(key)
+		if err == database.ErrNotFound {
+			// The key was deleted after we started rotating.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		val, isCurrent, err := db.decryptWithKey(encVal)
+		if err != nil {
+			return err
+		}
+		if isCurrent {
+			continue
+		}
+
+		encVal, _, err = db.encrypt(val)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(key, encVal); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
 func (db *Database) isClosed() bool {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -180,6 +334,9 @@ type batch struct {
 
 	db  *Database
 	ops []database.BatchOp
+	// The key generation of the oldest value encrypted in [Batch].
+	generation    uint64
+	hasGeneration bool
 }
 
 func (b *batch) Put(key, value []byte) error {
@@ -187,10 +344,14 @@ func (b *batch) Put(key, value []byte) error {
 		Key:   slices.Clone(key),
 		Value: slices.Clone(value),
 	})
-	encValue, err := b.db.encrypt(value)
+	encValue, generation, err := b.db.encrypt(value)
 	if err != nil {
 		return err
 	}
+	if !b.hasGeneration {
+		b.generation = generation
+		b.hasGeneration = true
+	}
 	return b.Batch.Put(key, encValue)
 }
 
@@ -210,9 +371,46 @@ func (b *batch) Write() error {
 		return database.ErrClosed
 	}
 
+	b.db.keyLock.RLock()
+	generation := b.db.generation
+	b.db.keyLock.RUnlock()
+	if b.hasGeneration && b.generation != generation {
+		// The key was rotated after some values were encrypted, so they must
+		// be encrypted again with the current key.
+		if err := b.reencrypt(); err != nil {
+			return err
+		}
+	}
 	return b.Batch.Write()
 }
 
+// reencrypt rebuilds [b.Batch] from [b.ops] with the current key.
+func (b *batch) reencrypt() error {
+	b.Batch.Reset()
+	b.hasGeneration = false
+	for _, op := range b.ops {
+		if op.Delete {
+			if err := b.Batch.Delete(op.Key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		encValue, generation, err := b.db.encrypt(op.Value)
+		if err != nil {
+			return err
+		}
+		if !b.hasGeneration {
+			b.generation = generation
+			b.hasGeneration = true
+		}
+		if err := b.Batch.Put(op.Key, encValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Reset resets the batch for reuse.
 func (b *batch) Reset() {
 	if cap(b.ops) > len(b.ops)*database.MaxExcessCapacityFactor {
@@ -220,6 +418,7 @@ func (b *batch) Reset() {
 	} else {
 		b.ops = b.ops[:0]
 	}
+	b.hasGeneration = false
 	b.Batch.Reset()
 }
 
@@ -291,22 +490,52 @@ type encryptedValue struct {
 	Nonce      []byte `serialize:"true"`
 }
 
-func (db *Database) encrypt(plaintext []byte) ([]byte, error) {
+// encrypt encrypts [plaintext] with the current key.
+// Returns the encrypted value and the generation of the key used.
+func (db *Database) encrypt(plaintext []byte) ([]byte, uint64, error) {
 	nonce := make([]byte, chacha20poly1305.NonceSizeX)
 	if _, err := rand.Read(nonce); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+
+	db.keyLock.RLock()
 	ciphertext := db.cipher.Seal(nil, nonce, plaintext, nil)
-	return db.codec.Marshal(codecVersion, &encryptedValue{
+	generation := db.generation
+	db.keyLock.RUnlock()
+
+	encValue, err := db.codec.Marshal(codecVersion, &encryptedValue{
 		Ciphertext: ciphertext,
 		Nonce:      nonce,
 	})
+	return encValue, generation, err
 }
 
 func (db *Database) decrypt(ciphertext []byte) ([]byte, error) {
+	plaintext, _, err := db.decryptWithKey(ciphertext)
+	return plaintext, err
+}
+
+// decryptWithKey decrypts [ciphertext] with the current key or, while the key
+// is being rotated, an old key.
+// Returns the plaintext and true if it was encrypted with the current key.
+func (db *Database) decryptWithKey(ciphertext []byte) ([]byte, bool, error) {
 	val := encryptedValue{}
 	if _, err := db.codec.Unmarshal(ciphertext, &val); err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	db.keyLock.RLock()
+	defer db.keyLock.RUnlock()
+
+	plaintext, err := db.cipher.Open(nil, val.Nonce, val.Ciphertext, nil)
+	if err == nil {
+		return plaintext, true, nil
+	}
+	// Try the most recent keys first.
+	for i := len(db.oldCiphers) - 1; i >= 0; i-- {
+		if plaintext, oldErr := db.oldCiphers[i].Open(nil, val.Nonce, val.Ciphertext, nil); oldErr == nil {
+			return plaintext, false, nil
+		}
 	}
-	return db.cipher.Open(nil, val.Nonce, val.Ciphertext, nil)
+	return nil, false, err
 }
@@ -4,6 +4,8 @@
 package encdb
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -38,6 +40,120 @@ func FuzzNewIteratorWithPrefix(f *testing.F) {
 	database.FuzzNewIteratorWithPrefix(f, db)
 }
 
+func FuzzNewIteratorWithOptions(f *testing.F) {
+	unencryptedDB := memdb.New()
+	db, err := New([]byte(testPassword), unencryptedDB)
+	require.NoError(f, err)
+	database.FuzzNewIteratorWithOptions(f, db)
+}
+
+// staticKeyProvider returns a fixed key.
+type staticKeyProvider []byte
+
+func (p staticKeyProvider) Key(context.Context) ([]byte, error) {
+	return p, nil
+}
+
+func TestInterfaceAfterRotate(t *testing.T) {
+	for _, test := range database.Tests {
+		db, err := New([]byte(testPassword), memdb.New())
+		require.NoError(t, err)
+		require.NoError(t, db.Rotate(context.Background(), staticKeyProvider("new password")))
+
+		test(t, db)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	require := require.New(t)
+
+	unencryptedDB := memdb.New()
+	db, err := New([]byte(testPassword), unencryptedDB)
+	require.NoError(err)
+
+	numKeys := 2*rotationBatchSize + 1
+	for i := 0; i < numKeys; i++ {
+		require.NoError(db.Put([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))))
+	}
+
+	// A batch that is written after the key is rotated.
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("batchKey"), []byte("batchValue")))
+
+	newKey := staticKeyProvider("new password")
+	require.NoError(db.Rotate(context.Background(), newKey))
+	require.NoError(batch.Write())
+
+	// All values are readable after rotating.
+	for i := 0; i < numKeys; i++ {
+		value, err := db.Get([]byte(fmt.Sprintf("key%d", i)))
+		require.NoError(err)
+		require.Equal([]byte(fmt.Sprintf("value%d", i)), value)
+	}
+	value, err := db.Get([]byte("batchKey"))
+	require.NoError(err)
+	require.Equal([]byte("batchValue"), value)
+
+	// All values are encrypted with only the new key.
+	require.Empty(db.oldCiphers)
+
+	newDB, err := NewWithKeyProvider(context.Background(), newKey, unencryptedDB)
+	require.NoError(err)
+	it := newDB.NewIterator()
+	numRead := 0
+	for it.Next() {
+		numRead++
+	}
+	require.NoError(it.Error())
+	it.Release()
+	require.Equal(numKeys+1, numRead)
+
+	oldDB, err := New([]byte(testPassword), unencryptedDB)
+	require.NoError(err)
+	_, err = oldDB.Get([]byte("key0"))
+	require.Error(err) //nolint:forbidigo // the error is returned by the cipher
+}
+
+func TestRotateInterrupted(t *testing.T) {
+	require := require.New(t)
+
+	unencryptedDB := memdb.New()
+	db, err := New([]byte(testPassword), unencryptedDB)
+	require.NoError(err)
+
+	numKeys := 2 * rotationBatchSize
+	for i := 0; i < numKeys; i++ {
+		require.NoError(db.Put([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	newKey := staticKeyProvider("new password")
+	err = db.Rotate(ctx, newKey)
+	require.ErrorIs(err, context.Canceled)
+
+	// Values are readable and writable with either key until the rotation
+	// finishes.
+	require.NotEmpty(db.oldCiphers)
+	require.NoError(db.Put([]byte("newKey"), []byte("newValue")))
+	for i := 0; i < numKeys; i++ {
+		value, err := db.Get([]byte(fmt.Sprintf("key%d", i)))
+		require.NoError(err)
+		require.Equal([]byte(fmt.Sprintf("value%d", i)), value)
+	}
+
+	require.NoError(db.Rotate(context.Background(), newKey))
+	require.Empty(db.oldCiphers)
+	for i := 0; i < numKeys; i++ {
+		value, err := db.Get([]byte(fmt.Sprintf("key%d", i)))
+		require.NoError(err)
+		require.Equal([]byte(fmt.Sprintf("value%d", i)), value)
+	}
+	value, err := db.Get([]byte("newKey"))
+	require.NoError(err)
+	require.Equal([]byte("newValue"), value)
+}
+
 func BenchmarkInterface(b *testing.B) {
 	for _, size := range database.BenchmarkSizes {
 		keys, values := database.SetupBenchmark(b, size[0], size[1], size[2])
@@ -0,0 +1,143 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+// The largest key a socket key provider will read.
+const maxSocketKeySize = 64 * units.KiB
+
+var (
+	_ KeyProvider = (*fileKeyProvider)(nil)
+	_ KeyProvider = (*envKeyProvider)(nil)
+	_ KeyProvider = (*socketKeyProvider)(nil)
+
+	errEmptyKey        = errors.New("empty key")
+	errKeyNotSet       = errors.New("key environment variable not set")
+	errInsecureKeyFile = errors.New("key file is accessible by other users")
+	errKeyTooLarge     = errors.New("key too large")
+)
+
+// KeyProvider supplies the secret that a Database derives its encryption key
+// from.
+type KeyProvider interface {
+	// Key returns the secret. An error is returned if the secret is
+	// unavailable or empty.
+	Key(ctx context.Context) ([]byte, error)
+}
+
+type fileKeyProvider struct {
+	path string
+}
+
+// NewFileKeyProvider returns a KeyProvider that reads the secret from the file
+// at [path]. Trailing whitespace in the file is ignored.
+//
+// The file must not be readable or writable by the group or other users.
+func NewFileKeyProvider(path string) KeyProvider {
+	return &fileKeyProvider{
+		path: path,
+	}
+}
+
+func (p *fileKeyProvider) Key(context.Context) ([]byte, error) {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return nil, err
+	}
+	if mode := info.Mode().Perm(); mode&0o077 != 0 {
+		return nil, fmt.Errorf("%w: %s has mode %s", errInsecureKeyFile, p.path, mode)
+	}
+
+	key, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	return nonEmptyKey(bytes.TrimRight(key, " \t\r\n"))
+}
+
+type envKeyProvider struct {
+	name string
+}
+
+// NewEnvKeyProvider returns a KeyProvider that reads the secret from the
+// environment variable [name].
+func NewEnvKeyProvider(name string) KeyProvider {
+	return &envKeyProvider{
+		name: name,
+	}
+}
+
+func (p *envKeyProvider) Key(context.Context) ([]byte, error) {
+	key, ok := os.LookupEnv(p.name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errKeyNotSet, p.name)
+	}
+	return nonEmptyKey([]byte(key))
+}
+
+type socketKeyProvider struct {
+	path  string
+	keyID string
+}
+
+// NewSocketKeyProvider returns a KeyProvider that requests the secret named
+// [keyID] from a key management service listening on the unix socket at
+// [path].
+//
+// The provider writes [keyID] followed by a newline to the socket, and the
+// service responds with the secret and closes the connection. An empty
+// response means the service doesn't have the secret.
+func NewSocketKeyProvider(path string, keyID string) KeyProvider {
+	return &socketKeyProvider{
+		path:  path,
+		keyID: keyID,
+	}
+}
+
+func (p *socketKeyProvider) Key(ctx context.Context) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", p.path)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := io.WriteString(conn, p.keyID+"\n"); err != nil {
+		return nil, err
+	}
+
+	// Read one byte more than the limit to detect keys that are too large.
+	key, err := io.ReadAll(io.LimitReader(conn, maxSocketKeySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) > maxSocketKeySize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", errKeyTooLarge, maxSocketKeySize)
+	}
+	return nonEmptyKey(key)
+}
+
+func nonEmptyKey(key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, errEmptyKey
+	}
+	return key, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+func TestFileKeyProvider(t *testing.T) {
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(os.WriteFile(path, []byte(testPassword+"\n"), 0o600))
+
+	key, err := NewFileKeyProvider(path).Key(context.Background())
+	require.NoError(err)
+	require.Equal([]byte(testPassword), key)
+
+	// The key derived from the file is the same as the one derived from the
+	// password.
+	unencryptedDB := memdb.New()
+	db, err := New([]byte(testPassword), unencryptedDB)
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+
+	db, err = NewWithKeyProvider(context.Background(), NewFileKeyProvider(path), unencryptedDB)
+	require.NoError(err)
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	require.NoError(os.Chmod(path, 0o644))
+	_, err = NewFileKeyProvider(path).Key(context.Background())
+	require.ErrorIs(err, errInsecureKeyFile)
+
+	require.NoError(os.WriteFile(path, []byte("\n"), 0o600))
+	require.NoError(os.Chmod(path, 0o600))
+	_, err = NewFileKeyProvider(path).Key(context.Background())
+	require.ErrorIs(err, errEmptyKey)
+
+	_, err = NewFileKeyProvider(filepath.Join(t.TempDir(), "missing")).Key(context.Background())
+	require.ErrorIs(err, os.ErrNotExist)
+}
+
+func TestEnvKeyProvider(t *testing.T) {
+	require := require.New(t)
+
+	const name = "ENCDB_TEST_KEY"
+	t.Setenv(name, testPassword)
+
+	key, err := NewEnvKeyProvider(name).Key(context.Background())
+	require.NoError(err)
+	require.Equal([]byte(testPassword), key)
+
+	t.Setenv(name, "")
+	_, err = NewEnvKeyProvider(name).Key(context.Background())
+	require.ErrorIs(err, errEmptyKey)
+
+	_, err = NewEnvKeyProvider("ENCDB_TEST_MISSING_KEY").Key(context.Background())
+	require.ErrorIs(err, errKeyNotSet)
+}
+
+// serveKeys responds to key requests on [listener] with the secrets in [keys]
+// until [listener] is closed.
+func serveKeys(listener net.Listener, keys map[string]string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		keyID, err := bufio.NewReader(conn).ReadString('\n')
+		if err == nil {
+			_, _ = conn.Write([]byte(keys[strings.TrimSuffix(keyID, "\n")]))
+		}
+		_ = conn.Close()
+	}
+}
+
+func TestSocketKeyProvider(t *testing.T) {
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "kms.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(err)
+	defer listener.Close()
+
+	go serveKeys(listener, map[string]string{
+		"db":    testPassword,
+		"large": strings.Repeat("a", maxSocketKeySize+1),
+	})
+
+	key, err := NewSocketKeyProvider(path, "db").Key(context.Background())
+	require.NoError(err)
+	require.Equal([]byte(testPassword), key)
+
+	_, err = NewSocketKeyProvider(path, "missing").Key(context.Background())
+	require.ErrorIs(err, errEmptyKey)
+
+	_, err = NewSocketKeyProvider(path, "large").Key(context.Background())
+	require.ErrorIs(err, errKeyTooLarge)
+}
@@ -9,4 +9,5 @@ import "errors"
 var (
 	ErrClosed   = errors.New("closed")
 	ErrNotFound = errors.New("not found")
+	ErrReadOnly = errors.New("read-only")
 )
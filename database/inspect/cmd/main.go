@@ -0,0 +1,354 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/spf13/cobra"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/corruptabledb"
+	"github.com/ava-labs/avalanchego/database/inspect"
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+var (
+	errDBDirRequired     = errors.New("--db-dir is required")
+	errUnknownDBType     = errors.New("unknown db-type")
+	errPrefixRequired    = errors.New("--prefix or --name is required")
+	errPrefixAndName     = errors.New("only one of --prefix and --name may be given")
+	errUnknownPrefixName = errors.New("unknown prefix name")
+	errCorrupted         = errors.New("database is corrupted")
+	errNotCorruptable    = errors.New("database doesn't support integrity scans")
+)
+
+type flags struct {
+	dbType      string
+	dbDir       string
+	dbVersion   string
+	network     string
+	genesisFile string
+	chainIDs    []string
+	logLevel    string
+}
+
+func main() {
+	f := &flags{}
+	rootCmd := &cobra.Command{
+		Use:   "dbinspect",
+		Short: "Inspects the database of a stopped avalanchego node",
+		Long: "Opens the versioned database under --db-dir in read-only mode and reports on its contents. " +
+			"The node must be stopped, as the database can't be opened while the node holds its lock.",
+	}
+
+	pflags := rootCmd.PersistentFlags()
+	pflags.StringVar(&f.dbType, "db-type", leveldb.Name, fmt.Sprintf("Type of the database. Should be one of {%s, %s}", leveldb.Name, pebbledb.Name))
+	pflags.StringVar(&f.dbDir, "db-dir", "", "Path to the database directory. Equivalent to the --db-dir used by the node")
+	pflags.StringVar(&f.dbVersion, "db-version", version.CurrentDatabase.String(), "Version of the database")
+	pflags.StringVar(&f.network, "network-id", constants.MainnetName, "Name or ID of the network the node was run on")
+	pflags.StringVar(&f.genesisFile, "genesis-file", "", "Path to the genesis of the network. Defaults to the genesis of --network-id")
+	pflags.StringSliceVar(&f.chainIDs, "chain-ids", nil, "Comma-separated IDs of chains to report on, in addition to the primary network chains")
+	pflags.StringVar(&f.logLevel, "log-level", logging.Warn.String(), "The log level")
+
+	rootCmd.AddCommand(
+		statsCmd(f),
+		dumpCmd(f),
+		lastAcceptedCmd(f),
+		verifyCmd(f),
+	)
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "dbinspect failed: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func statsCmd(f *flags) *cobra.Command {
+	var prefixLen int
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Prints the number and size of the keys under each prefix",
+		RunE: func(*cobra.Command, []string) error {
+			chains, err := f.chains()
+			if err != nil {
+				return err
+			}
+			return f.withDB(func(db database.Database) error {
+				stats, err := inspect.Stats(db, prefixLen, inspect.Names(chains))
+				if err != nil {
+					return err
+				}
+
+				prefixes := maps.Keys(stats)
+				slices.SortFunc(prefixes, func(a, b string) bool {
+					return stats[a].Size() > stats[b].Size()
+				})
+
+				var total inspect.PrefixStats
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+				fmt.Fprintln(w, "PREFIX\tNAME\tKEYS\tKEY BYTES\tVALUE BYTES\tTOTAL BYTES\t")
+				for _, prefix := range prefixes {
+					s := stats[prefix]
+					fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t\n", prefix, s.Name, s.Keys, s.KeyBytes, s.ValueBytes, s.Size())
+
+					total.Keys += s.Keys
+					total.KeyBytes += s.KeyBytes
+					total.ValueBytes += s.ValueBytes
+				}
+				fmt.Fprintf(w, "\t%s\t%d\t%d\t%d\t%d\t\n", "TOTAL", total.Keys, total.KeyBytes, total.ValueBytes, total.Size())
+				return w.Flush()
+			})
+		},
+	}
+	cmd.Flags().IntVar(&prefixLen, "prefix-len", inspect.DefaultPrefixLen, "Number of leading key bytes used to group keys")
+	return cmd
+}
+
+func dumpCmd(f *flags) *cobra.Command {
+	var (
+		prefixHex string
+		name      string
+		limit     int
+	)
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Prints the hex encoded keys and values under a prefix",
+		RunE: func(*cobra.Command, []string) error {
+			prefix, err := f.prefix(prefixHex, name)
+			if err != nil {
+				return err
+			}
+			return f.withDB(func(db database.Database) error {
+				return inspect.Dump(db, prefix, limit, func(key, value []byte) bool {
+					fmt.Printf("%x %x\n", key, value)
+					return true
+				})
+			})
+		},
+	}
+	cmd.Flags().StringVar(&prefixHex, "prefix", "", "Hex encoded prefix of the keys to print")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the prefix of the keys to print, as printed by the stats command. For example, C/vm")
+	cmd.Flags().IntVar(&limit, "limit", 100, "Maximum number of keys to print. 0 prints every key")
+	return cmd
+}
+
+func lastAcceptedCmd(f *flags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "last-accepted",
+		Short: "Prints the last accepted block of each chain",
+		RunE: func(*cobra.Command, []string) error {
+			chains, err := f.chains()
+			if err != nil {
+				return err
+			}
+			chainIDs := maps.Keys(chains)
+			slices.SortFunc(chainIDs, func(a, b ids.ID) bool {
+				return chains[a] < chains[b]
+			})
+			return f.withDB(func(db database.Database) error {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "CHAIN\tCHAIN ID\tBLOCK ID\tPARENT ID\tSTATUS\tTIMESTAMP\tP-CHAIN HEIGHT\tINNER BLOCK BYTES")
+				for _, chainID := range chainIDs {
+					blk, err := inspect.LastAccepted(db, chainID)
+					if errors.Is(err, database.ErrNotFound) {
+						fmt.Fprintf(w, "%s\t%s\tnone\t\t\t\t\t\n", chains[chainID], chainID)
+						continue
+					}
+					if err != nil {
+						return fmt.Errorf("couldn't get last accepted block of %s: %w", chains[chainID], err)
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\n",
+						chains[chainID],
+						chainID,
+						blk.ID,
+						blk.ParentID,
+						blk.Status,
+						blk.Timestamp.UTC(),
+						blk.PChainHeight,
+						blk.InnerBlockSize,
+					)
+				}
+				return w.Flush()
+			})
+		},
+	}
+}
+
+func verifyCmd(f *flags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Reads every key to check the integrity of the database",
+		Long: "Reads every key of the database and prints the prefixes that returned errors. " +
+			"Chains whose prefixes are corrupted can be re-synced with the admin.resyncChain API, " +
+			"unless they are primary network chains or may have written to shared memory.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			chains, err := f.chains()
+			if err != nil {
+				return err
+			}
+			return f.withDB(func(db database.Database) error {
+				corruptableDB, ok := db.(*corruptabledb.Database)
+				if !ok {
+					return errNotCorruptable
+				}
+				corruptableDB.EnableQuarantine(inspect.DefaultPrefixLen)
+				if err := corruptableDB.Scan(cmd.Context()); err != nil {
+					return err
+				}
+
+				corruptions := corruptableDB.Corruptions()
+				if len(corruptions) == 0 {
+					fmt.Println("no corruption found")
+					return nil
+				}
+
+				names := inspect.Names(chains)
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "PREFIX\tNAME\tKEY\tERROR")
+				for _, c := range corruptions {
+					prefix := hex.EncodeToString(c.Prefix)
+					fmt.Fprintf(w, "%s\t%s\t%x\t%s\n", prefix, names[prefix], c.Key, c.Err)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+				return fmt.Errorf("%w: %d corrupted prefixes", errCorrupted, len(corruptions))
+			})
+		},
+	}
+}
+
+// withDB opens the database in read-only mode, calls [fn] with it and closes
+// it.
+func (f *flags) withDB(fn func(database.Database) error) error {
+	if len(f.dbDir) == 0 {
+		return errDBDirRequired
+	}
+	level, err := logging.ToLevel(f.logLevel)
+	if err != nil {
+		return err
+	}
+	log := logging.NewLogger(
+		"dbinspect",
+		logging.NewWrappedCore(level, os.Stderr, logging.Colors.ConsoleEncoder()),
+	)
+	networkID, err := constants.NetworkID(f.network)
+	if err != nil {
+		return err
+	}
+	dbVersion, err := version.Parse(f.dbVersion)
+	if err != nil {
+		return fmt.Errorf("couldn't parse db version %q: %w", f.dbVersion, err)
+	}
+
+	// The node stores the database of each network in its own directory.
+	dbDir := filepath.Join(f.dbDir, constants.NetworkName(networkID))
+	var newManager func(string, []byte, logging.Logger, *version.Semantic, string, prometheus.Registerer) (manager.Manager, error)
+	switch f.dbType {
+	case leveldb.Name:
+		newManager = manager.NewReadOnlyLevelDB
+	case pebbledb.Name:
+		newManager = manager.NewReadOnlyPebbleDB
+	default:
+		return fmt.Errorf(
+			"%w: %q should have been one of {%s, %s}",
+			errUnknownDBType,
+			f.dbType,
+			leveldb.Name,
+			pebbledb.Name,
+		)
+	}
+
+	dbManager, err := newManager(dbDir, nil, log, dbVersion, "", prometheus.NewRegistry())
+	if err != nil {
+		return err
+	}
+	err = fn(dbManager.Current().Database)
+	if closeErr := dbManager.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// chains returns the names of the primary network chains and of the chains
+// given by --chain-ids, keyed by chain ID.
+func (f *flags) chains() (map[ids.ID]string, error) {
+	networkID, err := constants.NetworkID(f.network)
+	if err != nil {
+		return nil, err
+	}
+	config := genesis.GetConfig(networkID)
+	if len(f.genesisFile) > 0 {
+		config, err = genesis.GetConfigFile(f.genesisFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	genesisBytes, _, err := genesis.FromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build genesis: %w", err)
+	}
+	_, chainAliases, err := genesis.Aliases(genesisBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	chains := make(map[ids.ID]string, len(chainAliases)+len(f.chainIDs))
+	for chainID, aliases := range chainAliases {
+		chains[chainID] = aliases[0]
+	}
+	for _, chainIDStr := range f.chainIDs {
+		chainID, err := ids.FromString(chainIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse chain ID %q: %w", chainIDStr, err)
+		}
+		if _, ok := chains[chainID]; !ok {
+			chains[chainID] = chainID.String()
+		}
+	}
+	return chains, nil
+}
+
+// prefix returns the prefix given by --prefix or --name.
+func (f *flags) prefix(prefixHex string, name string) ([]byte, error) {
+	switch {
+	case len(prefixHex) > 0 && len(name) > 0:
+		return nil, errPrefixAndName
+	case len(prefixHex) > 0:
+		return hex.DecodeString(strings.TrimPrefix(prefixHex, "0x"))
+	case len(name) == 0:
+		return nil, errPrefixRequired
+	}
+
+	chains, err := f.chains()
+	if err != nil {
+		return nil, err
+	}
+	for prefix, prefixName := range inspect.Names(chains) {
+		if prefixName == name {
+			return hex.DecodeString(prefix)
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", errUnknownPrefixName, name)
+}
@@ -0,0 +1,233 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package inspect reports on the contents of the database of a stopped node.
+package inspect
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/database/versiondb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/proposervm/block"
+	"github.com/ava-labs/avalanchego/vms/proposervm/state"
+)
+
+// DefaultPrefixLen is the number of leading key bytes used to group keys.
+// Every prefixdb prepends a hash to its keys, so this groups keys by the
+// prefixdb they were written by.
+const DefaultPrefixLen = hashing.HashLen
+
+var (
+	errInvalidPrefixLen = errors.New("invalid prefix length")
+
+	// The prefixes below must match the prefixes used by the node and by the
+	// chain manager when partitioning the database.
+	nodePrefixes = map[string][]byte{
+		"indexer":                   {0x00},
+		"shared memory":             []byte("shared memory"),
+		"keystore":                  []byte("keystore"),
+		"chain generations":         []byte("chain generations"),
+		"deleted chain generations": []byte("deleted chain generations"),
+		"shared memory chains":      []byte("shared memory chains"),
+	}
+	chainPrefixes = map[string][]byte{
+		"vertex":    []byte("vertex"),
+		"vertex_bs": []byte("vertex_bs"),
+		"tx_bs":     []byte("tx_bs"),
+		"block_bs":  []byte("block_bs"),
+		"bs":        []byte("bs"),
+	}
+	vmDBPrefix         = []byte("vm")
+	proposerVMDBPrefix = []byte("proposervm")
+)
+
+// PrefixStats describes the keys in a database that start with a given prefix.
+type PrefixStats struct {
+	// Name is the name of the prefix, or empty if the prefix isn't known.
+	Name       string `json:"name,omitempty"`
+	Keys       uint64 `json:"keys"`
+	KeyBytes   uint64 `json:"keyBytes"`
+	ValueBytes uint64 `json:"valueBytes"`
+}
+
+// Size returns the number of bytes of the keys and values with the prefix.
+func (s PrefixStats) Size() uint64 {
+	return s.KeyBytes + s.ValueBytes
+}
+
+// Names returns the names of the prefixes that the node writes to the database,
+// keyed by the hex encoding of the prefix. [chains] maps the ID of each chain
+// whose prefixes should be named to the name of the chain.
+//
+// The chains are assumed to have never been re-synced. See [PrefixNames].
+func Names(chains map[ids.ID]string) map[string]string {
+	chainPrefixes := make(map[string][]byte, len(chains))
+	for chainID, chainName := range chains {
+		chainPrefixes[chainName] = chainID[:]
+	}
+	return PrefixNames(chainPrefixes)
+}
+
+// PrefixNames returns the names of the prefixes that the node writes to the
+// database, keyed by the hex encoding of the prefix. [chains] maps the name of
+// each chain whose prefixes should be named to the prefix of the chain's
+// partition of the database, as returned by chains.ChainDBPrefix.
+func PrefixNames(chains map[string][]byte) map[string]string {
+	// The prefixes don't depend on the underlying database.
+	var db database.Database
+	names := make(map[string]string, len(nodePrefixes)+len(chains)*(len(chainPrefixes)+3))
+	for name, prefix := range nodePrefixes {
+		names[hex.EncodeToString(prefixdb.New(prefix, db).Prefix())] = name
+	}
+	for chainName, chainDBPrefix := range chains {
+		for name, prefix := range ChainPrefixes(chainDBPrefix) {
+			if name != "" {
+				name = fmt.Sprintf("%s/%s", chainName, name)
+			} else {
+				name = chainName
+			}
+			names[hex.EncodeToString(prefix)] = name
+		}
+	}
+	return names
+}
+
+// ChainPrefixes returns the prefixes that the node writes the keys of a chain
+// to, keyed by name relative to the chain. [chainDBPrefix] is the prefix of the
+// chain's partition of the database, as returned by chains.ChainDBPrefix,
+// whose prefix is keyed by the empty name.
+func ChainPrefixes(chainDBPrefix []byte) map[string][]byte {
+	// The prefixes don't depend on the underlying database.
+	var db database.Database
+	chainDB := prefixdb.New(chainDBPrefix, db)
+	prefixes := make(map[string][]byte, len(chainPrefixes)+3)
+	prefixes[""] = chainDB.Prefix()
+	for name, prefix := range chainPrefixes {
+		prefixes[name] = prefixdb.New(prefix, chainDB).Prefix()
+	}
+
+	vmDB := prefixdb.New(vmDBPrefix, chainDB)
+	prefixes["vm"] = vmDB.Prefix()
+	prefixes["vm/proposervm"] = prefixdb.New(proposerVMDBPrefix, vmDB).Prefix()
+	return prefixes
+}
+
+// Prefixes returns the prefixes that the node writes to the database, keyed by
+// name. It is the inverse of [PrefixNames].
+func Prefixes(chains map[string][]byte) (map[string][]byte, error) {
+	names := PrefixNames(chains)
+	prefixes := make(map[string][]byte, len(names))
+	for hexPrefix, name := range names {
+		prefix, err := hex.DecodeString(hexPrefix)
+		if err != nil {
+			return nil, err
+		}
+		prefixes[name] = prefix
+	}
+	return prefixes, nil
+}
+
+// ChainDB returns the partition of [db] that the chain manager gives to the
+// chain [chainID].
+func ChainDB(db database.Database, chainID ids.ID) *prefixdb.Database {
+	return prefixdb.New(chainID[:], db)
+}
+
+// VMDB returns the partition of [db] that the chain manager gives to the VM of
+// the chain [chainID].
+func VMDB(db database.Database, chainID ids.ID) *prefixdb.Database {
+	return prefixdb.New(vmDBPrefix, ChainDB(db, chainID))
+}
+
+// Stats returns the number and size of the keys and values in [db], grouped by
+// the hex encoding of the first [prefixLen] bytes of each key. Prefixes in
+// [names] are named.
+func Stats(db database.Iteratee, prefixLen int, names map[string]string) (map[string]PrefixStats, error) {
+	if prefixLen < 0 {
+		return nil, fmt.Errorf("%w: %d", errInvalidPrefixLen, prefixLen)
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	stats := make(map[string]PrefixStats)
+	for it.Next() {
+		key := it.Key()
+		prefix := hex.EncodeToString(key[:math.Min(prefixLen, len(key))])
+		s := stats[prefix]
+		s.Name = names[prefix]
+		s.Keys++
+		s.KeyBytes += uint64(len(key))
+		s.ValueBytes += uint64(len(it.Value()))
+		stats[prefix] = s
+	}
+	return stats, it.Error()
+}
+
+// Dump calls [f] with each key/value pair in [db] that starts with [prefix], in
+// order, until [f] returns false or [limit] pairs have been visited. If [limit]
+// is 0, every pair is visited.
+func Dump(db database.Iteratee, prefix []byte, limit int, f func(key, value []byte) bool) error {
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	for i := 0; (limit == 0 || i < limit) && it.Next(); i++ {
+		if !f(it.Key(), it.Value()) {
+			break
+		}
+	}
+	return it.Error()
+}
+
+// Block describes the last accepted block of a chain.
+type Block struct {
+	ID       ids.ID         `json:"id"`
+	ParentID ids.ID         `json:"parentID"`
+	Status   choices.Status `json:"status"`
+	// Timestamp and PChainHeight are only set if the block was signed. Option
+	// blocks aren't signed.
+	Timestamp    time.Time `json:"timestamp"`
+	PChainHeight uint64    `json:"pChainHeight,omitempty"`
+	// InnerBlockSize is the size, in bytes, of the block wrapped by the
+	// proposervm block.
+	InnerBlockSize int `json:"innerBlockSize"`
+}
+
+// LastAccepted returns the last accepted block of the chain [chainID], as
+// recorded by the proposervm. If the chain hasn't accepted a proposervm block,
+// database.ErrNotFound is returned.
+func LastAccepted(db database.Database, chainID ids.ID) (*Block, error) {
+	proposerDB := prefixdb.New(proposerVMDBPrefix, VMDB(db, chainID))
+	// The versiondb is never committed, so [db] is never written to.
+	s := state.New(versiondb.New(proposerDB))
+
+	blkID, err := s.GetLastAccepted()
+	if err != nil {
+		return nil, err
+	}
+	blk, status, err := s.GetBlock(blkID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get block %s: %w", blkID, err)
+	}
+
+	b := &Block{
+		ID:             blk.ID(),
+		ParentID:       blk.ParentID(),
+		Status:         status,
+		InnerBlockSize: len(blk.Block()),
+	}
+	if signedBlk, ok := blk.(block.SignedBlock); ok {
+		b.Timestamp = signedBlk.Timestamp()
+		b.PChainHeight = signedBlk.PChainHeight()
+	}
+	return b, nil
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package inspect
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/database/versiondb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/vms/proposervm/block"
+	"github.com/ava-labs/avalanchego/vms/proposervm/state"
+)
+
+func TestStats(t *testing.T) {
+	require := require.New(t)
+
+	chainID := ids.GenerateTestID()
+	db := memdb.New()
+	vmDB := VMDB(db, chainID)
+	require.NoError(vmDB.Put([]byte{1}, []byte{2, 3}))
+	require.NoError(vmDB.Put([]byte{4, 5}, []byte{6}))
+	bootstrappingDB := prefixdb.New([]byte("bs"), ChainDB(db, chainID))
+	require.NoError(bootstrappingDB.Put([]byte{7}, nil))
+	require.NoError(db.Put([]byte{8}, []byte{9}))
+
+	stats, err := Stats(db, DefaultPrefixLen, Names(map[ids.ID]string{
+		chainID: "C",
+	}))
+	require.NoError(err)
+
+	prefixLen := uint64(DefaultPrefixLen)
+	require.Equal(map[string]PrefixStats{
+		hex.EncodeToString(vmDB.Prefix()): {
+			Name:       "C/vm",
+			Keys:       2,
+			KeyBytes:   2*prefixLen + 3,
+			ValueBytes: 3,
+		},
+		hex.EncodeToString(bootstrappingDB.Prefix()): {
+			Name:     "C/bs",
+			Keys:     1,
+			KeyBytes: prefixLen + 1,
+		},
+		"08": {
+			Keys:       1,
+			KeyBytes:   1,
+			ValueBytes: 1,
+		},
+	}, stats)
+
+	stats, err = Stats(db, 0, nil)
+	require.NoError(err)
+	require.Equal(map[string]PrefixStats{
+		"": {
+			Keys:       4,
+			KeyBytes:   3*prefixLen + 5,
+			ValueBytes: 4,
+		},
+	}, stats)
+
+	_, err = Stats(db, -1, nil)
+	require.ErrorIs(err, errInvalidPrefixLen)
+}
+
+func TestPrefixes(t *testing.T) {
+	require := require.New(t)
+
+	// A re-synced chain has a partition that isn't prefixed by its chain ID.
+	chainDBPrefix := []byte("re-synced chain")
+	prefixes, err := Prefixes(map[string][]byte{
+		"C": chainDBPrefix,
+	})
+	require.NoError(err)
+
+	var db database.Database
+	chainDB := prefixdb.New(chainDBPrefix, db)
+	vmDB := prefixdb.New(vmDBPrefix, chainDB)
+	require.Equal(chainDB.Prefix(), prefixes["C"])
+	require.Equal(vmDB.Prefix(), prefixes["C/vm"])
+	require.Equal(prefixdb.New([]byte("bs"), chainDB).Prefix(), prefixes["C/bs"])
+	require.Equal(prefixdb.New([]byte("shared memory"), db).Prefix(), prefixes["shared memory"])
+
+	// Every prefix should be named by [PrefixNames].
+	names := PrefixNames(map[string][]byte{
+		"C": chainDBPrefix,
+	})
+	require.Len(names, len(prefixes))
+	for name, prefix := range prefixes {
+		require.Equal(name, names[hex.EncodeToString(prefix)])
+	}
+}
+
+func TestDump(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	require.NoError(db.Put([]byte{0, 1}, []byte{1}))
+	require.NoError(db.Put([]byte{0, 2}, []byte{2}))
+	require.NoError(db.Put([]byte{0, 3}, []byte{3}))
+	require.NoError(db.Put([]byte{1}, []byte{4}))
+
+	var values []byte
+	collect := func(_, value []byte) bool {
+		values = append(values, value...)
+		return true
+	}
+	require.NoError(Dump(db, []byte{0}, 0, collect))
+	require.Equal([]byte{1, 2, 3}, values)
+
+	values = nil
+	require.NoError(Dump(db, nil, 2, collect))
+	require.Equal([]byte{1, 2}, values)
+
+	values = nil
+	require.NoError(Dump(db, nil, 0, func(key, value []byte) bool {
+		values = append(values, value...)
+		return len(values) < 3
+	}))
+	require.Equal([]byte{1, 2, 3}, values)
+}
+
+func TestLastAccepted(t *testing.T) {
+	require := require.New(t)
+
+	chainID := ids.GenerateTestID()
+	db := memdb.New()
+
+	_, err := LastAccepted(db, chainID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Write the block the same way the proposervm does.
+	vdb := versiondb.New(prefixdb.New(proposerVMDBPrefix, VMDB(db, chainID)))
+	s := state.New(vdb)
+
+	timestamp := time.Unix(123, 0)
+	blk, err := block.BuildUnsigned(ids.GenerateTestID(), timestamp, 456, []byte{1, 2, 3})
+	require.NoError(err)
+	require.NoError(s.PutBlock(blk, choices.Accepted))
+	require.NoError(s.SetLastAccepted(blk.ID()))
+	require.NoError(vdb.Commit())
+
+	lastAccepted, err := LastAccepted(db, chainID)
+	require.NoError(err)
+	require.Equal(&Block{
+		ID:             blk.ID(),
+		ParentID:       blk.ParentID(),
+		Status:         choices.Accepted,
+		Timestamp:      timestamp,
+		PChainHeight:   456,
+		InnerBlockSize: 3,
+	}, lastAccepted)
+}
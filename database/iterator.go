@@ -7,7 +7,17 @@
 
 package database
 
-var _ Iterator = (*IteratorError)(nil)
+import (
+	"bytes"
+
+	"golang.org/x/exp/slices"
+)
+
+var (
+	_ Iterator = (*IteratorError)(nil)
+	_ Iterator = (*boundedIterator)(nil)
+	_ Iterator = (*reverseIterator)(nil)
+)
 
 // Iterator iterates over a database's key/value pairs.
 //
@@ -87,3 +97,193 @@ func (*IteratorError) Value() []byte {
 }
 
 func (*IteratorError) Release() {}
+
+// IteratorOptions describes the key/value pairs that an iterator iterates
+// over.
+//
+// An iterator created with IteratorOptions iterates over the keys that start
+// with Prefix, are greater than or equal to Start and are less than End.
+type IteratorOptions struct {
+	// Start is the inclusive lower bound of the keys. If empty, there is no
+	// lower bound.
+	Start []byte
+	// End is the exclusive upper bound of the keys. If empty, there is no
+	// upper bound.
+	End []byte
+	// Prefix is the prefix of the keys. If empty, every key has the prefix.
+	Prefix []byte
+	// Reverse iterates over the keys in descending order, rather than in
+	// ascending order.
+	Reverse bool
+}
+
+// Bounds returns the inclusive lower bound and the exclusive upper bound of
+// the keys described by [o]. A nil bound means that the keys are unbounded in
+// that direction.
+func (o IteratorOptions) Bounds() ([]byte, []byte) {
+	lower := o.Prefix
+	if bytes.Compare(o.Start, lower) > 0 {
+		lower = o.Start
+	}
+	upper := PrefixUpperBound(o.Prefix)
+	if len(o.End) > 0 && (upper == nil || bytes.Compare(o.End, upper) < 0) {
+		upper = o.End
+	}
+	if len(lower) == 0 {
+		lower = nil
+	}
+	return lower, upper
+}
+
+// PrefixUpperBound returns the smallest key that is larger than every key
+// starting with [prefix]. If no such key exists, nil is returned.
+func PrefixUpperBound(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xFF {
+			upperBound := slices.Clone(prefix[:i+1])
+			upperBound[i]++
+			return upperBound
+		}
+	}
+	return nil
+}
+
+// RangeIteratee is implemented by databases that natively support bounded and
+// reverse iteration.
+type RangeIteratee interface {
+	// NewIteratorWithOptions creates an iterator over the subset of database
+	// content described by [options].
+	NewIteratorWithOptions(options IteratorOptions) Iterator
+}
+
+// NewIteratorWithOptions creates an iterator over the subset of the content of
+// [db] described by [options].
+//
+// If [db] doesn't implement RangeIteratee, the upper bound is applied to a
+// forward iterator and reverse iteration reads every key/value pair in range
+// into memory before returning the first one.
+func NewIteratorWithOptions(db Iteratee, options IteratorOptions) Iterator {
+	if db, ok := db.(RangeIteratee); ok {
+		return db.NewIteratorWithOptions(options)
+	}
+
+	return ApplyIteratorOptions(
+		db.NewIteratorWithStartAndPrefix(options.Start, options.Prefix),
+		options,
+	)
+}
+
+// ApplyIteratorOptions returns an iterator over the key/value pairs of [it]
+// that are described by [options]. [it] must iterate, in ascending order, over
+// the keys that start with options.Prefix and are greater than or equal to
+// options.Start.
+//
+// Reverse iteration reads every key/value pair in range into memory before
+// returning the first one.
+func ApplyIteratorOptions(it Iterator, options IteratorOptions) Iterator {
+	_, upper := options.Bounds()
+	bounded := &boundedIterator{
+		Iterator: it,
+		upper:    upper,
+	}
+	if !options.Reverse {
+		return bounded
+	}
+	defer bounded.Release()
+
+	var (
+		keys   [][]byte
+		values [][]byte
+	)
+	for bounded.Next() {
+		keys = append(keys, slices.Clone(bounded.Key()))
+		values = append(values, slices.Clone(bounded.Value()))
+	}
+	if err := bounded.Error(); err != nil {
+		return &IteratorError{
+			Err: err,
+		}
+	}
+	return &reverseIterator{
+		keys:   keys,
+		values: values,
+	}
+}
+
+// boundedIterator stops iterating at the first key that isn't less than
+// [upper].
+type boundedIterator struct {
+	Iterator
+
+	upper     []byte
+	exhausted bool
+}
+
+func (it *boundedIterator) Next() bool {
+	if it.exhausted {
+		return false
+	}
+	if !it.Iterator.Next() {
+		it.exhausted = true
+		return false
+	}
+	if it.upper != nil && bytes.Compare(it.Iterator.Key(), it.upper) >= 0 {
+		it.exhausted = true
+		return false
+	}
+	return true
+}
+
+func (it *boundedIterator) Key() []byte {
+	if it.exhausted {
+		return nil
+	}
+	return it.Iterator.Key()
+}
+
+func (it *boundedIterator) Value() []byte {
+	if it.exhausted {
+		return nil
+	}
+	return it.Iterator.Value()
+}
+
+// reverseIterator iterates over [keys] and [values] from last to first.
+type reverseIterator struct {
+	keys   [][]byte
+	values [][]byte
+
+	initialized bool
+}
+
+func (it *reverseIterator) Next() bool {
+	if it.initialized && len(it.keys) > 0 {
+		it.keys = it.keys[:len(it.keys)-1]
+		it.values = it.values[:len(it.values)-1]
+	}
+	it.initialized = true
+	return len(it.keys) > 0
+}
+
+func (*reverseIterator) Error() error {
+	return nil
+}
+
+func (it *reverseIterator) Key() []byte {
+	if !it.initialized || len(it.keys) == 0 {
+		return nil
+	}
+	return it.keys[len(it.keys)-1]
+}
+
+func (it *reverseIterator) Value() []byte {
+	if !it.initialized || len(it.values) == 0 {
+		return nil
+	}
+	return it.values[len(it.values)-1]
+}
+
+func (it *reverseIterator) Release() {
+	it.keys = nil
+	it.values = nil
+}
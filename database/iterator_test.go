@@ -0,0 +1,238 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ Iterator = (*sliceIterator)(nil)
+
+// sliceIterator iterates over [keys] and [values] from first to last.
+type sliceIterator struct {
+	keys   [][]byte
+	values [][]byte
+	// index is one more than the index of the current key/value pair.
+	index int
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.index <= len(it.keys) {
+		it.index++
+	}
+	return it.index <= len(it.keys)
+}
+
+func (*sliceIterator) Error() error {
+	return nil
+}
+
+func (it *sliceIterator) Key() []byte {
+	if it.index == 0 || it.index > len(it.keys) {
+		return nil
+	}
+	return it.keys[it.index-1]
+}
+
+func (it *sliceIterator) Value() []byte {
+	if it.index == 0 || it.index > len(it.values) {
+		return nil
+	}
+	return it.values[it.index-1]
+}
+
+func (*sliceIterator) Release() {}
+
+func TestIteratorOptionsBounds(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       IteratorOptions
+		expectedLower []byte
+		expectedUpper []byte
+	}{
+		{
+			name:          "unbounded",
+			options:       IteratorOptions{},
+			expectedLower: nil,
+			expectedUpper: nil,
+		},
+		{
+			name: "start and end",
+			options: IteratorOptions{
+				Start: []byte{1},
+				End:   []byte{2},
+			},
+			expectedLower: []byte{1},
+			expectedUpper: []byte{2},
+		},
+		{
+			name: "prefix",
+			options: IteratorOptions{
+				Prefix: []byte{1, 2},
+			},
+			expectedLower: []byte{1, 2},
+			expectedUpper: []byte{1, 3},
+		},
+		{
+			name: "start before prefix",
+			options: IteratorOptions{
+				Start:  []byte{0},
+				Prefix: []byte{1},
+			},
+			expectedLower: []byte{1},
+			expectedUpper: []byte{2},
+		},
+		{
+			name: "start after prefix",
+			options: IteratorOptions{
+				Start:  []byte{1, 5},
+				Prefix: []byte{1},
+			},
+			expectedLower: []byte{1, 5},
+			expectedUpper: []byte{2},
+		},
+		{
+			name: "end before prefix upper bound",
+			options: IteratorOptions{
+				End:    []byte{1, 5},
+				Prefix: []byte{1},
+			},
+			expectedLower: []byte{1},
+			expectedUpper: []byte{1, 5},
+		},
+		{
+			name: "end after prefix upper bound",
+			options: IteratorOptions{
+				End:    []byte{3},
+				Prefix: []byte{1},
+			},
+			expectedLower: []byte{1},
+			expectedUpper: []byte{2},
+		},
+		{
+			name: "end with unbounded prefix",
+			options: IteratorOptions{
+				End:    []byte{0xff, 0xff, 0x01},
+				Prefix: []byte{0xff, 0xff},
+			},
+			expectedLower: []byte{0xff, 0xff},
+			expectedUpper: []byte{0xff, 0xff, 0x01},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			lower, upper := test.options.Bounds()
+			require.Equal(test.expectedLower, lower)
+			require.Equal(test.expectedUpper, upper)
+		})
+	}
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	require := require.New(t)
+
+	require.Nil(PrefixUpperBound(nil))
+	require.Nil(PrefixUpperBound([]byte{0xff, 0xff}))
+	require.Equal([]byte{1, 3}, PrefixUpperBound([]byte{1, 2}))
+	require.Equal([]byte{2}, PrefixUpperBound([]byte{1, 0xff}))
+
+	// The prefix must not be modified.
+	prefix := []byte{1, 2}
+	_ = PrefixUpperBound(prefix)
+	require.Equal([]byte{1, 2}, prefix)
+}
+
+func TestApplyIteratorOptions(t *testing.T) {
+	newIterator := func() Iterator {
+		return &sliceIterator{
+			keys:   [][]byte{{1}, {2}, {3}},
+			values: [][]byte{{4}, {5}, {6}},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		options        IteratorOptions
+		expectedKeys   [][]byte
+		expectedValues [][]byte
+	}{
+		{
+			name:           "forward",
+			options:        IteratorOptions{},
+			expectedKeys:   [][]byte{{1}, {2}, {3}},
+			expectedValues: [][]byte{{4}, {5}, {6}},
+		},
+		{
+			name: "forward with end",
+			options: IteratorOptions{
+				End: []byte{3},
+			},
+			expectedKeys:   [][]byte{{1}, {2}},
+			expectedValues: [][]byte{{4}, {5}},
+		},
+		{
+			name: "reverse",
+			options: IteratorOptions{
+				Reverse: true,
+			},
+			expectedKeys:   [][]byte{{3}, {2}, {1}},
+			expectedValues: [][]byte{{6}, {5}, {4}},
+		},
+		{
+			name: "reverse with end",
+			options: IteratorOptions{
+				End:     []byte{2},
+				Reverse: true,
+			},
+			expectedKeys:   [][]byte{{1}},
+			expectedValues: [][]byte{{4}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			it := ApplyIteratorOptions(newIterator(), test.options)
+			defer it.Release()
+
+			var (
+				keys   [][]byte
+				values [][]byte
+			)
+			for it.Next() {
+				keys = append(keys, it.Key())
+				values = append(values, it.Value())
+			}
+			require.NoError(it.Error())
+			require.Equal(test.expectedKeys, keys)
+			require.Equal(test.expectedValues, values)
+
+			require.False(it.Next())
+			require.Nil(it.Key())
+			require.Nil(it.Value())
+		})
+	}
+}
+
+func TestApplyIteratorOptionsError(t *testing.T) {
+	errTest := errors.New("non-nil error")
+	for _, reverse := range []bool{false, true} {
+		it := ApplyIteratorOptions(
+			&IteratorError{
+				Err: errTest,
+			},
+			IteratorOptions{
+				Reverse: reverse,
+			},
+		)
+		require.False(t, it.Next())
+		require.ErrorIs(t, it.Error(), errTest)
+		it.Release()
+	}
+}
@@ -66,9 +66,10 @@ const (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iter)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.RangeIteratee = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.Iterator      = (*iter)(nil)
 
 	ErrInvalidConfig = errors.New("invalid config")
 	ErrCouldNotOpen  = errors.New("could not open")
@@ -190,6 +191,19 @@ type config struct {
 
 // New returns a wrapped LevelDB object.
 func New(file string, configBytes []byte, log logging.Logger, namespace string, reg prometheus.Registerer) (database.Database, error) {
+	return newDB(file, configBytes, log, namespace, reg, false)
+}
+
+// NewReadOnly returns a wrapped LevelDB object that can't be written to. The
+// database must already exist at [file]. Writes return database.ErrReadOnly.
+//
+// The database is opened with a shared lock, so it can be opened by multiple
+// readers, but not while it is opened by a writer, such as a running node.
+func NewReadOnly(file string, configBytes []byte, log logging.Logger, namespace string, reg prometheus.Registerer) (database.Database, error) {
+	return newDB(file, configBytes, log, namespace, reg, true)
+}
+
+func newDB(file string, configBytes []byte, log logging.Logger, namespace string, reg prometheus.Registerer, readOnly bool) (database.Database, error) {
 	parsedConfig := config{
 		BlockCacheCapacity:     DefaultBlockCacheSize,
 		DisableSeeksCompaction: true,
@@ -207,6 +221,7 @@ func New(file string, configBytes []byte, log logging.Logger, namespace string,
 
 	log.Info("creating leveldb",
 		zap.Reflect("config", parsedConfig),
+		zap.Bool("readOnly", readOnly),
 	)
 
 	// Open the db and recover any potential corruptions
@@ -226,8 +241,11 @@ func New(file string, configBytes []byte, log logging.Logger, namespace string,
 		WriteBuffer:                   parsedConfig.WriteBuffer,
 		Filter:                        filter.NewBloomFilter(parsedConfig.FilterBitsPerKey),
 		MaxManifestFileSize:           parsedConfig.MaxManifestFileSize,
+		ReadOnly:                      readOnly,
 	})
-	if _, corrupted := err.(*errors.ErrCorrupted); corrupted {
+	// Recovering the database rewrites its manifest, so corruptions are only
+	// recovered when the database is writable.
+	if _, corrupted := err.(*errors.ErrCorrupted); corrupted && !readOnly {
 		db, err = leveldb.RecoverFile(file, nil)
 	}
 	if err != nil {
@@ -340,6 +358,21 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	}
 }
 
+// NewIteratorWithOptions creates an iterator over the keys described by
+// [options]
+func (db *Database) NewIteratorWithOptions(options database.IteratorOptions) database.Iterator {
+	start, limit := options.Bounds()
+	iterRange := &util.Range{
+		Start: slices.Clone(start),
+		Limit: slices.Clone(limit),
+	}
+	return &iter{
+		db:       db,
+		Iterator: db.DB.NewIterator(iterRange, nil),
+		reverse:  options.Reverse,
+	}
+}
+
 // This comment is basically copy pasted from the underlying levelDB library:
 
 // Compact the underlying DB for the given key range.
@@ -445,6 +478,9 @@ func (r *replayer) Delete(key []byte) {
 type iter struct {
 	db *Database
 	iterator.Iterator
+	// reverse iterates from the last key to the first key.
+	reverse     bool
+	initialized bool
 
 	key, val []byte
 	err      error
@@ -459,7 +495,16 @@ func (it *iter) Next() bool {
 		return false
 	}
 
-	hasNext := it.Iterator.Next()
+	var hasNext bool
+	switch {
+	case !it.reverse:
+		hasNext = it.Iterator.Next()
+	case !it.initialized:
+		hasNext = it.Iterator.Last()
+		it.initialized = true
+	default:
+		hasNext = it.Iterator.Prev()
+	}
 	if hasNext {
 		it.key = slices.Clone(it.Iterator.Key())
 		it.val = slices.Clone(it.Iterator.Value())
@@ -491,6 +536,8 @@ func updateError(err error) error {
 		return database.ErrClosed
 	case leveldb.ErrNotFound:
 		return database.ErrNotFound
+	case leveldb.ErrReadOnly:
+		return database.ErrReadOnly
 	default:
 		return err
 	}
@@ -4,6 +4,7 @@
 package leveldb
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -26,6 +27,42 @@ func TestInterface(t *testing.T) {
 	}
 }
 
+func TestReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	folder := t.TempDir()
+	db, err := New(folder, nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	require.NoError(db.Close())
+
+	db, err = NewReadOnly(folder, nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(err)
+
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	err = db.Put([]byte("key"), []byte("other"))
+	require.ErrorIs(err, database.ErrReadOnly)
+	err = db.Delete([]byte("key"))
+	require.ErrorIs(err, database.ErrReadOnly)
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("other"), []byte("value")))
+	err = batch.Write()
+	require.ErrorIs(err, database.ErrReadOnly)
+
+	value, err = db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+	require.NoError(db.Close())
+
+	// Read-only databases aren't created.
+	_, err = NewReadOnly(filepath.Join(folder, "missing"), nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	require.ErrorIs(err, ErrCouldNotOpen)
+}
+
 func FuzzKeyValue(f *testing.F) {
 	folder := f.TempDir()
 	db, err := New(folder, nil, logging.NoLog{}, "", prometheus.NewRegistry())
@@ -46,6 +83,16 @@ func FuzzNewIteratorWithPrefix(f *testing.F) {
 	database.FuzzNewIteratorWithPrefix(f, db)
 }
 
+func FuzzNewIteratorWithOptions(f *testing.F) {
+	folder := f.TempDir()
+	db, err := New(folder, nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(f, err)
+
+	defer db.Close()
+
+	database.FuzzNewIteratorWithOptions(f, db)
+}
+
 func BenchmarkInterface(b *testing.B) {
 	for _, size := range database.BenchmarkSizes {
 		keys, values := database.SetupBenchmark(b, size[0], size[1], size[2])
@@ -17,6 +17,7 @@ import (
 	"github.com/ava-labs/avalanchego/database/leveldb"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/database/meterdb"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -59,6 +60,11 @@ type Manager interface {
 	// performance.
 	NewMeterDBManager(namespace string, registerer prometheus.Registerer) (Manager, error)
 
+	// NewMeterDBManagerWithPrefixes is like NewMeterDBManager, but the reads
+	// and writes of keys that start with one of the [prefixes] are also
+	// reported under the name of the prefix. See meterdb.NewWithPrefixes.
+	NewMeterDBManagerWithPrefixes(namespace string, registerer prometheus.Registerer, prefixes map[string][]byte) (Manager, error)
+
 	// NewCompleteMeterDBManager wraps each database instance with a meterdb
 	// instance. The namespace is concatenated with the version of the database.
 	// Note: calling this more than once with the same [namespace] will cause a
@@ -96,6 +102,73 @@ func NewLevelDB(
 	)
 }
 
+// NewPebbleDB creates a database manager of pebbleDBs at [filePath] by creating
+// a database instance from each directory with a version <= [currentVersion].
+func NewPebbleDB(
+	dbDirPath string,
+	dbConfig []byte,
+	log logging.Logger,
+	currentVersion *version.Semantic,
+	namespace string,
+	reg prometheus.Registerer,
+) (Manager, error) {
+	return new(
+		pebbledb.New,
+		dbDirPath,
+		dbConfig,
+		log,
+		currentVersion,
+		namespace,
+		reg,
+	)
+}
+
+// NewReadOnlyLevelDB creates a database manager of read-only levelDBs at
+// [filePath] by opening a database instance from each directory with a
+// version <= [currentVersion]. The database with [currentVersion] must
+// already exist. Writes to the managed databases return database.ErrReadOnly.
+func NewReadOnlyLevelDB(
+	dbDirPath string,
+	dbConfig []byte,
+	log logging.Logger,
+	currentVersion *version.Semantic,
+	namespace string,
+	reg prometheus.Registerer,
+) (Manager, error) {
+	return new(
+		leveldb.NewReadOnly,
+		dbDirPath,
+		dbConfig,
+		log,
+		currentVersion,
+		namespace,
+		reg,
+	)
+}
+
+// NewReadOnlyPebbleDB creates a database manager of read-only pebbleDBs at
+// [filePath] by opening a database instance from each directory with a
+// version <= [currentVersion]. The database with [currentVersion] must
+// already exist. Writes to the managed databases return database.ErrReadOnly.
+func NewReadOnlyPebbleDB(
+	dbDirPath string,
+	dbConfig []byte,
+	log logging.Logger,
+	currentVersion *version.Semantic,
+	namespace string,
+	reg prometheus.Registerer,
+) (Manager, error) {
+	return new(
+		pebbledb.NewReadOnly,
+		dbDirPath,
+		dbConfig,
+		log,
+		currentVersion,
+		namespace,
+		reg,
+	)
+}
+
 // new creates a database manager at [filePath] by creating a database instance
 // from each directory with a version <= [currentVersion]. If
 // [includePreviousVersions], opens previous database versions and includes them
@@ -270,8 +343,15 @@ func (m *manager) NewNestedPrefixDBManager(prefix []byte) Manager {
 // NewMeterDBManager wraps the current database instance with a meterdb instance.
 // Note: calling this more than once with the same [namespace] will cause a conflict error for the [registerer]
 func (m *manager) NewMeterDBManager(namespace string, registerer prometheus.Registerer) (Manager, error) {
+	return m.NewMeterDBManagerWithPrefixes(namespace, registerer, nil)
+}
+
+// NewMeterDBManagerWithPrefixes wraps the current database instance with a
+// meterdb instance that also reports the metrics of each of the [prefixes].
+// Note: calling this more than once with the same [namespace] will cause a conflict error for the [registerer]
+func (m *manager) NewMeterDBManagerWithPrefixes(namespace string, registerer prometheus.Registerer, prefixes map[string][]byte) (Manager, error) {
 	currentDB := m.Current()
-	currentMeterDB, err := meterdb.New(namespace, registerer, currentDB.Database)
+	currentMeterDB, err := meterdb.NewWithPrefixes(namespace, registerer, currentDB.Database, prefixes)
 	if err != nil {
 		return nil, err
 	}
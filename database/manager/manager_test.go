@@ -12,9 +12,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/leveldb"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/database/meterdb"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/metric"
@@ -46,6 +48,94 @@ func TestNewSingleLevelDB(t *testing.T) {
 	require.NoError(manager.Close())
 }
 
+func TestNewSinglePebbleDB(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+
+	v1 := version.Semantic1_0_0
+
+	dbPath := filepath.Join(dir, v1.String())
+	db, err := pebbledb.New(dbPath, nil, logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(err)
+
+	require.NoError(db.Close())
+
+	manager, err := NewPebbleDB(dir, nil, logging.NoLog{}, v1, "", prometheus.NewRegistry())
+	require.NoError(err)
+
+	semDB := manager.Current()
+	require.Zero(semDB.Version.Compare(v1))
+
+	_, exists := manager.Previous()
+	require.False(exists)
+	require.Len(manager.GetDatabases(), 1)
+
+	require.NoError(manager.Close())
+}
+
+func TestNewReadOnly(t *testing.T) {
+	tests := []struct {
+		name          string
+		newDB         func(string, []byte, logging.Logger, string, prometheus.Registerer) (database.Database, error)
+		newReadOnlyDB func(string, []byte, logging.Logger, *version.Semantic, string, prometheus.Registerer) (Manager, error)
+		openErr       error
+	}{
+		{
+			name:          leveldb.Name,
+			newDB:         leveldb.New,
+			newReadOnlyDB: NewReadOnlyLevelDB,
+			openErr:       leveldb.ErrCouldNotOpen,
+		},
+		{
+			name:          pebbledb.Name,
+			newDB:         pebbledb.New,
+			newReadOnlyDB: NewReadOnlyPebbleDB,
+			openErr:       pebbledb.ErrCouldNotOpen,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			dir := t.TempDir()
+
+			v1 := version.Semantic1_0_0
+			v2 := &version.Semantic{
+				Major: 1,
+				Minor: 1,
+			}
+
+			// The current database doesn't exist yet.
+			_, err := test.newReadOnlyDB(dir, nil, logging.NoLog{}, v1, "", prometheus.NewRegistry())
+			require.ErrorIs(err, test.openErr)
+			_, err = os.Stat(filepath.Join(dir, v1.String()))
+			require.ErrorIs(err, os.ErrNotExist)
+
+			for _, v := range []*version.Semantic{v1, v2} {
+				db, err := test.newDB(filepath.Join(dir, v.String()), nil, logging.NoLog{}, "", prometheus.NewRegistry())
+				require.NoError(err)
+				require.NoError(db.Put([]byte("version"), []byte(v.String())))
+				require.NoError(db.Close())
+			}
+
+			manager, err := test.newReadOnlyDB(dir, nil, logging.NoLog{}, v2, "", prometheus.NewRegistry())
+			require.NoError(err)
+
+			dbs := manager.GetDatabases()
+			require.Len(dbs, 2)
+			for _, db := range dbs {
+				value, err := db.Database.Get([]byte("version"))
+				require.NoError(err)
+				require.Equal([]byte(db.Version.String()), value)
+
+				err = db.Database.Put([]byte("version"), nil)
+				require.ErrorIs(err, database.ErrReadOnly)
+			}
+
+			require.NoError(manager.Close())
+		})
+	}
+}
+
 func TestNewCreatesSingleDB(t *testing.T) {
 	require := require.New(t)
 
@@ -298,6 +388,37 @@ func TestMeterDBManager(t *testing.T) {
 	require.ErrorIs(err, metric.ErrFailedRegistering)
 }
 
+func TestMeterDBManagerWithPrefixes(t *testing.T) {
+	require := require.New(t)
+
+	registry := prometheus.NewRegistry()
+	m := &manager{databases: []*VersionedDatabase{
+		{
+			Database: memdb.New(),
+			Version:  version.Semantic1_0_0,
+		},
+	}}
+
+	manager, err := m.NewMeterDBManagerWithPrefixes("", registry, map[string][]byte{
+		"prefix": {1},
+	})
+	require.NoError(err)
+	require.IsType(&meterdb.Database{}, manager.Current().Database)
+	require.NoError(manager.Current().Database.Put([]byte{1, 2}, []byte{3}))
+
+	metrics, err := registry.Gather()
+	require.NoError(err)
+	var found bool
+	for _, metric := range metrics {
+		if metric.GetName() == "prefix_write_size" {
+			found = true
+			require.Len(metric.GetMetric(), 1)
+			require.Equal(float64(3), metric.GetMetric()[0].GetCounter().GetValue())
+		}
+	}
+	require.True(found)
+}
+
 func TestCompleteMeterDBManager(t *testing.T) {
 	require := require.New(t)
 
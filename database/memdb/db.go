@@ -5,7 +5,6 @@ package memdb
 
 import (
 	"context"
-	"strings"
 	"sync"
 
 	"golang.org/x/exp/slices"
@@ -22,9 +21,10 @@ const (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.RangeIteratee = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.Iterator      = (*iterator)(nil)
 )
 
 // Database is an ephemeral key-value store that implements the Database
@@ -126,6 +126,13 @@ func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
 }
 
 func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return db.NewIteratorWithOptions(database.IteratorOptions{
+		Start:  start,
+		Prefix: prefix,
+	})
+}
+
+func (db *Database) NewIteratorWithOptions(options database.IteratorOptions) database.Iterator {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
@@ -135,15 +142,23 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 		}
 	}
 
-	startString := string(start)
-	prefixString := string(prefix)
+	lower, upper := options.Bounds()
+	lowerString := string(lower)
+	upperString := string(upper)
 	keys := make([]string, 0, len(db.db))
 	for key := range db.db {
-		if strings.HasPrefix(key, prefixString) && key >= startString {
+		if key >= lowerString && (upper == nil || key < upperString) {
 			keys = append(keys, key)
 		}
 	}
-	slices.Sort(keys) // Keys need to be in sorted order
+	// Keys need to be in sorted order
+	if options.Reverse {
+		slices.SortFunc(keys, func(a, b string) bool {
+			return a > b
+		})
+	} else {
+		slices.Sort(keys)
+	}
 	values := make([][]byte, 0, len(keys))
 	for _, key := range keys {
 		values = append(values, db.db[key])
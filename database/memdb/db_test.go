@@ -23,6 +23,10 @@ func FuzzNewIteratorWithPrefix(f *testing.F) {
 	database.FuzzNewIteratorWithPrefix(f, New())
 }
 
+func FuzzNewIteratorWithOptions(f *testing.F) {
+	database.FuzzNewIteratorWithOptions(f, New())
+}
+
 func BenchmarkInterface(b *testing.B) {
 	for _, size := range database.BenchmarkSizes {
 		keys, values := database.SetupBenchmark(b, size[0], size[1], size[2])
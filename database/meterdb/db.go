@@ -8,22 +8,26 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"golang.org/x/exp/maps"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.RangeIteratee = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.Iterator      = (*iterator)(nil)
 )
 
 // Database tracks the amount of time each operation takes and how many bytes
 // are read/written to the underlying database instance.
 type Database struct {
 	metrics
-	db    database.Database
-	clock mockable.Clock
+	prefixes prefixMetrics
+	db       database.Database
+	clock    mockable.Clock
 }
 
 // New returns a new database with added metrics
@@ -31,11 +35,30 @@ func New(
 	namespace string,
 	registerer prometheus.Registerer,
 	db database.Database,
+) (*Database, error) {
+	return NewWithPrefixes(namespace, registerer, db, nil)
+}
+
+// NewWithPrefixes returns a new database with added metrics. Additionally, the
+// time and bytes of reads and writes of keys that start with one of the
+// [prefixes] are reported under the name of the longest such prefix.
+//
+// For example, the keys written by a prefixdb.Database start with its Prefix.
+func NewWithPrefixes(
+	namespace string,
+	registerer prometheus.Registerer,
+	db database.Database,
+	prefixes map[string][]byte,
 ) (*Database, error) {
 	metrics, err := newMetrics(namespace, registerer)
+	if err != nil {
+		return nil, err
+	}
+	prefixMetrics, err := newPrefixMetrics(namespace, registerer, prefixes)
 	return &Database{
-		metrics: metrics,
-		db:      db,
+		metrics:  metrics,
+		prefixes: prefixMetrics,
+		db:       db,
 	}, err
 }
 
@@ -46,6 +69,7 @@ func (db *Database) Has(key []byte) (bool, error) {
 	db.readSize.Observe(float64(len(key)))
 	db.has.Observe(float64(end.Sub(start)))
 	db.hasSize.Observe(float64(len(key)))
+	db.prefixes.observeRead(key, float64(end.Sub(start)), len(key))
 	return has, err
 }
 
@@ -56,6 +80,7 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 	db.readSize.Observe(float64(len(key) + len(value)))
 	db.get.Observe(float64(end.Sub(start)))
 	db.getSize.Observe(float64(len(key) + len(value)))
+	db.prefixes.observeRead(key, float64(end.Sub(start)), len(key)+len(value))
 	return value, err
 }
 
@@ -66,6 +91,7 @@ func (db *Database) Put(key, value []byte) error {
 	db.writeSize.Observe(float64(len(key) + len(value)))
 	db.put.Observe(float64(end.Sub(start)))
 	db.putSize.Observe(float64(len(key) + len(value)))
+	db.prefixes.observeWrite(key, float64(end.Sub(start)), len(key)+len(value))
 	return err
 }
 
@@ -76,6 +102,7 @@ func (db *Database) Delete(key []byte) error {
 	db.writeSize.Observe(float64(len(key)))
 	db.delete.Observe(float64(end.Sub(start)))
 	db.deleteSize.Observe(float64(len(key)))
+	db.prefixes.observeWrite(key, float64(end.Sub(start)), len(key))
 	return err
 }
 
@@ -116,6 +143,17 @@ func (db *Database) NewIteratorWithStartAndPrefix(
 	return it
 }
 
+func (db *Database) NewIteratorWithOptions(options database.IteratorOptions) database.Iterator {
+	startTime := db.clock.Time()
+	it := &iterator{
+		iterator: database.NewIteratorWithOptions(db.db, options),
+		db:       db,
+	}
+	end := db.clock.Time()
+	db.newIterator.Observe(float64(end.Sub(startTime)))
+	return it
+}
+
 func (db *Database) Compact(start, limit []byte) error {
 	startTime := db.clock.Time()
 	err := db.db.Compact(start, limit)
@@ -143,6 +181,8 @@ func (db *Database) HealthCheck(ctx context.Context) (interface{}, error) {
 type batch struct {
 	batch database.Batch
 	db    *Database
+	// prefixSizes is the number of bytes written to each prefix by the batch.
+	prefixSizes map[*prefix]int
 }
 
 func (b *batch) Put(key, value []byte) error {
@@ -151,6 +191,7 @@ func (b *batch) Put(key, value []byte) error {
 	end := b.db.clock.Time()
 	b.db.bPut.Observe(float64(end.Sub(start)))
 	b.db.bPutSize.Observe(float64(len(key) + len(value)))
+	b.addPrefixSize(key, len(key)+len(value))
 	return err
 }
 
@@ -160,6 +201,7 @@ func (b *batch) Delete(key []byte) error {
 	end := b.db.clock.Time()
 	b.db.bDelete.Observe(float64(end.Sub(start)))
 	b.db.bDeleteSize.Observe(float64(len(key)))
+	b.addPrefixSize(key, len(key))
 	return err
 }
 
@@ -179,6 +221,12 @@ func (b *batch) Write() error {
 	b.db.writeSize.Observe(batchSize)
 	b.db.bWrite.Observe(float64(end.Sub(start)))
 	b.db.bWriteSize.Observe(batchSize)
+	// The batch is written atomically, so every prefix written by the batch
+	// observes the time of the whole write.
+	for p, size := range b.prefixSizes {
+		p.writeTime.Observe(float64(end.Sub(start)))
+		p.writeSize.Add(float64(size))
+	}
 	return err
 }
 
@@ -187,6 +235,7 @@ func (b *batch) Reset() {
 	b.batch.Reset()
 	end := b.db.clock.Time()
 	b.db.bReset.Observe(float64(end.Sub(start)))
+	maps.Clear(b.prefixSizes)
 }
 
 func (b *batch) Replay(w database.KeyValueWriterDeleter) error {
@@ -205,6 +254,18 @@ func (b *batch) Inner() database.Batch {
 	return inner
 }
 
+// addPrefixSize records that [size] bytes were written to [key].
+func (b *batch) addPrefixSize(key []byte, size int) {
+	p := b.db.prefixes.lookup(key)
+	if p == nil {
+		return
+	}
+	if b.prefixSizes == nil {
+		b.prefixSizes = make(map[*prefix]int)
+	}
+	b.prefixSizes[p] += size
+}
+
 type iterator struct {
 	iterator database.Iterator
 	db       *Database
@@ -215,9 +276,13 @@ func (it *iterator) Next() bool {
 	next := it.iterator.Next()
 	end := it.db.clock.Time()
 	it.db.iNext.Observe(float64(end.Sub(start)))
-	size := float64(len(it.iterator.Key()) + len(it.iterator.Value()))
-	it.db.readSize.Observe(size)
-	it.db.iNextSize.Observe(size)
+	key := it.iterator.Key()
+	size := len(key) + len(it.iterator.Value())
+	it.db.readSize.Observe(float64(size))
+	it.db.iNextSize.Observe(float64(size))
+	if next {
+		it.db.prefixes.observeRead(key, float64(end.Sub(start)), size)
+	}
 	return next
 }
 
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/stretchr/testify/require"
 
@@ -38,6 +39,13 @@ func FuzzNewIteratorWithPrefix(f *testing.F) {
 	database.FuzzNewIteratorWithPrefix(f, db)
 }
 
+func FuzzNewIteratorWithOptions(f *testing.F) {
+	baseDB := memdb.New()
+	db, err := New("", prometheus.NewRegistry(), baseDB)
+	require.NoError(f, err)
+	database.FuzzNewIteratorWithOptions(f, db)
+}
+
 func BenchmarkInterface(b *testing.B) {
 	for _, size := range database.BenchmarkSizes {
 		keys, values := database.SetupBenchmark(b, size[0], size[1], size[2])
@@ -49,3 +57,90 @@ func BenchmarkInterface(b *testing.B) {
 		}
 	}
 }
+
+func TestPrefixMetrics(t *testing.T) {
+	require := require.New(t)
+
+	registry := prometheus.NewRegistry()
+	db, err := NewWithPrefixes("", registry, memdb.New(), map[string][]byte{
+		"a":  {0x01},
+		"ab": {0x01, 0x02},
+	})
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte{0x01, 0x00}, []byte{0x00}))       // a
+	require.NoError(db.Put([]byte{0x01, 0x02, 0x00}, []byte{0x00})) // ab
+	require.NoError(db.Put([]byte{0x02}, []byte{0x00}))             // neither
+	require.NoError(db.Delete([]byte{0x01}))                        // a
+
+	_, err = db.Get([]byte{0x01, 0x00}) // a
+	require.NoError(err)
+	_, err = db.Has([]byte{0x01, 0x02, 0x00}) // ab
+	require.NoError(err)
+
+	it := db.NewIteratorWithPrefix([]byte{0x01})
+	numKeys := 0
+	for it.Next() {
+		numKeys++
+	}
+	require.NoError(it.Error())
+	require.Equal(2, numKeys)
+	it.Release()
+
+	b := db.NewBatch()
+	require.NoError(b.Put([]byte{0x01, 0x02, 0x01}, []byte{0x00})) // ab
+	require.NoError(b.Delete([]byte{0x01, 0x02, 0x02}))            // ab
+	require.NoError(b.Put([]byte{0x02}, []byte{0x00}))             // neither
+	require.NoError(b.Write())
+
+	a := db.prefixes.lookup([]byte{0x01})
+	require.NotNil(a)
+	ab := db.prefixes.lookup([]byte{0x01, 0x02})
+	require.NotNil(ab)
+	require.Nil(db.prefixes.lookup([]byte{0x02}))
+
+	require.Equal(float64(3+1), testutil.ToFloat64(a.writeSize))
+	require.Equal(float64(4+4+3), testutil.ToFloat64(ab.writeSize))
+	// Get of 3 bytes, then the iterator reads 3 bytes.
+	require.Equal(float64(3+3), testutil.ToFloat64(a.readSize))
+	// Has of 3 bytes, then the iterator reads 4 bytes.
+	require.Equal(float64(3+4), testutil.ToFloat64(ab.readSize))
+
+	require.Equal(map[string]uint64{"a": 2, "ab": 2}, sampleCounts(t, registry, "prefix_read_time"))
+	require.Equal(map[string]uint64{"a": 2, "ab": 2}, sampleCounts(t, registry, "prefix_write_time"))
+}
+
+func TestPrefixMetricsInvalid(t *testing.T) {
+	_, err := NewWithPrefixes("", prometheus.NewRegistry(), memdb.New(), map[string][]byte{
+		"": {0x01},
+	})
+	require.ErrorIs(t, err, errEmptyPrefixName)
+
+	_, err = NewWithPrefixes("", prometheus.NewRegistry(), memdb.New(), map[string][]byte{
+		"a": {0x01},
+		"b": {0x01},
+	})
+	require.ErrorIs(t, err, errDuplicatePrefix)
+}
+
+// sampleCounts returns the number of observations of the histogram [name],
+// keyed by prefix.
+func sampleCounts(t *testing.T, gatherer prometheus.Gatherer, name string) map[string]uint64 {
+	families, err := gatherer.Gather()
+	require.NoError(t, err)
+
+	counts := make(map[string]uint64)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == prefixLabel {
+					counts[label.GetValue()] = m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return counts
+}
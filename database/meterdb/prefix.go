@@ -0,0 +1,142 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package meterdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/utils/metric"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const prefixLabel = "prefix"
+
+var (
+	errEmptyPrefixName = errors.New("empty prefix name")
+	errDuplicatePrefix = errors.New("duplicate prefix")
+
+	// timeBuckets are the upper bounds, in ns, of the buckets of the time
+	// histograms. They range from 1us to ~262ms.
+	timeBuckets = prometheus.ExponentialBuckets(1_000, 4, 10)
+)
+
+// prefix holds the metrics of the keys that start with [prefix].
+type prefix struct {
+	prefix    []byte
+	readTime  prometheus.Observer
+	readSize  prometheus.Counter
+	writeTime prometheus.Observer
+	writeSize prometheus.Counter
+}
+
+// prefixMetrics reports the reads and writes of the keys that start with each
+// of a set of prefixes.
+type prefixMetrics struct {
+	// prefixes is sorted by length in descending order, so that the first
+	// matching prefix is the longest matching prefix.
+	prefixes []*prefix
+}
+
+func newPrefixMetrics(
+	namespace string,
+	reg prometheus.Registerer,
+	prefixes map[string][]byte,
+) (prefixMetrics, error) {
+	if len(prefixes) == 0 {
+		return prefixMetrics{}, nil
+	}
+
+	var (
+		readTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "prefix_read_time",
+			Help:      "time (in ns) of a read of a key with the prefix",
+			Buckets:   timeBuckets,
+		}, []string{prefixLabel})
+		readSize = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "prefix_read_size",
+			Help:      "bytes read from keys with the prefix",
+		}, []string{prefixLabel})
+		writeTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "prefix_write_time",
+			Help:      "time (in ns) of a write of a key with the prefix",
+			Buckets:   timeBuckets,
+		}, []string{prefixLabel})
+		writeSize = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "prefix_write_size",
+			Help:      "bytes written to keys with the prefix",
+		}, []string{prefixLabel})
+	)
+
+	m := prefixMetrics{
+		prefixes: make([]*prefix, 0, len(prefixes)),
+	}
+	// Sort the names so that errors are deterministic.
+	names := maps.Keys(prefixes)
+	slices.Sort(names)
+	for _, name := range names {
+		if len(name) == 0 {
+			return prefixMetrics{}, errEmptyPrefixName
+		}
+		p := prefixes[name]
+		for _, other := range m.prefixes {
+			if bytes.Equal(p, other.prefix) {
+				return prefixMetrics{}, fmt.Errorf("%w: %q", errDuplicatePrefix, name)
+			}
+		}
+		m.prefixes = append(m.prefixes, &prefix{
+			prefix:    slices.Clone(p),
+			readTime:  readTime.WithLabelValues(name),
+			readSize:  readSize.WithLabelValues(name),
+			writeTime: writeTime.WithLabelValues(name),
+			writeSize: writeSize.WithLabelValues(name),
+		})
+	}
+	slices.SortStableFunc(m.prefixes, func(a, b *prefix) bool {
+		return len(a.prefix) > len(b.prefix)
+	})
+
+	errs := wrappers.Errs{}
+	for _, c := range []prometheus.Collector{readTime, readSize, writeTime, writeSize} {
+		if err := reg.Register(c); err != nil {
+			errs.Add(fmt.Errorf("%w: %w", metric.ErrFailedRegistering, err))
+		}
+	}
+	return m, errs.Err
+}
+
+// lookup returns the longest prefix of [key], or nil if [key] doesn't start
+// with any of the prefixes.
+func (m *prefixMetrics) lookup(key []byte) *prefix {
+	for _, p := range m.prefixes {
+		if bytes.HasPrefix(key, p.prefix) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (m *prefixMetrics) observeRead(key []byte, duration float64, size int) {
+	if p := m.lookup(key); p != nil {
+		p.readTime.Observe(duration)
+		p.readSize.Add(float64(size))
+	}
+}
+
+func (m *prefixMetrics) observeWrite(key []byte, duration float64, size int) {
+	if p := m.lookup(key); p != nil {
+		p.writeTime.Observe(duration)
+		p.writeSize.Add(float64(size))
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/spf13/cobra"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/database/migrate"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+const progressFileName = "migration_progress.json"
+
+var (
+	errSrcDirRequired = errors.New("--src-db-dir is required")
+	errDstDirRequired = errors.New("--dst-db-dir is required")
+	errSameDir        = errors.New("source and destination databases must be different")
+	errSrcNotFound    = errors.New("source database doesn't exist")
+	errUnknownDBType  = errors.New("unknown db-type")
+)
+
+func main() {
+	var (
+		srcType    string
+		srcDir     string
+		srcVersion string
+		dstType    string
+		dstDir     string
+		dstVersion string
+		batchSize  int
+		prefixLen  int
+		compact    bool
+		skipVerify bool
+		logLevel   string
+	)
+	rootCmd := &cobra.Command{
+		Use:   "dbmigrate",
+		Short: "Copies an offline avalanchego database into a new database",
+		Long: "Copies every key/value pair from a versioned database under --src-db-dir into a versioned database under --dst-db-dir " +
+			"and verifies the per-prefix counts and checksums of both databases. An interrupted migration is resumed when run again with the same arguments.",
+		RunE: func(*cobra.Command, []string) error {
+			if len(srcDir) == 0 {
+				return errSrcDirRequired
+			}
+			if len(dstDir) == 0 {
+				return errDstDirRequired
+			}
+
+			level, err := logging.ToLevel(logLevel)
+			if err != nil {
+				return err
+			}
+			log := logging.NewLogger(
+				"dbmigrate",
+				logging.NewWrappedCore(level, os.Stdout, logging.Colors.ConsoleEncoder()),
+			)
+
+			srcPath, err := versionedPath(srcDir, srcVersion)
+			if err != nil {
+				return err
+			}
+			dstPath, err := versionedPath(dstDir, dstVersion)
+			if err != nil {
+				return err
+			}
+			if filepath.Clean(srcPath) == filepath.Clean(dstPath) {
+				return errSameDir
+			}
+
+			// The source is opened read-only so that migrating can't modify it,
+			// and so that a mistyped path isn't silently created as an empty
+			// database.
+			if _, err := os.Stat(srcPath); err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("%w at %s", errSrcNotFound, srcPath)
+				}
+				return err
+			}
+			src, err := openReadOnlyDB(srcType, srcPath, log)
+			if err != nil {
+				return fmt.Errorf("couldn't open source db at %s: %w", srcPath, err)
+			}
+			defer src.Close()
+
+			dst, err := openDB(dstType, dstPath, log)
+			if err != nil {
+				return fmt.Errorf("couldn't open destination db at %s: %w", dstPath, err)
+			}
+			defer dst.Close()
+
+			log.Info("starting migration",
+				zap.String("srcType", srcType),
+				zap.String("srcPath", srcPath),
+				zap.String("dstType", dstType),
+				zap.String("dstPath", dstPath),
+			)
+			progress, err := migrate.Copy(src, dst, migrate.Config{
+				BatchSize:    batchSize,
+				PrefixLen:    prefixLen,
+				ProgressFile: filepath.Join(dstDir, progressFileName),
+				Log:          log,
+			})
+			if err != nil {
+				return fmt.Errorf("migration failed after copying %d keys: %w", progress.Copied, err)
+			}
+			log.Info("finished copying",
+				zap.Uint64("copied", progress.Copied),
+			)
+
+			if compact {
+				log.Info("compacting destination")
+				if err := dst.Compact(nil, nil); err != nil {
+					return fmt.Errorf("failed to compact destination: %w", err)
+				}
+			}
+
+			if skipVerify {
+				return nil
+			}
+			log.Info("verifying migration")
+			if err := migrate.Verify(src, dst, prefixLen, log); err != nil {
+				return err
+			}
+			log.Info("verified migration")
+			return nil
+		},
+	}
+
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&srcType, "src-db-type", leveldb.Name, fmt.Sprintf("Type of the source database. Should be one of {%s, %s}", leveldb.Name, pebbledb.Name))
+	flags.StringVar(&srcDir, "src-db-dir", "", "Path to the source database directory. Equivalent to the --db-dir used by the node")
+	flags.StringVar(&srcVersion, "src-db-version", version.CurrentDatabase.String(), "Version of the source database")
+	flags.StringVar(&dstType, "dst-db-type", pebbledb.Name, fmt.Sprintf("Type of the destination database. Should be one of {%s, %s}", leveldb.Name, pebbledb.Name))
+	flags.StringVar(&dstDir, "dst-db-dir", "", "Path to the destination database directory")
+	flags.StringVar(&dstVersion, "dst-db-version", version.CurrentDatabase.String(), "Version of the destination database")
+	flags.IntVar(&batchSize, "batch-size", migrate.DefaultBatchSize, "Number of bytes to buffer before writing to the destination database")
+	flags.IntVar(&prefixLen, "prefix-len", migrate.DefaultPrefixLen, "Number of leading key bytes used to group keys during verification")
+	flags.BoolVar(&compact, "compact", false, "Compact the destination database after copying")
+	flags.BoolVar(&skipVerify, "skip-verify", false, "Skip verifying the destination database after copying")
+	flags.StringVar(&logLevel, "log-level", logging.Info.String(), "The log level")
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "dbmigrate failed: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func versionedPath(dir string, dbVersion string) (string, error) {
+	parsedVersion, err := version.Parse(dbVersion)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse db version %q: %w", dbVersion, err)
+	}
+	return filepath.Join(dir, parsedVersion.String()), nil
+}
+
+func openDB(dbType string, path string, log logging.Logger) (database.Database, error) {
+	switch dbType {
+	case leveldb.Name:
+		return leveldb.New(path, nil, log, "", prometheus.NewRegistry())
+	case pebbledb.Name:
+		return pebbledb.New(path, nil, log, "", prometheus.NewRegistry())
+	default:
+		return nil, unknownDBTypeErr(dbType)
+	}
+}
+
+func openReadOnlyDB(dbType string, path string, log logging.Logger) (database.Database, error) {
+	switch dbType {
+	case leveldb.Name:
+		return leveldb.NewReadOnly(path, nil, log, "", prometheus.NewRegistry())
+	case pebbledb.Name:
+		return pebbledb.NewReadOnly(path, nil, log, "", prometheus.NewRegistry())
+	default:
+		return nil, unknownDBTypeErr(dbType)
+	}
+}
+
+func unknownDBTypeErr(dbType string) error {
+	return fmt.Errorf(
+		"%w: %q should have been one of {%s, %s}",
+		errUnknownDBType,
+		dbType,
+		leveldb.Name,
+		pebbledb.Name,
+	)
+}
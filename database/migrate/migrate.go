@@ -0,0 +1,328 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package migrate copies the contents of one database into another and
+// verifies that the copy is complete.
+package migrate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+const (
+	// DefaultBatchSize is the number of bytes that are buffered before being
+	// written to the destination database.
+	DefaultBatchSize = 4 * units.MiB
+
+	// DefaultPrefixLen is the number of leading key bytes used to group keys
+	// when verifying a migration.
+	DefaultPrefixLen = 1
+)
+
+var (
+	ErrMismatch = errors.New("databases don't match")
+
+	errInvalidPrefixLen = errors.New("invalid prefix length")
+	errInvalidBatchSize = errors.New("invalid batch size")
+)
+
+type Config struct {
+	// BatchSize is the number of bytes that are buffered before being written
+	// to the destination database.
+	BatchSize int
+	// PrefixLen is the number of leading key bytes used to group keys when
+	// verifying the migration.
+	PrefixLen int
+	// ProgressFile, if non-empty, is the path of the file used to record the
+	// progress of the migration. If the file already exists, the migration
+	// resumes from the recorded progress.
+	ProgressFile string
+	// Log is used to report the progress of the migration.
+	Log logging.Logger
+}
+
+func (c *Config) Verify() error {
+	switch {
+	case c.BatchSize <= 0:
+		return fmt.Errorf("%w: %d", errInvalidBatchSize, c.BatchSize)
+	case c.PrefixLen < 0:
+		return fmt.Errorf("%w: %d", errInvalidPrefixLen, c.PrefixLen)
+	default:
+		return nil
+	}
+}
+
+// Progress is the persisted state of a migration.
+type Progress struct {
+	// LastKey is the last key that has been written to the destination.
+	LastKey []byte `json:"lastKey"`
+	// Copied is the number of key/value pairs that have been written to the
+	// destination.
+	Copied uint64 `json:"copied"`
+	// Done is true once every key/value pair has been written to the
+	// destination.
+	Done bool `json:"done"`
+}
+
+// PrefixSummary describes the keys in a database that start with a given
+// prefix.
+type PrefixSummary struct {
+	Count    uint64 `json:"count"`
+	Checksum string `json:"checksum"`
+}
+
+// Copy streams every key/value pair from [src] into [dst]. If a progress file
+// is configured, the copy resumes from the last recorded key and the progress
+// is recorded after every batch that is written.
+func Copy(src database.Iteratee, dst database.Batcher, config Config) (Progress, error) {
+	if err := config.Verify(); err != nil {
+		return Progress{}, err
+	}
+
+	progress, err := readProgress(config.ProgressFile)
+	if err != nil {
+		return Progress{}, err
+	}
+	if progress.Done {
+		config.Log.Info("migration already completed",
+			zap.Uint64("copied", progress.Copied),
+		)
+		return progress, nil
+	}
+	if progress.LastKey != nil {
+		config.Log.Info("resuming migration",
+			zap.Binary("lastKey", progress.LastKey),
+			zap.Uint64("copied", progress.Copied),
+		)
+	}
+
+	it := src.NewIteratorWithStart(progress.LastKey)
+	defer it.Release()
+
+	batch := dst.NewBatch()
+	pending := uint64(0)
+	for it.Next() {
+		key := it.Key()
+		if progress.LastKey != nil && bytes.Equal(key, progress.LastKey) {
+			// The last key was already written prior to the restart.
+			continue
+		}
+		if err := batch.Put(key, it.Value()); err != nil {
+			return progress, err
+		}
+		pending++
+
+		if batch.Size() < config.BatchSize {
+			continue
+		}
+
+		if err := batch.Write(); err != nil {
+			return progress, err
+		}
+		batch.Reset()
+
+		progress.LastKey = slices.Clone(key)
+		progress.Copied += pending
+		pending = 0
+		if err := writeProgress(config.ProgressFile, progress); err != nil {
+			return progress, err
+		}
+
+		config.Log.Debug("migrated batch",
+			zap.Binary("lastKey", progress.LastKey),
+			zap.Uint64("copied", progress.Copied),
+		)
+	}
+	if err := it.Error(); err != nil {
+		return progress, err
+	}
+
+	if err := batch.Write(); err != nil {
+		return progress, err
+	}
+	progress.Copied += pending
+	progress.Done = true
+	return progress, writeProgress(config.ProgressFile, progress)
+}
+
+// Summarize returns the number of keys and a checksum of the key/value pairs
+// in [db], grouped by the first [prefixLen] bytes of each key.
+func Summarize(db database.Iteratee, prefixLen int) (map[string]PrefixSummary, error) {
+	if prefixLen < 0 {
+		return nil, fmt.Errorf("%w: %d", errInvalidPrefixLen, prefixLen)
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	var (
+		counts  = make(map[string]uint64)
+		hashers = make(map[string]hash.Hash)
+		lenBuf  [binary.MaxVarintLen64]byte
+	)
+	for it.Next() {
+		key := it.Key()
+		value := it.Value()
+
+		prefix := hex.EncodeToString(key[:math.Min(prefixLen, len(key))])
+		hasher, ok := hashers[prefix]
+		if !ok {
+			hasher = sha256.New()
+			hashers[prefix] = hasher
+		}
+		counts[prefix]++
+
+		// Length prefixing ensures that the key/value boundary is unambiguous.
+		n := binary.PutUvarint(lenBuf[:], uint64(len(key)))
+		_, _ = hasher.Write(lenBuf[:n])
+		_, _ = hasher.Write(key)
+		n = binary.PutUvarint(lenBuf[:], uint64(len(value)))
+		_, _ = hasher.Write(lenBuf[:n])
+		_, _ = hasher.Write(value)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]PrefixSummary, len(counts))
+	for prefix, count := range counts {
+		summaries[prefix] = PrefixSummary{
+			Count:    count,
+			Checksum: hex.EncodeToString(hashers[prefix].Sum(nil)),
+		}
+	}
+	return summaries, nil
+}
+
+// Verify returns an error if [src] and [dst] don't contain the same key/value
+// pairs. Keys are grouped by the first [prefixLen] bytes so that any mismatch
+// can be reported per prefix.
+func Verify(src, dst database.Iteratee, prefixLen int, log logging.Logger) error {
+	srcSummaries, err := Summarize(src, prefixLen)
+	if err != nil {
+		return fmt.Errorf("failed to summarize source: %w", err)
+	}
+	dstSummaries, err := Summarize(dst, prefixLen)
+	if err != nil {
+		return fmt.Errorf("failed to summarize destination: %w", err)
+	}
+
+	prefixes := maps.Keys(srcSummaries)
+	for prefix := range dstSummaries {
+		if _, ok := srcSummaries[prefix]; !ok {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	slices.Sort(prefixes)
+
+	var mismatched []string
+	for _, prefix := range prefixes {
+		srcSummary := srcSummaries[prefix]
+		dstSummary := dstSummaries[prefix]
+		if srcSummary == dstSummary {
+			log.Debug("verified prefix",
+				zap.String("prefix", prefix),
+				zap.Uint64("count", srcSummary.Count),
+			)
+			continue
+		}
+
+		log.Error("prefix mismatch",
+			zap.String("prefix", prefix),
+			zap.Uint64("srcCount", srcSummary.Count),
+			zap.Uint64("dstCount", dstSummary.Count),
+			zap.String("srcChecksum", srcSummary.Checksum),
+			zap.String("dstChecksum", dstSummary.Checksum),
+		)
+		mismatched = append(mismatched, prefix)
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("%w: mismatched prefixes %v", ErrMismatch, mismatched)
+	}
+	return nil
+}
+
+func readProgress(path string) (Progress, error) {
+	var progress Progress
+	if len(path) == 0 {
+		return progress, nil
+	}
+
+	progressBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return progress, fmt.Errorf("failed to read progress file %q: %w", path, err)
+	}
+	if err := json.Unmarshal(progressBytes, &progress); err != nil {
+		return progress, fmt.Errorf("failed to parse progress file %q: %w", path, err)
+	}
+	return progress, nil
+}
+
+func writeProgress(path string, progress Progress) error {
+	if len(path) == 0 {
+		return nil
+	}
+
+	progressBytes, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+
+	// The progress is written to a temporary file that is then renamed over
+	// [path], so that a crash mid-write never leaves a truncated progress file
+	// that would prevent the migration from being resumed.
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary progress file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	if err := writeAndSync(tmpFile, progressBytes); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write temporary progress file %q: %w", tmpPath, err)
+	}
+	if err := os.Chmod(tmpPath, perms.ReadWrite); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace progress file %q: %w", path, err)
+	}
+	return nil
+}
+
+// writeAndSync writes [data] to [file], flushes it to disk, and closes it.
+func writeAndSync(file *os.File, data []byte) error {
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+func newTestDB(t *testing.T, numKeys int) database.Database {
+	db := memdb.New()
+	for i := 0; i < numKeys; i++ {
+		require.NoError(t, db.Put([]byte{byte(i % 4), byte(i)}, []byte{byte(i)}))
+	}
+	return db
+}
+
+func TestCopy(t *testing.T) {
+	require := require.New(t)
+
+	src := newTestDB(t, 100)
+	dst := memdb.New()
+
+	progress, err := Copy(src, dst, Config{
+		BatchSize: 16,
+		PrefixLen: DefaultPrefixLen,
+		Log:       logging.NoLog{},
+	})
+	require.NoError(err)
+	require.True(progress.Done)
+	require.Equal(uint64(100), progress.Copied)
+
+	require.NoError(Verify(src, dst, DefaultPrefixLen, logging.NoLog{}))
+}
+
+func TestCopyResume(t *testing.T) {
+	require := require.New(t)
+
+	src := newTestDB(t, 100)
+	dst := memdb.New()
+	config := Config{
+		BatchSize:    16,
+		PrefixLen:    DefaultPrefixLen,
+		ProgressFile: filepath.Join(t.TempDir(), "progress.json"),
+		Log:          logging.NoLog{},
+	}
+
+	// Simulate a migration that was interrupted after copying the first key.
+	it := src.NewIterator()
+	require.True(it.Next())
+	firstKey := it.Key()
+	require.NoError(dst.Put(firstKey, it.Value()))
+	it.Release()
+	require.NoError(writeProgress(config.ProgressFile, Progress{
+		LastKey: firstKey,
+		Copied:  1,
+	}))
+
+	progress, err := Copy(src, dst, config)
+	require.NoError(err)
+	require.True(progress.Done)
+	require.Equal(uint64(100), progress.Copied)
+	require.NoError(Verify(src, dst, DefaultPrefixLen, logging.NoLog{}))
+
+	// A completed migration should not be run again.
+	require.NoError(dst.Delete(firstKey))
+	progress, err = Copy(src, dst, config)
+	require.NoError(err)
+	require.True(progress.Done)
+
+	has, err := dst.Has(firstKey)
+	require.NoError(err)
+	require.False(has)
+
+	// Progress is written atomically, so no temporary files should remain.
+	entries, err := os.ReadDir(filepath.Dir(config.ProgressFile))
+	require.NoError(err)
+	require.Len(entries, 1)
+}
+
+func TestVerifyMismatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(database.Database) error
+	}{
+		{
+			name: "missing key",
+			modify: func(db database.Database) error {
+				return db.Delete([]byte{0, 0})
+			},
+		},
+		{
+			name: "extra key",
+			modify: func(db database.Database) error {
+				return db.Put([]byte{5}, nil)
+			},
+		},
+		{
+			name: "modified value",
+			modify: func(db database.Database) error {
+				return db.Put([]byte{1, 1}, []byte{2})
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			src := newTestDB(t, 100)
+			dst := newTestDB(t, 100)
+			require.NoError(Verify(src, dst, DefaultPrefixLen, logging.NoLog{}))
+
+			require.NoError(test.modify(dst))
+			err := Verify(src, dst, DefaultPrefixLen, logging.NoLog{})
+			require.ErrorIs(err, ErrMismatch)
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	require := require.New(t)
+
+	db := newTestDB(t, 100)
+	summaries, err := Summarize(db, DefaultPrefixLen)
+	require.NoError(err)
+	require.Len(summaries, 4)
+	for _, summary := range summaries {
+		require.Equal(uint64(25), summary.Count)
+	}
+
+	summaries, err = Summarize(db, 0)
+	require.NoError(err)
+	require.Len(summaries, 1)
+	require.Equal(uint64(100), summaries[""].Count)
+}
@@ -0,0 +1,469 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pebbledb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.uber.org/zap"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+const (
+	// Name is the name of this database for database switches
+	Name = "pebbledb"
+
+	// DefaultCacheSize is the number of bytes to use for block caching in
+	// pebble.
+	DefaultCacheSize = 512 * units.MiB
+
+	// DefaultBytesPerSync is the number of bytes to write to an sstable before
+	// requesting the OS to sync it to disk in the background.
+	DefaultBytesPerSync = 512 * units.KiB
+
+	// DefaultWALBytesPerSync is the number of bytes to write to the WAL before
+	// requesting the OS to sync it to disk in the background.
+	DefaultWALBytesPerSync = 0
+
+	// DefaultMemTableStopWritesThreshold is the maximum number of queued
+	// memtables before writes are stopped.
+	DefaultMemTableStopWritesThreshold = 8
+
+	// DefaultMemTableSize is the number of bytes a memtable may hold before
+	// it is flushed to disk.
+	DefaultMemTableSize = 16 * units.MiB
+
+	// DefaultMaxOpenFiles is the number of files descriptors to cap pebble to
+	// use.
+	DefaultMaxOpenFiles = 4096
+
+	// DefaultBitsPerKey is the number of bits to add to the bloom filter per
+	// key.
+	DefaultBitsPerKey = 10
+
+	// DefaultMetricUpdateFrequency is the frequency to poll the pebble
+	// metrics.
+	DefaultMetricUpdateFrequency = 10 * time.Second
+
+	// pebbleByteOverhead is the number of bytes of constant overhead that
+	// should be added to a batch size per operation.
+	pebbleByteOverhead = 8
+)
+
+var (
+	_ database.Database      = (*Database)(nil)
+	_ database.RangeIteratee = (*Database)(nil)
+
+	ErrInvalidConfig = errors.New("invalid config")
+	ErrCouldNotOpen  = errors.New("could not open")
+
+	errInvalidOperation = errors.New("invalid operation")
+)
+
+// Database is a persistent key-value store backed by pebble. Apart from basic
+// data storage functionality it also supports batch writes and iterating over
+// the keyspace in binary-alphabetical order.
+type Database struct {
+	// [lock] protects [closed] and [openIterators]. Iterators created by this
+	// database are released by Close, so [lock] must be held when registering
+	// or removing them.
+	lock          sync.RWMutex
+	pebbleDB      *pebble.DB
+	closed        bool
+	openIterators set.Set[*iter]
+
+	writeOptions *pebble.WriteOptions
+
+	// metrics is only initialized and used when [MetricUpdateFrequency] is > 0
+	// in the config
+	metrics *metrics
+	// closeCh is closed when Close() is called.
+	closeCh chan struct{}
+	// closeWg is used to wait for all goroutines created by New() to exit.
+	closeWg sync.WaitGroup
+}
+
+type config struct {
+	// CacheSize is the number of bytes of the block cache shared between all
+	// the tables.
+	//
+	// The default value is 512MiB.
+	CacheSize int64 `json:"cacheSize"`
+	// BytesPerSync is the number of bytes to write to an sstable before
+	// requesting the OS to sync it to disk in the background.
+	//
+	// The default value is 512KiB.
+	BytesPerSync int `json:"bytesPerSync"`
+	// WALBytesPerSync is the number of bytes to write to the WAL before
+	// requesting the OS to sync it to disk in the background. 0 disables
+	// background syncing of the WAL.
+	//
+	// The default value is 0.
+	WALBytesPerSync int `json:"walBytesPerSync"`
+	// MemTableStopWritesThreshold is the hard limit on the number of queued
+	// memtables. Writes are stopped once this threshold is reached.
+	//
+	// The default value is 8.
+	MemTableStopWritesThreshold int `json:"memTableStopWritesThreshold"`
+	// MemTableSize is the number of bytes a memtable may hold before it is
+	// flushed to disk.
+	//
+	// The default value is 16MiB.
+	MemTableSize int `json:"memTableSize"`
+	// MaxOpenFiles is the soft limit on the number of open files that pebble
+	// may use.
+	//
+	// The default value is 4096.
+	MaxOpenFiles int `json:"maxOpenFiles"`
+	// MaxConcurrentCompactions is the maximum number of concurrent
+	// compactions. If <= 0, the number of available CPUs is used.
+	//
+	// The default value is 0.
+	MaxConcurrentCompactions int `json:"maxConcurrentCompactions"`
+	// FilterBitsPerKey is the number of bits to add to the bloom filter per
+	// key.
+	//
+	// The default value is 10.
+	FilterBitsPerKey int `json:"filterBitsPerKey"`
+	// Sync defines whether writes should be synced to disk before returning.
+	//
+	// The default value is false.
+	Sync bool `json:"sync"`
+
+	// MetricUpdateFrequency is the frequency to poll pebble metrics.
+	// If <= 0, pebble metrics aren't polled.
+	MetricUpdateFrequency time.Duration `json:"metricUpdateFrequency"`
+}
+
+// New returns a wrapped pebble DB object.
+func New(file string, configBytes []byte, log logging.Logger, namespace string, reg prometheus.Registerer) (database.Database, error) {
+	return newDB(file, configBytes, log, namespace, reg, false)
+}
+
+// NewReadOnly returns a wrapped pebble DB object that can't be written to. The
+// database must already exist at [file]. Writes return database.ErrReadOnly.
+func NewReadOnly(file string, configBytes []byte, log logging.Logger, namespace string, reg prometheus.Registerer) (database.Database, error) {
+	return newDB(file, configBytes, log, namespace, reg, true)
+}
+
+func newDB(file string, configBytes []byte, log logging.Logger, namespace string, reg prometheus.Registerer, readOnly bool) (database.Database, error) {
+	parsedConfig := config{
+		CacheSize:                   DefaultCacheSize,
+		BytesPerSync:                DefaultBytesPerSync,
+		WALBytesPerSync:             DefaultWALBytesPerSync,
+		MemTableStopWritesThreshold: DefaultMemTableStopWritesThreshold,
+		MemTableSize:                DefaultMemTableSize,
+		MaxOpenFiles:                DefaultMaxOpenFiles,
+		FilterBitsPerKey:            DefaultBitsPerKey,
+		MetricUpdateFrequency:       DefaultMetricUpdateFrequency,
+	}
+	if len(configBytes) > 0 {
+		if err := json.Unmarshal(configBytes, &parsedConfig); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+
+	log.Info("creating pebble",
+		zap.Reflect("config", parsedConfig),
+		zap.Bool("readOnly", readOnly),
+	)
+
+	maxConcurrentCompactions := parsedConfig.MaxConcurrentCompactions
+	if maxConcurrentCompactions <= 0 {
+		maxConcurrentCompactions = runtime.NumCPU()
+	}
+
+	wrappedDB := &Database{
+		openIterators: set.Set[*iter]{},
+		writeOptions:  &pebble.WriteOptions{Sync: parsedConfig.Sync},
+		closeCh:       make(chan struct{}),
+	}
+
+	opts := &pebble.Options{
+		Cache:                       pebble.NewCache(parsedConfig.CacheSize),
+		BytesPerSync:                parsedConfig.BytesPerSync,
+		WALBytesPerSync:             parsedConfig.WALBytesPerSync,
+		MemTableStopWritesThreshold: parsedConfig.MemTableStopWritesThreshold,
+		MemTableSize:                parsedConfig.MemTableSize,
+		MaxOpenFiles:                parsedConfig.MaxOpenFiles,
+		MaxConcurrentCompactions:    func() int { return maxConcurrentCompactions },
+		ReadOnly:                    readOnly,
+	}
+	opts.Experimental.ReadSamplingMultiplier = -1 // Disable seek compaction
+	// The cache is reference counted. Release our reference once the DB holds
+	// its own.
+	defer opts.Cache.Unref()
+
+	// Setup bloom filters for every level.
+	opts.Levels = make([]pebble.LevelOptions, 7)
+	for i := range opts.Levels {
+		opts.Levels[i].FilterPolicy = bloom.FilterPolicy(parsedConfig.FilterBitsPerKey)
+		opts.Levels[i].FilterType = pebble.TableFilter
+	}
+
+	if parsedConfig.MetricUpdateFrequency > 0 {
+		metrics, err := newMetrics(namespace, reg)
+		if err != nil {
+			return nil, err
+		}
+		wrappedDB.metrics = metrics
+		opts.EventListener = &pebble.EventListener{
+			WriteStallBegin: wrappedDB.onWriteStallBegin,
+			WriteStallEnd:   wrappedDB.onWriteStallEnd,
+		}
+	}
+
+	db, err := pebble.Open(file, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCouldNotOpen, err)
+	}
+	wrappedDB.pebbleDB = db
+
+	if parsedConfig.MetricUpdateFrequency > 0 {
+		wrappedDB.closeWg.Add(1)
+		go func() {
+			t := time.NewTicker(parsedConfig.MetricUpdateFrequency)
+			defer func() {
+				t.Stop()
+				wrappedDB.closeWg.Done()
+			}()
+
+			for {
+				wrappedDB.updateMetrics()
+
+				select {
+				case <-t.C:
+				case <-wrappedDB.closeCh:
+					return
+				}
+			}
+		}()
+	}
+	return wrappedDB, nil
+}
+
+// Has returns if the key is set in the database
+func (db *Database) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return false, database.ErrClosed
+	}
+
+	_, closer, err := db.pebbleDB.Get(key)
+	if err == pebble.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, updateError(err)
+	}
+	return true, closer.Close()
+}
+
+// Get returns the value the key maps to in the database
+func (db *Database) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	data, closer, err := db.pebbleDB.Get(key)
+	if err != nil {
+		return nil, updateError(err)
+	}
+	// The returned slice is only valid until [closer] is closed, so it must
+	// be copied.
+	value := slices.Clone(data)
+	return value, closer.Close()
+}
+
+// Put sets the value of the provided key to the provided value
+func (db *Database) Put(key []byte, value []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return updateError(db.pebbleDB.Set(key, value, db.writeOptions))
+}
+
+// Delete removes the key from the database
+func (db *Database) Delete(key []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return updateError(db.pebbleDB.Delete(key, db.writeOptions))
+}
+
+// NewBatch creates a write/delete-only buffer that is atomically committed to
+// the database when write is called
+func (db *Database) NewBatch() database.Batch {
+	return &batch{
+		db:    db,
+		batch: db.pebbleDB.NewBatch(),
+	}
+}
+
+// NewIterator creates a lexicographically ordered iterator over the database
+func (db *Database) NewIterator() database.Iterator {
+	return db.NewIteratorWithOptions(database.IteratorOptions{})
+}
+
+// NewIteratorWithStart creates a lexicographically ordered iterator over the
+// database starting at the provided key
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithOptions(database.IteratorOptions{
+		Start: start,
+	})
+}
+
+// NewIteratorWithPrefix creates a lexicographically ordered iterator over the
+// database ignoring keys that do not start with the provided prefix
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithOptions(database.IteratorOptions{
+		Prefix: prefix,
+	})
+}
+
+// NewIteratorWithStartAndPrefix creates a lexicographically ordered iterator
+// over the database starting at start and ignoring keys that do not start with
+// the provided prefix
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return db.NewIteratorWithOptions(database.IteratorOptions{
+		Start:  start,
+		Prefix: prefix,
+	})
+}
+
+// NewIteratorWithOptions creates an iterator over the keys described by
+// [options]
+func (db *Database) NewIteratorWithOptions(options database.IteratorOptions) database.Iterator {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+
+	lowerBound, upperBound := options.Bounds()
+	it := &iter{
+		db: db,
+		iter: db.pebbleDB.NewIter(&pebble.IterOptions{
+			LowerBound: slices.Clone(lowerBound),
+			UpperBound: slices.Clone(upperBound),
+		}),
+		reverse: options.Reverse,
+	}
+	db.openIterators.Add(it)
+	return it
+}
+
+// Compact the underlying DB for the given key range.
+//
+// A nil start is treated as a key before all keys in the DB.
+// And a nil limit is treated as a key after all keys in the DB.
+// Therefore if both are nil then it will compact entire DB.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	if limit == nil {
+		// Pebble treats the end of the range as exclusive and has no way to
+		// represent "after all keys", so compact up to the key immediately
+		// after the last key in the DB.
+		it := db.pebbleDB.NewIter(&pebble.IterOptions{})
+		if it.Last() {
+			limit = append(slices.Clone(it.Key()), 0)
+		}
+		if err := it.Close(); err != nil {
+			return updateError(err)
+		}
+		if limit == nil {
+			// The DB is empty.
+			return nil
+		}
+	}
+
+	if bytes.Compare(start, limit) >= 0 {
+		// Pebble requires [start] < [limit]. Nothing to compact.
+		return nil
+	}
+	return updateError(db.pebbleDB.Compact(start, limit, true /*=parallelize*/))
+}
+
+func (db *Database) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	db.closed = true
+	close(db.closeCh)
+
+	for it := range db.openIterators {
+		it.lock.Lock()
+		it.release()
+		it.lock.Unlock()
+	}
+	db.openIterators.Clear()
+
+	db.closeWg.Wait()
+	return updateError(db.pebbleDB.Close())
+}
+
+func (db *Database) HealthCheck(context.Context) (interface{}, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	return nil, nil
+}
+
+func updateError(err error) error {
+	switch {
+	case errors.Is(err, pebble.ErrClosed):
+		return database.ErrClosed
+	case errors.Is(err, pebble.ErrNotFound):
+		return database.ErrNotFound
+	case errors.Is(err, pebble.ErrReadOnly):
+		return database.ErrReadOnly
+	default:
+		return err
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pebbledb
+
+import (
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+var _ database.Iterator = (*iter)(nil)
+
+type iter struct {
+	// [lock] ensures that only one goroutine can access [iter] at a time.
+	// Close calls release on every open iterator, so [lock] is required to
+	// ensure that the user and Close don't release the iterator concurrently.
+	// Invariant: [Database.lock] is never grabbed while holding [lock].
+	lock sync.Mutex
+
+	db   *Database
+	iter *pebble.Iterator
+	// reverse iterates from the last key to the first key.
+	reverse bool
+
+	initialized bool
+	released    bool
+	err         error
+
+	key, val []byte
+}
+
+func (it *iter) Next() bool {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	switch {
+	case it.err != nil:
+		it.key = nil
+		it.val = nil
+		return false
+	case it.released:
+		// Short-circuit and set an error if the underlying database has been
+		// closed.
+		it.key = nil
+		it.val = nil
+		it.err = database.ErrClosed
+		return false
+	}
+
+	var hasNext bool
+	switch {
+	case !it.initialized && it.reverse:
+		hasNext = it.iter.Last()
+		it.initialized = true
+	case !it.initialized:
+		hasNext = it.iter.First()
+		it.initialized = true
+	case it.reverse:
+		hasNext = it.iter.Prev()
+	default:
+		hasNext = it.iter.Next()
+	}
+
+	if !hasNext {
+		it.key = nil
+		it.val = nil
+		return false
+	}
+
+	it.key = slices.Clone(it.iter.Key())
+	it.val = slices.Clone(it.iter.Value())
+	return true
+}
+
+func (it *iter) Error() error {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	if it.err != nil || it.released {
+		return it.err
+	}
+	return updateError(it.iter.Error())
+}
+
+func (it *iter) Key() []byte {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	return it.key
+}
+
+func (it *iter) Value() []byte {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	return it.val
+}
+
+func (it *iter) Release() {
+	it.db.lock.Lock()
+	defer it.db.lock.Unlock()
+
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	it.release()
+	it.db.openIterators.Remove(it)
+}
+
+// release closes the underlying pebble iterator.
+// Assumes [it.lock] is held.
+func (it *iter) release() {
+	if it.released {
+		return
+	}
+	it.released = true
+
+	if err := it.iter.Close(); err != nil && it.err == nil {
+		it.err = updateError(err)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pebbledb
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var levelLabels = []string{"level"}
+
+type metrics struct {
+	// [lock] protects [writeStallStart]
+	lock sync.Mutex
+	// time that the current write stall began, or the zero value if writes
+	// aren't currently stalled
+	writeStallStart time.Time
+
+	// total number of writes that have been delayed due to compaction
+	writesDelayedCount prometheus.Counter
+	// total amount of time (in ns) that writes that have been delayed due to
+	// compaction
+	writesDelayedDuration prometheus.Counter
+	// set to 1 if there is currently at least one write that is being delayed
+	// due to compaction
+	writeIsDelayed prometheus.Gauge
+
+	// total number of bytes used on disk, including live and obsolete files
+	diskSize prometheus.Gauge
+	// total number of bytes written to the WAL
+	walWrite prometheus.Gauge
+	// current read amplification of the database
+	readAmplification prometheus.Gauge
+
+	// total number of bytes of cached data blocks
+	blockCacheSize prometheus.Gauge
+	// total number of block cache hits
+	blockCacheHits prometheus.Gauge
+	// total number of block cache misses
+	blockCacheMisses prometheus.Gauge
+	// current number of bytes held by memtables
+	memTableSize prometheus.Gauge
+	// current number of open tables
+	openTables prometheus.Gauge
+
+	// number of tables per level
+	levelTableCount *prometheus.GaugeVec
+	// size of each level
+	levelSize *prometheus.GaugeVec
+	// amount of bytes read while compacting each level
+	levelReads *prometheus.GaugeVec
+	// amount of bytes written while compacting each level
+	levelWrites *prometheus.GaugeVec
+
+	// total number of memtable flushes performed
+	flushes prometheus.Gauge
+	// total number of compactions performed
+	compactions prometheus.Gauge
+}
+
+func newMetrics(namespace string, reg prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		writesDelayedCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "writes_delayed",
+			Help:      "number of cumulative writes that have been delayed due to compaction",
+		}),
+		writesDelayedDuration: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "writes_delayed_duration",
+			Help:      "amount of time (in ns) that writes have been delayed due to compaction",
+		}),
+		writeIsDelayed: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "write_delayed",
+			Help:      "1 if there is currently a write that is being delayed due to compaction",
+		}),
+
+		diskSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "disk_size",
+			Help:      "total amount of bytes used on disk, including obsolete files",
+		}),
+		walWrite: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "wal_write",
+			Help:      "cumulative amount of bytes written to the WAL",
+		}),
+		readAmplification: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "read_amplification",
+			Help:      "current read amplification",
+		}),
+
+		blockCacheSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "block_cache_size",
+			Help:      "total size of cached blocks",
+		}),
+		blockCacheHits: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "block_cache_hits",
+			Help:      "cumulative number of block cache hits",
+		}),
+		blockCacheMisses: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "block_cache_misses",
+			Help:      "cumulative number of block cache misses",
+		}),
+		memTableSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "mem_table_size",
+			Help:      "total size of the memtables",
+		}),
+		openTables: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "open_tables",
+			Help:      "number of currently opened tables",
+		}),
+
+		levelTableCount: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "table_count",
+				Help:      "number of tables allocated by level",
+			},
+			levelLabels,
+		),
+		levelSize: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "size",
+				Help:      "amount of bytes allocated by level",
+			},
+			levelLabels,
+		),
+		levelReads: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "reads",
+				Help:      "cumulative amount of bytes read during compaction by level",
+			},
+			levelLabels,
+		),
+		levelWrites: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "writes",
+				Help:      "cumulative amount of bytes written during compaction by level",
+			},
+			levelLabels,
+		),
+
+		flushes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "flushes",
+			Help:      "cumulative number of memtable flushes performed",
+		}),
+		compactions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "comps",
+			Help:      "cumulative number of compactions performed",
+		}),
+	}
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		reg.Register(m.writesDelayedCount),
+		reg.Register(m.writesDelayedDuration),
+		reg.Register(m.writeIsDelayed),
+
+		reg.Register(m.diskSize),
+		reg.Register(m.walWrite),
+		reg.Register(m.readAmplification),
+
+		reg.Register(m.blockCacheSize),
+		reg.Register(m.blockCacheHits),
+		reg.Register(m.blockCacheMisses),
+		reg.Register(m.memTableSize),
+		reg.Register(m.openTables),
+
+		reg.Register(m.levelTableCount),
+		reg.Register(m.levelSize),
+		reg.Register(m.levelReads),
+		reg.Register(m.levelWrites),
+
+		reg.Register(m.flushes),
+		reg.Register(m.compactions),
+	)
+	return m, errs.Err
+}
+
+func (db *Database) onWriteStallBegin(pebble.WriteStallBeginInfo) {
+	metrics := db.metrics
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	metrics.writeStallStart = time.Now()
+	metrics.writesDelayedCount.Inc()
+	metrics.writeIsDelayed.Set(1)
+}
+
+func (db *Database) onWriteStallEnd() {
+	metrics := db.metrics
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	if !metrics.writeStallStart.IsZero() {
+		metrics.writesDelayedDuration.Add(float64(time.Since(metrics.writeStallStart)))
+	}
+	metrics.writeStallStart = time.Time{}
+	metrics.writeIsDelayed.Set(0)
+}
+
+func (db *Database) updateMetrics() {
+	metrics := db.metrics
+	stats := db.pebbleDB.Metrics()
+
+	metrics.diskSize.Set(float64(stats.DiskSpaceUsage()))
+	metrics.walWrite.Set(float64(stats.WAL.BytesWritten))
+	metrics.readAmplification.Set(float64(stats.ReadAmp()))
+
+	metrics.blockCacheSize.Set(float64(stats.BlockCache.Size))
+	metrics.blockCacheHits.Set(float64(stats.BlockCache.Hits))
+	metrics.blockCacheMisses.Set(float64(stats.BlockCache.Misses))
+	metrics.memTableSize.Set(float64(stats.MemTable.Size))
+	metrics.openTables.Set(float64(stats.TableCache.Count))
+
+	for level, levelStats := range stats.Levels {
+		levelStr := strconv.Itoa(level)
+		metrics.levelTableCount.WithLabelValues(levelStr).Set(float64(levelStats.NumFiles))
+		metrics.levelSize.WithLabelValues(levelStr).Set(float64(levelStats.Size))
+		metrics.levelReads.WithLabelValues(levelStr).Set(float64(levelStats.BytesRead))
+		metrics.levelWrites.WithLabelValues(levelStr).Set(float64(levelStats.BytesCompacted + levelStats.BytesFlushed))
+	}
+
+	metrics.flushes.Set(float64(stats.Flush.Count))
+	metrics.compactions.Set(float64(stats.Compact.Count))
+}
@@ -18,9 +18,10 @@ const (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.RangeIteratee = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.Iterator      = (*iterator)(nil)
 )
 
 // Database partitions a database into a sub-database by prefixing all keys with
@@ -64,6 +65,12 @@ func NewNested(prefix []byte, db database.Database) *Database {
 	}
 }
 
+// Prefix returns the bytes that are prepended to every key of this database
+// when it is written to the underlying database.
+func (db *Database) Prefix() []byte {
+	return slices.Clone(db.dbPrefix)
+}
+
 // Assumes that it is OK for the argument to db.db.Has
 // to be modified after db.db.Has returns
 // [key] may be modified after this method returns.
@@ -170,6 +177,32 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	return it
 }
 
+// It is safe to modify [options] after this method returns.
+func (db *Database) NewIteratorWithOptions(options database.IteratorOptions) database.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	prefixedOptions := database.IteratorOptions{
+		Prefix:  db.prefixedBound(options.Prefix),
+		Reverse: options.Reverse,
+	}
+	if len(options.Start) > 0 {
+		prefixedOptions.Start = db.prefixedBound(options.Start)
+	}
+	if len(options.End) > 0 {
+		prefixedOptions.End = db.prefixedBound(options.End)
+	}
+	return &iterator{
+		Iterator: database.NewIteratorWithOptions(db.db, prefixedOptions),
+		db:       db,
+	}
+}
+
 func (db *Database) Compact(start, limit []byte) error {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -229,6 +262,16 @@ func (db *Database) prefix(key []byte) []byte {
 	return prefixedKey
 }
 
+// prefixedBound returns [key] with the prefix of this database prepended.
+// Unlike prefix, the returned slice isn't taken from the buffer pool, so it may
+// be retained by iterators.
+func (db *Database) prefixedBound(key []byte) []byte {
+	prefixedKey := make([]byte, len(db.dbPrefix)+len(key))
+	copy(prefixedKey, db.dbPrefix)
+	copy(prefixedKey[len(db.dbPrefix):], key)
+	return prefixedKey
+}
+
 // Batch of database operations
 type batch struct {
 	database.Batch
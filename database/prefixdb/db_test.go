@@ -4,8 +4,11 @@
 package prefixdb
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 )
@@ -22,6 +25,23 @@ func TestInterface(t *testing.T) {
 	}
 }
 
+func TestPrefix(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db := New([]byte("wor"), New([]byte("ld"), baseDB))
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+
+	it := baseDB.NewIterator()
+	defer it.Release()
+
+	require.True(it.Next())
+	require.True(bytes.HasPrefix(it.Key(), db.Prefix()))
+	require.Equal([]byte("key"), it.Key()[len(db.Prefix()):])
+	require.False(it.Next())
+	require.NoError(it.Error())
+}
+
 func FuzzKeyValue(f *testing.F) {
 	database.FuzzKeyValue(f, New([]byte(""), memdb.New()))
 }
@@ -30,6 +50,10 @@ func FuzzNewIteratorWithPrefix(f *testing.F) {
 	database.FuzzNewIteratorWithPrefix(f, New([]byte(""), memdb.New()))
 }
 
+func FuzzNewIteratorWithOptions(f *testing.F) {
+	database.FuzzNewIteratorWithOptions(f, New([]byte(""), memdb.New()))
+}
+
 func BenchmarkInterface(b *testing.B) {
 	for _, size := range database.BenchmarkSizes {
 		keys, values := database.SetupBenchmark(b, size[0], size[1], size[2])
@@ -18,9 +18,10 @@ import (
 )
 
 var (
-	_ database.Database = (*DatabaseClient)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database      = (*DatabaseClient)(nil)
+	_ database.RangeIteratee = (*DatabaseClient)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.Iterator      = (*iterator)(nil)
 )
 
 // DatabaseClient is an implementation of database that talks over RPC.
@@ -111,6 +112,28 @@ func (db *DatabaseClient) NewIteratorWithStartAndPrefix(start, prefix []byte) da
 	return newIterator(db, resp.Id)
 }
 
+// NewIteratorWithOptions returns a new iterator over the keys described by
+// [options]
+func (db *DatabaseClient) NewIteratorWithOptions(options database.IteratorOptions) database.Iterator {
+	resp, err := db.client.NewIteratorWithStartAndPrefix(context.Background(), &rpcdbpb.NewIteratorWithStartAndPrefixRequest{
+		Start:   options.Start,
+		Prefix:  options.Prefix,
+		End:     options.End,
+		Reverse: options.Reverse,
+	})
+	if err != nil {
+		return &database.IteratorError{
+			Err: err,
+		}
+	}
+	it := newIterator(db, resp.Id)
+	if resp.OptionsSupported {
+		return it
+	}
+	// The server ignored [End] and [Reverse], so they are applied locally.
+	return database.ApplyIteratorOptions(it, options)
+}
+
 // Compact attempts to optimize the space utilization in the provided range
 func (db *DatabaseClient) Compact(start, limit []byte) error {
 	resp, err := db.client.Compact(context.Background(), &rpcdbpb.CompactRequest{
@@ -130,7 +130,12 @@ func (db *DatabaseServer) WriteBatch(_ context.Context, req *rpcdbpb.WriteBatchR
 // NewIteratorWithStartAndPrefix allocates an iterator and returns the iterator
 // ID
 func (db *DatabaseServer) NewIteratorWithStartAndPrefix(_ context.Context, req *rpcdbpb.NewIteratorWithStartAndPrefixRequest) (*rpcdbpb.NewIteratorWithStartAndPrefixResponse, error) {
-	it := db.db.NewIteratorWithStartAndPrefix(req.Start, req.Prefix)
+	it := database.NewIteratorWithOptions(db.db, database.IteratorOptions{
+		Start:   req.Start,
+		End:     req.End,
+		Prefix:  req.Prefix,
+		Reverse: req.Reverse,
+	})
 
 	db.iteratorLock.Lock()
 	defer db.iteratorLock.Unlock()
@@ -138,7 +143,10 @@ func (db *DatabaseServer) NewIteratorWithStartAndPrefix(_ context.Context, req *
 	id := db.nextIteratorID
 	db.iterators[id] = it
 	db.nextIteratorID++
-	return &rpcdbpb.NewIteratorWithStartAndPrefixResponse{Id: id}, nil
+	return &rpcdbpb.NewIteratorWithStartAndPrefixResponse{
+		Id:               id,
+		OptionsSupported: true,
+	}, nil
 }
 
 // IteratorNext attempts to call next on the requested iterator
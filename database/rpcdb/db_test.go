@@ -23,7 +23,32 @@ type testDatabase struct {
 	closeFn func()
 }
 
+// legacyServer is a DatabaseServer that doesn't support iterator options, as
+// served by older versions.
+type legacyServer struct {
+	*DatabaseServer
+}
+
+func (s legacyServer) NewIteratorWithStartAndPrefix(ctx context.Context, req *rpcdbpb.NewIteratorWithStartAndPrefixRequest) (*rpcdbpb.NewIteratorWithStartAndPrefixResponse, error) {
+	resp, err := s.DatabaseServer.NewIteratorWithStartAndPrefix(ctx, &rpcdbpb.NewIteratorWithStartAndPrefixRequest{
+		Start:  req.Start,
+		Prefix: req.Prefix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rpcdbpb.NewIteratorWithStartAndPrefixResponse{
+		Id: resp.Id,
+	}, nil
+}
+
 func setupDB(t testing.TB) *testDatabase {
+	return setupDBWithServer(t, func(db database.Database) rpcdbpb.DatabaseServer {
+		return NewServer(db)
+	})
+}
+
+func setupDBWithServer(t testing.TB, newServer func(database.Database) rpcdbpb.DatabaseServer) *testDatabase {
 	require := require.New(t)
 
 	db := &testDatabase{
@@ -35,7 +60,7 @@ func setupDB(t testing.TB) *testDatabase {
 	serverCloser := grpcutils.ServerCloser{}
 
 	server := grpcutils.NewServer()
-	rpcdbpb.RegisterDatabaseServer(server, NewServer(db.server))
+	rpcdbpb.RegisterDatabaseServer(server, newServer(db.server))
 	serverCloser.Add(server)
 
 	go grpcutils.Serve(listener, server)
@@ -61,6 +86,20 @@ func TestInterface(t *testing.T) {
 	}
 }
 
+func TestInterfaceLegacyServer(t *testing.T) {
+	newLegacyServer := func(db database.Database) rpcdbpb.DatabaseServer {
+		return legacyServer{
+			DatabaseServer: NewServer(db),
+		}
+	}
+	for _, test := range database.Tests {
+		db := setupDBWithServer(t, newLegacyServer)
+		test(t, db.client)
+
+		db.closeFn()
+	}
+}
+
 func FuzzKeyValue(f *testing.F) {
 	db := setupDB(f)
 	database.FuzzKeyValue(f, db.client)
@@ -75,6 +114,13 @@ func FuzzNewIteratorWithPrefix(f *testing.F) {
 	db.closeFn()
 }
 
+func FuzzNewIteratorWithOptions(f *testing.F) {
+	db := setupDB(f)
+	database.FuzzNewIteratorWithOptions(f, db.client)
+
+	db.closeFn()
+}
+
 func BenchmarkInterface(b *testing.B) {
 	for _, size := range database.BenchmarkSizes {
 		keys, values := database.SetupBenchmark(b, size[0], size[1], size[2])
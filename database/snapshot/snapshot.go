@@ -0,0 +1,415 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package snapshot creates and restores point-in-time copies of the databases
+// managed by a node.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.uber.org/zap"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+const (
+	// ManifestFileName is the name of the file, in the root of a snapshot,
+	// that describes the snapshot. A snapshot is only complete once its
+	// manifest has been written.
+	ManifestFileName = "manifest.json"
+
+	// writeBatchSize is the number of bytes that are buffered before being
+	// written to a snapshot database.
+	writeBatchSize = 4 * units.MiB
+
+	tmpSuffix = ".tmp"
+)
+
+var (
+	_ Creator = (*Snapshotter)(nil)
+
+	ErrInvalidName        = errors.New("invalid snapshot name")
+	ErrAlreadyExists      = errors.New("snapshot already exists")
+	ErrUnsupportedDBType  = errors.New("snapshots are not supported for this db-type")
+	ErrMismatchedDBType   = errors.New("snapshot db-type doesn't match the configured db-type")
+	ErrDBDirNotEmpty      = errors.New("db-dir is not empty")
+	errMissingManifest    = errors.New("missing snapshot manifest")
+	errSnapshotInProgress = errors.New("snapshot already in progress")
+)
+
+// NewDBFunc creates a new database at the provided path.
+type NewDBFunc func(path string, config []byte, log logging.Logger, namespace string, reg prometheus.Registerer) (database.Database, error)
+
+// Creator creates snapshots of a node's databases.
+type Creator interface {
+	// Create writes a consistent snapshot of every managed database into a
+	// new directory named [name] and returns the manifest of the snapshot.
+	Create(ctx context.Context, name string) (*Manifest, error)
+}
+
+// Manifest describes the contents of a snapshot.
+type Manifest struct {
+	// DBType is the type of the databases in the snapshot.
+	DBType string `json:"dbType"`
+	// Timestamp is the time the snapshot was taken.
+	Timestamp time.Time `json:"timestamp"`
+	// Databases are the versioned databases included in the snapshot.
+	Databases []DatabaseManifest `json:"databases"`
+	// Chains are the chains that were running when the snapshot was taken.
+	Chains []ChainManifest `json:"chains"`
+}
+
+type DatabaseManifest struct {
+	Version string `json:"version"`
+	NumKeys uint64 `json:"numKeys"`
+}
+
+type ChainManifest struct {
+	ChainID      ids.ID `json:"chainID"`
+	Name         string `json:"name"`
+	LastAccepted ids.ID `json:"lastAccepted"`
+	Height       uint64 `json:"height"`
+}
+
+type Config struct {
+	Log logging.Logger
+	// DBType is the type of the databases managed by [DBManager].
+	DBType string
+	// NewDB creates the databases the snapshot is written into. If nil,
+	// snapshots are not supported.
+	NewDB NewDBFunc
+	// DBConfig is the config passed to [NewDB].
+	DBConfig  []byte
+	DBManager manager.Manager
+	// Dir is the directory that snapshots are written into.
+	Dir string
+}
+
+type chain struct {
+	name string
+	ctx  *snow.ConsensusContext
+	vm   common.VM
+}
+
+// Snapshotter tracks the chains running on a node so that chain acceptance can
+// be paused while a snapshot is started.
+type Snapshotter struct {
+	config Config
+
+	// [createLock] ensures only one snapshot is taken at a time
+	createLock sync.Mutex
+
+	// [chainsLock] protects [chains]
+	chainsLock sync.Mutex
+	chains     map[ids.ID]*chain
+}
+
+func New(config Config) *Snapshotter {
+	return &Snapshotter{
+		config: config,
+		chains: make(map[ids.ID]*chain),
+	}
+}
+
+// RegisterChain implements chains.Registrant.
+func (s *Snapshotter) RegisterChain(name string, ctx *snow.ConsensusContext, vm common.VM) {
+	s.chainsLock.Lock()
+	defer s.chainsLock.Unlock()
+
+	s.chains[ctx.ChainID] = &chain{
+		name: name,
+		ctx:  ctx,
+		vm:   vm,
+	}
+}
+
+// Create writes a snapshot of every managed database into a new directory.
+//
+// Every chain's lock is held while the last accepted blocks are recorded and
+// the database iterators are opened. Because iterators are point-in-time
+// views, chains may continue accepting while the snapshot is written.
+func (s *Snapshotter) Create(ctx context.Context, name string) (*Manifest, error) {
+	if s.config.NewDB == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedDBType, s.config.DBType)
+	}
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	if !s.createLock.TryLock() {
+		return nil, errSnapshotInProgress
+	}
+	defer s.createLock.Unlock()
+
+	snapshotDir := filepath.Join(s.config.Dir, name)
+	if _, err := os.Stat(snapshotDir); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyExists, snapshotDir)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// Any partially written snapshot from a prior failure is removed.
+	tmpDir := snapshotDir + tmpSuffix
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(tmpDir, perms.ReadWriteExecute); err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		DBType:    s.config.DBType,
+		Timestamp: time.Now().UTC(),
+	}
+	dbs := s.config.DBManager.GetDatabases()
+	iterators, err := s.quiesce(ctx, manifest, dbs)
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, err
+	}
+
+	for i, db := range dbs {
+		numKeys, err := s.write(filepath.Join(tmpDir, db.Version.String()), iterators[i])
+		if err != nil {
+			for _, it := range iterators[i:] {
+				it.Release()
+			}
+			_ = os.RemoveAll(tmpDir)
+			return nil, fmt.Errorf("failed to write database %s: %w", db.Version, err)
+		}
+		manifest.Databases = append(manifest.Databases, DatabaseManifest{
+			Version: db.Version.String(),
+			NumKeys: numKeys,
+		})
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, err
+	}
+	if err := perms.WriteFile(filepath.Join(tmpDir, ManifestFileName), manifestBytes, perms.ReadWrite); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, err
+	}
+	if err := os.Rename(tmpDir, snapshotDir); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, err
+	}
+
+	s.config.Log.Info("created database snapshot",
+		zap.String("path", snapshotDir),
+		zap.Int("numChains", len(manifest.Chains)),
+	)
+	return manifest, nil
+}
+
+// quiesce holds every chain's lock while the last accepted block of each chain
+// is recorded into [manifest] and an iterator is opened over each database.
+func (s *Snapshotter) quiesce(
+	ctx context.Context,
+	manifest *Manifest,
+	dbs []*manager.VersionedDatabase,
+) ([]database.Iterator, error) {
+	s.chainsLock.Lock()
+	chainIDs := maps.Keys(s.chains)
+	chains := make([]*chain, len(chainIDs))
+	utils.Sort(chainIDs)
+	for i, chainID := range chainIDs {
+		chains[i] = s.chains[chainID]
+	}
+	s.chainsLock.Unlock()
+
+	// Locks are always grabbed in the same order to avoid deadlocking with
+	// concurrent snapshots.
+	for _, chain := range chains {
+		chain.ctx.Lock.Lock()
+	}
+	defer func() {
+		for _, chain := range chains {
+			chain.ctx.Lock.Unlock()
+		}
+	}()
+
+	for _, chain := range chains {
+		chainManifest := ChainManifest{
+			ChainID: chain.ctx.ChainID,
+			Name:    chain.name,
+		}
+		if vm, ok := chain.vm.(block.ChainVM); ok {
+			lastAcceptedID, err := vm.LastAccepted(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch last accepted of %s: %w", chain.name, err)
+			}
+			lastAccepted, err := vm.GetBlock(ctx, lastAcceptedID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch block %s of %s: %w", lastAcceptedID, chain.name, err)
+			}
+			chainManifest.LastAccepted = lastAcceptedID
+			chainManifest.Height = lastAccepted.Height()
+		}
+		manifest.Chains = append(manifest.Chains, chainManifest)
+	}
+
+	iterators := make([]database.Iterator, len(dbs))
+	for i, db := range dbs {
+		iterators[i] = db.Database.NewIterator()
+	}
+	return iterators, nil
+}
+
+// write copies every key/value pair in [it] into a new database at [path] and
+// returns the number of copied pairs. [it] is released before returning.
+func (s *Snapshotter) write(path string, it database.Iterator) (uint64, error) {
+	defer it.Release()
+
+	db, err := s.config.NewDB(path, s.config.DBConfig, s.config.Log, "", prometheus.NewRegistry())
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		batch   = db.NewBatch()
+		numKeys uint64
+	)
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			_ = db.Close()
+			return 0, err
+		}
+		numKeys++
+
+		if batch.Size() < writeBatchSize {
+			continue
+		}
+		if err := batch.Write(); err != nil {
+			_ = db.Close()
+			return 0, err
+		}
+		batch.Reset()
+	}
+	if err := it.Error(); err != nil {
+		_ = db.Close()
+		return 0, err
+	}
+	if err := batch.Write(); err != nil {
+		_ = db.Close()
+		return 0, err
+	}
+	return numKeys, db.Close()
+}
+
+// ReadManifest returns the manifest of the snapshot at [snapshotDir].
+func ReadManifest(snapshotDir string) (*Manifest, error) {
+	manifestBytes, err := os.ReadFile(filepath.Join(snapshotDir, ManifestFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w in %s", errMissingManifest, snapshotDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(manifestBytes, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// Restore copies the databases in the snapshot at [snapshotDir] into
+// [dbDir]. [dbDir] must either not exist or be empty.
+func Restore(snapshotDir string, dbDir string, dbType string, log logging.Logger) (*Manifest, error) {
+	manifest, err := ReadManifest(snapshotDir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.DBType != dbType {
+		return nil, fmt.Errorf("%w: snapshot has %q but %q is configured", ErrMismatchedDBType, manifest.DBType, dbType)
+	}
+
+	entries, err := os.ReadDir(dbDir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	case len(entries) > 0:
+		return nil, fmt.Errorf("%w: %s", ErrDBDirNotEmpty, dbDir)
+	}
+
+	for _, db := range manifest.Databases {
+		dbVersion, err := version.Parse(db.Version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid database version %q in manifest: %w", db.Version, err)
+		}
+		src := filepath.Join(snapshotDir, dbVersion.String())
+		dst := filepath.Join(dbDir, dbVersion.String())
+		if err := copyDir(src, dst); err != nil {
+			return nil, fmt.Errorf("failed to restore database %s: %w", dbVersion, err)
+		}
+	}
+
+	log.Info("restored database snapshot",
+		zap.String("snapshot", snapshotDir),
+		zap.String("dbDir", dbDir),
+		zap.Time("timestamp", manifest.Timestamp),
+	)
+	for _, chain := range manifest.Chains {
+		log.Info("restored chain",
+			zap.String("name", chain.Name),
+			zap.Stringer("chainID", chain.ChainID),
+			zap.Stringer("lastAccepted", chain.LastAccepted),
+			zap.Uint64("height", chain.Height),
+		)
+	}
+	return manifest, nil
+}
+
+// copyDir recursively copies the regular files and directories in [src] into
+// [dst].
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+		if entry.IsDir() {
+			return os.MkdirAll(target, perms.ReadWriteExecute)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return perms.WriteFile(target, data, perms.ReadWrite)
+	})
+}
@@ -0,0 +1,174 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+func newTestSnapshotter(t *testing.T) (*Snapshotter, manager.Manager) {
+	require := require.New(t)
+
+	dbManager, err := manager.NewManagerFromDBs([]*manager.VersionedDatabase{
+		{
+			Database: memdb.New(),
+			Version:  version.Semantic1_0_0,
+		},
+		{
+			Database: memdb.New(),
+			Version:  &version.Semantic{Major: 1, Minor: 1, Patch: 0},
+		},
+	})
+	require.NoError(err)
+
+	for i, db := range dbManager.GetDatabases() {
+		require.NoError(db.Database.Put([]byte{byte(i)}, []byte{byte(i)}))
+		require.NoError(db.Database.Put([]byte{byte(i), 1}, []byte{byte(i), 1}))
+	}
+
+	return New(Config{
+		Log:       logging.NoLog{},
+		DBType:    leveldb.Name,
+		NewDB:     leveldb.New,
+		DBManager: dbManager,
+		Dir:       t.TempDir(),
+	}), dbManager
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	require := require.New(t)
+
+	snapshotter, dbManager := newTestSnapshotter(t)
+
+	blk := &snowman.TestBlock{
+		TestDecidable: choices.TestDecidable{
+			IDV:     ids.GenerateTestID(),
+			StatusV: choices.Accepted,
+		},
+		HeightV: 10,
+	}
+	vm := &block.TestVM{
+		LastAcceptedF: func(context.Context) (ids.ID, error) {
+			return blk.ID(), nil
+		},
+		GetBlockF: func(_ context.Context, blkID ids.ID) (snowman.Block, error) {
+			require.Equal(blk.ID(), blkID)
+			return blk, nil
+		},
+	}
+	ctx := snow.DefaultConsensusContextTest()
+	ctx.ChainID = ids.GenerateTestID()
+	snapshotter.RegisterChain("test", ctx, vm)
+
+	manifest, err := snapshotter.Create(context.Background(), "snapshot")
+	require.NoError(err)
+	require.Equal(leveldb.Name, manifest.DBType)
+	require.Equal([]ChainManifest{
+		{
+			ChainID:      ctx.ChainID,
+			Name:         "test",
+			LastAccepted: blk.ID(),
+			Height:       10,
+		},
+	}, manifest.Chains)
+	require.Len(manifest.Databases, 2)
+	for _, db := range manifest.Databases {
+		require.Equal(uint64(2), db.NumKeys)
+	}
+
+	// Snapshots can't be overwritten
+	_, err = snapshotter.Create(context.Background(), "snapshot")
+	require.ErrorIs(err, ErrAlreadyExists)
+
+	snapshotDir := filepath.Join(snapshotter.config.Dir, "snapshot")
+	readManifest, err := ReadManifest(snapshotDir)
+	require.NoError(err)
+	require.Equal(manifest.Chains, readManifest.Chains)
+	require.Equal(manifest.Databases, readManifest.Databases)
+
+	dbDir := filepath.Join(t.TempDir(), "db")
+	_, err = Restore(snapshotDir, dbDir, leveldb.Name, logging.NoLog{})
+	require.NoError(err)
+
+	// Restoring into a non-empty directory is not allowed
+	_, err = Restore(snapshotDir, dbDir, leveldb.Name, logging.NoLog{})
+	require.ErrorIs(err, ErrDBDirNotEmpty)
+
+	restoredManager, err := manager.NewLevelDB(
+		dbDir,
+		nil,
+		logging.NoLog{},
+		&version.Semantic{Major: 1, Minor: 1, Patch: 0},
+		"",
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+	defer restoredManager.Close()
+
+	restoredDBs := restoredManager.GetDatabases()
+	expectedDBs := dbManager.GetDatabases()
+	require.Len(restoredDBs, len(expectedDBs))
+	for i, expectedDB := range expectedDBs {
+		restoredDB := restoredDBs[i]
+		require.Zero(expectedDB.Version.Compare(restoredDB.Version))
+
+		it := expectedDB.Database.NewIterator()
+		for it.Next() {
+			value, err := restoredDB.Database.Get(it.Key())
+			require.NoError(err)
+			require.Equal(it.Value(), value)
+		}
+		require.NoError(it.Error())
+		it.Release()
+	}
+}
+
+func TestCreateInvalidName(t *testing.T) {
+	snapshotter, _ := newTestSnapshotter(t)
+	for _, name := range []string{"", ".", "..", "a/b", "../a"} {
+		_, err := snapshotter.Create(context.Background(), name)
+		require.ErrorIs(t, err, ErrInvalidName)
+	}
+}
+
+func TestCreateUnsupportedDBType(t *testing.T) {
+	snapshotter, _ := newTestSnapshotter(t)
+	snapshotter.config.NewDB = nil
+
+	_, err := snapshotter.Create(context.Background(), "snapshot")
+	require.ErrorIs(t, err, ErrUnsupportedDBType)
+}
+
+func TestRestoreMismatchedDBType(t *testing.T) {
+	require := require.New(t)
+
+	snapshotter, _ := newTestSnapshotter(t)
+	_, err := snapshotter.Create(context.Background(), "snapshot")
+	require.NoError(err)
+
+	_, err = Restore(
+		filepath.Join(snapshotter.config.Dir, "snapshot"),
+		t.TempDir(),
+		memdb.Name,
+		logging.NoLog{},
+	)
+	require.ErrorIs(err, ErrMismatchedDBType)
+}
@@ -45,6 +45,9 @@ var Tests = []func(t *testing.T, db Database){
 	TestIteratorStart,
 	TestIteratorPrefix,
 	TestIteratorStartPrefix,
+	TestIteratorOptionsEnd,
+	TestIteratorOptionsReverse,
+	TestIteratorOptionsEmptyRange,
 	TestIteratorMemorySafety,
 	TestIteratorClosed,
 	TestIteratorError,
@@ -747,6 +750,205 @@ func TestIteratorStartPrefix(t *testing.T, db Database) {
 	require.NoError(iterator.Error())
 }
 
+// TestIteratorOptionsEnd tests to make sure the iterator can be configured
+// to stop before an exclusive upper bound.
+func TestIteratorOptionsEnd(t *testing.T, db Database) {
+	require := require.New(t)
+
+	key1 := []byte("hello1")
+	value1 := []byte("world1")
+
+	key2 := []byte("hello2")
+	value2 := []byte("world2")
+
+	key3 := []byte("hello3")
+	value3 := []byte("world3")
+
+	key4 := []byte("z")
+	value4 := []byte("world4")
+
+	require.NoError(db.Put(key1, value1))
+	require.NoError(db.Put(key2, value2))
+	require.NoError(db.Put(key3, value3))
+	require.NoError(db.Put(key4, value4))
+
+	iterator := NewIteratorWithOptions(db, IteratorOptions{
+		Start: key1,
+		End:   key3,
+	})
+	require.NotNil(iterator)
+
+	defer iterator.Release()
+
+	require.True(iterator.Next())
+	require.Equal(key1, iterator.Key())
+	require.Equal(value1, iterator.Value())
+
+	require.True(iterator.Next())
+	require.Equal(key2, iterator.Key())
+	require.Equal(value2, iterator.Value())
+
+	require.False(iterator.Next())
+	require.Nil(iterator.Key())
+	require.Nil(iterator.Value())
+	require.NoError(iterator.Error())
+}
+
+// TestIteratorOptionsReverse tests to make sure the iterator can be
+// configured to iterate in descending order within its bounds.
+func TestIteratorOptionsReverse(t *testing.T, db Database) {
+	require := require.New(t)
+
+	key1 := []byte("a")
+	value1 := []byte("world1")
+
+	key2 := []byte("hello1")
+	value2 := []byte("world2")
+
+	key3 := []byte("hello2")
+	value3 := []byte("world3")
+
+	key4 := []byte("hello3")
+	value4 := []byte("world4")
+
+	key5 := []byte("hello4")
+	value5 := []byte("world5")
+
+	key6 := []byte("z")
+	value6 := []byte("world6")
+
+	require.NoError(db.Put(key1, value1))
+	require.NoError(db.Put(key2, value2))
+	require.NoError(db.Put(key3, value3))
+	require.NoError(db.Put(key4, value4))
+	require.NoError(db.Put(key5, value5))
+	require.NoError(db.Put(key6, value6))
+
+	{
+		iterator := NewIteratorWithOptions(db, IteratorOptions{
+			Reverse: true,
+		})
+		require.NotNil(iterator)
+
+		defer iterator.Release()
+
+		expectedKeys := [][]byte{key6, key5, key4, key3, key2, key1}
+		expectedValues := [][]byte{value6, value5, value4, value3, value2, value1}
+		for i := range expectedKeys {
+			require.True(iterator.Next())
+			require.Equal(expectedKeys[i], iterator.Key())
+			require.Equal(expectedValues[i], iterator.Value())
+		}
+
+		require.False(iterator.Next())
+		require.Nil(iterator.Key())
+		require.Nil(iterator.Value())
+		require.NoError(iterator.Error())
+	}
+
+	{
+		iterator := NewIteratorWithOptions(db, IteratorOptions{
+			Prefix:  []byte("h"),
+			Reverse: true,
+		})
+		require.NotNil(iterator)
+
+		defer iterator.Release()
+
+		require.True(iterator.Next())
+		require.Equal(key5, iterator.Key())
+		require.Equal(value5, iterator.Value())
+
+		require.True(iterator.Next())
+		require.Equal(key4, iterator.Key())
+		require.Equal(value4, iterator.Value())
+
+		require.True(iterator.Next())
+		require.Equal(key3, iterator.Key())
+		require.Equal(value3, iterator.Value())
+
+		require.True(iterator.Next())
+		require.Equal(key2, iterator.Key())
+		require.Equal(value2, iterator.Value())
+
+		require.False(iterator.Next())
+		require.Nil(iterator.Key())
+		require.Nil(iterator.Value())
+		require.NoError(iterator.Error())
+	}
+
+	{
+		iterator := NewIteratorWithOptions(db, IteratorOptions{
+			Start:   key3,
+			End:     key5,
+			Prefix:  []byte("h"),
+			Reverse: true,
+		})
+		require.NotNil(iterator)
+
+		defer iterator.Release()
+
+		require.True(iterator.Next())
+		require.Equal(key4, iterator.Key())
+		require.Equal(value4, iterator.Value())
+
+		require.True(iterator.Next())
+		require.Equal(key3, iterator.Key())
+		require.Equal(value3, iterator.Value())
+
+		require.False(iterator.Next())
+		require.Nil(iterator.Key())
+		require.Nil(iterator.Value())
+		require.NoError(iterator.Error())
+	}
+}
+
+// TestIteratorOptionsEmptyRange tests to make sure the iterator is
+// immediately exhausted when its bounds don't contain any keys.
+func TestIteratorOptionsEmptyRange(t *testing.T, db Database) {
+	require := require.New(t)
+
+	key1 := []byte("hello1")
+	value1 := []byte("world1")
+
+	key2 := []byte("hello2")
+	value2 := []byte("world2")
+
+	require.NoError(db.Put(key1, value1))
+	require.NoError(db.Put(key2, value2))
+
+	for _, options := range []IteratorOptions{
+		{
+			Start: key2,
+			End:   key1,
+		},
+		{
+			Start: key1,
+			End:   key1,
+		},
+		{
+			End:    []byte("hello"),
+			Prefix: []byte("hello"),
+		},
+		{
+			Prefix: []byte("z"),
+		},
+	} {
+		for _, reverse := range []bool{false, true} {
+			options.Reverse = reverse
+			iterator := NewIteratorWithOptions(db, options)
+			require.NotNil(iterator)
+
+			require.False(iterator.Next())
+			require.Nil(iterator.Key())
+			require.Nil(iterator.Value())
+			require.NoError(iterator.Error())
+
+			iterator.Release()
+		}
+	}
+}
+
 // TestIteratorMemorySafety tests to make sure that keys can values are able to
 // be modified from the returned iterator.
 func TestIteratorMemorySafety(t *testing.T, db Database) {
@@ -856,6 +1058,20 @@ func TestIteratorClosed(t *testing.T, db Database) {
 		require.Nil(iterator.Value())
 		require.Equal(ErrClosed, iterator.Error())
 	}
+
+	{
+		iterator := NewIteratorWithOptions(db, IteratorOptions{
+			Reverse: true,
+		})
+		require.NotNil(iterator)
+
+		defer iterator.Release()
+
+		require.False(iterator.Next())
+		require.Nil(iterator.Key())
+		require.Nil(iterator.Value())
+		require.Equal(ErrClosed, iterator.Error())
+	}
 }
 
 // TestIteratorError tests to make sure that an iterator on a database will report
@@ -1264,3 +1480,84 @@ func FuzzNewIteratorWithPrefix(f *testing.F, db Database) {
 		require.NoError(AtomicClear(db, db))
 	})
 }
+
+func FuzzNewIteratorWithOptions(f *testing.F, db Database) {
+	const (
+		maxKeyLen   = 32
+		maxValueLen = 32
+	)
+
+	f.Fuzz(func(
+		t *testing.T,
+		randSeed int64,
+		start []byte,
+		end []byte,
+		prefix []byte,
+		reverse bool,
+		numKeyValues uint,
+	) {
+		require := require.New(t)
+		r := rand.New(rand.NewSource(randSeed)) // #nosec G404
+
+		// Put a bunch of key-values
+		expected := map[string][]byte{}
+		for i := 0; i < int(numKeyValues); i++ {
+			key := make([]byte, r.Intn(maxKeyLen))
+			_, _ = r.Read(key) // #nosec G404
+
+			value := make([]byte, r.Intn(maxValueLen))
+			_, _ = r.Read(value) // #nosec G404
+
+			if len(value) == 0 {
+				// Consistently treat zero length values as nil
+				// so that we can compare [expected] and [got] with
+				// require.Equal, which treats nil and empty byte
+				// as being unequal, whereas the database treats
+				// them as being equal.
+				value = nil
+			}
+
+			if bytes.HasPrefix(key, prefix) &&
+				bytes.Compare(key, start) >= 0 &&
+				(len(end) == 0 || bytes.Compare(key, end) < 0) {
+				expected[string(key)] = value
+			}
+
+			require.NoError(db.Put(key, value))
+		}
+		expectedList := maps.Keys(expected)
+		if reverse {
+			slices.SortFunc(expectedList, func(a, b string) bool {
+				return a > b
+			})
+		} else {
+			slices.Sort(expectedList)
+		}
+
+		iter := NewIteratorWithOptions(db, IteratorOptions{
+			Start:   start,
+			End:     end,
+			Prefix:  prefix,
+			Reverse: reverse,
+		})
+		defer iter.Release()
+
+		// Assert the iterator returns the expected key-values.
+		numIterElts := 0
+		for iter.Next() {
+			val := iter.Value()
+			if len(val) == 0 {
+				val = nil
+			}
+			require.Less(numIterElts, len(expectedList))
+			require.Equal(expectedList[numIterElts], string(iter.Key()))
+			require.Equal(expected[string(iter.Key())], val)
+			numIterElts++
+		}
+		require.NoError(iter.Error())
+		require.Equal(len(expectedList), numIterElts)
+
+		// Clear the database for the next fuzz iteration.
+		require.NoError(AtomicClear(db, db))
+	})
+}
@@ -5,7 +5,6 @@ package versiondb
 
 import (
 	"context"
-	"strings"
 	"sync"
 
 	"golang.org/x/exp/maps"
@@ -16,10 +15,11 @@ import (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ Commitable        = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.RangeIteratee = (*Database)(nil)
+	_ Commitable             = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.Iterator      = (*iterator)(nil)
 )
 
 // Commitable defines the interface that specifies that something may be
@@ -37,6 +37,16 @@ type Database struct {
 	mem   map[string]valueDelete
 	db    database.Database
 	batch database.Batch
+
+	// savepoints are the live savepoints, ordered from oldest to newest.
+	savepoints []savepoint
+	// lastSavepointID is the ID of the most recently created savepoint. IDs
+	// start at 1 so that the zero Savepoint is never valid.
+	lastSavepointID uint64
+	// journal records the prior state of each key in [mem] that was modified
+	// while a savepoint was live. Writes are only journaled while a savepoint
+	// is live.
+	journal []journalEntry
 }
 
 type valueDelete struct {
@@ -89,7 +99,7 @@ func (db *Database) Put(key, value []byte) error {
 	if db.mem == nil {
 		return database.ErrClosed
 	}
-	db.mem[string(key)] = valueDelete{value: slices.Clone(value)}
+	db.set(string(key), valueDelete{value: slices.Clone(value)})
 	return nil
 }
 
@@ -100,7 +110,7 @@ func (db *Database) Delete(key []byte) error {
 	if db.mem == nil {
 		return database.ErrClosed
 	}
-	db.mem[string(key)] = valueDelete{delete: true}
+	db.set(string(key), valueDelete{delete: true})
 	return nil
 }
 
@@ -121,6 +131,13 @@ func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
 }
 
 func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return db.NewIteratorWithOptions(database.IteratorOptions{
+		Start:  start,
+		Prefix: prefix,
+	})
+}
+
+func (db *Database) NewIteratorWithOptions(options database.IteratorOptions) database.Iterator {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
@@ -130,15 +147,23 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 		}
 	}
 
-	startString := string(start)
-	prefixString := string(prefix)
+	lower, upper := options.Bounds()
+	lowerString := string(lower)
+	upperString := string(upper)
 	keys := make([]string, 0, len(db.mem))
 	for key := range db.mem {
-		if strings.HasPrefix(key, prefixString) && key >= startString {
+		if key >= lowerString && (upper == nil || key < upperString) {
 			keys = append(keys, key)
 		}
 	}
-	slices.Sort(keys) // Keys need to be in sorted order
+	// Keys need to be in the same order as the underlying iterator
+	if options.Reverse {
+		slices.SortFunc(keys, func(a, b string) bool {
+			return a > b
+		})
+	} else {
+		slices.Sort(keys)
+	}
 	values := make([]valueDelete, len(keys))
 	for i, key := range keys {
 		values[i] = db.mem[key]
@@ -146,9 +171,10 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 
 	return &iterator{
 		db:       db,
-		Iterator: db.db.NewIteratorWithStartAndPrefix(start, prefix),
+		Iterator: database.NewIteratorWithOptions(db.db, options),
 		keys:     keys,
 		values:   values,
+		reverse:  options.Reverse,
 	}
 }
 
@@ -211,6 +237,7 @@ func (db *Database) Abort() {
 
 func (db *Database) abort() {
 	maps.Clear(db.mem)
+	db.clearSavepoints()
 }
 
 // CommitBatch returns a batch that contains all uncommitted puts/deletes.
@@ -255,6 +282,7 @@ func (db *Database) Close() error {
 	db.batch = nil
 	db.mem = nil
 	db.db = nil
+	db.clearSavepoints()
 	return nil
 }
 
@@ -290,10 +318,10 @@ func (b *batch) Write() error {
 	}
 
 	for _, op := range b.Ops {
-		b.db.mem[string(op.Key)] = valueDelete{
+		b.db.set(string(op.Key), valueDelete{
 			value:  op.Value,
 			delete: op.Delete,
-		}
+		})
 	}
 	return nil
 }
@@ -313,6 +341,8 @@ type iterator struct {
 
 	keys   []string
 	values []valueDelete
+	// reverse is true if the keys are iterated over in descending order.
+	reverse bool
 
 	initialized, exhausted bool
 }
@@ -367,7 +397,7 @@ func (it *iterator) Next() bool {
 
 			dbStringKey := string(dbKey)
 			switch {
-			case memKey < dbStringKey:
+			case it.before(memKey, dbStringKey):
 				it.keys[0] = ""
 				it.keys = it.keys[1:]
 				it.values[0].value = nil
@@ -378,7 +408,7 @@ func (it *iterator) Next() bool {
 					it.value = memValue.value
 					return true
 				}
-			case dbStringKey < memKey:
+			case it.before(dbStringKey, memKey):
 				it.key = dbKey
 				it.value = it.Iterator.Value()
 				it.exhausted = !it.Iterator.Next()
@@ -400,6 +430,14 @@ func (it *iterator) Next() bool {
 	}
 }
 
+// before returns true if [a] is iterated over before [b].
+func (it *iterator) before(a, b string) bool {
+	if it.reverse {
+		return a > b
+	}
+	return a < b
+}
+
 func (it *iterator) Error() error {
 	if it.err != nil {
 		return it.err
@@ -27,6 +27,10 @@ func FuzzNewIteratorWithPrefix(f *testing.F) {
 	database.FuzzNewIteratorWithPrefix(f, New(memdb.New()))
 }
 
+func FuzzNewIteratorWithOptions(f *testing.F) {
+	database.FuzzNewIteratorWithOptions(f, New(memdb.New()))
+}
+
 func TestIterate(t *testing.T) {
 	require := require.New(t)
 
@@ -0,0 +1,143 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package versiondb
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+var ErrInvalidSavepoint = errors.New("invalid savepoint")
+
+// Savepoint marks the uncommitted operations of a Database at a point in time.
+//
+// A savepoint is invalidated when the Database is committed, aborted or
+// closed, when it is released and when the Database is rolled back to an
+// older savepoint.
+type Savepoint struct {
+	id uint64
+}
+
+type savepoint struct {
+	id uint64
+	// journalLen is the length of the journal when the savepoint was created.
+	journalLen int
+}
+
+type journalEntry struct {
+	key string
+	// prev is the value of [key] in [mem] before it was modified. If [exists]
+	// is false, [key] wasn't in [mem].
+	prev   valueDelete
+	exists bool
+}
+
+// Savepoint returns a savepoint of the uncommitted operations of the database.
+// Rolling back to the savepoint undoes every operation performed after it was
+// created, while keeping the operations performed before it.
+//
+// Savepoints may be nested. Operations are only journaled while a savepoint is
+// live, so creating a savepoint doesn't copy the uncommitted operations.
+func (db *Database) Savepoint() Savepoint {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.lastSavepointID++
+	id := db.lastSavepointID
+	db.savepoints = append(db.savepoints, savepoint{
+		id:         id,
+		journalLen: len(db.journal),
+	})
+	return Savepoint{id: id}
+}
+
+// RollbackTo undoes every operation performed after [sp] was created.
+// Savepoints created after [sp] are invalidated. [sp] remains valid, so it can
+// be rolled back to again.
+func (db *Database) RollbackTo(sp Savepoint) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.mem == nil {
+		return database.ErrClosed
+	}
+	index, ok := db.savepointIndex(sp)
+	if !ok {
+		return ErrInvalidSavepoint
+	}
+
+	journalLen := db.savepoints[index].journalLen
+	for i := len(db.journal) - 1; i >= journalLen; i-- {
+		entry := db.journal[i]
+		if entry.exists {
+			db.mem[entry.key] = entry.prev
+		} else {
+			delete(db.mem, entry.key)
+		}
+		db.journal[i] = journalEntry{}
+	}
+	db.journal = db.journal[:journalLen]
+	db.savepoints = db.savepoints[:index+1]
+	return nil
+}
+
+// ReleaseSavepoint invalidates [sp] and every savepoint created after it,
+// keeping the operations performed after they were created.
+func (db *Database) ReleaseSavepoint(sp Savepoint) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.mem == nil {
+		return database.ErrClosed
+	}
+	index, ok := db.savepointIndex(sp)
+	if !ok {
+		return ErrInvalidSavepoint
+	}
+
+	db.savepoints = db.savepoints[:index]
+	if len(db.savepoints) == 0 {
+		// The journal is only needed to roll back to a live savepoint.
+		db.clearSavepoints()
+	}
+	return nil
+}
+
+// set sets [key] to [value] in [mem], journaling the prior state of [key] if a
+// savepoint is live.
+//
+// Assumes [db.lock] is held.
+func (db *Database) set(key string, value valueDelete) {
+	if len(db.savepoints) > 0 {
+		prev, exists := db.mem[key]
+		db.journal = append(db.journal, journalEntry{
+			key:    key,
+			prev:   prev,
+			exists: exists,
+		})
+	}
+	db.mem[key] = value
+}
+
+// savepointIndex returns the index of [sp] in [db.savepoints] and true if [sp]
+// is live.
+//
+// Assumes [db.lock] is held.
+func (db *Database) savepointIndex(sp Savepoint) (int, bool) {
+	for i := len(db.savepoints) - 1; i >= 0; i-- {
+		if db.savepoints[i].id == sp.id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// clearSavepoints invalidates every savepoint.
+//
+// Assumes [db.lock] is held.
+func (db *Database) clearSavepoints() {
+	db.savepoints = nil
+	db.journal = nil
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package versiondb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+func TestSavepointRollback(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	require.NoError(baseDB.Put([]byte("base"), []byte("base")))
+	db := New(baseDB)
+
+	require.NoError(db.Put([]byte("a"), []byte("a0")))
+
+	sp1 := db.Savepoint()
+	require.NoError(db.Put([]byte("a"), []byte("a1")))
+	require.NoError(db.Put([]byte("b"), []byte("b1")))
+
+	sp2 := db.Savepoint()
+	require.NoError(db.Delete([]byte("a")))
+	require.NoError(db.Delete([]byte("base")))
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("c"), []byte("c2")))
+	require.NoError(batch.Write())
+
+	has, err := db.Has([]byte("a"))
+	require.NoError(err)
+	require.False(has)
+
+	require.NoError(db.RollbackTo(sp2))
+	requireValues(t, db, map[string]string{
+		"a":    "a1",
+		"b":    "b1",
+		"base": "base",
+	})
+
+	// Rolling back keeps the savepoint valid.
+	require.NoError(db.Put([]byte("d"), []byte("d2")))
+	require.NoError(db.RollbackTo(sp2))
+	requireValues(t, db, map[string]string{
+		"a":    "a1",
+		"b":    "b1",
+		"base": "base",
+	})
+
+	require.NoError(db.RollbackTo(sp1))
+	requireValues(t, db, map[string]string{
+		"a":    "a0",
+		"base": "base",
+	})
+
+	// Rolling back to an older savepoint invalidates newer savepoints.
+	err = db.RollbackTo(sp2)
+	require.ErrorIs(err, ErrInvalidSavepoint)
+
+	require.NoError(db.Commit())
+	requireValues(t, baseDB, map[string]string{
+		"a":    "a0",
+		"base": "base",
+	})
+
+	// Committing invalidates every savepoint.
+	err = db.RollbackTo(sp1)
+	require.ErrorIs(err, ErrInvalidSavepoint)
+	err = db.RollbackTo(Savepoint{})
+	require.ErrorIs(err, ErrInvalidSavepoint)
+}
+
+func TestSavepointRelease(t *testing.T) {
+	require := require.New(t)
+
+	db := New(memdb.New())
+
+	sp1 := db.Savepoint()
+	require.NoError(db.Put([]byte("a"), []byte("a1")))
+	sp2 := db.Savepoint()
+	require.NoError(db.Put([]byte("b"), []byte("b2")))
+	sp3 := db.Savepoint()
+	require.NoError(db.Put([]byte("c"), []byte("c3")))
+
+	// Releasing a savepoint keeps its operations and invalidates newer
+	// savepoints.
+	require.NoError(db.ReleaseSavepoint(sp2))
+	err := db.RollbackTo(sp3)
+	require.ErrorIs(err, ErrInvalidSavepoint)
+	err = db.ReleaseSavepoint(sp2)
+	require.ErrorIs(err, ErrInvalidSavepoint)
+	requireValues(t, db, map[string]string{
+		"a": "a1",
+		"b": "b2",
+		"c": "c3",
+	})
+
+	require.NoError(db.RollbackTo(sp1))
+	requireValues(t, db, map[string]string{})
+
+	require.NoError(db.ReleaseSavepoint(sp1))
+	require.Empty(db.journal)
+
+	// Writes aren't journaled without a live savepoint.
+	require.NoError(db.Put([]byte("a"), []byte("a1")))
+	require.Empty(db.journal)
+}
+
+func TestSavepointAbort(t *testing.T) {
+	require := require.New(t)
+
+	db := New(memdb.New())
+
+	sp := db.Savepoint()
+	require.NoError(db.Put([]byte("a"), []byte("a1")))
+	db.Abort()
+
+	err := db.RollbackTo(sp)
+	require.ErrorIs(err, ErrInvalidSavepoint)
+	requireValues(t, db, map[string]string{})
+}
+
+func TestSavepointClosed(t *testing.T) {
+	require := require.New(t)
+
+	db := New(memdb.New())
+	sp := db.Savepoint()
+	require.NoError(db.Close())
+
+	err := db.RollbackTo(sp)
+	require.ErrorIs(err, database.ErrClosed)
+	err = db.ReleaseSavepoint(sp)
+	require.ErrorIs(err, database.ErrClosed)
+}
+
+// requireValues requires that [db] contains exactly [expected].
+func requireValues(t *testing.T, db database.Iteratee, expected map[string]string) {
+	require := require.New(t)
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	values := make(map[string]string)
+	for it.Next() {
+		values[string(it.Key())] = string(it.Value())
+	}
+	require.NoError(it.Error())
+	require.Equal(expected, values)
+}
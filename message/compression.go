@@ -0,0 +1,157 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/proto/pb/p2p"
+	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/metric"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	// compressionTypes are the compression types, other than
+	// [compression.TypeNone], that messages can be compressed with.
+	compressionTypes = []compression.Type{
+		compression.TypeGzip,
+		compression.TypeZstd,
+		compression.TypeLz4,
+		compression.TypeSnappy,
+	}
+
+	// legacyCompressionTypes are the compression types supported by peers that
+	// don't advertise the compression types they support.
+	legacyCompressionTypes = []compression.Type{
+		compression.TypeGzip,
+		compression.TypeZstd,
+	}
+
+	// fallbackCompressionTypes are the compression types, in order of
+	// preference, that a message is compressed with if the peer doesn't
+	// support the compression type it was created with.
+	fallbackCompressionTypes = []compression.Type{
+		compression.TypeZstd,
+		compression.TypeLz4,
+		compression.TypeSnappy,
+		compression.TypeGzip,
+	}
+
+	wireCompressionTypes = map[compression.Type]p2p.CompressionType{
+		compression.TypeGzip:   p2p.CompressionType_COMPRESSION_TYPE_GZIP,
+		compression.TypeZstd:   p2p.CompressionType_COMPRESSION_TYPE_ZSTD,
+		compression.TypeLz4:    p2p.CompressionType_COMPRESSION_TYPE_LZ4,
+		compression.TypeSnappy: p2p.CompressionType_COMPRESSION_TYPE_SNAPPY,
+	}
+)
+
+// PeerCompression describes the messages that a peer is able to decompress.
+type PeerCompression struct {
+	// Types are the compression types the peer supports.
+	Types set.Set[compression.Type]
+	// ZstdDictIDs are the IDs of the zstd dictionaries the peer has.
+	ZstdDictIDs set.Set[uint32]
+}
+
+// NewPeerCompression returns the messages that a peer, which sent [version],
+// is able to decompress. If the Version message hasn't been received yet,
+// [version] should be nil.
+func NewPeerCompression(version *p2p.Version) PeerCompression {
+	return PeerCompression{
+		Types:       SupportedCompressionTypes(version.GetSupportedCompressionTypes()),
+		ZstdDictIDs: set.Of(version.GetZstdDictionaryIds()...),
+	}
+}
+
+// SupportedCompressionTypes returns the compression types that a peer is able
+// to decompress, given the compression types [advertised] in its Version
+// message. Unknown compression types are ignored.
+//
+// Peers running a version that predates the negotiation of compression types
+// don't advertise any, and are assumed to support gzip and zstd.
+func SupportedCompressionTypes(advertised []p2p.CompressionType) set.Set[compression.Type] {
+	if len(advertised) == 0 {
+		return set.Of(legacyCompressionTypes...)
+	}
+
+	supported := set.NewSet[compression.Type](len(advertised))
+	for _, compressionType := range compressionTypes {
+		wireType := wireCompressionTypes[compressionType]
+		for _, advertisedType := range advertised {
+			if advertisedType == wireType {
+				supported.Add(compressionType)
+				break
+			}
+		}
+	}
+	return supported
+}
+
+// supports returns true if the peer is able to decompress messages compressed
+// with [compressionType] and, if it isn't 0, the zstd dictionary
+// [zstdDictID].
+func (p PeerCompression) supports(compressionType compression.Type, zstdDictID uint32) bool {
+	if compressionType == compression.TypeNone {
+		return true
+	}
+	return p.Types.Contains(compressionType) && (zstdDictID == 0 || p.ZstdDictIDs.Contains(zstdDictID))
+}
+
+// compressor records the time it takes to compress and decompress each op.
+type compressor struct {
+	compression.Compressor
+	compressTimeMetrics   map[Op]metric.Averager
+	decompressTimeMetrics map[Op]metric.Averager
+}
+
+func newCompressor(
+	name string,
+	c compression.Compressor,
+	namespace string,
+	metrics prometheus.Registerer,
+	errs *wrappers.Errs,
+) *compressor {
+	mc := &compressor{
+		Compressor:            c,
+		compressTimeMetrics:   make(map[Op]metric.Averager, len(ExternalOps)),
+		decompressTimeMetrics: make(map[Op]metric.Averager, len(ExternalOps)),
+	}
+	for _, op := range ExternalOps {
+		mc.compressTimeMetrics[op] = metric.NewAveragerWithErrs(
+			namespace,
+			fmt.Sprintf("%s_%s_compress_time", name, op),
+			fmt.Sprintf("time (in ns) to compress %s messages with %s", op, name),
+			metrics,
+			errs,
+		)
+		mc.decompressTimeMetrics[op] = metric.NewAveragerWithErrs(
+			namespace,
+			fmt.Sprintf("%s_%s_decompress_time", name, op),
+			fmt.Sprintf("time (in ns) to decompress %s messages with %s", op, name),
+			metrics,
+			errs,
+		)
+	}
+	return mc
+}
+
+func newTypeCompressor(compressionType compression.Type) (compression.Compressor, error) {
+	switch compressionType {
+	case compression.TypeGzip:
+		return compression.NewGzipCompressor(constants.DefaultMaxMessageSize)
+	case compression.TypeZstd:
+		return compression.NewZstdCompressor(constants.DefaultMaxMessageSize)
+	case compression.TypeLz4:
+		return compression.NewLz4Compressor(constants.DefaultMaxMessageSize)
+	case compression.TypeSnappy:
+		return compression.NewSnappyCompressor(constants.DefaultMaxMessageSize)
+	default:
+		return nil, errUnknownCompressionType
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/proto/pb/p2p"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/ips"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+func TestSupportedCompressionTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		advertised []p2p.CompressionType
+		expected   set.Set[compression.Type]
+	}{
+		{
+			name:       "legacy peer",
+			advertised: nil,
+			expected:   set.Of(compression.TypeGzip, compression.TypeZstd),
+		},
+		{
+			name: "subset",
+			advertised: []p2p.CompressionType{
+				p2p.CompressionType_COMPRESSION_TYPE_ZSTD,
+				p2p.CompressionType_COMPRESSION_TYPE_SNAPPY,
+			},
+			expected: set.Of(compression.TypeZstd, compression.TypeSnappy),
+		},
+		{
+			name: "unknown types ignored",
+			advertised: []p2p.CompressionType{
+				p2p.CompressionType_COMPRESSION_TYPE_UNSPECIFIED,
+				p2p.CompressionType_COMPRESSION_TYPE_LZ4,
+				p2p.CompressionType(100),
+			},
+			expected: set.Of(compression.TypeLz4),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, SupportedCompressionTypes(tt.advertised))
+		})
+	}
+}
+
+func TestVersionAdvertisesCompression(t *testing.T) {
+	require := require.New(t)
+
+	zstdDict := newTestZstdDict(t)
+	zstdDictID, err := compression.ZstdDictID(zstdDict)
+	require.NoError(err)
+
+	mb, err := newMsgBuilder(
+		logging.NoLog{},
+		"test",
+		prometheus.NewRegistry(),
+		zstdDict,
+		5*time.Second,
+	)
+	require.NoError(err)
+
+	outMsgBuilder := newOutboundBuilder(compression.TypeZstd, mb)
+	msg, err := outMsgBuilder.Version(
+		constants.UnitTestID,
+		1,
+		ips.IPPort{IP: net.IPv6loopback},
+		"v1.0.0",
+		1,
+		nil,
+		nil,
+		0,
+	)
+	require.NoError(err)
+
+	parsedMsg, err := mb.parseInbound(msg.Bytes(), ids.EmptyNodeID, func() {})
+	require.NoError(err)
+	version := parsedMsg.Message().(*p2p.Version)
+
+	require.Equal(
+		PeerCompression{
+			Types:       set.Of(compressionTypes...),
+			ZstdDictIDs: set.Of(zstdDictID),
+		},
+		NewPeerCompression(version),
+	)
+}
+
+// newTestZstdDict returns a zstd dictionary trained on AppGossip messages.
+func newTestZstdDict(t *testing.T) []byte {
+	chainID := ids.GenerateTestID()
+	samples := make([][]byte, 1_000)
+	for i := range samples {
+		sample, err := proto.Marshal(&p2p.Message{
+			Message: &p2p.Message_AppGossip{
+				AppGossip: &p2p.AppGossip{
+					ChainId:  chainID[:],
+					AppBytes: utils.RandomBytes(32),
+				},
+			},
+		})
+		require.NoError(t, err)
+		samples[i] = sample
+	}
+	zstdDict, err := compression.TrainZstdDict(samples, 4*units.KiB)
+	require.NoError(t, err)
+	return zstdDict
+}
+
+func TestOutboundMessageCompressedFor(t *testing.T) {
+	zstdDict := newTestZstdDict(t)
+	zstdDictID, err := compression.ZstdDictID(zstdDict)
+	require.NoError(t, err)
+
+	mb, err := newMsgBuilder(
+		logging.NoLog{},
+		"test",
+		prometheus.NewRegistry(),
+		zstdDict,
+		5*time.Second,
+	)
+	require.NoError(t, err)
+
+	chainID := ids.GenerateTestID()
+	newMsg := func() *p2p.Message {
+		return &p2p.Message{
+			Message: &p2p.Message_AppGossip{
+				AppGossip: &p2p.AppGossip{
+					ChainId:  chainID[:],
+					AppBytes: bytes.Repeat([]byte{0}, 1024),
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		compressionType  compression.Type
+		peer             PeerCompression
+		expectedEncoding encoding
+	}{
+		{
+			name:            "uncompressed",
+			compressionType: compression.TypeNone,
+			peer: PeerCompression{
+				Types: set.Of(compression.TypeGzip),
+			},
+			expectedEncoding: encoding{
+				compressionType: compression.TypeNone,
+			},
+		},
+		{
+			name:            "supported",
+			compressionType: compression.TypeLz4,
+			peer: PeerCompression{
+				Types: set.Of(compression.TypeLz4, compression.TypeZstd),
+			},
+			expectedEncoding: encoding{
+				compressionType: compression.TypeLz4,
+			},
+		},
+		{
+			name:            "legacy peer",
+			compressionType: compression.TypeSnappy,
+			peer:            NewPeerCompression(nil),
+			expectedEncoding: encoding{
+				compressionType: compression.TypeZstd,
+			},
+		},
+		{
+			name:            "fallback",
+			compressionType: compression.TypeZstd,
+			peer: PeerCompression{
+				Types: set.Of(compression.TypeGzip, compression.TypeSnappy),
+			},
+			expectedEncoding: encoding{
+				compressionType: compression.TypeSnappy,
+			},
+		},
+		{
+			name:            "nothing supported",
+			compressionType: compression.TypeZstd,
+			peer:            PeerCompression{},
+			expectedEncoding: encoding{
+				compressionType: compression.TypeNone,
+			},
+		},
+		{
+			name:            "zstd dictionary",
+			compressionType: compression.TypeZstd,
+			peer: PeerCompression{
+				Types:       set.Of(compression.TypeZstd),
+				ZstdDictIDs: set.Of(zstdDictID),
+			},
+			expectedEncoding: encoding{
+				compressionType: compression.TypeZstd,
+				zstdDictID:      zstdDictID,
+			},
+		},
+		{
+			name:            "peer without zstd dictionary",
+			compressionType: compression.TypeZstd,
+			peer: PeerCompression{
+				Types:       set.Of(compression.TypeZstd),
+				ZstdDictIDs: set.Of(zstdDictID + 1),
+			},
+			expectedEncoding: encoding{
+				compressionType: compression.TypeZstd,
+			},
+		},
+		{
+			name:            "fallback to zstd dictionary",
+			compressionType: compression.TypeLz4,
+			peer: PeerCompression{
+				Types:       set.Of(compression.TypeZstd),
+				ZstdDictIDs: set.Of(zstdDictID),
+			},
+			expectedEncoding: encoding{
+				compressionType: compression.TypeZstd,
+				zstdDictID:      zstdDictID,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			msg, err := mb.createOutbound(newMsg(), tt.compressionType, false)
+			require.NoError(err)
+
+			compressedMsg, err := msg.CompressedFor(tt.peer)
+			require.NoError(err)
+			require.Equal(msg.Op(), compressedMsg.Op())
+			require.Equal(tt.expectedEncoding, compressedMsg.(*outboundMessage).encoding)
+
+			// The message is only compressed once per encoding.
+			compressedMsgAgain, err := msg.CompressedFor(tt.peer)
+			require.NoError(err)
+			require.Same(compressedMsg, compressedMsgAgain)
+
+			parsedMsg, err := mb.parseInbound(compressedMsg.Bytes(), ids.EmptyNodeID, func() {})
+			require.NoError(err)
+			require.Equal(AppGossipOp, parsedMsg.Op())
+		})
+	}
+}
+
+func TestParseZstdDictWithoutDict(t *testing.T) {
+	require := require.New(t)
+
+	mb, err := newMsgBuilder(
+		logging.NoLog{},
+		"test",
+		prometheus.NewRegistry(),
+		newTestZstdDict(t),
+		5*time.Second,
+	)
+	require.NoError(err)
+
+	msg, err := mb.createOutbound(
+		&p2p.Message{
+			Message: &p2p.Message_AppGossip{
+				AppGossip: &p2p.AppGossip{
+					AppBytes: bytes.Repeat([]byte{0}, 1024),
+				},
+			},
+		},
+		compression.TypeZstd,
+		false,
+	)
+	require.NoError(err)
+
+	mbWithoutDict, err := newMsgBuilder(
+		logging.NoLog{},
+		"test",
+		prometheus.NewRegistry(),
+		nil,
+		5*time.Second,
+	)
+	require.NoError(err)
+
+	_, err = mbWithoutDict.parseInbound(msg.Bytes(), ids.EmptyNodeID, func() {})
+	require.ErrorIs(err, errNoZstdDict)
+}
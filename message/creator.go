@@ -25,11 +25,15 @@ type creator struct {
 	InboundMsgBuilder
 }
 
+// NewCreator returns a Creator that compresses outbound messages with
+// [compressionType]. If [zstdDict] is non-empty, messages compressed with zstd
+// are compressed with the dictionary when sent to peers that have it.
 func NewCreator(
 	log logging.Logger,
 	metrics prometheus.Registerer,
 	parentNamespace string,
 	compressionType compression.Type,
+	zstdDict []byte,
 	maxMessageTimeout time.Duration,
 ) (Creator, error) {
 	namespace := fmt.Sprintf("%s_codec", parentNamespace)
@@ -37,6 +41,7 @@ func NewCreator(
 		log,
 		namespace,
 		metrics,
+		zstdDict,
 		maxMessageTimeout,
 	)
 	if err != nil {
@@ -25,6 +25,7 @@ func Test_newMsgBuilder(t *testing.T) {
 		logging.NoLog{},
 		"test",
 		prometheus.NewRegistry(),
+		nil,
 		10*time.Second,
 	)
 	require.NoError(err)
@@ -6,6 +6,7 @@ package message
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -20,7 +21,6 @@ import (
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/math"
-	"github.com/ava-labs/avalanchego/utils/metric"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
@@ -30,6 +30,7 @@ var (
 	_ OutboundMessage = (*outboundMessage)(nil)
 
 	errUnknownCompressionType = errors.New("message is compressed with an unknown compression type")
+	errNoZstdDict             = errors.New("message is compressed with a zstd dictionary, but no dictionary was provided")
 )
 
 // InboundMessage represents a set of fields for an inbound message
@@ -105,6 +106,18 @@ type OutboundMessage interface {
 	// BytesSavedCompression returns the number of bytes that this message saved
 	// due to being compressed
 	BytesSavedCompression() int
+	// CompressedFor returns the message to send to [peer]. If [peer] isn't
+	// able to decompress this message, the returned message is compressed
+	// with a compression type [peer] supports.
+	CompressedFor(peer PeerCompression) (OutboundMessage, error)
+}
+
+// encoding is how the bytes of an outbound message are compressed.
+type encoding struct {
+	compressionType compression.Type
+	// zstdDictID is the ID of the zstd dictionary the message is compressed
+	// with, or 0 if the message isn't compressed with a dictionary.
+	zstdDictID uint32
 }
 
 type outboundMessage struct {
@@ -112,6 +125,19 @@ type outboundMessage struct {
 	op                    Op
 	bytes                 []byte
 	bytesSavedCompression int
+	encoding              encoding
+
+	// [builder] and [uncompressedMsg] are only set if the message is
+	// compressed.
+	builder         *msgBuilder
+	uncompressedMsg *p2p.Message
+
+	// recompressedLock must be held while accessing [recompressed]
+	recompressedLock sync.Mutex
+	// recompressed caches the message compressed with other encodings, so
+	// that it is only compressed once per encoding when it is sent to many
+	// peers.
+	recompressed map[encoding]*outboundMessage
 }
 
 func (m *outboundMessage) BypassThrottling() bool {
@@ -130,17 +156,43 @@ func (m *outboundMessage) BytesSavedCompression() int {
 	return m.bytesSavedCompression
 }
 
-// TODO: add other compression algorithms with extended interface
+func (m *outboundMessage) CompressedFor(peer PeerCompression) (OutboundMessage, error) {
+	if peer.supports(m.encoding.compressionType, m.encoding.zstdDictID) {
+		return m, nil
+	}
+
+	e := m.builder.encodingFor(peer, m.encoding.compressionType)
+
+	m.recompressedLock.Lock()
+	defer m.recompressedLock.Unlock()
+
+	if msg, ok := m.recompressed[e]; ok {
+		return msg, nil
+	}
+	msg, err := m.builder.createOutboundWithEncoding(m.uncompressedMsg, e, m.bypassThrottling)
+	if err != nil {
+		return nil, err
+	}
+	if m.recompressed == nil {
+		m.recompressed = make(map[encoding]*outboundMessage)
+	}
+	m.recompressed[e] = msg
+	return msg, nil
+}
+
 type msgBuilder struct {
 	log logging.Logger
 
-	gzipCompressor            compression.Compressor
-	gzipCompressTimeMetrics   map[Op]metric.Averager
-	gzipDecompressTimeMetrics map[Op]metric.Averager
+	// compressors contains the compressor of each compression type, other
+	// than [compression.TypeNone], that messages can be compressed with.
+	compressors map[compression.Type]*compressor
+	// supportedCompressionTypes are the compression types advertised to peers
+	// in the Version message.
+	supportedCompressionTypes []p2p.CompressionType
 
-	zstdCompressor            compression.Compressor
-	zstdCompressTimeMetrics   map[Op]metric.Averager
-	zstdDecompressTimeMetrics map[Op]metric.Averager
+	// zstdDictCompressor is nil if no zstd dictionary was provided.
+	zstdDictCompressor *compressor
+	zstdDictID         uint32
 
 	maxMessageTimeout time.Duration
 }
@@ -149,68 +201,90 @@ func newMsgBuilder(
 	log logging.Logger,
 	namespace string,
 	metrics prometheus.Registerer,
+	zstdDict []byte,
 	maxMessageTimeout time.Duration,
 ) (*msgBuilder, error) {
-	gzipCompressor, err := compression.NewGzipCompressor(constants.DefaultMaxMessageSize)
-	if err != nil {
-		return nil, err
-	}
-	zstdCompressor, err := compression.NewZstdCompressor(constants.DefaultMaxMessageSize)
-	if err != nil {
-		return nil, err
-	}
-
 	mb := &msgBuilder{
 		log: log,
 
-		gzipCompressor:            gzipCompressor,
-		gzipCompressTimeMetrics:   make(map[Op]metric.Averager, len(ExternalOps)),
-		gzipDecompressTimeMetrics: make(map[Op]metric.Averager, len(ExternalOps)),
-
-		zstdCompressor:            zstdCompressor,
-		zstdCompressTimeMetrics:   make(map[Op]metric.Averager, len(ExternalOps)),
-		zstdDecompressTimeMetrics: make(map[Op]metric.Averager, len(ExternalOps)),
+		compressors:               make(map[compression.Type]*compressor, len(compressionTypes)),
+		supportedCompressionTypes: make([]p2p.CompressionType, 0, len(compressionTypes)),
 
 		maxMessageTimeout: maxMessageTimeout,
 	}
 
 	errs := wrappers.Errs{}
-	for _, op := range ExternalOps {
-		mb.gzipCompressTimeMetrics[op] = metric.NewAveragerWithErrs(
-			namespace,
-			fmt.Sprintf("gzip_%s_compress_time", op),
-			fmt.Sprintf("time (in ns) to compress %s messages with gzip", op),
-			metrics,
-			&errs,
-		)
-		mb.gzipDecompressTimeMetrics[op] = metric.NewAveragerWithErrs(
-			namespace,
-			fmt.Sprintf("gzip_%s_decompress_time", op),
-			fmt.Sprintf("time (in ns) to decompress %s messages with gzip", op),
-			metrics,
-			&errs,
-		)
-		mb.zstdCompressTimeMetrics[op] = metric.NewAveragerWithErrs(
-			namespace,
-			fmt.Sprintf("zstd_%s_compress_time", op),
-			fmt.Sprintf("time (in ns) to compress %s messages with zstd", op),
-			metrics,
-			&errs,
-		)
-		mb.zstdDecompressTimeMetrics[op] = metric.NewAveragerWithErrs(
-			namespace,
-			fmt.Sprintf("zstd_%s_decompress_time", op),
-			fmt.Sprintf("time (in ns) to decompress %s messages with zstd", op),
-			metrics,
-			&errs,
-		)
+	for _, compressionType := range compressionTypes {
+		c, err := newTypeCompressor(compressionType)
+		if err != nil {
+			return nil, err
+		}
+
+		mb.compressors[compressionType] = newCompressor(compressionType.String(), c, namespace, metrics, &errs)
+		mb.supportedCompressionTypes = append(mb.supportedCompressionTypes, wireCompressionTypes[compressionType])
+	}
+
+	if len(zstdDict) > 0 {
+		zstdDictID, err := compression.ZstdDictID(zstdDict)
+		if err != nil {
+			return nil, err
+		}
+		c, err := compression.NewZstdDictCompressor(constants.DefaultMaxMessageSize, zstdDict)
+		if err != nil {
+			return nil, err
+		}
+
+		mb.zstdDictCompressor = newCompressor("zstd_dict", c, namespace, metrics, &errs)
+		mb.zstdDictID = zstdDictID
 	}
 	return mb, errs.Err
 }
 
+// zstdDictionaryIDs returns the IDs of the zstd dictionaries advertised to
+// peers in the Version message.
+func (mb *msgBuilder) zstdDictionaryIDs() []uint32 {
+	if mb.zstdDictCompressor == nil {
+		return nil
+	}
+	return []uint32{mb.zstdDictID}
+}
+
+// defaultEncoding returns the encoding of messages created with
+// [compressionType]. Messages compressed with zstd use the zstd dictionary,
+// if one was provided.
+func (mb *msgBuilder) defaultEncoding(compressionType compression.Type) encoding {
+	e := encoding{
+		compressionType: compressionType,
+	}
+	if compressionType == compression.TypeZstd && mb.zstdDictCompressor != nil {
+		e.zstdDictID = mb.zstdDictID
+	}
+	return e
+}
+
+// encodingFor returns the encoding of messages, created with
+// [compressionType], that are sent to [peer].
+func (mb *msgBuilder) encodingFor(peer PeerCompression, compressionType compression.Type) encoding {
+	if !peer.Types.Contains(compressionType) {
+		compressionType = compression.TypeNone
+		for _, fallbackType := range fallbackCompressionTypes {
+			if peer.Types.Contains(fallbackType) {
+				compressionType = fallbackType
+				break
+			}
+		}
+	}
+
+	e := mb.defaultEncoding(compressionType)
+	if !peer.ZstdDictIDs.Contains(e.zstdDictID) {
+		e.zstdDictID = 0
+	}
+	return e
+}
+
 func (mb *msgBuilder) marshal(
 	uncompressedMsg *p2p.Message,
-	compressionType compression.Type,
+	encoding encoding,
 ) ([]byte, int, Op, error) {
 	uncompressedMsgBytes, err := proto.Marshal(uncompressedMsg)
 	if err != nil {
@@ -222,44 +296,53 @@ func (mb *msgBuilder) marshal(
 		return nil, 0, 0, err
 	}
 
+	if encoding.compressionType == compression.TypeNone {
+		return uncompressedMsgBytes, 0, op, nil
+	}
+	compressor, ok := mb.compressors[encoding.compressionType]
+	if !ok {
+		return nil, 0, 0, errUnknownCompressionType
+	}
+	if encoding.zstdDictID != 0 {
+		compressor = mb.zstdDictCompressor
+	}
+
 	// If compression is enabled, we marshal twice:
 	// 1. the original message
 	// 2. the message with compressed bytes
 	//
 	// This recursive packing allows us to avoid an extra compression on/off
 	// field in the message.
-	var (
-		startTime               = time.Now()
-		compressedMsg           p2p.Message
-		opToCompressTimeMetrics map[Op]metric.Averager
-	)
-	switch compressionType {
-	case compression.TypeNone:
-		return uncompressedMsgBytes, 0, op, nil
+	startTime := time.Now()
+	compressedBytes, err := compressor.Compress(uncompressedMsgBytes)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	var compressedMsg p2p.Message
+	switch encoding.compressionType {
 	case compression.TypeGzip:
-		compressedBytes, err := mb.gzipCompressor.Compress(uncompressedMsgBytes)
-		if err != nil {
-			return nil, 0, 0, err
+		compressedMsg.Message = &p2p.Message_CompressedGzip{
+			CompressedGzip: compressedBytes,
 		}
-		compressedMsg = p2p.Message{
-			Message: &p2p.Message_CompressedGzip{
-				CompressedGzip: compressedBytes,
-			},
-		}
-		opToCompressTimeMetrics = mb.gzipCompressTimeMetrics
 	case compression.TypeZstd:
-		compressedBytes, err := mb.zstdCompressor.Compress(uncompressedMsgBytes)
-		if err != nil {
-			return nil, 0, 0, err
-		}
-		compressedMsg = p2p.Message{
-			Message: &p2p.Message_CompressedZstd{
+		if encoding.zstdDictID != 0 {
+			compressedMsg.Message = &p2p.Message_CompressedZstdDictionary{
+				CompressedZstdDictionary: compressedBytes,
+			}
+		} else {
+			compressedMsg.Message = &p2p.Message_CompressedZstd{
 				CompressedZstd: compressedBytes,
-			},
+			}
+		}
+	case compression.TypeLz4:
+		compressedMsg.Message = &p2p.Message_CompressedLz4{
+			CompressedLz4: compressedBytes,
+		}
+	case compression.TypeSnappy:
+		compressedMsg.Message = &p2p.Message_CompressedSnappy{
+			CompressedSnappy: compressedBytes,
 		}
-		opToCompressTimeMetrics = mb.zstdCompressTimeMetrics
-	default:
-		return nil, 0, 0, errUnknownCompressionType
 	}
 
 	compressedMsgBytes, err := proto.Marshal(&compressedMsg)
@@ -268,13 +351,13 @@ func (mb *msgBuilder) marshal(
 	}
 	compressTook := time.Since(startTime)
 
-	if compressTimeMetric, ok := opToCompressTimeMetrics[op]; ok {
+	if compressTimeMetric, ok := compressor.compressTimeMetrics[op]; ok {
 		compressTimeMetric.Observe(float64(compressTook))
 	} else {
 		// Should never happen
 		mb.log.Warn("no compression metric found for op",
 			zap.Stringer("op", op),
-			zap.Stringer("compressionType", compressionType),
+			zap.Stringer("compressionType", encoding.compressionType),
 		)
 	}
 
@@ -290,26 +373,39 @@ func (mb *msgBuilder) unmarshal(b []byte) (*p2p.Message, int, Op, error) {
 
 	// Figure out what compression type, if any, was used to compress the message.
 	var (
-		opToDecompressTimeMetrics map[Op]metric.Averager
-		compressor                compression.Compressor
-		compressedBytes           []byte
-		gzipCompressed            = m.GetCompressedGzip()
-		zstdCompressed            = m.GetCompressedZstd()
+		compressionType compression.Type
+		compressor      *compressor
+		compressedBytes []byte
 	)
-	switch {
-	case len(gzipCompressed) > 0:
-		opToDecompressTimeMetrics = mb.gzipDecompressTimeMetrics
-		compressor = mb.gzipCompressor
-		compressedBytes = gzipCompressed
-	case len(zstdCompressed) > 0:
-		opToDecompressTimeMetrics = mb.zstdDecompressTimeMetrics
-		compressor = mb.zstdCompressor
-		compressedBytes = zstdCompressed
-	default:
+	switch msg := m.GetMessage().(type) {
+	case *p2p.Message_CompressedGzip:
+		compressionType = compression.TypeGzip
+		compressedBytes = msg.CompressedGzip
+	case *p2p.Message_CompressedZstd:
+		compressionType = compression.TypeZstd
+		compressedBytes = msg.CompressedZstd
+	case *p2p.Message_CompressedLz4:
+		compressionType = compression.TypeLz4
+		compressedBytes = msg.CompressedLz4
+	case *p2p.Message_CompressedSnappy:
+		compressionType = compression.TypeSnappy
+		compressedBytes = msg.CompressedSnappy
+	case *p2p.Message_CompressedZstdDictionary:
+		if mb.zstdDictCompressor == nil {
+			return nil, 0, 0, errNoZstdDict
+		}
+		compressionType = compression.TypeZstd
+		compressor = mb.zstdDictCompressor
+		compressedBytes = msg.CompressedZstdDictionary
+	}
+	if len(compressedBytes) == 0 {
 		// The message wasn't compressed
 		op, err := ToOp(m)
 		return m, 0, op, err
 	}
+	if compressor == nil {
+		compressor = mb.compressors[compressionType]
+	}
 
 	startTime := time.Now()
 
@@ -329,12 +425,13 @@ func (mb *msgBuilder) unmarshal(b []byte) (*p2p.Message, int, Op, error) {
 	if err != nil {
 		return nil, 0, 0, err
 	}
-	if decompressTimeMetric, ok := opToDecompressTimeMetrics[op]; ok {
+	if decompressTimeMetric, ok := compressor.decompressTimeMetrics[op]; ok {
 		decompressTimeMetric.Observe(float64(decompressTook))
 	} else {
 		// Should never happen
 		mb.log.Warn("no decompression metric found for op",
 			zap.Stringer("op", op),
+			zap.Stringer("compressionType", compressionType),
 		)
 	}
 
@@ -342,17 +439,29 @@ func (mb *msgBuilder) unmarshal(b []byte) (*p2p.Message, int, Op, error) {
 }
 
 func (mb *msgBuilder) createOutbound(m *p2p.Message, compressionType compression.Type, bypassThrottling bool) (*outboundMessage, error) {
-	b, saved, op, err := mb.marshal(m, compressionType)
+	return mb.createOutboundWithEncoding(m, mb.defaultEncoding(compressionType), bypassThrottling)
+}
+
+func (mb *msgBuilder) createOutboundWithEncoding(m *p2p.Message, encoding encoding, bypassThrottling bool) (*outboundMessage, error) {
+	b, saved, op, err := mb.marshal(m, encoding)
 	if err != nil {
 		return nil, err
 	}
 
-	return &outboundMessage{
+	msg := &outboundMessage{
 		bypassThrottling:      bypassThrottling,
 		op:                    op,
 		bytes:                 b,
 		bytesSavedCompression: saved,
-	}, nil
+		encoding:              encoding,
+	}
+	if encoding.compressionType != compression.TypeNone {
+		// Keep the uncompressed message so that it can be compressed again for
+		// peers that don't support [encoding].
+		msg.builder = mb
+		msg.uncompressedMsg = m
+	}
+	return msg, nil
 }
 
 func (mb *msgBuilder) parseInbound(
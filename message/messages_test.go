@@ -29,6 +29,7 @@ func TestMessage(t *testing.T) {
 		logging.NoLog{},
 		"test",
 		prometheus.NewRegistry(),
+		nil,
 		5*time.Second,
 	)
 	require.NoError(t, err)
@@ -208,6 +209,50 @@ func TestMessage(t *testing.T) {
 			bypassThrottling: true,
 			bytesSaved:       true,
 		},
+		{
+			desc: "peer_list message with lz4 compression",
+			op:   PeerListOp,
+			msg: &p2p.Message{
+				Message: &p2p.Message_PeerList{
+					PeerList: &p2p.PeerList{
+						ClaimedIpPorts: []*p2p.ClaimedIpPort{
+							{
+								X509Certificate: testTLSCert.Certificate[0],
+								IpAddr:          []byte(net.IPv6zero),
+								IpPort:          9651,
+								Timestamp:       uint64(nowUnix),
+								Signature:       compressibleContainers[0],
+							},
+						},
+					},
+				},
+			},
+			compressionType:  compression.TypeLz4,
+			bypassThrottling: true,
+			bytesSaved:       true,
+		},
+		{
+			desc: "peer_list message with snappy compression",
+			op:   PeerListOp,
+			msg: &p2p.Message{
+				Message: &p2p.Message_PeerList{
+					PeerList: &p2p.PeerList{
+						ClaimedIpPorts: []*p2p.ClaimedIpPort{
+							{
+								X509Certificate: testTLSCert.Certificate[0],
+								IpAddr:          []byte(net.IPv6zero),
+								IpPort:          9651,
+								Timestamp:       uint64(nowUnix),
+								Signature:       compressibleContainers[0],
+							},
+						},
+					},
+				},
+			},
+			compressionType:  compression.TypeSnappy,
+			bypassThrottling: true,
+			bytesSaved:       true,
+		},
 		{
 			desc: "peer_list_ack message with no compression",
 			op:   PeerListAckOp,
@@ -856,6 +901,7 @@ func TestInboundMessageToString(t *testing.T) {
 		logging.NoLog{},
 		"test",
 		prometheus.NewRegistry(),
+		nil,
 		5*time.Second,
 	)
 	require.NoError(err)
@@ -889,6 +935,7 @@ func TestEmptyInboundMessage(t *testing.T) {
 		logging.NoLog{},
 		"test",
 		prometheus.NewRegistry(),
+		nil,
 		5*time.Second,
 	)
 	require.NoError(err)
@@ -910,6 +957,7 @@ func TestNilInboundMessage(t *testing.T) {
 		logging.NoLog{},
 		"test",
 		prometheus.NewRegistry(),
+		nil,
 		5*time.Second,
 	)
 	require.NoError(err)
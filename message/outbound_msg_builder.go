@@ -26,6 +26,7 @@ type OutboundMsgBuilder interface {
 		myVersionTime uint64,
 		sig []byte,
 		trackedSubnets []ids.ID,
+		quicPort uint16,
 	) (OutboundMessage, error)
 
 	PeerList(
@@ -229,6 +230,7 @@ func (b *outMsgBuilder) Version(
 	myVersionTime uint64,
 	sig []byte,
 	trackedSubnets []ids.ID,
+	quicPort uint16,
 ) (OutboundMessage, error) {
 	subnetIDBytes := make([][]byte, len(trackedSubnets))
 	encodeIDs(trackedSubnets, subnetIDBytes)
@@ -244,6 +246,10 @@ func (b *outMsgBuilder) Version(
 					MyVersionTime:  myVersionTime,
 					Sig:            sig,
 					TrackedSubnets: subnetIDBytes,
+
+					SupportedCompressionTypes: b.builder.supportedCompressionTypes,
+					ZstdDictionaryIds:         b.builder.zstdDictionaryIDs(),
+					QuicPort:                  uint32(quicPort),
 				},
 			},
 		},
@@ -23,6 +23,7 @@ func Test_newOutboundBuilder(t *testing.T) {
 		logging.NoLog{},
 		"test",
 		prometheus.NewRegistry(),
+		nil,
 		10*time.Second,
 	)
 	require.NoError(t, err)
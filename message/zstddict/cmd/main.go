@@ -0,0 +1,150 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/message/zstddict"
+	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+var (
+	errSamplesRequired = errors.New("--samples is required")
+	errOutputRequired  = errors.New("--output is required")
+	errUnknownOp       = errors.New("unknown op")
+	errNoMatchingOps   = errors.New("no samples matched --ops")
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "zstddict",
+		Short: "Trains zstd dictionaries to compress p2p messages with",
+		Long: "Trains zstd dictionaries from captured p2p messages. " +
+			"Nodes that are given the same dictionary with --network-compression-zstd-dictionary-file " +
+			"compress the messages they send each other with it.",
+	}
+
+	rootCmd.AddCommand(
+		trainCmd(),
+		infoCmd(),
+	)
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "zstddict failed: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func trainCmd() *cobra.Command {
+	var (
+		sampleFiles []string
+		output      string
+		maxSize     int
+		opNames     []string
+	)
+	cmd := &cobra.Command{
+		Use:   "train",
+		Short: "Trains a dictionary from captured messages",
+		Long: "Trains a dictionary from files of captured messages. Each message must be " +
+			"prefixed by its length as a 4 byte big-endian integer, as sent to peers.",
+		RunE: func(*cobra.Command, []string) error {
+			if len(sampleFiles) == 0 {
+				return errSamplesRequired
+			}
+			if len(output) == 0 {
+				return errOutputRequired
+			}
+			ops, err := parseOps(opNames)
+			if err != nil {
+				return err
+			}
+
+			var samples [][]byte
+			for _, sampleFile := range sampleFiles {
+				f, err := os.Open(sampleFile)
+				if err != nil {
+					return err
+				}
+				fileSamples, err := zstddict.ReadSamples(f)
+				_ = f.Close()
+				if err != nil {
+					return fmt.Errorf("couldn't read samples from %q: %w", sampleFile, err)
+				}
+				for _, sample := range fileSamples {
+					if ops.Len() == 0 || ops.Contains(sample.Op) {
+						samples = append(samples, sample.Bytes)
+					}
+				}
+			}
+			if len(samples) == 0 {
+				return errNoMatchingOps
+			}
+
+			zstdDict, err := compression.TrainZstdDict(samples, maxSize)
+			if err != nil {
+				return err
+			}
+			dictID, err := compression.ZstdDictID(zstdDict)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(output, zstdDict, perms.ReadWrite); err != nil {
+				return err
+			}
+			fmt.Printf("trained dictionary %d (%d bytes) from %d samples\n", dictID, len(zstdDict), len(samples))
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&sampleFiles, "samples", nil, "Comma-separated paths to files of captured messages")
+	cmd.Flags().StringVar(&output, "output", "", "Path to write the dictionary to")
+	cmd.Flags().IntVar(&maxSize, "max-size", 112*1024, "Maximum size of the dictionary in bytes")
+	cmd.Flags().StringSliceVar(&opNames, "ops", nil, "Comma-separated ops of the messages to train on, for example chits,push_query. Defaults to every op")
+	return cmd
+}
+
+func infoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info [dictionary]",
+		Short: "Prints the ID and size of a dictionary",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			zstdDict, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			dictID, err := compression.ZstdDictID(zstdDict)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("id: %d\nsize: %d\n", dictID, len(zstdDict))
+			return nil
+		},
+	}
+}
+
+func parseOps(opNames []string) (set.Set[message.Op], error) {
+	ops := make(map[string]message.Op, len(message.ExternalOps))
+	for _, op := range message.ExternalOps {
+		ops[op.String()] = op
+	}
+
+	parsed := set.NewSet[message.Op](len(opNames))
+	for _, name := range opNames {
+		op, ok := ops[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errUnknownOp, name)
+		}
+		parsed.Add(op)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package zstddict reads captured p2p messages to train zstd dictionaries
+// with.
+package zstddict
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/proto/pb/p2p"
+	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	ErrSampleTooLarge = errors.New("sample too large")
+	ErrZstdDictSample = errors.New("sample is compressed with a zstd dictionary")
+)
+
+// Sample is an uncompressed p2p message.
+type Sample struct {
+	Op    message.Op
+	Bytes []byte
+}
+
+// ReadSamples reads the p2p messages in [r]. Each message must be prefixed by
+// its length as a 4 byte big-endian integer, which is how messages are sent
+// to peers. Compressed messages are decompressed.
+func ReadSamples(r io.Reader) ([]Sample, error) {
+	var (
+		samples  []Sample
+		lenBytes [wrappers.IntLen]byte
+	)
+	for {
+		if _, err := io.ReadFull(r, lenBytes[:]); err != nil {
+			if err == io.EOF {
+				return samples, nil
+			}
+			return nil, err
+		}
+
+		msgLen := binary.BigEndian.Uint32(lenBytes[:])
+		if msgLen > constants.DefaultMaxMessageSize {
+			return nil, fmt.Errorf("%w: (%d) > (%d)", ErrSampleTooLarge, msgLen, constants.DefaultMaxMessageSize)
+		}
+		msgBytes := make([]byte, msgLen)
+		if _, err := io.ReadFull(r, msgBytes); err != nil {
+			return nil, err
+		}
+
+		sample, err := parseSample(msgBytes)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse sample %d: %w", len(samples), err)
+		}
+		samples = append(samples, sample)
+	}
+}
+
+func parseSample(msgBytes []byte) (Sample, error) {
+	msg := new(p2p.Message)
+	if err := proto.Unmarshal(msgBytes, msg); err != nil {
+		return Sample{}, err
+	}
+
+	var (
+		compressor      compression.Compressor
+		compressedBytes []byte
+		err             error
+	)
+	switch m := msg.GetMessage().(type) {
+	case *p2p.Message_CompressedGzip:
+		compressor, err = compression.NewGzipCompressor(constants.DefaultMaxMessageSize)
+		compressedBytes = m.CompressedGzip
+	case *p2p.Message_CompressedZstd:
+		compressor, err = compression.NewZstdCompressor(constants.DefaultMaxMessageSize)
+		compressedBytes = m.CompressedZstd
+	case *p2p.Message_CompressedLz4:
+		compressor, err = compression.NewLz4Compressor(constants.DefaultMaxMessageSize)
+		compressedBytes = m.CompressedLz4
+	case *p2p.Message_CompressedSnappy:
+		compressor, err = compression.NewSnappyCompressor(constants.DefaultMaxMessageSize)
+		compressedBytes = m.CompressedSnappy
+	case *p2p.Message_CompressedZstdDictionary:
+		return Sample{}, ErrZstdDictSample
+	}
+	if err != nil {
+		return Sample{}, err
+	}
+
+	if compressor != nil {
+		msgBytes, err = compressor.Decompress(compressedBytes)
+		if err != nil {
+			return Sample{}, err
+		}
+		msg = new(p2p.Message)
+		if err := proto.Unmarshal(msgBytes, msg); err != nil {
+			return Sample{}, err
+		}
+	}
+
+	op, err := message.ToOp(msg)
+	if err != nil {
+		return Sample{}, err
+	}
+	return Sample{
+		Op:    op,
+		Bytes: msgBytes,
+	}, nil
+}
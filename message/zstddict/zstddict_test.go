@@ -0,0 +1,130 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package zstddict
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/proto/pb/p2p"
+	"github.com/ava-labs/avalanchego/utils/compression"
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+func writeSample(t *testing.T, w io.Writer, msg *p2p.Message) {
+	msgBytes, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	require.NoError(t, binary.Write(w, binary.BigEndian, uint32(len(msgBytes))))
+	_, err = w.Write(msgBytes)
+	require.NoError(t, err)
+}
+
+func TestReadSamples(t *testing.T) {
+	require := require.New(t)
+
+	chainID := ids.GenerateTestID()
+	chits := &p2p.Message{
+		Message: &p2p.Message_Chits{
+			Chits: &p2p.Chits{
+				ChainId:   chainID[:],
+				RequestId: 1,
+			},
+		},
+	}
+	chitsBytes, err := proto.Marshal(chits)
+	require.NoError(err)
+
+	gossip := &p2p.Message{
+		Message: &p2p.Message_AppGossip{
+			AppGossip: &p2p.AppGossip{
+				ChainId:  chainID[:],
+				AppBytes: []byte("gossip"),
+			},
+		},
+	}
+	gossipBytes, err := proto.Marshal(gossip)
+	require.NoError(err)
+
+	compressor, err := compression.NewZstdCompressor(constants.DefaultMaxMessageSize)
+	require.NoError(err)
+	compressedGossip, err := compressor.Compress(gossipBytes)
+	require.NoError(err)
+
+	buf := &bytes.Buffer{}
+	writeSample(t, buf, chits)
+	writeSample(t, buf, &p2p.Message{
+		Message: &p2p.Message_CompressedZstd{
+			CompressedZstd: compressedGossip,
+		},
+	})
+
+	samples, err := ReadSamples(buf)
+	require.NoError(err)
+	require.Equal(
+		[]Sample{
+			{
+				Op:    message.ChitsOp,
+				Bytes: chitsBytes,
+			},
+			{
+				Op:    message.AppGossipOp,
+				Bytes: gossipBytes,
+			},
+		},
+		samples,
+	)
+}
+
+func TestReadSamplesErrors(t *testing.T) {
+	tooLarge := make([]byte, 4)
+	binary.BigEndian.PutUint32(tooLarge, constants.DefaultMaxMessageSize+1)
+
+	zstdDictSample := &bytes.Buffer{}
+	writeSample(t, zstdDictSample, &p2p.Message{
+		Message: &p2p.Message_CompressedZstdDictionary{
+			CompressedZstdDictionary: []byte{1},
+		},
+	})
+
+	tests := []struct {
+		name        string
+		samples     []byte
+		expectedErr error
+	}{
+		{
+			name:        "truncated length",
+			samples:     []byte{0, 0},
+			expectedErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:        "truncated message",
+			samples:     []byte{0, 0, 0, 2, 0},
+			expectedErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:        "too large",
+			samples:     tooLarge,
+			expectedErr: ErrSampleTooLarge,
+		},
+		{
+			name:        "compressed with a zstd dictionary",
+			samples:     zstdDictSample.Bytes(),
+			expectedErr: ErrZstdDictSample,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSamples(bytes.NewReader(tt.samples))
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
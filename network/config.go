@@ -116,6 +116,11 @@ type Config struct {
 	DialerConfig dialer.Config `json:"dialerConfig"`
 	TLSConfig    *tls.Config   `json:"-"`
 
+	// QUICEnabled specifies whether QUIC connections are accepted, on the UDP
+	// port with the same number as the staking port, and made to peers that
+	// accept them.
+	QUICEnabled bool `json:"quicEnabled"`
+
 	TLSKeyLogFile string `json:"tlsKeyLogFile"`
 
 	Namespace          string            `json:"namespace"`
@@ -127,9 +132,15 @@ type Config struct {
 	AllowPrivateIPs    bool              `json:"allowPrivateIPs"`
 
 	// The compression type to use when compressing outbound messages.
-	// Assumes all peers support this compression type.
+	// Messages sent to peers that don't support this compression type are
+	// compressed with a compression type they support.
 	CompressionType compression.Type `json:"compressionType"`
 
+	// CompressionZstdDictionary is the dictionary that messages compressed
+	// with zstd are compressed with, when sent to peers that have it. If
+	// empty, no dictionary is used.
+	CompressionZstdDictionary []byte `json:"-"`
+
 	// TLSKey is this node's TLS key that is used to sign IPs.
 	TLSKey crypto.Signer `json:"-"`
 
@@ -43,12 +43,6 @@ type Config struct {
 // [dialerConfig.throttleRps] gives the max number of outgoing connection attempts/second.
 // If [dialerConfig.throttleRps] == 0, outgoing connections aren't rate-limited.
 func NewDialer(network string, dialerConfig Config, log logging.Logger) Dialer {
-	var throttler throttling.DialThrottler
-	if dialerConfig.ThrottleRps <= 0 {
-		throttler = throttling.NewNoDialThrottler()
-	} else {
-		throttler = throttling.NewDialThrottler(int(dialerConfig.ThrottleRps))
-	}
 	log.Debug(
 		"creating dialer",
 		zap.Uint32("throttleRPS", dialerConfig.ThrottleRps),
@@ -58,8 +52,15 @@ func NewDialer(network string, dialerConfig Config, log logging.Logger) Dialer {
 		dialer:    net.Dialer{Timeout: dialerConfig.ConnectionTimeout},
 		log:       log,
 		network:   network,
-		throttler: throttler,
+		throttler: newDialThrottler(dialerConfig),
+	}
+}
+
+func newDialThrottler(dialerConfig Config) throttling.DialThrottler {
+	if dialerConfig.ThrottleRps <= 0 {
+		return throttling.NewNoDialThrottler()
 	}
+	return throttling.NewDialThrottler(int(dialerConfig.ThrottleRps))
 }
 
 func (d *dialer) Dial(ctx context.Context, ip ips.IPPort) (net.Conn, error) {
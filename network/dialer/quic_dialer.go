@@ -0,0 +1,68 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dialer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/network/quic"
+	"github.com/ava-labs/avalanchego/network/throttling"
+	"github.com/ava-labs/avalanchego/utils/ips"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+var _ Dialer = (*quicDialer)(nil)
+
+type quicDialer struct {
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	log       logging.Logger
+	throttler throttling.DialThrottler
+}
+
+// NewQUICDialer returns a new Dialer that establishes QUIC connections. The
+// returned connections are of type [*quic.Conn] and have already completed the
+// TLS handshake with [tlsConfig].
+// [dialerConfig.connectionTimeout] gives the timeout when dialing an IP,
+// including the TLS handshake.
+// [dialerConfig.throttleRps] gives the max number of outgoing connection attempts/second.
+// If [dialerConfig.throttleRps] == 0, outgoing connections aren't rate-limited.
+func NewQUICDialer(tlsConfig *tls.Config, dialerConfig Config, log logging.Logger) Dialer {
+	log.Debug(
+		"creating QUIC dialer",
+		zap.Uint32("throttleRPS", dialerConfig.ThrottleRps),
+		zap.Duration("dialTimeout", dialerConfig.ConnectionTimeout),
+	)
+	return &quicDialer{
+		tlsConfig: tlsConfig,
+		timeout:   dialerConfig.ConnectionTimeout,
+		log:       log,
+		throttler: newDialThrottler(dialerConfig),
+	}
+}
+
+func (d *quicDialer) Dial(ctx context.Context, ip ips.IPPort) (net.Conn, error) {
+	if err := d.throttler.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	d.log.Verbo("dialing over QUIC",
+		zap.Stringer("ip", ip),
+	)
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+	conn, err := quic.Dial(ctx, ip.String(), d.tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error while dialing %s over QUIC: %w", ip, err)
+	}
+	return conn, nil
+}
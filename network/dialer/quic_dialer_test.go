@@ -0,0 +1,75 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dialer
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/network/quic"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/ips"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+// Test that canceling a context passed into Dial results in giving up trying to
+// connect over QUIC
+func TestQUICDialerCancelDial(t *testing.T) {
+	require := require.New(t)
+
+	tlsCert, err := staking.NewTLSCert()
+	require.NoError(err)
+	// #nosec G402
+	tlsConfig := &tls.Config{
+		Certificates:       []tls.Certificate{*tlsCert},
+		ClientAuth:         tls.RequireAnyClientCert,
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS13,
+	}
+
+	l, err := quic.Listen("127.0.0.1:0", tlsConfig)
+	require.NoError(err)
+	defer l.Close()
+
+	go func() {
+		for {
+			// Continuously accept connections from myself
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	myIP, err := ips.ToIPPort(l.Addr().String())
+	require.NoError(err)
+
+	dialer := NewQUICDialer(
+		tlsConfig,
+		Config{
+			ThrottleRps:       10,
+			ConnectionTimeout: 30 * time.Second,
+		},
+		logging.NoLog{},
+	)
+
+	// Make an outgoing connection with a cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = dialer.Dial(ctx, myIP)
+	require.ErrorIs(err, context.Canceled)
+
+	// Make an outgoing connection with a non-cancelled context
+	conn, err := dialer.Dial(context.Background(), myIP)
+	require.NoError(err)
+	require.IsType(&quic.Conn{}, conn)
+	require.IsType(&net.UDPAddr{}, conn.RemoteAddr())
+	_ = conn.Close()
+}
@@ -136,6 +136,16 @@ type network struct {
 	// Does TLS handshakes for outbound connections
 	clientUpgrader peer.Upgrader
 
+	// Listens for and accepts new inbound QUIC connections. Nil if QUIC is
+	// disabled.
+	quicListener net.Listener
+	// Makes new outbound QUIC connections. Nil if QUIC is disabled.
+	quicDialer dialer.Dialer
+	// Accepts the streams of inbound QUIC connections
+	quicServerUpgrader peer.Upgrader
+	// Opens the streams of outbound QUIC connections
+	quicClientUpgrader peer.Upgrader
+
 	// ensures the close of the network only happens once.
 	closeOnce sync.Once
 	// Cancelled on close
@@ -180,6 +190,10 @@ type network struct {
 }
 
 // NewNetwork returns a new Network implementation with the provided parameters.
+//
+// If [quicListener] and [quicDialer] are provided, QUIC connections are
+// accepted and made to peers that accept them. Otherwise, only TLS over TCP
+// connections are used.
 func NewNetwork(
 	config *Config,
 	msgCreator message.Creator,
@@ -187,6 +201,8 @@ func NewNetwork(
 	log logging.Logger,
 	listener net.Listener,
 	dialer dialer.Dialer,
+	quicListener net.Listener,
+	quicDialer dialer.Dialer,
 	router router.ExternalHandler,
 ) (Network, error) {
 	primaryNetworkValidators, ok := config.Validators.Get(constants.PrimaryNetworkID)
@@ -230,6 +246,28 @@ func NewNetwork(
 		return nil, fmt.Errorf("initializing inbound message throttler failed with: %w", err)
 	}
 
+	// Peers connected over QUIC throttle each of their streams separately, so
+	// that a throttled stream doesn't delay the messages of the others.
+	var streamInboundMsgThrottlers []throttling.InboundMsgThrottler
+	if quicListener != nil || quicDialer != nil {
+		for stream := peer.ConsensusStream + 1; stream < peer.NumStreams; stream++ {
+			streamInboundMsgThrottler, err := throttling.NewInboundMsgThrottler(
+				log,
+				fmt.Sprintf("%s_stream_%d", config.Namespace, stream),
+				metricsRegisterer,
+				primaryNetworkValidators,
+				config.ThrottlerConfig.InboundMsgThrottlerConfig,
+				config.ResourceTracker,
+				config.CPUTargeter,
+				config.DiskTargeter,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("initializing inbound message throttler of stream %d failed with: %w", stream, err)
+			}
+			streamInboundMsgThrottlers = append(streamInboundMsgThrottlers, streamInboundMsgThrottler)
+		}
+	}
+
 	outboundMsgThrottler, err := throttling.NewSybilOutboundMsgThrottler(
 		log,
 		config.Namespace,
@@ -271,6 +309,9 @@ func NewNetwork(
 		ResourceTracker:      config.ResourceTracker,
 		UptimeCalculator:     config.UptimeCalculator,
 		IPSigner:             peer.NewIPSigner(config.MyIPPort, config.TLSKey),
+		QUICEnabled:          quicListener != nil,
+
+		StreamInboundMsgThrottlers: streamInboundMsgThrottlers,
 	}
 
 	onCloseCtx, cancel := context.WithCancel(context.Background())
@@ -285,6 +326,10 @@ func NewNetwork(
 		dialer:                      dialer,
 		serverUpgrader:              peer.NewTLSServerUpgrader(config.TLSConfig, metrics.tlsConnRejected),
 		clientUpgrader:              peer.NewTLSClientUpgrader(config.TLSConfig, metrics.tlsConnRejected),
+		quicListener:                quicListener,
+		quicDialer:                  quicDialer,
+		quicServerUpgrader:          peer.NewQUICServerUpgrader(metrics.tlsConnRejected),
+		quicClientUpgrader:          peer.NewQUICClientUpgrader(metrics.tlsConnRejected),
 
 		onCloseCtx:       onCloseCtx,
 		onCloseCtxCancel: cancel,
@@ -425,6 +470,7 @@ func (n *network) Connected(nodeID ids.NodeID) {
 		IPPort:    peerIP.IPPort,
 		Timestamp: peerIP.Timestamp,
 		Signature: peerIP.Signature,
+		QUICPort:  peer.QUICPort(),
 	}
 	prevIP, ok := n.peerIPs[nodeID]
 	if !ok {
@@ -443,6 +489,10 @@ func (n *network) Connected(nodeID ids.NodeID) {
 			)
 			_ = n.gossipTracker.ResetValidator(nodeID)
 		}
+	} else if prevIP.IPPort.Equal(newIP.IPPort) {
+		// The QUIC port is only ever learned from the peer itself, so it is
+		// recorded even if the IP was already known.
+		prevIP.QUICPort = newIP.QUICPort
 	}
 
 	if tracked, ok := n.trackedIPs[nodeID]; ok {
@@ -537,13 +587,21 @@ func (n *network) Track(peerID ids.NodeID, claimedIPPorts []*ips.ClaimedIPPort)
 			newestTimestamp[ip.TxID] = ip.Timestamp
 			txIDsWithUpToDateIP = append(txIDsWithUpToDateIP, ip.TxID)
 
+			// The QUIC port isn't covered by the IP signature, so it is never
+			// gossiped. The port the peer sent us directly remains valid as
+			// long as its IP doesn't change.
+			sameIP := prevIP.IPPort.Equal(ip.IPPort)
+			if sameIP {
+				ip.QUICPort = prevIP.QUICPort
+			}
+
 			// In the future, we should gossip this IP rather than the old IP.
 			n.peerIPs[nodeID] = ip
 
 			// If the new IP is equal to the old IP, there is no reason to
 			// refresh the references to it. This can happen when a node
 			// restarts but does not change their IP.
-			if prevIP.IPPort.Equal(ip.IPPort) {
+			if sameIP {
 				continue
 			}
 
@@ -556,7 +614,7 @@ func (n *network) Track(peerID ids.NodeID, claimedIPPorts []*ips.ClaimedIPPort)
 			// We should update any existing outbound connection attempts.
 			if isTracked {
 				// Stop tracking the old IP and start tracking the new one.
-				tracked := tracked.trackNewIP(ip.IPPort)
+				tracked := tracked.trackNewIP(ip.IPPort, 0)
 				n.trackedIPs[nodeID] = tracked
 				n.dial(n.onCloseCtx, nodeID, tracked)
 			}
@@ -573,7 +631,7 @@ func (n *network) Track(peerID ids.NodeID, claimedIPPorts []*ips.ClaimedIPPort)
 			// we've never gossiped it before.
 			n.peerIPs[nodeID] = ip
 
-			tracked := newTrackedIP(ip.IPPort)
+			tracked := newTrackedIP(ip.IPPort, 0)
 			n.trackedIPs[nodeID] = tracked
 			n.dial(n.onCloseCtx, nodeID, tracked)
 		default:
@@ -728,12 +786,33 @@ func (n *network) Dispatch() error {
 	go n.runTimers() // Periodically perform operations
 	go n.inboundConnUpgradeThrottler.Dispatch()
 	errs := wrappers.Errs{}
+	if n.quicListener != nil {
+		go n.acceptConns(n.quicListener, n.quicServerUpgrader)
+	}
+	n.acceptConns(n.listener, n.serverUpgrader)
+	n.inboundConnUpgradeThrottler.Stop()
+	n.StartClose()
+
+	n.peersLock.RLock()
+	connecting := n.connectingPeers.Sample(n.connectingPeers.Len(), peer.NoPrecondition)
+	connected := n.connectedPeers.Sample(n.connectedPeers.Len(), peer.NoPrecondition)
+	n.peersLock.RUnlock()
+
+	for _, peer := range append(connecting, connected...) {
+		errs.Add(peer.AwaitClosed(context.TODO()))
+	}
+	return errs.Err
+}
+
+// acceptConns accepts connections from [listener] and upgrades them with
+// [upgrader] until the network is closed.
+func (n *network) acceptConns(listener net.Listener, upgrader peer.Upgrader) {
 	for { // Continuously accept new connections
 		if n.onCloseCtx.Err() != nil {
 			break
 		}
 
-		conn, err := n.listener.Accept() // Returns error when n.Close() is called
+		conn, err := listener.Accept() // Returns error when n.Close() is called
 		if err != nil {
 			n.peerConfig.Log.Debug("error during server accept", zap.Error(err))
 			// Sleep for a small amount of time to try to wait for the
@@ -777,7 +856,7 @@ func (n *network) Dispatch() error {
 				zap.Stringer("peerIP", ip),
 			)
 
-			if err := n.upgrade(conn, n.serverUpgrader); err != nil {
+			if err := n.upgrade(conn, upgrader); err != nil {
 				n.peerConfig.Log.Verbo("failed to upgrade connection",
 					zap.String("direction", "inbound"),
 					zap.Error(err),
@@ -785,18 +864,6 @@ func (n *network) Dispatch() error {
 			}
 		}()
 	}
-	n.inboundConnUpgradeThrottler.Stop()
-	n.StartClose()
-
-	n.peersLock.RLock()
-	connecting := n.connectingPeers.Sample(n.connectingPeers.Len(), peer.NoPrecondition)
-	connected := n.connectedPeers.Sample(n.connectedPeers.Len(), peer.NoPrecondition)
-	n.peersLock.RUnlock()
-
-	for _, peer := range append(connecting, connected...) {
-		errs.Add(peer.AwaitClosed(context.TODO()))
-	}
-	return errs.Err
 }
 
 func (n *network) WantsConnection(nodeID ids.NodeID) bool {
@@ -827,7 +894,9 @@ func (n *network) ManuallyTrack(nodeID ids.NodeID, ip ips.IPPort) {
 
 	_, isTracked := n.trackedIPs[nodeID]
 	if !isTracked {
-		tracked := newTrackedIP(ip)
+		// Manually tracked peers are dialed over TCP until they tell us the
+		// port they accept QUIC connections on.
+		tracked := newTrackedIP(ip, 0)
 		n.trackedIPs[nodeID] = tracked
 		n.dial(n.onCloseCtx, nodeID, tracked)
 	}
@@ -963,7 +1032,7 @@ func (n *network) disconnectedFromConnecting(nodeID ids.NodeID) {
 	tracked, ok := n.trackedIPs[nodeID]
 	if ok {
 		if n.wantsConnection(nodeID) {
-			tracked := tracked.trackNewIP(tracked.ip)
+			tracked := tracked.trackNewIP(tracked.ip, tracked.quicPort)
 			n.trackedIPs[nodeID] = tracked
 			n.dial(n.onCloseCtx, nodeID, tracked)
 		} else {
@@ -987,7 +1056,7 @@ func (n *network) disconnectedFromConnected(peer peer.Peer, nodeID ids.NodeID) {
 	// The peer that is disconnecting from us finished the handshake
 	if n.wantsConnection(nodeID) {
 		prevIP := n.peerIPs[nodeID]
-		tracked := newTrackedIP(prevIP.IPPort)
+		tracked := newTrackedIP(prevIP.IPPort, prevIP.QUICPort)
 		n.trackedIPs[nodeID] = tracked
 		n.dial(n.onCloseCtx, nodeID, tracked)
 	} else {
@@ -1141,6 +1210,14 @@ func (n *network) dial(ctx context.Context, nodeID ids.NodeID, ip *trackedIP) {
 				continue
 			}
 
+			// Peers that accept QUIC connections are dialed over QUIC first.
+			// If that fails, the connection falls back to TLS over TCP.
+			if n.quicDialer != nil && ip.quicPort != 0 {
+				if n.dialQUIC(ctx, ip) {
+					return
+				}
+			}
+
 			conn, err := n.dialer.Dial(ctx, ip.ip)
 			if err != nil {
 				n.peerConfig.Log.Verbo(
@@ -1170,6 +1247,39 @@ func (n *network) dial(ctx context.Context, nodeID ids.NodeID, ip *trackedIP) {
 	}()
 }
 
+// dialQUIC attempts to connect to [ip] over QUIC. Returns true if the
+// connection was successfully upgraded.
+func (n *network) dialQUIC(ctx context.Context, ip *trackedIP) bool {
+	quicIP := ip.quicIP()
+	conn, err := n.quicDialer.Dial(ctx, quicIP)
+	if err != nil {
+		n.metrics.quicDialFailed.Inc()
+		n.peerConfig.Log.Verbo(
+			"failed to reach peer over QUIC, falling back to TCP",
+			zap.Stringer("peerIP", quicIP),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	n.peerConfig.Log.Verbo("starting to upgrade connection",
+		zap.String("direction", "outbound"),
+		zap.String("transport", "quic"),
+		zap.Stringer("peerIP", quicIP),
+	)
+
+	if err := n.upgrade(conn, n.quicClientUpgrader); err != nil {
+		n.metrics.quicDialFailed.Inc()
+		n.peerConfig.Log.Verbo(
+			"failed to upgrade QUIC connection, falling back to TCP",
+			zap.Stringer("peerIP", quicIP),
+			zap.Error(err),
+		)
+		return false
+	}
+	return true
+}
+
 // upgrade the provided connection, which may be an inbound connection or an
 // outbound connection, with the provided [upgrader].
 //
@@ -1274,23 +1384,42 @@ func (n *network) upgrade(conn net.Conn, upgrader peer.Upgrader) error {
 	// peer.Start requires there is only ever one peer instance running with the
 	// same [peerConfig.InboundMsgThrottler]. This is guaranteed by the above
 	// de-duplications for [connectingPeers] and [connectedPeers].
-	peer := peer.Start(
-		n.peerConfig,
-		tlsConn,
-		cert,
-		nodeID,
-		peer.NewThrottledMessageQueue(
-			n.peerConfig.Metrics,
+	var p peer.Peer
+	if streamConn, ok := tlsConn.(peer.StreamConn); ok {
+		messageQueues := make([]peer.MessageQueue, peer.NumStreams)
+		for i := range messageQueues {
+			messageQueues[i] = n.newMessageQueue(nodeID)
+		}
+		p = peer.StartStreams(
+			n.peerConfig,
+			streamConn,
+			cert,
 			nodeID,
-			n.peerConfig.Log,
-			n.outboundMsgThrottler,
-		),
-	)
-	n.connectingPeers.Add(peer)
+			messageQueues,
+		)
+	} else {
+		p = peer.Start(
+			n.peerConfig,
+			tlsConn,
+			cert,
+			nodeID,
+			n.newMessageQueue(nodeID),
+		)
+	}
+	n.connectingPeers.Add(p)
 	n.peersLock.Unlock()
 	return nil
 }
 
+func (n *network) newMessageQueue(nodeID ids.NodeID) peer.MessageQueue {
+	return peer.NewThrottledMessageQueue(
+		n.peerConfig.Metrics,
+		nodeID,
+		n.peerConfig.Log,
+		n.outboundMsgThrottler,
+	)
+}
+
 func (n *network) PeerInfo(nodeIDs []ids.NodeID) []peer.Info {
 	n.peersLock.RLock()
 	defer n.peersLock.RUnlock()
@@ -1310,6 +1439,13 @@ func (n *network) StartClose() {
 				zap.Error(err),
 			)
 		}
+		if n.quicListener != nil {
+			if err := n.quicListener.Close(); err != nil {
+				n.peerConfig.Log.Debug("closing the network QUIC listener",
+					zap.Error(err),
+				)
+			}
+		}
 
 		n.peersLock.Lock()
 		defer n.peersLock.Unlock()
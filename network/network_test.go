@@ -189,6 +189,7 @@ func newMessageCreator(t *testing.T) message.Creator {
 		prometheus.NewRegistry(),
 		"",
 		constants.DefaultNetworkCompressionType,
+		nil,
 		10*time.Second,
 	)
 	require.NoError(t, err)
@@ -248,6 +249,8 @@ func newFullyConnectedTestNetwork(t *testing.T, handlers []router.InboundHandler
 			log,
 			listeners[i],
 			dialer,
+			nil,
+			nil,
 			&testHandler{
 				InboundHandler: handlers[i],
 				ConnectedF: func(nodeID ids.NodeID, _ *version.Application, _ ids.ID) {
@@ -474,6 +477,8 @@ func TestTrackDoesNotDialPrivateIPs(t *testing.T) {
 			log,
 			listeners[i],
 			dialer,
+			nil,
+			nil,
 			&testHandler{
 				InboundHandler: nil,
 				ConnectedF: func(ids.NodeID, *version.Application, ids.ID) {
@@ -568,6 +573,8 @@ func TestDialDeletesNonValidators(t *testing.T) {
 			log,
 			listeners[i],
 			dialer,
+			nil,
+			nil,
 			&testHandler{
 				InboundHandler: nil,
 				ConnectedF: func(ids.NodeID, *version.Application, ids.ID) {
@@ -5,6 +5,7 @@ package gossip
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,13 +17,16 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
 	"github.com/ava-labs/avalanchego/proto/pb/sdk"
+	"github.com/ava-labs/avalanchego/utils/buffer"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
 var (
 	_ Gossiper = (*ValidatorGossiper)(nil)
 	_ Gossiper = (*PullGossiper[testTx, *testTx])(nil)
+	_ Gossiper = (*PushGossiper[*testTx])(nil)
 )
 
 // Gossiper gossips Gossipables to other nodes
@@ -181,6 +185,230 @@ func (p *PullGossiper[T, U]) handleResponse(
 	p.receivedBytes.Add(float64(receivedBytes))
 }
 
+type PushGossiperConfig struct {
+	Namespace string
+	// Fanout is the number of nodes, sampled from the provided NodeSampler,
+	// that each gossip message is pushed to. If 0, gossip is pushed to the
+	// peers chosen by [p2p.Client.AppGossip].
+	Fanout int
+	// TargetGossipSize is the number of bytes after which no more gossip is
+	// added to a gossip message.
+	TargetGossipSize int
+	// MaxPushedElements is the number of recently pushed gossipables the
+	// filter used to deduplicate pushes is sized for. It is also the maximum
+	// number of gossipables waiting to be pushed, after which the oldest
+	// pending gossipables are dropped.
+	MaxPushedElements uint64
+	// PushedFalsePositiveProbability is the target false positive
+	// probability of the filter used to deduplicate pushes.
+	PushedFalsePositiveProbability float64
+	// MaxPushedFalsePositiveProbability is the false positive probability
+	// after which the filter used to deduplicate pushes is reset.
+	MaxPushedFalsePositiveProbability float64
+}
+
+func NewPushGossiper[T Gossipable](
+	config PushGossiperConfig,
+	log logging.Logger,
+	client *p2p.Client,
+	nodeSampler p2p.NodeSampler,
+	metrics prometheus.Registerer,
+) (*PushGossiper[T], error) {
+	pushed, err := NewBloomFilter(
+		config.MaxPushedElements,
+		config.PushedFalsePositiveProbability,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PushGossiper[T]{
+		config:      config,
+		log:         log,
+		client:      client,
+		nodeSampler: nodeSampler,
+		pushed:      pushed,
+		pendingIDs:  set.Set[ids.ID]{},
+		sentN: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "gossip_push_sent_n",
+			Help:      "amount of gossip pushed (n)",
+		}),
+		sentBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "gossip_push_sent_bytes",
+			Help:      "amount of gossip pushed (bytes)",
+		}),
+		duplicateN: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "gossip_push_duplicate_n",
+			Help:      "amount of gossip not pushed because it was already pushed (n)",
+		}),
+		droppedN: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "gossip_push_dropped_n",
+			Help:      "amount of gossip dropped because too much gossip was waiting to be pushed (n)",
+		}),
+		pendingN: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Name:      "gossip_push_pending_n",
+			Help:      "amount of gossip waiting to be pushed (n)",
+		}),
+	}
+
+	p.pending, err = buffer.NewBoundedQueue(int(config.MaxPushedElements), p.onEvict)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		metrics.Register(p.sentN),
+		metrics.Register(p.sentBytes),
+		metrics.Register(p.duplicateN),
+		metrics.Register(p.droppedN),
+		metrics.Register(p.pendingN),
+	)
+
+	return p, errs.Err
+}
+
+// PushGossiper pushes newly added gossip to other nodes rather than waiting
+// for them to pull it.
+type PushGossiper[T Gossipable] struct {
+	config      PushGossiperConfig
+	log         logging.Logger
+	client      *p2p.Client
+	nodeSampler p2p.NodeSampler
+
+	lock sync.Mutex
+	// pushed contains the gossip that has already been pushed
+	pushed *BloomFilter
+	// pending is the gossip waiting to be pushed, oldest first
+	pending    buffer.Queue[T]
+	pendingIDs set.Set[ids.ID]
+
+	sentN      prometheus.Counter
+	sentBytes  prometheus.Counter
+	duplicateN prometheus.Counter
+	droppedN   prometheus.Counter
+	pendingN   prometheus.Gauge
+}
+
+// Add queues [gossipables] to be pushed on the next call to [Gossip].
+// Gossipables that were recently pushed, or that are already queued, are
+// ignored. If too much gossip is queued, the oldest gossip is dropped.
+func (p *PushGossiper[T]) Add(gossipables ...T) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, gossipable := range gossipables {
+		gossipID := gossipable.GetID()
+		if p.pendingIDs.Contains(gossipID) || p.pushed.Has(gossipable) {
+			p.duplicateN.Inc()
+			continue
+		}
+
+		p.pendingIDs.Add(gossipID)
+		p.pending.Push(gossipable)
+	}
+	p.pendingN.Set(float64(p.pending.Len()))
+}
+
+// Gossip pushes all the pending gossip. Gossip is batched into messages of
+// about [TargetGossipSize] bytes, each of which is pushed to a newly sampled
+// set of nodes. If there aren't any nodes to push to, or pushing fails, the
+// gossip remains pending.
+func (p *PushGossiper[T]) Gossip(ctx context.Context) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for p.pending.Len() > 0 {
+		var nodeIDs set.Set[ids.NodeID]
+		if p.config.Fanout > 0 {
+			nodeIDs = set.Of(p.nodeSampler.Sample(ctx, p.config.Fanout)...)
+			if nodeIDs.Len() == 0 {
+				p.log.Debug("no nodes to push gossip to")
+				return nil
+			}
+		}
+
+		var (
+			msg          = &sdk.PushGossip{}
+			marshaled    = make([]T, 0)
+			sentBytes    = 0
+			numProcessed = 0
+		)
+		for ; numProcessed < p.pending.Len(); numProcessed++ {
+			if sentBytes >= p.config.TargetGossipSize && len(msg.Gossip) > 0 {
+				break
+			}
+
+			gossipable, _ := p.pending.Index(numProcessed)
+			bytes, err := gossipable.Marshal()
+			if err != nil {
+				p.log.Debug(
+					"dropping gossip that failed to marshal",
+					zap.Stringer("id", gossipable.GetID()),
+					zap.Error(err),
+				)
+				continue
+			}
+
+			msg.Gossip = append(msg.Gossip, bytes)
+			marshaled = append(marshaled, gossipable)
+			sentBytes += len(bytes)
+		}
+		if len(msg.Gossip) > 0 {
+			msgBytes, err := proto.Marshal(msg)
+			if err != nil {
+				return err
+			}
+
+			if nodeIDs.Len() > 0 {
+				err = p.client.AppGossipSpecific(ctx, nodeIDs, msgBytes)
+			} else {
+				err = p.client.AppGossip(ctx, msgBytes)
+			}
+			if err != nil {
+				return err
+			}
+
+			// Gossip is only marked as pushed once it was actually sent, so
+			// that gossip that failed to be sent can be added again.
+			for _, gossipable := range marshaled {
+				p.pushed.Add(gossipable)
+			}
+			p.sentN.Add(float64(len(msg.Gossip)))
+			p.sentBytes.Add(float64(sentBytes))
+		}
+
+		p.removePending(numProcessed)
+		if _, err := ResetBloomFilterIfNeeded(p.pushed, p.config.MaxPushedFalsePositiveProbability); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removePending removes the oldest [n] pending gossipables.
+func (p *PushGossiper[T]) removePending(n int) {
+	for i := 0; i < n; i++ {
+		gossipable, _ := p.pending.Pop()
+		p.pendingIDs.Remove(gossipable.GetID())
+	}
+	p.pendingN.Set(float64(p.pending.Len()))
+}
+
+// onEvict is called when pending gossip is dropped to make room for newer
+// gossip.
+//
+// Invariant: Assumes [p.lock] is held.
+func (p *PushGossiper[T]) onEvict(gossipable T) {
+	p.pendingIDs.Remove(gossipable.GetID())
+	p.droppedN.Inc()
+}
+
 // Every calls [Gossip] every [frequency] amount of time.
 func Every(ctx context.Context, log logging.Logger, gossiper Gossiper, frequency time.Duration) {
 	ticker := time.NewTicker(frequency)
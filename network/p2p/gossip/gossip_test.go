@@ -5,18 +5,23 @@ package gossip
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/stretchr/testify/require"
 
 	"go.uber.org/mock/gomock"
 
+	"google.golang.org/protobuf/proto"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/proto/pb/sdk"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/set"
@@ -25,6 +30,8 @@ import (
 var (
 	_ p2p.ValidatorSet = (*testValidatorSet)(nil)
 	_ Gossiper         = (*testGossiper)(nil)
+
+	errTestSend = errors.New("test send failed")
 )
 
 func TestGossiperShutdown(t *testing.T) {
@@ -200,6 +207,213 @@ func TestGossiperGossip(t *testing.T) {
 	}
 }
 
+func TestPushGossiperGossip(t *testing.T) {
+	tests := []struct {
+		name                string
+		fanout              int
+		targetGossipSize    int
+		maxPushedElements   uint64
+		peers               []ids.NodeID
+		gossip              [][]*testTx // gossip added before each cycle
+		failedSends         int         // number of sends that fail before sends succeed
+		expectedNumMessages int
+		expectedReceived    []*testTx
+	}{
+		{
+			name:             "no gossip",
+			fanout:           1,
+			targetGossipSize: 1024,
+			peers:            []ids.NodeID{ids.EmptyNodeID},
+		},
+		{
+			name:                "gossip pushed in one message",
+			fanout:              1,
+			targetGossipSize:    1024,
+			peers:               []ids.NodeID{ids.EmptyNodeID},
+			gossip:              [][]*testTx{{{id: ids.ID{0}}, {id: ids.ID{1}}}},
+			expectedNumMessages: 1,
+			expectedReceived:    []*testTx{{id: ids.ID{0}}, {id: ids.ID{1}}},
+		},
+		{
+			name:                "gossip batched by target gossip size",
+			fanout:              1,
+			targetGossipSize:    32,
+			peers:               []ids.NodeID{ids.EmptyNodeID},
+			gossip:              [][]*testTx{{{id: ids.ID{0}}, {id: ids.ID{1}}, {id: ids.ID{2}}}},
+			expectedNumMessages: 3,
+			expectedReceived:    []*testTx{{id: ids.ID{0}}, {id: ids.ID{1}}, {id: ids.ID{2}}},
+		},
+		{
+			name:             "duplicate gossip pushed once",
+			fanout:           1,
+			targetGossipSize: 1024,
+			peers:            []ids.NodeID{ids.EmptyNodeID},
+			gossip: [][]*testTx{
+				{{id: ids.ID{0}}, {id: ids.ID{0}}},
+				{{id: ids.ID{0}}, {id: ids.ID{1}}},
+			},
+			expectedNumMessages: 2,
+			expectedReceived:    []*testTx{{id: ids.ID{0}}, {id: ids.ID{1}}},
+		},
+		{
+			name:             "no peers to push to",
+			fanout:           1,
+			targetGossipSize: 1024,
+			gossip:           [][]*testTx{{{id: ids.ID{0}}}},
+		},
+		{
+			name:                "gossip pushed to the network's peers",
+			fanout:              0,
+			targetGossipSize:    1024,
+			gossip:              [][]*testTx{{{id: ids.ID{0}}}},
+			expectedNumMessages: 1,
+			expectedReceived:    []*testTx{{id: ids.ID{0}}},
+		},
+		{
+			name:                "oldest pending gossip dropped",
+			fanout:              1,
+			targetGossipSize:    1024,
+			maxPushedElements:   2,
+			peers:               []ids.NodeID{ids.EmptyNodeID},
+			gossip:              [][]*testTx{{{id: ids.ID{0}}, {id: ids.ID{1}}, {id: ids.ID{2}}}},
+			expectedNumMessages: 1,
+			expectedReceived:    []*testTx{{id: ids.ID{1}}, {id: ids.ID{2}}},
+		},
+		{
+			name:             "gossip that failed to be pushed is pushed again",
+			fanout:           1,
+			targetGossipSize: 1024,
+			peers:            []ids.NodeID{ids.EmptyNodeID},
+			gossip: [][]*testTx{
+				{{id: ids.ID{0}}},
+				{{id: ids.ID{0}}},
+			},
+			failedSends:         1,
+			expectedNumMessages: 1,
+			expectedReceived:    []*testTx{{id: ids.ID{0}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			receiveBloom, err := NewBloomFilter(1000, 0.01)
+			require.NoError(err)
+			receiveSet := testSet{
+				set:   set.Set[*testTx]{},
+				bloom: receiveBloom,
+			}
+			handler, err := NewPushHandler[testTx, *testTx](
+				p2p.NoOpHandler{},
+				receiveSet,
+				HandlerConfig{},
+				logging.NoLog{},
+				prometheus.NewRegistry(),
+			)
+			require.NoError(err)
+			receiveRouter := p2p.NewRouter(logging.NoLog{}, common.NewMockSender(ctrl), prometheus.NewRegistry(), "")
+			_, err = receiveRouter.RegisterAppProtocol(0x0, handler, nil)
+			require.NoError(err)
+
+			var (
+				numMessages int
+				failedSends int
+			)
+			pushSender := common.NewMockSender(ctrl)
+			pushSender.EXPECT().SendAppGossipSpecific(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, nodeIDs set.Set[ids.NodeID], gossip []byte) error {
+					require.Equal(tt.fanout, nodeIDs.Len())
+					if failedSends < tt.failedSends {
+						failedSends++
+						return errTestSend
+					}
+					numMessages++
+					return receiveRouter.AppGossip(ctx, ids.EmptyNodeID, gossip)
+				}).AnyTimes()
+			pushSender.EXPECT().SendAppGossip(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, gossip []byte) error {
+					require.Zero(tt.fanout)
+					numMessages++
+					return receiveRouter.AppGossip(ctx, ids.EmptyNodeID, gossip)
+				}).AnyTimes()
+			pushRouter := p2p.NewRouter(logging.NoLog{}, pushSender, prometheus.NewRegistry(), "")
+
+			peers := &p2p.Peers{}
+			for _, nodeID := range tt.peers {
+				require.NoError(peers.Connected(context.Background(), nodeID, nil))
+			}
+			client, err := pushRouter.RegisterAppProtocol(0x0, nil, peers)
+			require.NoError(err)
+
+			maxPushedElements := tt.maxPushedElements
+			if maxPushedElements == 0 {
+				maxPushedElements = 1000
+			}
+			gossiper, err := NewPushGossiper[*testTx](
+				PushGossiperConfig{
+					Fanout:                            tt.fanout,
+					TargetGossipSize:                  tt.targetGossipSize,
+					MaxPushedElements:                 maxPushedElements,
+					PushedFalsePositiveProbability:    0.01,
+					MaxPushedFalsePositiveProbability: 0.05,
+				},
+				logging.NoLog{},
+				client,
+				peers,
+				prometheus.NewRegistry(),
+			)
+			require.NoError(err)
+
+			for i, gossip := range tt.gossip {
+				gossiper.Add(gossip...)
+				err := gossiper.Gossip(context.Background())
+				if i < tt.failedSends {
+					require.ErrorIs(err, errTestSend)
+					continue
+				}
+				require.NoError(err)
+			}
+
+			require.Equal(tt.expectedNumMessages, numMessages)
+			require.ElementsMatch(tt.expectedReceived, receiveSet.set.List())
+		})
+	}
+}
+
+func TestPushHandlerSkipsInvalidGossip(t *testing.T) {
+	require := require.New(t)
+
+	bloom, err := NewBloomFilter(1000, 0.01)
+	require.NoError(err)
+	receiveSet := testSet{
+		set:   set.Set[*testTx]{},
+		bloom: bloom,
+	}
+	handler, err := NewPushHandler[testTx, *testTx](
+		p2p.NoOpHandler{},
+		receiveSet,
+		HandlerConfig{},
+		logging.NoLog{},
+		prometheus.NewRegistry(),
+	)
+	require.NoError(err)
+
+	valid := &testTx{id: ids.ID{1}}
+	validBytes, err := valid.Marshal()
+	require.NoError(err)
+	msgBytes, err := proto.Marshal(&sdk.PushGossip{
+		Gossip: [][]byte{{0x01}, validBytes},
+	})
+	require.NoError(err)
+
+	require.NoError(handler.AppGossip(context.Background(), ids.EmptyNodeID, msgBytes))
+	require.Equal([]*testTx{valid}, receiveSet.set.List())
+	require.Equal(float64(1), testutil.ToFloat64(handler.invalidN))
+	require.Equal(float64(2), testutil.ToFloat64(handler.receivedN))
+}
+
 func TestEvery(*testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	calls := 0
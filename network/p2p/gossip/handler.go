@@ -12,16 +12,20 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"go.uber.org/zap"
+
 	"google.golang.org/protobuf/proto"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p"
 	"github.com/ava-labs/avalanchego/proto/pb/sdk"
+	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
 var (
 	_ p2p.Handler = (*Handler[Gossipable])(nil)
+	_ p2p.Handler = (*PushHandler[testTx, *testTx])(nil)
 
 	ErrInvalidID = errors.New("invalid id")
 )
@@ -124,3 +128,92 @@ func (h Handler[T]) AppRequest(_ context.Context, _ ids.NodeID, _ time.Time, req
 
 	return proto.Marshal(response)
 }
+
+// NewPushHandler returns a handler that adds the gossip pushed by a
+// [PushGossiper] to [set]. All other messages are forwarded to [handler], so
+// that pushed and pulled gossip can be handled by the same protocol.
+func NewPushHandler[T any, U GossipableAny[T]](
+	handler p2p.Handler,
+	set Set[U],
+	config HandlerConfig,
+	log logging.Logger,
+	metrics prometheus.Registerer,
+) (*PushHandler[T, U], error) {
+	h := &PushHandler[T, U]{
+		Handler: handler,
+		set:     set,
+		log:     log,
+		receivedN: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "gossip_push_received_n",
+			Help:      "amount of pushed gossip received (n)",
+		}),
+		receivedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "gossip_push_received_bytes",
+			Help:      "amount of pushed gossip received (bytes)",
+		}),
+		invalidN: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "gossip_push_invalid_n",
+			Help:      "amount of pushed gossip that failed to unmarshal (n)",
+		}),
+	}
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		metrics.Register(h.receivedN),
+		metrics.Register(h.receivedBytes),
+		metrics.Register(h.invalidN),
+	)
+
+	return h, errs.Err
+}
+
+type PushHandler[T any, U GossipableAny[T]] struct {
+	p2p.Handler
+	set Set[U]
+	log logging.Logger
+
+	receivedN     prometheus.Counter
+	receivedBytes prometheus.Counter
+	invalidN      prometheus.Counter
+}
+
+// AppGossip adds the gossip pushed by a [PushGossiper] to the set. Gossip that
+// fails to unmarshal is skipped.
+func (h PushHandler[T, U]) AppGossip(_ context.Context, nodeID ids.NodeID, gossipBytes []byte) error {
+	msg := &sdk.PushGossip{}
+	if err := proto.Unmarshal(gossipBytes, msg); err != nil {
+		return err
+	}
+
+	receivedBytes := 0
+	for _, bytes := range msg.Gossip {
+		receivedBytes += len(bytes)
+
+		gossipable := U(new(T))
+		if err := gossipable.Unmarshal(bytes); err != nil {
+			h.log.Debug(
+				"failed to unmarshal pushed gossip",
+				zap.Stringer("nodeID", nodeID),
+				zap.Error(err),
+			)
+			h.invalidN.Inc()
+			continue
+		}
+
+		if err := h.set.Add(gossipable); err != nil {
+			h.log.Debug(
+				"failed to add pushed gossip to the known set",
+				zap.Stringer("nodeID", nodeID),
+				zap.Stringer("id", gossipable.GetID()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	h.receivedN.Add(float64(len(msg.Gossip)))
+	h.receivedBytes.Add(float64(receivedBytes))
+	return nil
+}
@@ -4,6 +4,8 @@
 package gossip
 
 import (
+	"errors"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/set"
 )
@@ -11,6 +13,8 @@ import (
 var (
 	_ Gossipable   = (*testTx)(nil)
 	_ Set[*testTx] = (*testSet)(nil)
+
+	errInvalidTestTx = errors.New("invalid test tx")
 )
 
 type testTx struct {
@@ -26,7 +30,9 @@ func (t *testTx) Marshal() ([]byte, error) {
 }
 
 func (t *testTx) Unmarshal(bytes []byte) error {
-	t.id = ids.ID{}
+	if len(bytes) != ids.IDLen {
+		return errInvalidTestTx
+	}
 	copy(t.id[:], bytes)
 	return nil
 }
@@ -0,0 +1,112 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package p2p
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+// NodeLatencies tracks how long nodes take to respond to requests.
+type NodeLatencies struct {
+	halflife       time.Duration
+	failurePenalty time.Duration
+
+	lock sync.Mutex
+	// latencies is the moving average of the latency of each node
+	latencies map[ids.NodeID]safemath.Averager
+	// recent is a ring buffer of the most recent response latencies, used to
+	// calculate latency percentiles.
+	recent     []time.Duration
+	recentNext int
+}
+
+// NewNodeLatencies returns a new NodeLatencies that averages the latency of
+// each node with [halflife], and calculates percentiles from the last
+// [windowSize] responses. Failed requests are counted as taking
+// [failurePenalty] towards the latency of a node.
+func NewNodeLatencies(halflife time.Duration, failurePenalty time.Duration, windowSize int) *NodeLatencies {
+	return &NodeLatencies{
+		halflife:       halflife,
+		failurePenalty: failurePenalty,
+		latencies:      make(map[ids.NodeID]safemath.Averager),
+		recent:         make([]time.Duration, 0, windowSize),
+	}
+}
+
+// Observe records that [nodeID] responded to a request after [latency].
+func (n *NodeLatencies) Observe(nodeID ids.NodeID, latency time.Duration) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.observe(nodeID, latency)
+
+	if len(n.recent) < cap(n.recent) {
+		n.recent = append(n.recent, latency)
+		return
+	}
+	if len(n.recent) == 0 {
+		return
+	}
+	n.recent[n.recentNext] = latency
+	n.recentNext = (n.recentNext + 1) % len(n.recent)
+}
+
+// ObserveFailure records that a request to [nodeID] failed.
+func (n *NodeLatencies) ObserveFailure(nodeID ids.NodeID) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.observe(nodeID, n.failurePenalty)
+}
+
+// Latency returns the average latency of [nodeID]. Returns false if no
+// requests have been made to [nodeID].
+func (n *NodeLatencies) Latency(nodeID ids.NodeID) (time.Duration, bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	averager, ok := n.latencies[nodeID]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(averager.Read()), true
+}
+
+// Percentile returns the latency that [percentile], in [0, 1], of the recent
+// responses were received within. Returns false if no responses have been
+// received.
+func (n *NodeLatencies) Percentile(percentile float64) (time.Duration, bool) {
+	n.lock.Lock()
+	recent := slices.Clone(n.recent)
+	n.lock.Unlock()
+
+	if len(recent) == 0 {
+		return 0, false
+	}
+
+	slices.Sort(recent)
+	index := int(math.Ceil(percentile*float64(len(recent)))) - 1
+	index = safemath.Max(index, 0)
+	index = safemath.Min(index, len(recent)-1)
+	return recent[index], true
+}
+
+// Invariant: Assumes [n.lock] is held.
+func (n *NodeLatencies) observe(nodeID ids.NodeID, latency time.Duration) {
+	now := time.Now()
+	averager, ok := n.latencies[nodeID]
+	if !ok {
+		n.latencies[nodeID] = safemath.NewAverager(float64(latency), n.halflife, now)
+		return
+	}
+	averager.Observe(float64(latency), now)
+}
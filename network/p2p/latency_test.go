@@ -0,0 +1,85 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestNodeLatenciesPercentile(t *testing.T) {
+	require := require.New(t)
+
+	latencies := NewNodeLatencies(time.Minute, time.Second, 4)
+	_, ok := latencies.Percentile(0.5)
+	require.False(ok)
+
+	nodeID := ids.GenerateTestNodeID()
+	for i := 1; i <= 4; i++ {
+		latencies.Observe(nodeID, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		percentile float64
+		expected   time.Duration
+	}{
+		{
+			percentile: 0,
+			expected:   time.Millisecond,
+		},
+		{
+			percentile: 0.5,
+			expected:   2 * time.Millisecond,
+		},
+		{
+			percentile: 0.9,
+			expected:   4 * time.Millisecond,
+		},
+		{
+			percentile: 1,
+			expected:   4 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		latency, ok := latencies.Percentile(tt.percentile)
+		require.True(ok)
+		require.Equal(tt.expected, latency)
+	}
+
+	// Only the most recent latencies are used
+	latencies.Observe(nodeID, 10*time.Millisecond)
+	latency, ok := latencies.Percentile(0)
+	require.True(ok)
+	require.Equal(2*time.Millisecond, latency)
+
+	// Failures don't affect percentiles
+	latencies.ObserveFailure(nodeID)
+	latency, ok = latencies.Percentile(1)
+	require.True(ok)
+	require.Equal(10*time.Millisecond, latency)
+}
+
+func TestNodeLatenciesLatency(t *testing.T) {
+	require := require.New(t)
+
+	latencies := NewNodeLatencies(time.Minute, time.Second, 4)
+
+	nodeID := ids.GenerateTestNodeID()
+	_, ok := latencies.Latency(nodeID)
+	require.False(ok)
+
+	latencies.Observe(nodeID, time.Millisecond)
+	latency, ok := latencies.Latency(nodeID)
+	require.True(ok)
+	require.Equal(time.Millisecond, latency)
+
+	latencies.ObserveFailure(nodeID)
+	latency, ok = latencies.Latency(nodeID)
+	require.True(ok)
+	require.Greater(latency, time.Millisecond)
+}
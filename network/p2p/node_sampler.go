@@ -5,13 +5,81 @@ package p2p
 
 import (
 	"context"
+	"math"
+
+	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanchego/ids"
 )
 
+var (
+	_ NodeSampler = (*ValidatorNodeSampler)(nil)
+	_ NodeSampler = (*LowestLatencyNodeSampler)(nil)
+	_ NodeSampler = (*StakeWeightedNodeSampler)(nil)
+)
+
 // NodeSampler samples nodes in network
 type NodeSampler interface {
 	// Sample returns at most [limit] nodes. This may return fewer nodes if
 	// fewer than [limit] are available.
 	Sample(ctx context.Context, limit int) []ids.NodeID
 }
+
+// ValidatorNodeSampler only samples the nodes of [NodeSampler] that are
+// validators.
+type ValidatorNodeSampler struct {
+	NodeSampler NodeSampler
+	Validators  ValidatorSet
+}
+
+func (v ValidatorNodeSampler) Sample(ctx context.Context, limit int) []ids.NodeID {
+	// The nodes are sampled uniformly, so they are already in a random order.
+	nodeIDs := v.NodeSampler.Sample(ctx, math.MaxInt)
+	validatorIDs := make([]ids.NodeID, 0, limit)
+	for _, nodeID := range nodeIDs {
+		if len(validatorIDs) >= limit {
+			break
+		}
+		if v.Validators.Has(ctx, nodeID) {
+			validatorIDs = append(validatorIDs, nodeID)
+		}
+	}
+	return validatorIDs
+}
+
+// LowestLatencyNodeSampler samples the nodes of [NodeSampler] that have
+// responded the fastest to recent requests. Nodes that haven't been requested
+// yet are sampled first, so that their latency is discovered.
+type LowestLatencyNodeSampler struct {
+	NodeSampler NodeSampler
+	Latencies   *NodeLatencies
+}
+
+func (l LowestLatencyNodeSampler) Sample(ctx context.Context, limit int) []ids.NodeID {
+	nodeIDs := l.NodeSampler.Sample(ctx, math.MaxInt)
+	latencies := make(map[ids.NodeID]float64, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		latency, _ := l.Latencies.Latency(nodeID)
+		latencies[nodeID] = float64(latency)
+	}
+
+	// The sort is stable so that nodes with the same latency remain in the
+	// random order they were sampled in.
+	slices.SortStableFunc(nodeIDs, func(a, b ids.NodeID) bool {
+		return latencies[a] < latencies[b]
+	})
+	if len(nodeIDs) > limit {
+		nodeIDs = nodeIDs[:limit]
+	}
+	return nodeIDs
+}
+
+// StakeWeightedNodeSampler samples the validators of [Validators] weighted by
+// their stake.
+type StakeWeightedNodeSampler struct {
+	Validators *Validators
+}
+
+func (s StakeWeightedNodeSampler) Sample(ctx context.Context, limit int) []ids.NodeID {
+	return s.Validators.sampleWeighted(ctx, limit)
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+func TestValidatorNodeSampler(t *testing.T) {
+	require := require.New(t)
+
+	nodeID1 := ids.GenerateTestNodeID()
+	nodeID2 := ids.GenerateTestNodeID()
+	nodeID3 := ids.GenerateTestNodeID()
+
+	peers := &Peers{}
+	for _, nodeID := range []ids.NodeID{nodeID1, nodeID2, nodeID3} {
+		require.NoError(peers.Connected(context.Background(), nodeID, nil))
+	}
+
+	sampler := ValidatorNodeSampler{
+		NodeSampler: peers,
+		Validators: testValidatorSet{
+			validators: set.Of(nodeID1, nodeID2, ids.GenerateTestNodeID()),
+		},
+	}
+
+	// Only connected validators are sampled
+	require.ElementsMatch([]ids.NodeID{nodeID1, nodeID2}, sampler.Sample(context.Background(), 3))
+	require.Len(sampler.Sample(context.Background(), 1), 1)
+	require.Empty(sampler.Sample(context.Background(), 0))
+}
+
+func TestLowestLatencyNodeSampler(t *testing.T) {
+	require := require.New(t)
+
+	nodeID1 := ids.GenerateTestNodeID()
+	nodeID2 := ids.GenerateTestNodeID()
+	nodeID3 := ids.GenerateTestNodeID()
+
+	latencies := NewNodeLatencies(time.Minute, time.Second, 10)
+	latencies.Observe(nodeID1, 2*time.Millisecond)
+	latencies.Observe(nodeID2, time.Millisecond)
+
+	sampler := LowestLatencyNodeSampler{
+		NodeSampler: testNodeSampler{
+			nodeIDs: []ids.NodeID{nodeID1, nodeID2},
+		},
+		Latencies: latencies,
+	}
+	require.Equal([]ids.NodeID{nodeID2, nodeID1}, sampler.Sample(context.Background(), 2))
+	require.Equal([]ids.NodeID{nodeID2}, sampler.Sample(context.Background(), 1))
+
+	// Nodes that haven't been requested yet are sampled first
+	sampler.NodeSampler = testNodeSampler{
+		nodeIDs: []ids.NodeID{nodeID1, nodeID2, nodeID3},
+	}
+	require.Equal([]ids.NodeID{nodeID3, nodeID2}, sampler.Sample(context.Background(), 2))
+
+	// Failed requests are penalized
+	latencies.ObserveFailure(nodeID2)
+	latencies.Observe(nodeID3, 3*time.Millisecond)
+	require.Equal([]ids.NodeID{nodeID1, nodeID3, nodeID2}, sampler.Sample(context.Background(), 3))
+}
+
+func TestStakeWeightedNodeSampler(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	nodeID1 := ids.GenerateTestNodeID()
+	nodeID2 := ids.GenerateTestNodeID()
+	nodeID3 := ids.GenerateTestNodeID()
+
+	subnetID := ids.GenerateTestID()
+	mockValidators := validators.NewMockState(ctrl)
+	mockValidators.EXPECT().GetCurrentHeight(gomock.Any()).Return(uint64(1), nil)
+	mockValidators.EXPECT().GetValidatorSet(gomock.Any(), uint64(1), subnetID).Return(
+		map[ids.NodeID]*validators.GetValidatorOutput{
+			nodeID1: {
+				NodeID: nodeID1,
+				Weight: 1,
+			},
+			nodeID2: {
+				NodeID: nodeID2,
+				Weight: 2,
+			},
+			nodeID3: {
+				NodeID: nodeID3,
+				Weight: 0,
+			},
+		},
+		nil,
+	)
+
+	sampler := StakeWeightedNodeSampler{
+		Validators: NewValidators(logging.NoLog{}, subnetID, mockValidators, time.Hour),
+	}
+
+	// Validators without any weight are never sampled
+	require.ElementsMatch([]ids.NodeID{nodeID1, nodeID2}, sampler.Sample(context.Background(), 3))
+	require.Len(sampler.Sample(context.Background(), 1), 1)
+	require.Empty(sampler.Sample(context.Background(), 0))
+}
@@ -0,0 +1,241 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+var (
+	errInvalidMaxAttempts     = errors.New("max attempts must be positive")
+	errInvalidRetryDelay      = errors.New("retry delay must be non-negative")
+	errInvalidMaxRetryDelay   = errors.New("max retry delay must be at least the initial retry delay")
+	errInvalidHedgePercentile = errors.New("hedge percentile must be in [0, 1]")
+	errNoLatencies            = errors.New("hedging requires node latencies")
+)
+
+type RequesterConfig struct {
+	// MaxAttempts is the maximum number of nodes a request is sent to,
+	// including hedged requests.
+	MaxAttempts int
+	// InitialRetryDelay is how long to wait before retrying a failed request.
+	// The delay doubles after every failed attempt, up to [MaxRetryDelay].
+	InitialRetryDelay time.Duration
+	MaxRetryDelay     time.Duration
+	// HedgePercentile is the percentile, in [0, 1], of recent response
+	// latencies after which a request that hasn't been responded to is also
+	// sent to another node. If 0, requests aren't hedged.
+	HedgePercentile float64
+}
+
+// NewRequester returns a Requester that sends requests with [client] to nodes
+// sampled from [nodeSampler]. If [latencies] is non-nil, the latency of every
+// response is recorded in it.
+func NewRequester(
+	client *Client,
+	nodeSampler NodeSampler,
+	latencies *NodeLatencies,
+	config RequesterConfig,
+) (*Requester, error) {
+	switch {
+	case config.MaxAttempts <= 0:
+		return nil, errInvalidMaxAttempts
+	case config.InitialRetryDelay < 0:
+		return nil, errInvalidRetryDelay
+	case config.MaxRetryDelay < config.InitialRetryDelay:
+		return nil, errInvalidMaxRetryDelay
+	case config.HedgePercentile < 0 || config.HedgePercentile > 1:
+		return nil, errInvalidHedgePercentile
+	case config.HedgePercentile > 0 && latencies == nil:
+		return nil, errNoLatencies
+	}
+
+	return &Requester{
+		client:      client,
+		nodeSampler: nodeSampler,
+		latencies:   latencies,
+		config:      config,
+	}, nil
+}
+
+// Requester sends requests that are retried on other nodes when they fail,
+// and hedged to other nodes when they are slow to be responded to.
+type Requester struct {
+	client      *Client
+	nodeSampler NodeSampler
+	latencies   *NodeLatencies
+	config      RequesterConfig
+}
+
+type requesterResponse struct {
+	nodeID        ids.NodeID
+	responseBytes []byte
+	err           error
+}
+
+// Request sends [requestBytes] to a sampled node and returns the first
+// successful response, along with the node that sent it.
+//
+// If the request fails, it is retried on another node after a backoff, until
+// [MaxAttempts] nodes have been requested. If the request isn't responded to
+// within [HedgePercentile] of recent response latencies, it is also sent to
+// another node.
+func (r *Requester) Request(
+	ctx context.Context,
+	requestBytes []byte,
+) (ids.NodeID, []byte, error) {
+	// Every request sent gets exactly one response, so the callbacks never
+	// block, even after Request returns.
+	responses := make(chan requesterResponse, r.config.MaxAttempts)
+
+	var (
+		requested   = set.Set[ids.NodeID]{}
+		outstanding = 0
+		hedged      = false
+		retryDelay  = r.config.InitialRetryDelay
+		lastErr     error
+	)
+	for {
+		if outstanding == 0 {
+			if requested.Len() >= r.config.MaxAttempts {
+				return ids.EmptyNodeID, nil, fmt.Errorf(
+					"%w after %d attempts: %w",
+					ErrAppRequestFailed,
+					requested.Len(),
+					lastErr,
+				)
+			}
+
+			sent, err := r.send(ctx, requested, requestBytes, responses)
+			if err != nil {
+				return ids.EmptyNodeID, nil, err
+			}
+			if !sent {
+				if lastErr != nil {
+					return ids.EmptyNodeID, nil, fmt.Errorf("%w: %w", ErrNoPeers, lastErr)
+				}
+				return ids.EmptyNodeID, nil, ErrNoPeers
+			}
+			outstanding++
+			hedged = false
+		}
+
+		var (
+			hedgeTimer *time.Timer
+			hedge      <-chan time.Time
+		)
+		if !hedged && requested.Len() < r.config.MaxAttempts {
+			if hedgeDelay, ok := r.hedgeDelay(); ok {
+				hedgeTimer = time.NewTimer(hedgeDelay)
+				hedge = hedgeTimer.C
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(hedgeTimer)
+			return ids.EmptyNodeID, nil, ctx.Err()
+		case <-hedge:
+			// If there aren't any other nodes to hedge the request to, we keep
+			// waiting for the outstanding request.
+			hedged = true
+			sent, err := r.send(ctx, requested, requestBytes, responses)
+			if err != nil {
+				return ids.EmptyNodeID, nil, err
+			}
+			if sent {
+				outstanding++
+			}
+		case response := <-responses:
+			stopTimer(hedgeTimer)
+			outstanding--
+			if response.err == nil {
+				return response.nodeID, response.responseBytes, nil
+			}
+			lastErr = response.err
+
+			if outstanding > 0 || requested.Len() >= r.config.MaxAttempts {
+				continue
+			}
+
+			retryTimer := time.NewTimer(retryDelay)
+			select {
+			case <-ctx.Done():
+				retryTimer.Stop()
+				return ids.EmptyNodeID, nil, ctx.Err()
+			case <-retryTimer.C:
+			}
+			retryDelay = math.Min(2*retryDelay, r.config.MaxRetryDelay)
+		}
+	}
+}
+
+// send sends [requestBytes] to a node that isn't in [requested]. Returns false
+// if there aren't any such nodes.
+func (r *Requester) send(
+	ctx context.Context,
+	requested set.Set[ids.NodeID],
+	requestBytes []byte,
+	responses chan<- requesterResponse,
+) (bool, error) {
+	// Sampling one more node than has already been requested guarantees that
+	// a node that hasn't been requested is sampled, if one is available.
+	var (
+		nodeID ids.NodeID
+		found  bool
+	)
+	for _, sampledID := range r.nodeSampler.Sample(ctx, requested.Len()+1) {
+		if !requested.Contains(sampledID) {
+			nodeID = sampledID
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false, nil
+	}
+
+	start := time.Now()
+	onResponse := func(_ context.Context, nodeID ids.NodeID, responseBytes []byte, err error) {
+		if r.latencies != nil {
+			if err != nil {
+				r.latencies.ObserveFailure(nodeID)
+			} else {
+				r.latencies.Observe(nodeID, time.Since(start))
+			}
+		}
+		responses <- requesterResponse{
+			nodeID:        nodeID,
+			responseBytes: responseBytes,
+			err:           err,
+		}
+	}
+	if err := r.client.AppRequest(ctx, set.Of(nodeID), requestBytes, onResponse); err != nil {
+		return false, err
+	}
+	requested.Add(nodeID)
+	return true, nil
+}
+
+// hedgeDelay returns how long to wait for a response before hedging a request.
+// Returns false if requests shouldn't be hedged.
+func (r *Requester) hedgeDelay() (time.Duration, bool) {
+	if r.config.HedgePercentile == 0 {
+		return 0, false
+	}
+	return r.latencies.Percentile(r.config.HedgePercentile)
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+// testNodeSampler samples nodes in a fixed order
+type testNodeSampler struct {
+	nodeIDs []ids.NodeID
+}
+
+func (t testNodeSampler) Sample(_ context.Context, limit int) []ids.NodeID {
+	if len(t.nodeIDs) > limit {
+		return t.nodeIDs[:limit]
+	}
+	return t.nodeIDs
+}
+
+func TestNewRequesterInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		latencies   *NodeLatencies
+		config      RequesterConfig
+		expectedErr error
+	}{
+		{
+			name:        "no attempts",
+			config:      RequesterConfig{},
+			expectedErr: errInvalidMaxAttempts,
+		},
+		{
+			name: "negative retry delay",
+			config: RequesterConfig{
+				MaxAttempts:       1,
+				InitialRetryDelay: -time.Second,
+			},
+			expectedErr: errInvalidRetryDelay,
+		},
+		{
+			name: "max retry delay less than initial retry delay",
+			config: RequesterConfig{
+				MaxAttempts:       1,
+				InitialRetryDelay: time.Second,
+				MaxRetryDelay:     time.Millisecond,
+			},
+			expectedErr: errInvalidMaxRetryDelay,
+		},
+		{
+			name: "negative hedge percentile",
+			config: RequesterConfig{
+				MaxAttempts:     1,
+				HedgePercentile: -0.5,
+			},
+			expectedErr: errInvalidHedgePercentile,
+		},
+		{
+			name: "hedge percentile too large",
+			config: RequesterConfig{
+				MaxAttempts:     1,
+				HedgePercentile: 1.5,
+			},
+			expectedErr: errInvalidHedgePercentile,
+		},
+		{
+			name: "hedging without latencies",
+			config: RequesterConfig{
+				MaxAttempts:     1,
+				HedgePercentile: 0.5,
+			},
+			expectedErr: errNoLatencies,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRequester(nil, nil, tt.latencies, tt.config)
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestRequesterRequest(t *testing.T) {
+	nodeID1 := ids.GenerateTestNodeID()
+	nodeID2 := ids.GenerateTestNodeID()
+	nodeID3 := ids.GenerateTestNodeID()
+
+	const (
+		respond = iota
+		fail
+		ignore
+	)
+
+	tests := []struct {
+		name              string
+		nodeIDs           []ids.NodeID
+		behavior          map[ids.NodeID]int
+		config            RequesterConfig
+		latencies         []time.Duration // latencies observed before requesting
+		expectedNodeID    ids.NodeID
+		expectedRequested []ids.NodeID
+		expectedErr       error
+	}{
+		{
+			name:    "first node responds",
+			nodeIDs: []ids.NodeID{nodeID1, nodeID2},
+			behavior: map[ids.NodeID]int{
+				nodeID1: respond,
+			},
+			config: RequesterConfig{
+				MaxAttempts: 2,
+			},
+			expectedNodeID:    nodeID1,
+			expectedRequested: []ids.NodeID{nodeID1},
+		},
+		{
+			name:    "retried after failure",
+			nodeIDs: []ids.NodeID{nodeID1, nodeID2},
+			behavior: map[ids.NodeID]int{
+				nodeID1: fail,
+				nodeID2: respond,
+			},
+			config: RequesterConfig{
+				MaxAttempts:       2,
+				InitialRetryDelay: time.Millisecond,
+				MaxRetryDelay:     time.Millisecond,
+			},
+			expectedNodeID:    nodeID2,
+			expectedRequested: []ids.NodeID{nodeID1, nodeID2},
+		},
+		{
+			name:    "attempts exhausted",
+			nodeIDs: []ids.NodeID{nodeID1, nodeID2, nodeID3},
+			behavior: map[ids.NodeID]int{
+				nodeID1: fail,
+				nodeID2: fail,
+				nodeID3: respond,
+			},
+			config: RequesterConfig{
+				MaxAttempts:       2,
+				InitialRetryDelay: time.Millisecond,
+				MaxRetryDelay:     time.Millisecond,
+			},
+			expectedRequested: []ids.NodeID{nodeID1, nodeID2},
+			expectedErr:       ErrAppRequestFailed,
+		},
+		{
+			name:    "nodes exhausted",
+			nodeIDs: []ids.NodeID{nodeID1},
+			behavior: map[ids.NodeID]int{
+				nodeID1: fail,
+			},
+			config: RequesterConfig{
+				MaxAttempts:       2,
+				InitialRetryDelay: time.Millisecond,
+				MaxRetryDelay:     time.Millisecond,
+			},
+			expectedRequested: []ids.NodeID{nodeID1},
+			expectedErr:       ErrNoPeers,
+		},
+		{
+			name: "no nodes",
+			config: RequesterConfig{
+				MaxAttempts: 1,
+			},
+			expectedErr: ErrNoPeers,
+		},
+		{
+			name:    "hedged after latency percentile",
+			nodeIDs: []ids.NodeID{nodeID1, nodeID2},
+			behavior: map[ids.NodeID]int{
+				nodeID1: ignore,
+				nodeID2: respond,
+			},
+			config: RequesterConfig{
+				MaxAttempts:     2,
+				HedgePercentile: 0.9,
+			},
+			latencies:         []time.Duration{time.Millisecond},
+			expectedNodeID:    nodeID2,
+			expectedRequested: []ids.NodeID{nodeID1, nodeID2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			sender := common.NewMockSender(ctrl)
+			router := NewRouter(logging.NoLog{}, sender, prometheus.NewRegistry(), "")
+
+			requested := make(chan ids.NodeID, len(tt.nodeIDs))
+			sender.EXPECT().SendAppRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Do(func(ctx context.Context, nodeIDs set.Set[ids.NodeID], requestID uint32, _ []byte) {
+					for nodeID := range nodeIDs {
+						requested <- nodeID
+
+						nodeID := nodeID
+						switch tt.behavior[nodeID] {
+						case respond:
+							go func() {
+								require.NoError(router.AppResponse(ctx, nodeID, requestID, nodeID[:]))
+							}()
+						case fail:
+							go func() {
+								require.NoError(router.AppRequestFailed(ctx, nodeID, requestID))
+							}()
+						}
+					}
+				}).AnyTimes()
+
+			client, err := router.RegisterAppProtocol(0x0, nil, nil)
+			require.NoError(err)
+
+			latencies := NewNodeLatencies(time.Minute, time.Second, 10)
+			for _, latency := range tt.latencies {
+				latencies.Observe(ids.GenerateTestNodeID(), latency)
+			}
+			requester, err := NewRequester(
+				client,
+				testNodeSampler{nodeIDs: tt.nodeIDs},
+				latencies,
+				tt.config,
+			)
+			require.NoError(err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			nodeID, response, err := requester.Request(ctx, []byte("request"))
+			require.ErrorIs(err, tt.expectedErr)
+			require.Equal(tt.expectedNodeID, nodeID)
+			if tt.expectedErr == nil {
+				require.Equal(nodeID[:], response)
+
+				_, ok := latencies.Latency(nodeID)
+				require.True(ok)
+			}
+
+			close(requested)
+			var requestedIDs []ids.NodeID
+			for nodeID := range requested {
+				requestedIDs = append(requestedIDs, nodeID)
+			}
+			require.Equal(tt.expectedRequested, requestedIDs)
+		})
+	}
+}
+
+func TestRequesterRequestCanceled(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	nodeID := ids.GenerateTestNodeID()
+	sender := common.NewMockSender(ctrl)
+	sender.EXPECT().SendAppRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+	router := NewRouter(logging.NoLog{}, sender, prometheus.NewRegistry(), "")
+	client, err := router.RegisterAppProtocol(0x0, nil, nil)
+	require.NoError(err)
+
+	requester, err := NewRequester(
+		client,
+		testNodeSampler{nodeIDs: []ids.NodeID{nodeID}},
+		nil,
+		RequesterConfig{
+			MaxAttempts: 1,
+		},
+	)
+	require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = requester.Request(ctx, []byte("request"))
+	require.ErrorIs(err, context.DeadlineExceeded)
+}
@@ -5,14 +5,18 @@ package p2p
 
 import (
 	"context"
+	"math/rand"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/utils/set"
 )
 
@@ -40,8 +44,12 @@ type Validators struct {
 	subnetID   ids.ID
 	validators validators.State
 
-	lock                     sync.Mutex
-	validatorIDs             set.SampleableSet[ids.NodeID]
+	lock         sync.Mutex
+	validatorIDs set.SampleableSet[ids.NodeID]
+	// weightedValidatorIDs are the validators with a non-zero weight, and
+	// validatorWeights are their corresponding weights.
+	weightedValidatorIDs     []ids.NodeID
+	validatorWeights         []uint64
 	lastUpdated              time.Time
 	maxValidatorSetStaleness time.Duration
 }
@@ -52,6 +60,8 @@ func (v *Validators) refresh(ctx context.Context) {
 	}
 
 	v.validatorIDs.Clear()
+	v.weightedValidatorIDs = nil
+	v.validatorWeights = nil
 
 	height, err := v.validators.GetCurrentHeight(ctx)
 	if err != nil {
@@ -64,8 +74,14 @@ func (v *Validators) refresh(ctx context.Context) {
 		return
 	}
 
-	for nodeID := range validatorSet {
+	for nodeID, validator := range validatorSet {
 		v.validatorIDs.Add(nodeID)
+
+		if validator == nil || validator.Weight == 0 {
+			continue
+		}
+		v.weightedValidatorIDs = append(v.weightedValidatorIDs, nodeID)
+		v.validatorWeights = append(v.validatorWeights, validator.Weight)
 	}
 
 	v.lastUpdated = time.Now()
@@ -80,6 +96,35 @@ func (v *Validators) Sample(ctx context.Context, limit int) []ids.NodeID {
 	return v.validatorIDs.Sample(limit)
 }
 
+// sampleWeighted samples up to [limit] validators weighted by their stake.
+func (v *Validators) sampleWeighted(ctx context.Context, limit int) []ids.NodeID {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.refresh(ctx)
+
+	numToSample := math.Min(limit, len(v.weightedValidatorIDs))
+	if numToSample <= 0 {
+		return nil
+	}
+
+	// Each validator is assigned an exponentially distributed key with a rate
+	// of its weight. Taking the validators with the smallest keys samples them
+	// weighted by their stake, without sampling any validator more than once.
+	keys := make(map[ids.NodeID]float64, len(v.weightedValidatorIDs))
+	for i, nodeID := range v.weightedValidatorIDs {
+		// This doesn't require cryptographically secure random number
+		// generation.
+		keys[nodeID] = rand.ExpFloat64() / float64(v.validatorWeights[i]) // #nosec G404
+	}
+
+	nodeIDs := slices.Clone(v.weightedValidatorIDs)
+	slices.SortFunc(nodeIDs, func(a, b ids.NodeID) bool {
+		return keys[a] < keys[b]
+	})
+	return nodeIDs[:numToSample]
+}
+
 func (v *Validators) Has(ctx context.Context, nodeID ids.NodeID) bool {
 	v.lock.Lock()
 	defer v.lock.Unlock()
@@ -52,4 +52,16 @@ type Config struct {
 
 	// Signs my IP so I can send my signed IP address in the Version message
 	IPSigner *IPSigner
+
+	// QUICEnabled is true if this node accepts QUIC connections, in which case
+	// the port they are accepted on is sent in the Version message.
+	QUICEnabled bool
+
+	// StreamInboundMsgThrottlers throttle the messages read from the streams
+	// other than [ConsensusStream] of peers with multiple streams, indexed by
+	// stream starting after [ConsensusStream]. Messages read from
+	// [ConsensusStream] are throttled by [InboundMsgThrottler]. Each stream is
+	// throttled separately, so that a throttled stream can't delay the
+	// messages read from the others.
+	StreamInboundMsgThrottlers []throttling.InboundMsgThrottler
 }
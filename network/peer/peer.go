@@ -18,6 +18,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/network/throttling"
 	"github.com/ava-labs/avalanchego/proto/pb/p2p"
 	"github.com/ava-labs/avalanchego/staking"
 	"github.com/ava-labs/avalanchego/utils"
@@ -68,6 +69,11 @@ type Peer interface {
 	// handshake. It should only be called after [Ready] returns true.
 	IP() *SignedIP
 
+	// QUICPort returns the port this peer claimed to accept QUIC connections
+	// on during the handshake, or 0 if it doesn't. It should only be called
+	// after [Ready] returns true.
+	QUICPort() uint16
+
 	// Version returns the claimed node version this peer is running. It should
 	// only be called after [Ready] returns true.
 	Version() *version.Application
@@ -109,6 +115,9 @@ type peer struct {
 
 	// the connection object that is used to read/write messages from
 	conn net.Conn
+	// streams of [conn] that messages are read from and written to. If [conn]
+	// doesn't support multiple streams, this only contains [conn].
+	streams []net.Conn
 
 	// [cert] is this peer's certificate, specifically the leaf of the
 	// certificate chain they provided.
@@ -117,17 +126,24 @@ type peer struct {
 	// node ID of this peer.
 	id ids.NodeID
 
-	// queue of messages to send to this peer.
-	messageQueue MessageQueue
+	// queues of messages to send to this peer, one per stream.
+	messageQueues []MessageQueue
 
 	// ip is the claimed IP the peer gave us in the Version message.
 	ip *SignedIP
+	// quicPort is the port the peer claimed to accept QUIC connections on in
+	// the Version message, or 0 if it doesn't.
+	quicPort uint16
 	// version is the claimed version the peer is running that we received in
 	// the Version message.
 	version *version.Application
 	// trackedSubnets is the subset of subnetIDs the peer sent us in the Version
 	// message that we are also tracking.
 	trackedSubnets set.Set[ids.ID]
+	// compression describes the messages the peer is able to decompress.
+	// Until the peer's Version message is received, the peer is assumed to
+	// only support the compression types of peers that don't advertise them.
+	compression utils.Atomic[message.PeerCompression]
 
 	observedUptimesLock sync.RWMutex
 	// [observedUptimesLock] must be held while accessing [observedUptime]
@@ -179,25 +195,76 @@ func Start(
 	cert *staking.Certificate,
 	id ids.NodeID,
 	messageQueue MessageQueue,
+) Peer {
+	return start(
+		config,
+		conn,
+		[]net.Conn{conn},
+		cert,
+		id,
+		[]MessageQueue{messageQueue},
+	)
+}
+
+// StartStreams starts a new peer instance that sends each class of messages
+// over its own stream of [conn]. [messageQueues] must contain a queue for each
+// of the [NumStreams] streams, and [config.StreamInboundMsgThrottlers] must
+// contain a throttler for each stream other than [ConsensusStream].
+//
+// Invariant: There must only be one peer running at a time with a reference to
+// the same [config.InboundMsgThrottler].
+func StartStreams(
+	config *Config,
+	conn StreamConn,
+	cert *staking.Certificate,
+	id ids.NodeID,
+	messageQueues []MessageQueue,
+) Peer {
+	return start(
+		config,
+		conn,
+		conn.Streams(),
+		cert,
+		id,
+		messageQueues,
+	)
+}
+
+func start(
+	config *Config,
+	conn net.Conn,
+	streams []net.Conn,
+	cert *staking.Certificate,
+	id ids.NodeID,
+	messageQueues []MessageQueue,
 ) Peer {
 	onClosingCtx, onClosingCtxCancel := context.WithCancel(context.Background())
 	p := &peer{
 		Config:             config,
 		conn:               conn,
+		streams:            streams,
 		cert:               cert,
 		id:                 id,
-		messageQueue:       messageQueue,
+		messageQueues:      messageQueues,
 		onFinishHandshake:  make(chan struct{}),
-		numExecuting:       3,
+		numExecuting:       int64(1 + 2*len(streams)),
 		onClosingCtx:       onClosingCtx,
 		onClosingCtxCancel: onClosingCtxCancel,
 		onClosed:           make(chan struct{}),
 		observedUptimes:    make(map[ids.ID]uint32),
 		peerListChan:       make(chan struct{}, 1),
 	}
+	p.compression.Set(message.NewPeerCompression(nil))
+
+	// Track this node with the inbound message throttlers.
+	for i := range streams {
+		p.inboundMsgThrottler(i).AddNode(p.id)
+	}
 
-	go p.readMessages()
-	go p.writeMessages()
+	for i, stream := range streams {
+		go p.readMessages(i, stream)
+		go p.writeMessages(i, stream)
+	}
 	go p.sendNetworkMessages()
 
 	return p
@@ -279,6 +346,10 @@ func (p *peer) IP() *SignedIP {
 	return p.ip
 }
 
+func (p *peer) QUICPort() uint16 {
+	return p.quicPort
+}
+
 func (p *peer) Version() *version.Application {
 	return p.version
 }
@@ -296,7 +367,16 @@ func (p *peer) ObservedUptime(subnetID ids.ID) (uint32, bool) {
 }
 
 func (p *peer) Send(ctx context.Context, msg message.OutboundMessage) bool {
-	return p.messageQueue.Push(ctx, msg)
+	return p.messageQueues[p.streamOf(msg.Op())].Push(ctx, msg)
+}
+
+// streamOf returns the index of the stream that messages of type [op] are sent
+// over.
+func (p *peer) streamOf(op message.Op) int {
+	if len(p.streams) == 1 {
+		return 0
+	}
+	return StreamOf(op)
 }
 
 func (p *peer) StartSendPeerList() {
@@ -315,7 +395,9 @@ func (p *peer) StartClose() {
 			)
 		}
 
-		p.messageQueue.Close()
+		for _, messageQueue := range p.messageQueues {
+			messageQueue.Close()
+		}
 		p.onClosingCtxCancel()
 	})
 }
@@ -345,36 +427,29 @@ func (p *peer) close() {
 		return
 	}
 
+	for i := range p.streams {
+		p.inboundMsgThrottler(i).RemoveNode(p.id)
+	}
 	p.Network.Disconnected(p.id)
 	close(p.onClosed)
 }
 
-// Read and handle messages from this peer.
+// Read and handle messages from this peer over [conn], which is the stream with
+// index [stream].
 // When this method returns, the connection is closed.
-func (p *peer) readMessages() {
-	// Track this node with the inbound message throttler.
-	p.InboundMsgThrottler.AddNode(p.id)
+func (p *peer) readMessages(stream int, conn net.Conn) {
 	defer func() {
-		p.InboundMsgThrottler.RemoveNode(p.id)
 		p.StartClose()
 		p.close()
 	}()
 
 	// Continuously read and handle messages from this peer.
-	reader := bufio.NewReaderSize(p.conn, p.Config.ReadBufferSize)
+	reader := bufio.NewReaderSize(conn, p.Config.ReadBufferSize)
 	msgLenBytes := make([]byte, wrappers.IntLen)
+	inboundMsgThrottler := p.inboundMsgThrottler(stream)
 	for {
-		// Time out and close connection if we can't read the message length
-		if err := p.conn.SetReadDeadline(p.nextTimeout()); err != nil {
-			p.Log.Verbo("error setting the connection read timeout",
-				zap.Stringer("nodeID", p.id),
-				zap.Error(err),
-			)
-			return
-		}
-
 		// Read the message length
-		if _, err := io.ReadFull(reader, msgLenBytes); err != nil {
+		if err := p.readMsgLenBytes(stream, conn, reader, msgLenBytes); err != nil {
 			p.Log.Verbo("error reading message",
 				zap.Stringer("nodeID", p.id),
 				zap.Error(err),
@@ -400,12 +475,13 @@ func (p *peer) readMessages() {
 		// throttler metrics to verify that there is no leak.
 		//
 		// Invariant: There must only be one call to Acquire at any given time
-		// with the same nodeID. In this package, only this goroutine ever
-		// performs Acquire. Additionally, we ensure that this goroutine has
-		// exited before calling [Network.Disconnected] to guarantee that there
-		// can't be multiple instances of this goroutine running over different
-		// peer instances.
-		onFinishedHandling := p.InboundMsgThrottler.Acquire(
+		// with the same nodeID. In this package, each stream has its own
+		// throttler, and only the goroutine reading a stream ever performs
+		// Acquire on its throttler. Additionally, we ensure that this goroutine
+		// has exited before calling [Network.Disconnected] to guarantee that
+		// there can't be multiple instances of this goroutine running over
+		// different peer instances.
+		onFinishedHandling := inboundMsgThrottler.Acquire(
 			p.onClosingCtx,
 			uint64(msgLen),
 			p.id,
@@ -418,7 +494,7 @@ func (p *peer) readMessages() {
 		}
 
 		// Time out and close connection if we can't read message
-		if err := p.conn.SetReadDeadline(p.nextTimeout()); err != nil {
+		if err := conn.SetReadDeadline(p.nextTimeout()); err != nil {
 			p.Log.Verbo("error setting the connection read timeout",
 				zap.Stringer("nodeID", p.id),
 				zap.Error(err),
@@ -468,6 +544,21 @@ func (p *peer) readMessages() {
 			continue
 		}
 
+		// Messages must be sent over the stream of their class, which also
+		// ensures that the handshake messages are handled in order.
+		if op := msg.Op(); p.streamOf(op) != stream {
+			p.Log.Debug("dropping message",
+				zap.String("reason", "unexpected stream"),
+				zap.Stringer("nodeID", p.id),
+				zap.Stringer("messageOp", op),
+				zap.Int("stream", stream),
+			)
+
+			msg.OnFinishedHandling()
+			p.ResourceTracker.StopProcessing(p.id, p.Clock.Time())
+			continue
+		}
+
 		now := p.Clock.Time()
 		p.storeLastReceived(now)
 		p.Metrics.Received(msg, msgLen)
@@ -479,48 +570,61 @@ func (p *peer) readMessages() {
 	}
 }
 
-func (p *peer) writeMessages() {
+// Write the messages queued for this peer over [conn], which is the stream with
+// index [stream].
+func (p *peer) writeMessages(stream int, conn net.Conn) {
 	defer func() {
 		p.StartClose()
 		p.close()
 	}()
 
-	writer := bufio.NewWriterSize(p.conn, p.Config.WriteBufferSize)
+	writer := bufio.NewWriterSize(conn, p.Config.WriteBufferSize)
+	messageQueue := p.messageQueues[stream]
 
 	// Make sure that the version is the first message sent
-	mySignedIP, err := p.IPSigner.GetSignedIP()
-	if err != nil {
-		p.Log.Error("failed to get signed IP",
-			zap.Stringer("nodeID", p.id),
-			zap.Error(err),
-		)
-		return
-	}
+	if stream == ConsensusStream {
+		mySignedIP, err := p.IPSigner.GetSignedIP()
+		if err != nil {
+			p.Log.Error("failed to get signed IP",
+				zap.Stringer("nodeID", p.id),
+				zap.Error(err),
+			)
+			return
+		}
 
-	msg, err := p.MessageCreator.Version(
-		p.NetworkID,
-		p.Clock.Unix(),
-		mySignedIP.IPPort,
-		p.VersionCompatibility.Version().String(),
-		mySignedIP.Timestamp,
-		mySignedIP.Signature,
-		p.MySubnets.List(),
-	)
-	if err != nil {
-		p.Log.Error("failed to create message",
-			zap.Stringer("messageOp", message.VersionOp),
-			zap.Stringer("nodeID", p.id),
-			zap.Error(err),
+		// QUIC connections are accepted on the UDP port with the same number
+		// as the staking port.
+		var quicPort uint16
+		if p.QUICEnabled {
+			quicPort = mySignedIP.IPPort.Port
+		}
+
+		msg, err := p.MessageCreator.Version(
+			p.NetworkID,
+			p.Clock.Unix(),
+			mySignedIP.IPPort,
+			p.VersionCompatibility.Version().String(),
+			mySignedIP.Timestamp,
+			mySignedIP.Signature,
+			p.MySubnets.List(),
+			quicPort,
 		)
-		return
-	}
+		if err != nil {
+			p.Log.Error("failed to create message",
+				zap.Stringer("messageOp", message.VersionOp),
+				zap.Stringer("nodeID", p.id),
+				zap.Error(err),
+			)
+			return
+		}
 
-	p.writeMessage(writer, msg)
+		p.writeMessage(conn, writer, msg)
+	}
 
 	for {
-		msg, ok := p.messageQueue.PopNow()
+		msg, ok := messageQueue.PopNow()
 		if ok {
-			p.writeMessage(writer, msg)
+			p.writeMessage(conn, writer, msg)
 			continue
 		}
 
@@ -534,24 +638,37 @@ func (p *peer) writeMessages() {
 			return
 		}
 
-		msg, ok = p.messageQueue.Pop()
+		msg, ok = messageQueue.Pop()
 		if !ok {
 			// This peer is closing
 			return
 		}
 
-		p.writeMessage(writer, msg)
+		p.writeMessage(conn, writer, msg)
 	}
 }
 
-func (p *peer) writeMessage(writer io.Writer, msg message.OutboundMessage) {
+func (p *peer) writeMessage(conn net.Conn, writer io.Writer, msg message.OutboundMessage) {
+	// Messages may be created with a compression type the peer doesn't
+	// support.
+	compressedMsg, err := msg.CompressedFor(p.compression.Get())
+	if err != nil {
+		p.Log.Error("failed to compress message",
+			zap.Stringer("messageOp", msg.Op()),
+			zap.Stringer("nodeID", p.id),
+			zap.Error(err),
+		)
+		return
+	}
+	msg = compressedMsg
+
 	msgBytes := msg.Bytes()
 	p.Log.Verbo("sending message",
 		zap.Stringer("nodeID", p.id),
 		zap.Binary("messageBytes", msgBytes),
 	)
 
-	if err := p.conn.SetWriteDeadline(p.nextTimeout()); err != nil {
+	if err := conn.SetWriteDeadline(p.nextTimeout()); err != nil {
 		p.Log.Verbo("error setting write deadline",
 			zap.Stringer("nodeID", p.id),
 			zap.Error(err),
@@ -964,7 +1081,9 @@ func (p *peer) handleVersion(msg *p2p.Version) {
 		p.StartClose()
 		return
 	}
+	p.quicPort = uint16(msg.QuicPort)
 
+	p.compression.Set(message.NewPeerCompression(msg))
 	p.gotVersion.Set(true)
 
 	peerIPs, err := p.Network.Peers(p.id)
@@ -1105,6 +1224,46 @@ func (p *peer) handlePeerListAck(msg *p2p.PeerListAck) {
 	}
 }
 
+// readMsgLenBytes reads the length of the next message sent over [stream] into
+// [msgLenBytes].
+//
+// Pings are only sent over the consensus stream, so the other streams may be
+// idle for longer than [PongTimeout]. A read on those streams that times out
+// is only treated as an error if no messages have been received from the peer
+// within [PongTimeout] over any stream.
+func (p *peer) readMsgLenBytes(stream int, conn net.Conn, reader io.Reader, msgLenBytes []byte) error {
+	numRead := 0
+	for {
+		// Time out and close connection if we can't read the message length.
+		if err := conn.SetReadDeadline(p.nextTimeout()); err != nil {
+			return err
+		}
+
+		n, err := io.ReadFull(reader, msgLenBytes[numRead:])
+		numRead += n
+		if err == nil {
+			return nil
+		}
+
+		var netErr net.Error
+		if stream == ConsensusStream || !errors.As(err, &netErr) || !netErr.Timeout() {
+			return err
+		}
+		if p.Clock.Time().Sub(p.LastReceived()) > p.PongTimeout {
+			return err
+		}
+	}
+}
+
+// inboundMsgThrottler returns the throttler that messages read from [stream]
+// are throttled by.
+func (p *peer) inboundMsgThrottler(stream int) throttling.InboundMsgThrottler {
+	if stream == ConsensusStream {
+		return p.InboundMsgThrottler
+	}
+	return p.StreamInboundMsgThrottlers[stream-1]
+}
+
 func (p *peer) nextTimeout() time.Time {
 	return p.Clock.Time().Add(p.PongTimeout)
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/uptime"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/compression"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/ips"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -53,6 +54,7 @@ func newMessageCreator(t *testing.T) message.Creator {
 		prometheus.NewRegistry(),
 		"",
 		constants.DefaultNetworkCompressionType,
+		nil,
 		10*time.Second,
 	)
 	require.NoError(t, err)
@@ -262,6 +264,169 @@ func TestSend(t *testing.T) {
 	require.NoError(peer1.AwaitClosed(context.Background()))
 }
 
+func TestSendNegotiatesCompression(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression *message.PeerCompression
+	}{
+		{
+			name:        "negotiated",
+			compression: nil,
+		},
+		{
+			name: "legacy peer",
+			compression: &message.PeerCompression{
+				Types: message.SupportedCompressionTypes(nil),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			peer0, peer1 := makeReadyTestPeers(t, set.Set[ids.ID]{})
+			if tt.compression != nil {
+				peer0.Peer.(*peer).compression.Set(*tt.compression)
+			}
+
+			for _, compressionType := range []compression.Type{compression.TypeLz4, compression.TypeSnappy} {
+				mc, err := message.NewCreator(
+					logging.NoLog{},
+					prometheus.NewRegistry(),
+					"",
+					compressionType,
+					nil,
+					10*time.Second,
+				)
+				require.NoError(err)
+
+				outboundMsg, err := mc.AppGossip(ids.Empty, make([]byte, 1024))
+				require.NoError(err)
+
+				require.True(peer0.Send(context.Background(), outboundMsg))
+
+				inboundMsg := <-peer1.inboundMsgChan
+				require.Equal(message.AppGossipOp, inboundMsg.Op())
+				require.Positive(inboundMsg.BytesSavedCompression())
+			}
+
+			peer1.StartClose()
+			require.NoError(peer0.AwaitClosed(context.Background()))
+			require.NoError(peer1.AwaitClosed(context.Background()))
+		})
+	}
+}
+
+// testStreamConn is a connection whose streams are in-memory pipes.
+type testStreamConn struct {
+	net.Conn
+	streams []net.Conn
+}
+
+func (c *testStreamConn) Streams() []net.Conn {
+	return c.streams
+}
+
+func (c *testStreamConn) Close() error {
+	for _, s := range c.streams {
+		_ = s.Close()
+	}
+	return nil
+}
+
+func TestStartStreams(t *testing.T) {
+	require := require.New(t)
+
+	rawPeer0, rawPeer1 := makeRawTestPeers(t, set.Set[ids.ID]{})
+	rawPeer0.config.QUICEnabled = true
+	rawPeer1.config.QUICEnabled = true
+	// Pings are only sent over the consensus stream, so the other streams are
+	// idle for longer than the pong timeout below.
+	rawPeer0.config.PingFrequency = 100 * time.Millisecond
+	rawPeer1.config.PingFrequency = 100 * time.Millisecond
+	rawPeer0.config.PongTimeout = 1500 * time.Millisecond
+	rawPeer1.config.PongTimeout = 1500 * time.Millisecond
+	for i := 1; i < NumStreams; i++ {
+		rawPeer0.config.StreamInboundMsgThrottlers = append(rawPeer0.config.StreamInboundMsgThrottlers, throttling.NewNoInboundThrottler())
+		rawPeer1.config.StreamInboundMsgThrottlers = append(rawPeer1.config.StreamInboundMsgThrottlers, throttling.NewNoInboundThrottler())
+	}
+
+	streams0 := []net.Conn{rawPeer0.conn}
+	streams1 := []net.Conn{rawPeer1.conn}
+	for i := 1; i < NumStreams; i++ {
+		stream0, stream1 := net.Pipe()
+		streams0 = append(streams0, stream0)
+		streams1 = append(streams1, stream1)
+	}
+
+	newMessageQueues := func(config *Config, nodeID ids.NodeID) []MessageQueue {
+		messageQueues := make([]MessageQueue, NumStreams)
+		for i := range messageQueues {
+			messageQueues[i] = NewThrottledMessageQueue(
+				config.Metrics,
+				nodeID,
+				logging.NoLog{},
+				throttling.NewNoOutboundThrottler(),
+			)
+		}
+		return messageQueues
+	}
+	peer0 := StartStreams(
+		rawPeer0.config,
+		&testStreamConn{
+			Conn:    rawPeer0.conn,
+			streams: streams0,
+		},
+		rawPeer1.cert,
+		rawPeer1.nodeID,
+		newMessageQueues(rawPeer0.config, rawPeer1.nodeID),
+	)
+	peer1 := StartStreams(
+		rawPeer1.config,
+		&testStreamConn{
+			Conn:    rawPeer1.conn,
+			streams: streams1,
+		},
+		rawPeer0.cert,
+		rawPeer0.nodeID,
+		newMessageQueues(rawPeer1.config, rawPeer0.nodeID),
+	)
+
+	require.NoError(peer0.AwaitReady(context.Background()))
+	require.NoError(peer1.AwaitReady(context.Background()))
+
+	// Both peers advertised the port they accept QUIC connections on.
+	require.Equal(uint16(1), peer0.QUICPort())
+	require.Equal(uint16(0), peer1.QUICPort())
+
+	// Idle streams shouldn't be timed out while the peer is responsive.
+	time.Sleep(2 * time.Second)
+	require.True(peer0.Ready())
+	require.True(peer1.Ready())
+
+	mc := newMessageCreator(t)
+
+	// Messages sent over every stream should be received.
+	getMsg, err := mc.Get(ids.Empty, 1, time.Second, ids.Empty, p2p.EngineType_ENGINE_TYPE_SNOWMAN)
+	require.NoError(err)
+	appGossipMsg, err := mc.AppGossip(ids.Empty, []byte{1})
+	require.NoError(err)
+	ancestorsMsg, err := mc.Ancestors(ids.Empty, 1, [][]byte{{2}})
+	require.NoError(err)
+
+	for i, msg := range []message.OutboundMessage{getMsg, appGossipMsg, ancestorsMsg} {
+		require.Equal(i, StreamOf(msg.Op()))
+		require.True(peer0.Send(context.Background(), msg))
+
+		inboundMsg := <-rawPeer1.inboundMsgChan
+		require.Equal(msg.Op(), inboundMsg.Op())
+	}
+
+	peer0.StartClose()
+	require.NoError(peer0.AwaitClosed(context.Background()))
+	require.NoError(peer1.AwaitClosed(context.Background()))
+}
+
 func TestPingUptimes(t *testing.T) {
 	trackedSubnetID := ids.GenerateTestID()
 	untrackedSubnetID := ids.GenerateTestID()
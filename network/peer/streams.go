@@ -0,0 +1,49 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peer
+
+import (
+	"net"
+
+	"github.com/ava-labs/avalanchego/message"
+)
+
+// The streams that messages are sent over when the connection to a peer
+// supports multiple streams. Each class of messages is sent over its own
+// stream, so that a large or slowly handled message of one class doesn't delay
+// the messages of the others.
+const (
+	// ConsensusStream carries the handshake, network and consensus messages.
+	ConsensusStream = iota
+	// AppGossipStream carries AppGossip messages.
+	AppGossipStream
+	// AncestorsStream carries Ancestors messages, which are sent while
+	// bootstrapping and are typically large.
+	AncestorsStream
+
+	// NumStreams is the number of streams of a connection that supports
+	// multiple streams.
+	NumStreams
+)
+
+// StreamConn is a connection to a peer that supports multiple streams.
+type StreamConn interface {
+	net.Conn
+
+	// Streams returns the [NumStreams] streams of the connection, indexed by
+	// the class of messages they carry.
+	Streams() []net.Conn
+}
+
+// StreamOf returns the stream that messages of type [op] are sent over.
+func StreamOf(op message.Op) int {
+	switch op {
+	case message.AppGossipOp:
+		return AppGossipStream
+	case message.AncestorsOp:
+		return AncestorsStream
+	default:
+		return ConsensusStream
+	}
+}
@@ -77,6 +77,7 @@ func StartTestPeer(
 		prometheus.NewRegistry(),
 		"",
 		constants.DefaultNetworkCompressionType,
+		nil,
 		10*time.Second,
 	)
 	if err != nil {
@@ -11,14 +11,18 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/quic"
 	"github.com/ava-labs/avalanchego/staking"
 )
 
 var (
-	errNoCert = errors.New("tls handshake finished with no peer certificate")
+	errNoCert  = errors.New("tls handshake finished with no peer certificate")
+	errNotQUIC = errors.New("connection isn't a QUIC connection")
 
 	_ Upgrader = (*tlsServerUpgrader)(nil)
 	_ Upgrader = (*tlsClientUpgrader)(nil)
+	_ Upgrader = (*quicServerUpgrader)(nil)
+	_ Upgrader = (*quicClientUpgrader)(nil)
 )
 
 type Upgrader interface {
@@ -58,14 +62,77 @@ func (t *tlsClientUpgrader) Upgrade(conn net.Conn) (ids.NodeID, net.Conn, *staki
 	return connToIDAndCert(tls.Client(conn, t.config), t.invalidCerts)
 }
 
+// quicServerUpgrader accepts the streams of QUIC connections established by
+// the peer. The TLS handshake is performed when the connection is established,
+// so only the peer's certificate is verified here.
+type quicServerUpgrader struct {
+	invalidCerts prometheus.Counter
+}
+
+func NewQUICServerUpgrader(invalidCerts prometheus.Counter) Upgrader {
+	return &quicServerUpgrader{
+		invalidCerts: invalidCerts,
+	}
+}
+
+func (q *quicServerUpgrader) Upgrade(conn net.Conn) (ids.NodeID, net.Conn, *staking.Certificate, error) {
+	quicConn, ok := conn.(*quic.Conn)
+	if !ok {
+		return ids.NodeID{}, nil, nil, errNotQUIC
+	}
+	nodeID, cert, err := stateToIDAndCert(quicConn.ConnectionState(), q.invalidCerts)
+	if err != nil {
+		return ids.NodeID{}, nil, nil, err
+	}
+	if err := quicConn.AcceptStreams(NumStreams); err != nil {
+		return ids.NodeID{}, nil, nil, err
+	}
+	return nodeID, quicConn, cert, nil
+}
+
+// quicClientUpgrader opens the streams of QUIC connections established with
+// the peer. The TLS handshake is performed when the connection is established,
+// so only the peer's certificate is verified here.
+type quicClientUpgrader struct {
+	invalidCerts prometheus.Counter
+}
+
+func NewQUICClientUpgrader(invalidCerts prometheus.Counter) Upgrader {
+	return &quicClientUpgrader{
+		invalidCerts: invalidCerts,
+	}
+}
+
+func (q *quicClientUpgrader) Upgrade(conn net.Conn) (ids.NodeID, net.Conn, *staking.Certificate, error) {
+	quicConn, ok := conn.(*quic.Conn)
+	if !ok {
+		return ids.NodeID{}, nil, nil, errNotQUIC
+	}
+	nodeID, cert, err := stateToIDAndCert(quicConn.ConnectionState(), q.invalidCerts)
+	if err != nil {
+		return ids.NodeID{}, nil, nil, err
+	}
+	if err := quicConn.OpenStreams(NumStreams); err != nil {
+		return ids.NodeID{}, nil, nil, err
+	}
+	return nodeID, quicConn, cert, nil
+}
+
 func connToIDAndCert(conn *tls.Conn, invalidCerts prometheus.Counter) (ids.NodeID, net.Conn, *staking.Certificate, error) {
 	if err := conn.Handshake(); err != nil {
 		return ids.NodeID{}, nil, nil, err
 	}
 
-	state := conn.ConnectionState()
+	nodeID, peerCert, err := stateToIDAndCert(conn.ConnectionState(), invalidCerts)
+	if err != nil {
+		return ids.NodeID{}, nil, nil, err
+	}
+	return nodeID, conn, peerCert, nil
+}
+
+func stateToIDAndCert(state tls.ConnectionState, invalidCerts prometheus.Counter) (ids.NodeID, *staking.Certificate, error) {
 	if len(state.PeerCertificates) == 0 {
-		return ids.NodeID{}, nil, nil, errNoCert
+		return ids.NodeID{}, nil, errNoCert
 	}
 
 	tlsCert := state.PeerCertificates[0]
@@ -75,7 +142,7 @@ func connToIDAndCert(conn *tls.Conn, invalidCerts prometheus.Counter) (ids.NodeI
 	peerCert, err := staking.ParseCertificate(tlsCert.Raw)
 	if err != nil {
 		invalidCerts.Inc()
-		return ids.NodeID{}, nil, nil, err
+		return ids.NodeID{}, nil, err
 	}
 
 	// We validate the certificate here to attempt to make the validity of the
@@ -84,9 +151,9 @@ func connToIDAndCert(conn *tls.Conn, invalidCerts prometheus.Counter) (ids.NodeI
 	// healthy.
 	if err := staking.ValidateCertificate(peerCert); err != nil {
 		invalidCerts.Inc()
-		return ids.NodeID{}, nil, nil, err
+		return ids.NodeID{}, nil, err
 	}
 
 	nodeID := ids.NodeIDFromCert(peerCert)
-	return nodeID, conn, peerCert, nil
+	return nodeID, peerCert, nil
 }
@@ -0,0 +1,279 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package quic establishes peer connections over QUIC. Unlike a TLS over TCP
+// connection, a QUIC connection carries multiple independent streams, so a
+// stalled stream doesn't delay the messages sent over the others.
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	quicgo "github.com/quic-go/quic-go"
+)
+
+const (
+	// NextProto is the application protocol negotiated during the TLS
+	// handshake of QUIC peer connections.
+	NextProto = "avalanchego/p2p"
+
+	// MaxStreams is the maximum number of streams a connection can carry.
+	MaxStreams = 16
+
+	keepAlivePeriod = 15 * time.Second
+)
+
+var (
+	errStreamsEstablished = errors.New("streams already established")
+	errNoStreams          = errors.New("streams not established")
+	errTooManyStreams     = errors.New("too many streams")
+	errUnexpectedStream   = errors.New("unexpected stream")
+
+	_ net.Conn = (*Conn)(nil)
+	_ net.Conn = (*stream)(nil)
+)
+
+// Conn is a QUIC connection to a peer. The TLS handshake is performed when the
+// connection is established, after which the dialer opens the connection's
+// streams with [OpenStreams] and the listener accepts them with
+// [AcceptStreams].
+//
+// Conn implements net.Conn by reading from and writing to its first stream.
+type Conn struct {
+	conn quicgo.Connection
+
+	// deadlineLock must be held while accessing [readDeadline] and
+	// [writeDeadline].
+	deadlineLock  sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+
+	// streams is set once when the streams are established and not modified
+	// afterwards.
+	streams []net.Conn
+}
+
+func newConn(conn quicgo.Connection) *Conn {
+	return &Conn{
+		conn: conn,
+	}
+}
+
+// ConnectionState returns the state of the TLS handshake of the connection.
+func (c *Conn) ConnectionState() tls.ConnectionState {
+	return c.conn.ConnectionState().TLS
+}
+
+// OpenStreams opens [numStreams] streams to the peer, which must accept them
+// with [AcceptStreams].
+//
+// Opening the streams is bounded by the read deadline of the connection.
+func (c *Conn) OpenStreams(numStreams int) error {
+	if len(c.streams) != 0 {
+		return errStreamsEstablished
+	}
+	if numStreams > MaxStreams {
+		return fmt.Errorf("%w: %d > %d", errTooManyStreams, numStreams, MaxStreams)
+	}
+
+	ctx, cancel := c.readDeadlineContext()
+	defer cancel()
+
+	streams := make([]net.Conn, numStreams)
+	for i := range streams {
+		s, err := c.conn.OpenStreamSync(ctx)
+		if err != nil {
+			return err
+		}
+		if err := s.SetWriteDeadline(c.getWriteDeadline()); err != nil {
+			return err
+		}
+		// The peer isn't notified of a stream until data is sent over it, so
+		// each stream starts with its index. This also allows the peer to
+		// accept the streams in any order.
+		if _, err := s.Write([]byte{byte(i)}); err != nil {
+			return err
+		}
+		streams[i] = c.newStream(s)
+	}
+	c.streams = streams
+	return nil
+}
+
+// AcceptStreams accepts the [numStreams] streams opened by the peer with
+// [OpenStreams].
+//
+// Accepting the streams is bounded by the read deadline of the connection.
+func (c *Conn) AcceptStreams(numStreams int) error {
+	if len(c.streams) != 0 {
+		return errStreamsEstablished
+	}
+	if numStreams > MaxStreams {
+		return fmt.Errorf("%w: %d > %d", errTooManyStreams, numStreams, MaxStreams)
+	}
+
+	ctx, cancel := c.readDeadlineContext()
+	defer cancel()
+
+	streams := make([]net.Conn, numStreams)
+	for range streams {
+		s, err := c.conn.AcceptStream(ctx)
+		if err != nil {
+			return err
+		}
+		if err := s.SetReadDeadline(c.getReadDeadline()); err != nil {
+			return err
+		}
+
+		var index [1]byte
+		if _, err := io.ReadFull(s, index[:]); err != nil {
+			return err
+		}
+		i := int(index[0])
+		if i >= numStreams || streams[i] != nil {
+			return fmt.Errorf("%w: %d", errUnexpectedStream, i)
+		}
+		streams[i] = c.newStream(s)
+	}
+	c.streams = streams
+	return nil
+}
+
+// Streams returns the streams of the connection, in the order they were
+// opened. It returns nil until the streams are established.
+func (c *Conn) Streams() []net.Conn {
+	return c.streams
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	if len(c.streams) == 0 {
+		return 0, errNoStreams
+	}
+	return c.streams[0].Read(b)
+}
+
+func (c *Conn) Write(b []byte) (int, error) {
+	if len(c.streams) == 0 {
+		return 0, errNoStreams
+	}
+	return c.streams[0].Write(b)
+}
+
+// Close closes the connection along with all of its streams.
+func (c *Conn) Close() error {
+	return c.conn.CloseWithError(0, "")
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *Conn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline of every stream. If the streams
+// haven't been established yet, the deadline also bounds establishing them.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	c.readDeadline = t
+	c.deadlineLock.Unlock()
+
+	for _, s := range c.streams {
+		if err := s.SetReadDeadline(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetWriteDeadline sets the write deadline of every stream.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	c.writeDeadline = t
+	c.deadlineLock.Unlock()
+
+	for _, s := range c.streams {
+		if err := s.SetWriteDeadline(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Conn) getReadDeadline() time.Time {
+	c.deadlineLock.Lock()
+	defer c.deadlineLock.Unlock()
+
+	return c.readDeadline
+}
+
+func (c *Conn) getWriteDeadline() time.Time {
+	c.deadlineLock.Lock()
+	defer c.deadlineLock.Unlock()
+
+	return c.writeDeadline
+}
+
+func (c *Conn) readDeadlineContext() (context.Context, context.CancelFunc) {
+	deadline := c.getReadDeadline()
+	if deadline.IsZero() {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), deadline)
+}
+
+func (c *Conn) newStream(s quicgo.Stream) net.Conn {
+	return &stream{
+		Stream: s,
+		conn:   c.conn,
+	}
+}
+
+// stream implements net.Conn over a single stream of a connection.
+//
+// Closing a stream only closes the sending direction of the stream. Closing
+// the connection closes all of its streams.
+type stream struct {
+	quicgo.Stream
+	conn quicgo.Connection
+}
+
+func (s *stream) LocalAddr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+func (s *stream) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+// newConfig returns the QUIC config of peer connections.
+func newConfig() *quicgo.Config {
+	return &quicgo.Config{
+		MaxIncomingStreams: MaxStreams,
+		// Peer connections only use bidirectional streams.
+		MaxIncomingUniStreams: -1,
+		KeepAlivePeriod:       keepAlivePeriod,
+	}
+}
+
+// newTLSConfig returns a copy of [config] that negotiates [NextProto].
+func newTLSConfig(config *tls.Config) *tls.Config {
+	config = config.Clone()
+	config.NextProtos = []string{NextProto}
+	return config
+}
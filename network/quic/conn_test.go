@@ -0,0 +1,186 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/staking"
+)
+
+var (
+	// Generating certificates is slow, so they are shared between tests.
+	testTLSCertsOnce sync.Once
+	testTLSCerts     [2]*tls.Certificate
+	errTestTLSCerts  error
+)
+
+func newTestTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	testTLSCertsOnce.Do(func() {
+		for i := range testTLSCerts {
+			testTLSCerts[i], errTestTLSCerts = staking.NewTLSCert()
+			if errTestTLSCerts != nil {
+				return
+			}
+		}
+	})
+	require.NoError(t, errTestTLSCerts)
+
+	// #nosec G402
+	newTLSConfig := func(tlsCert *tls.Certificate) *tls.Config {
+		return &tls.Config{
+			Certificates:       []tls.Certificate{*tlsCert},
+			ClientAuth:         tls.RequireAnyClientCert,
+			InsecureSkipVerify: true,
+			MinVersion:         tls.VersionTLS13,
+		}
+	}
+	return newTLSConfig(testTLSCerts[0]), newTLSConfig(testTLSCerts[1])
+}
+
+// newTestConns returns both ends of a QUIC connection over loopback.
+func newTestConns(t *testing.T) (*Conn, *Conn) {
+	require := require.New(t)
+
+	serverTLSConfig, clientTLSConfig := newTestTLSConfigs(t)
+	l, err := Listen("127.0.0.1:0", serverTLSConfig)
+	require.NoError(err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	type acceptResult struct {
+		conn net.Conn
+		err  error
+	}
+	accepted := make(chan acceptResult, 1)
+	go func() {
+		conn, err := l.Accept()
+		accepted <- acceptResult{
+			conn: conn,
+			err:  err,
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	clientConn, err := Dial(ctx, l.Addr().String(), clientTLSConfig)
+	require.NoError(err)
+	t.Cleanup(func() {
+		_ = clientConn.Close()
+	})
+
+	result := <-accepted
+	require.NoError(result.err)
+	serverConn := result.conn.(*Conn)
+	t.Cleanup(func() {
+		_ = serverConn.Close()
+	})
+
+	require.Equal(serverTLSConfig.Certificates[0].Leaf.Raw, clientConn.ConnectionState().PeerCertificates[0].Raw)
+	require.Len(serverConn.ConnectionState().PeerCertificates, 1)
+	require.Equal(NextProto, clientConn.ConnectionState().NegotiatedProtocol)
+	return clientConn, serverConn
+}
+
+func TestConnStreams(t *testing.T) {
+	require := require.New(t)
+
+	clientConn, serverConn := newTestConns(t)
+
+	const numStreams = 3
+	deadline := time.Now().Add(10 * time.Second)
+	require.NoError(clientConn.SetDeadline(deadline))
+	require.NoError(serverConn.SetDeadline(deadline))
+
+	_, err := clientConn.Read(make([]byte, 1))
+	require.ErrorIs(err, errNoStreams)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- serverConn.AcceptStreams(numStreams)
+	}()
+	require.NoError(clientConn.OpenStreams(numStreams))
+	require.NoError(<-errs)
+
+	clientStreams := clientConn.Streams()
+	serverStreams := serverConn.Streams()
+	require.Len(clientStreams, numStreams)
+	require.Len(serverStreams, numStreams)
+
+	// Data written to a stream should be read from the stream with the same
+	// index, regardless of the order the streams are written to.
+	for i := numStreams - 1; i >= 0; i-- {
+		msg := []byte{byte(i), 1, 2, 3}
+		_, err := clientStreams[i].Write(msg)
+		require.NoError(err)
+
+		readMsg := make([]byte, len(msg))
+		_, err = io.ReadFull(serverStreams[i], readMsg)
+		require.NoError(err)
+		require.Equal(msg, readMsg)
+	}
+
+	// The connection reads from and writes to its first stream.
+	_, err = serverConn.Write([]byte{4})
+	require.NoError(err)
+	readMsg := make([]byte, 1)
+	_, err = io.ReadFull(clientStreams[0], readMsg)
+	require.NoError(err)
+	require.Equal([]byte{4}, readMsg)
+
+	require.ErrorIs(clientConn.OpenStreams(numStreams), errStreamsEstablished)
+	require.ErrorIs(serverConn.AcceptStreams(numStreams), errStreamsEstablished)
+
+	// Closing the connection should close all of its streams.
+	require.NoError(clientConn.Close())
+	for _, s := range serverStreams {
+		_, err := s.Read(readMsg)
+		require.Error(err) //nolint:forbidigo // the error is from the quic library
+	}
+}
+
+func TestConnAcceptStreamsTimeout(t *testing.T) {
+	require := require.New(t)
+
+	_, serverConn := newTestConns(t)
+
+	require.NoError(serverConn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)))
+	err := serverConn.AcceptStreams(1)
+	require.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func TestConnUnexpectedStream(t *testing.T) {
+	require := require.New(t)
+
+	clientConn, serverConn := newTestConns(t)
+
+	deadline := time.Now().Add(10 * time.Second)
+	require.NoError(clientConn.SetDeadline(deadline))
+	require.NoError(serverConn.SetDeadline(deadline))
+
+	s, err := clientConn.conn.OpenStream()
+	require.NoError(err)
+	_, err = s.Write([]byte{1})
+	require.NoError(err)
+
+	err = serverConn.AcceptStreams(1)
+	require.ErrorIs(err, errUnexpectedStream)
+}
+
+func TestConnTooManyStreams(t *testing.T) {
+	require := require.New(t)
+
+	clientConn, serverConn := newTestConns(t)
+	require.ErrorIs(clientConn.OpenStreams(MaxStreams+1), errTooManyStreams)
+	require.ErrorIs(serverConn.AcceptStreams(MaxStreams+1), errTooManyStreams)
+}
@@ -0,0 +1,21 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+
+	quicgo "github.com/quic-go/quic-go"
+)
+
+// Dial establishes a QUIC connection with the UDP address [addr] and performs
+// the TLS handshake. If [ctx] is canceled, the attempt is abandoned.
+func Dial(ctx context.Context, addr string, tlsConfig *tls.Config) (*Conn, error) {
+	conn, err := quicgo.DialAddr(ctx, addr, newTLSConfig(tlsConfig), newConfig())
+	if err != nil {
+		return nil, err
+	}
+	return newConn(conn), nil
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	quicgo "github.com/quic-go/quic-go"
+)
+
+var _ net.Listener = (*listener)(nil)
+
+type listener struct {
+	listener *quicgo.Listener
+}
+
+// Listen returns a listener that accepts QUIC connections on the UDP address
+// [addr]. The connections returned by Accept are of type [*Conn] and have
+// completed the TLS handshake.
+func Listen(addr string, tlsConfig *tls.Config) (net.Listener, error) {
+	l, err := quicgo.ListenAddr(addr, newTLSConfig(tlsConfig), newConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &listener{
+		listener: l,
+	}, nil
+}
+
+// Accept blocks until a connection is established or the listener is closed.
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.listener.Accept(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return newConn(conn), nil
+}
+
+func (l *listener) Close() error {
+	return l.listener.Close()
+}
+
+func (l *listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
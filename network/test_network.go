@@ -83,6 +83,7 @@ func NewTestNetwork(
 		metrics,
 		"",
 		constants.DefaultNetworkCompressionType,
+		nil,
 		constants.DefaultNetworkMaximumInboundTimeout,
 	)
 	if err != nil {
@@ -246,6 +247,8 @@ func NewTestNetwork(
 			},
 			log,
 		),
+		nil,
+		nil,
 		router,
 	)
 }
@@ -20,27 +20,41 @@ type trackedIP struct {
 	delay     time.Duration
 
 	ip ips.IPPort
+	// quicPort is the port the peer accepts QUIC connections on at [ip], or 0
+	// if it doesn't.
+	quicPort uint16
 
 	stopTrackingOnce sync.Once
 	onStopTracking   chan struct{}
 }
 
-func newTrackedIP(ip ips.IPPort) *trackedIP {
+func newTrackedIP(ip ips.IPPort, quicPort uint16) *trackedIP {
 	return &trackedIP{
 		ip:             ip,
+		quicPort:       quicPort,
 		onStopTracking: make(chan struct{}),
 	}
 }
 
-func (ip *trackedIP) trackNewIP(newIP ips.IPPort) *trackedIP {
+func (ip *trackedIP) trackNewIP(newIP ips.IPPort, quicPort uint16) *trackedIP {
 	ip.stopTracking()
 	return &trackedIP{
 		delay:          ip.getDelay(),
 		ip:             newIP,
+		quicPort:       quicPort,
 		onStopTracking: make(chan struct{}),
 	}
 }
 
+// quicIP returns the IP and port the peer accepts QUIC connections on. It
+// should only be called if [quicPort] is non-zero.
+func (ip *trackedIP) quicIP() ips.IPPort {
+	return ips.IPPort{
+		IP:   ip.ip.IP,
+		Port: ip.quicPort,
+	}
+}
+
 func (ip *trackedIP) getDelay() time.Duration {
 	ip.delayLock.RLock()
 	delay := ip.delay
@@ -4,10 +4,13 @@
 package network
 
 import (
+	"net"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/utils/ips"
 )
 
 func TestTrackedIP(t *testing.T) {
@@ -46,3 +49,26 @@ func TestTrackedIP(t *testing.T) {
 	ip.stopTracking()
 	<-ip.onStopTracking
 }
+
+func TestTrackedIPTrackNewIP(t *testing.T) {
+	require := require.New(t)
+
+	ip := newTrackedIP(ips.IPPort{
+		IP:   net.IPv4(1, 2, 3, 4),
+		Port: 9651,
+	}, 0)
+	ip.increaseDelay(time.Second, time.Minute)
+	delay := ip.getDelay()
+
+	newIP := ip.trackNewIP(ips.IPPort{
+		IP:   net.IPv4(1, 2, 3, 5),
+		Port: 9651,
+	}, 9652)
+	<-ip.onStopTracking
+
+	require.Equal(delay, newIP.getDelay())
+	require.Equal(ips.IPPort{
+		IP:   net.IPv4(1, 2, 3, 5),
+		Port: 9652,
+	}, newIP.quicIP())
+}
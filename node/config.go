@@ -139,6 +139,21 @@ type DatabaseConfig struct {
 
 	// Path to config file
 	Config []byte `json:"-"`
+
+	// Path to the directory that snapshots are written into
+	SnapshotDir string `json:"snapshotDir"`
+
+	// Path to a snapshot to restore before opening the database. Empty if no
+	// snapshot should be restored.
+	RestoreSnapshot string `json:"restoreSnapshot"`
+
+	// If true, unexpected database errors quarantine the partition of the
+	// database that returned them, rather than the whole database.
+	QuarantineEnabled bool `json:"quarantineEnabled"`
+
+	// If true, the metrics of the database partitions of the node and of the
+	// primary network chains are reported separately.
+	PrefixMetricsEnabled bool `json:"prefixMetricsEnabled"`
 }
 
 // Config contains all of the configurations of an Avalanche node.
@@ -36,10 +36,14 @@ import (
 	"github.com/ava-labs/avalanchego/chains"
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/corruptabledb"
+	"github.com/ava-labs/avalanchego/database/inspect"
 	"github.com/ava-labs/avalanchego/database/leveldb"
 	"github.com/ava-labs/avalanchego/database/manager"
 	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/pebbledb"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/database/snapshot"
 	"github.com/ava-labs/avalanchego/genesis"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/indexer"
@@ -48,6 +52,7 @@ import (
 	"github.com/ava-labs/avalanchego/network"
 	"github.com/ava-labs/avalanchego/network/dialer"
 	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/network/quic"
 	"github.com/ava-labs/avalanchego/network/throttling"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
@@ -93,8 +98,9 @@ var (
 	genesisHashKey  = []byte("genesisID")
 	indexerDBPrefix = []byte{0x00}
 
-	errInvalidTLSKey = errors.New("invalid TLS key")
-	errShuttingDown  = errors.New("server shutting down")
+	errInvalidTLSKey          = errors.New("invalid TLS key")
+	errShuttingDown           = errors.New("server shutting down")
+	errQuarantineNotSupported = errors.New("database quarantine is not supported for this db-type")
 )
 
 // Node is an instance of an Avalanche node.
@@ -111,6 +117,10 @@ type Node struct {
 	DBManager manager.Manager
 	DB        database.Database
 
+	// Creates the databases that snapshots are written into. Nil if the
+	// configured database type doesn't support snapshots.
+	newSnapshotDB snapshot.NewDBFunc
+
 	// Profiles the process. Nil if continuous profiling is disabled.
 	profiler profiler.ContinuousProfiler
 
@@ -285,6 +295,25 @@ func (n *Node) initNetworking(primaryNetVdrs validators.Set) error {
 
 	tlsConfig := peer.TLSConfig(n.Config.StakingTLSCert, n.tlsKeyLogWriterCloser)
 
+	var (
+		quicListener net.Listener
+		quicDialer   dialer.Dialer
+	)
+	if n.Config.NetworkConfig.QUICEnabled {
+		// QUIC connections are accepted on the UDP port with the same number
+		// as the TCP port that was bound above.
+		_, port, err := net.SplitHostPort(listener.Addr().String())
+		if err != nil {
+			return err
+		}
+		quicListener, err = quic.Listen(net.JoinHostPort(n.Config.ListenHost, port), tlsConfig)
+		if err != nil {
+			return err
+		}
+		quicListener = throttling.NewThrottledListener(quicListener, n.Config.NetworkConfig.ThrottlerConfig.MaxInboundConnsPerSec)
+		quicDialer = dialer.NewQUICDialer(tlsConfig, n.Config.NetworkConfig.DialerConfig, n.Log)
+	}
+
 	// Configure benchlist
 	n.Config.BenchlistConfig.Validators = n.vdrs
 	n.Config.BenchlistConfig.Benchable = n.Config.ConsensusRouter
@@ -382,6 +411,8 @@ func (n *Node) initNetworking(primaryNetVdrs validators.Set) error {
 		n.Log,
 		listener,
 		dialer.NewDialer(constants.NetworkType, n.Config.NetworkConfig.DialerConfig, n.Log),
+		quicListener,
+		quicDialer,
 		consensusRouter,
 	)
 
@@ -500,24 +531,67 @@ func (n *Node) initDatabase() error {
 		dbManager manager.Manager
 		err       error
 	)
+	switch n.Config.DatabaseConfig.Name {
+	case leveldb.Name:
+		n.newSnapshotDB = leveldb.New
+	case pebbledb.Name:
+		n.newSnapshotDB = pebbledb.New
+	}
+
+	if n.Config.DatabaseConfig.RestoreSnapshot != "" {
+		if n.newSnapshotDB == nil {
+			return fmt.Errorf("%w: %q", snapshot.ErrUnsupportedDBType, n.Config.DatabaseConfig.Name)
+		}
+		_, err := snapshot.Restore(
+			n.Config.DatabaseConfig.RestoreSnapshot,
+			n.Config.DatabaseConfig.Path,
+			n.Config.DatabaseConfig.Name,
+			n.Log,
+		)
+		if err != nil {
+			return fmt.Errorf("couldn't restore snapshot: %w", err)
+		}
+	}
+
 	switch n.Config.DatabaseConfig.Name {
 	case leveldb.Name:
 		dbManager, err = manager.NewLevelDB(n.Config.DatabaseConfig.Path, n.Config.DatabaseConfig.Config, n.Log, version.CurrentDatabase, "db_internal", n.MetricsRegisterer)
 	case memdb.Name:
 		dbManager = manager.NewMemDB(version.CurrentDatabase)
+	case pebbledb.Name:
+		dbManager, err = manager.NewPebbleDB(n.Config.DatabaseConfig.Path, n.Config.DatabaseConfig.Config, n.Log, version.CurrentDatabase, "db_internal", n.MetricsRegisterer)
 	default:
 		err = fmt.Errorf(
-			"db-type was %q but should have been one of {%s, %s}",
+			"db-type was %q but should have been one of {%s, %s, %s}",
 			n.Config.DatabaseConfig.Name,
 			leveldb.Name,
 			memdb.Name,
+			pebbledb.Name,
 		)
 	}
 	if err != nil {
 		return err
 	}
 
-	meterDBManager, err := dbManager.NewMeterDBManager("db", n.MetricsRegisterer)
+	if n.Config.DatabaseConfig.QuarantineEnabled {
+		corruptableDB, ok := dbManager.Current().Database.(*corruptabledb.Database)
+		if !ok {
+			return fmt.Errorf("%w: %q", errQuarantineNotSupported, n.Config.DatabaseConfig.Name)
+		}
+		// Every key written by a chain starts with the prefix of one of the
+		// chain's prefixdbs, so only the partitions of the chain that returned
+		// an error are quarantined.
+		corruptableDB.EnableQuarantine(hashing.HashLen)
+	}
+
+	var dbPrefixes map[string][]byte
+	if n.Config.DatabaseConfig.PrefixMetricsEnabled {
+		dbPrefixes, err = n.dbPrefixes(dbManager.Current().Database)
+		if err != nil {
+			return fmt.Errorf("couldn't get database prefixes: %w", err)
+		}
+	}
+	meterDBManager, err := dbManager.NewMeterDBManagerWithPrefixes("db", n.MetricsRegisterer, dbPrefixes)
 	if err != nil {
 		return err
 	}
@@ -556,6 +630,25 @@ func (n *Node) initDatabase() error {
 	return nil
 }
 
+// dbPrefixes returns the prefixes of the partitions of [db] that are written
+// to by the node and by the primary network chains, keyed by name. The
+// partitions of other chains aren't known until they are created.
+func (n *Node) dbPrefixes(db database.Database) (map[string][]byte, error) {
+	_, chainAliases, err := genesis.Aliases(n.Config.GenesisBytes)
+	if err != nil {
+		return nil, err
+	}
+	chainPrefixes := make(map[string][]byte, len(chainAliases))
+	for chainID, aliases := range chainAliases {
+		chainDBPrefix, err := chains.ChainDBPrefix(db, chainID)
+		if err != nil {
+			return nil, err
+		}
+		chainPrefixes[aliases[0]] = chainDBPrefix
+	}
+	return inspect.Prefixes(chainPrefixes)
+}
+
 // Set the node IDs of the peers this node should first connect to
 func (n *Node) initBootstrappers() error {
 	n.bootstrappers = validators.NewSet()
@@ -1033,6 +1126,19 @@ func (n *Node) initAdminAPI() error {
 		return nil
 	}
 	n.Log.Info("initializing admin API")
+
+	// The snapshotter must be registered before any chains are created so that
+	// every chain is quiesced when a snapshot is taken.
+	snapshotter := snapshot.New(snapshot.Config{
+		Log:       n.Log,
+		DBType:    n.Config.DatabaseConfig.Name,
+		NewDB:     n.newSnapshotDB,
+		DBConfig:  n.Config.DatabaseConfig.Config,
+		DBManager: n.DBManager,
+		Dir:       n.Config.DatabaseConfig.SnapshotDir,
+	})
+	n.chainManager.AddRegistrant(snapshotter)
+
 	service, err := admin.NewService(
 		admin.Config{
 			Log:          n.Log,
@@ -1043,6 +1149,7 @@ func (n *Node) initAdminAPI() error {
 			NodeConfig:   n.Config,
 			VMManager:    n.VMManager,
 			VMRegistry:   n.VMRegistry,
+			Snapshotter:  snapshotter,
 		},
 	)
 	if err != nil {
@@ -1420,6 +1527,7 @@ func (n *Node) Initialize(
 		n.MetricsRegisterer,
 		n.networkNamespace,
 		n.Config.NetworkConfig.CompressionType,
+		n.Config.NetworkConfig.CompressionZstdDictionary,
 		n.Config.NetworkConfig.MaximumInboundMessageTimeout,
 	)
 	if err != nil {
@@ -79,6 +79,7 @@ func TestTimeout(t *testing.T) {
 		metrics,
 		"dummyNamespace",
 		constants.DefaultNetworkCompressionType,
+		nil,
 		10*time.Second,
 	)
 	require.NoError(err)
@@ -337,6 +338,7 @@ func TestReliableMessages(t *testing.T) {
 		metrics,
 		"dummyNamespace",
 		constants.DefaultNetworkCompressionType,
+		nil,
 		10*time.Second,
 	)
 	require.NoError(err)
@@ -487,6 +489,7 @@ func TestReliableMessagesToMyself(t *testing.T) {
 		metrics,
 		"dummyNamespace",
 		constants.DefaultNetworkCompressionType,
+		nil,
 		10*time.Second,
 	)
 	require.NoError(err)
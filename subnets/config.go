@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/utils/set"
@@ -63,6 +64,9 @@ type Config struct {
 	// TODO: Move this flag once the proposervm is configurable on a per-chain
 	// basis.
 	ProposerNumHistoricalBlocks uint64 `json:"proposerNumHistoricalBlocks" yaml:"proposerNumHistoricalBlocks"`
+	// ProposerCachePolicy is the eviction policy of the snowman++ inner block
+	// cache.
+	ProposerCachePolicy cache.Policy `json:"proposerCachePolicy" yaml:"proposerCachePolicy"`
 }
 
 func (c *Config) Valid() error {
@@ -72,5 +76,8 @@ func (c *Config) Valid() error {
 	if !c.ValidatorOnly && c.AllowedNodes.Len() > 0 {
 		return errAllowedNodesWhenNotValidatorOnly
 	}
+	if err := c.ProposerCachePolicy.Verify(); err != nil {
+		return fmt.Errorf("proposer %w", err)
+	}
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/utils/set"
@@ -49,6 +50,14 @@ func TestValid(t *testing.T) {
 			},
 			expectedErr: errAllowedNodesWhenNotValidatorOnly,
 		},
+		{
+			name: "invalid proposer cache policy",
+			s: Config{
+				ConsensusParameters: validParameters,
+				ProposerCachePolicy: "lfu",
+			},
+			expectedErr: cache.ErrUnknownPolicy,
+		},
 		{
 			name: "valid",
 			s: Config{
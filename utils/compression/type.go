@@ -16,6 +16,8 @@ const (
 	TypeNone Type = iota + 1
 	TypeGzip
 	TypeZstd
+	TypeLz4
+	TypeSnappy
 )
 
 func (t Type) String() string {
@@ -26,6 +28,10 @@ func (t Type) String() string {
 		return "gzip"
 	case TypeZstd:
 		return "zstd"
+	case TypeLz4:
+		return "lz4"
+	case TypeSnappy:
+		return "snappy"
 	default:
 		return "unknown"
 	}
@@ -39,6 +45,10 @@ func TypeFromString(s string) (Type, error) {
 		return TypeGzip, nil
 	case TypeZstd.String():
 		return TypeZstd, nil
+	case TypeLz4.String():
+		return TypeLz4, nil
+	case TypeSnappy.String():
+		return TypeSnappy, nil
 	default:
 		return TypeNone, errUnknownCompressionType
 	}
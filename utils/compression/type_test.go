@@ -12,7 +12,7 @@ import (
 func TestTypeString(t *testing.T) {
 	require := require.New(t)
 
-	for _, compressionType := range []Type{TypeNone, TypeGzip, TypeZstd} {
+	for _, compressionType := range []Type{TypeNone, TypeGzip, TypeZstd, TypeLz4, TypeSnappy} {
 		s := compressionType.String()
 		parsedType, err := TypeFromString(s)
 		require.NoError(err)
@@ -42,6 +42,14 @@ func TestTypeMarshalJSON(t *testing.T) {
 			Type:     TypeZstd,
 			expected: `"zstd"`,
 		},
+		{
+			Type:     TypeLz4,
+			expected: `"lz4"`,
+		},
+		{
+			Type:     TypeSnappy,
+			expected: `"snappy"`,
+		},
 		{
 			Type:     Type(0),
 			expected: `"unknown"`,
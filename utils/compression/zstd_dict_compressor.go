@@ -0,0 +1,137 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	_ Compressor = (*zstdDictCompressor)(nil)
+
+	ErrInvalidZstdDict = errors.New("invalid zstd dictionary")
+	errNoSamples       = errors.New("no samples to train the dictionary with")
+)
+
+// NewZstdDictCompressor returns a zstd Compressor that compresses messages
+// with the dictionary [zstdDict]. Messages can only be decompressed by a
+// Compressor with the same dictionary.
+//
+// Dictionaries are created with TrainZstdDict.
+func NewZstdDictCompressor(maxSize int64, zstdDict []byte) (Compressor, error) {
+	if maxSize == math.MaxInt64 {
+		// "Decompress" creates "io.LimitReader" with max size + 1:
+		// if the max size + 1 overflows, "io.LimitReader" reads nothing
+		// returning 0 byte for the decompress call
+		// require max size < math.MaxInt64 to prevent int64 overflows
+		return nil, ErrInvalidMaxSizeCompressor
+	}
+	if _, err := ZstdDictID(zstdDict); err != nil {
+		return nil, err
+	}
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderDict(zstdDict))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidZstdDict, err)
+	}
+
+	// Make sure that the decoder options are valid, so that creating decoders
+	// in the pool can't fail.
+	decoderOptions := []zstd.DOption{
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderDicts(zstdDict),
+	}
+	decoder, err := zstd.NewReader(nil, decoderOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidZstdDict, err)
+	}
+
+	z := &zstdDictCompressor{
+		maxSize: maxSize,
+		encoder: encoder,
+		decoderPool: sync.Pool{
+			New: func() interface{} {
+				decoder, _ := zstd.NewReader(nil, decoderOptions...)
+				return decoder
+			},
+		},
+	}
+	z.decoderPool.Put(decoder)
+	return z, nil
+}
+
+// ZstdDictID returns the ID of the dictionary [zstdDict]. Frames compressed
+// with the dictionary reference it by this ID.
+func ZstdDictID(zstdDict []byte) (uint32, error) {
+	d, err := zstd.InspectDictionary(zstdDict)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidZstdDict, err)
+	}
+	id := d.ID()
+	if id == 0 {
+		// An ID of 0 means that a frame doesn't reference a dictionary.
+		return 0, fmt.Errorf("%w: dictionary ID must not be 0", ErrInvalidZstdDict)
+	}
+	return id, nil
+}
+
+// TrainZstdDict returns a dictionary of at most [maxDictSize] bytes that
+// improves the compression of messages similar to [samples].
+//
+// The dictionary is compatible with the reference zstd implementation.
+func TrainZstdDict(samples [][]byte, maxDictSize int) ([]byte, error) {
+	if len(samples) == 0 {
+		return nil, errNoSamples
+	}
+	return dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize:    maxDictSize,
+		HashBytes:      6,
+		ZstdDictCompat: true,
+		ZstdLevel:      zstd.SpeedDefault,
+	})
+}
+
+type zstdDictCompressor struct {
+	maxSize int64
+	// encoder is safe for concurrent use by EncodeAll.
+	encoder     *zstd.Encoder
+	decoderPool sync.Pool
+}
+
+func (z *zstdDictCompressor) Compress(msg []byte) ([]byte, error) {
+	if int64(len(msg)) > z.maxSize {
+		return nil, fmt.Errorf("%w: (%d) > (%d)", ErrMsgTooLarge, len(msg), z.maxSize)
+	}
+	return z.encoder.EncodeAll(msg, nil), nil
+}
+
+func (z *zstdDictCompressor) Decompress(msg []byte) ([]byte, error) {
+	decoder := z.decoderPool.Get().(*zstd.Decoder)
+	defer z.decoderPool.Put(decoder)
+
+	if err := decoder.Reset(bytes.NewReader(msg)); err != nil {
+		return nil, err
+	}
+
+	// We allow [io.LimitReader] to read up to [z.maxSize + 1] bytes, so that if
+	// the decompressed payload is greater than the maximum size, this function
+	// will return the appropriate error instead of an incomplete byte slice.
+	limitReader := io.LimitReader(decoder, z.maxSize+1)
+	decompressed, err := io.ReadAll(limitReader)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decompressed)) > z.maxSize {
+		return nil, fmt.Errorf("%w: (%d) > (%d)", ErrDecompressedMsgTooLarge, len(decompressed), z.maxSize)
+	}
+	return decompressed, nil
+}
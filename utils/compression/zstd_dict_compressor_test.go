@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package compression
+
+import (
+	"encoding/binary"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+// newZstdDictSample returns a sample with a repeated shape, similar to small
+// consensus messages.
+func newZstdDictSample(i int) []byte {
+	sample := []byte("chain_id:0123456789abcdef0123456789abcdef request_id:")
+	sample = binary.BigEndian.AppendUint32(sample, uint32(i))
+	sample = append(sample, []byte(" deadline:2000000000 preferred_id:")...)
+	return append(sample, utils.RandomBytes(32)...)
+}
+
+func trainTestZstdDict(t testing.TB) []byte {
+	samples := make([][]byte, 1_000)
+	for i := range samples {
+		samples[i] = newZstdDictSample(i)
+	}
+	zstdDict, err := TrainZstdDict(samples, 4*units.KiB)
+	require.NoError(t, err)
+	return zstdDict
+}
+
+func TestZstdDictCompressor(t *testing.T) {
+	require := require.New(t)
+
+	zstdDict := trainTestZstdDict(t)
+	id, err := ZstdDictID(zstdDict)
+	require.NoError(err)
+	require.NotZero(id)
+
+	compressor, err := NewZstdDictCompressor(maxMessageSize, zstdDict)
+	require.NoError(err)
+	zstdCompressor, err := NewZstdCompressor(maxMessageSize)
+	require.NoError(err)
+
+	sample := newZstdDictSample(1_000_000)
+	compressed, err := compressor.Compress(sample)
+	require.NoError(err)
+	zstdCompressed, err := zstdCompressor.Compress(sample)
+	require.NoError(err)
+
+	// The dictionary should make small messages with a known shape compress
+	// better.
+	require.Less(len(compressed), len(zstdCompressed))
+
+	decompressed, err := compressor.Decompress(compressed)
+	require.NoError(err)
+	require.Equal(sample, decompressed)
+
+	// Messages compressed without the dictionary can be decompressed.
+	decompressed, err = compressor.Decompress(zstdCompressed)
+	require.NoError(err)
+	require.Equal(sample, decompressed)
+
+	// Messages compressed with the dictionary can't be decompressed without
+	// it.
+	_, err = zstdCompressor.Decompress(compressed)
+	require.Error(err) //nolint:forbidigo // the error is returned by cgo
+}
+
+func TestZstdDictCompressorSizeLimiting(t *testing.T) {
+	require := require.New(t)
+
+	zstdDict := trainTestZstdDict(t)
+	compressor, err := NewZstdDictCompressor(maxMessageSize, zstdDict)
+	require.NoError(err)
+
+	data := make([]byte, maxMessageSize+1)
+	_, err = compressor.Compress(data) // should be too large
+	require.ErrorIs(err, ErrMsgTooLarge)
+
+	// Create a zip bomb that is a valid message.
+	largeCompressor, err := NewZstdDictCompressor(256*units.MiB, zstdDict)
+	require.NoError(err)
+	zipBomb, err := largeCompressor.Compress(make([]byte, 256*units.MiB))
+	require.NoError(err)
+	require.Less(len(zipBomb), maxMessageSize)
+
+	var (
+		beforeDecompressionStats runtime.MemStats
+		afterDecompressionStats  runtime.MemStats
+	)
+	runtime.ReadMemStats(&beforeDecompressionStats)
+	_, err = compressor.Decompress(zipBomb)
+	runtime.ReadMemStats(&afterDecompressionStats)
+
+	require.ErrorIs(err, ErrDecompressedMsgTooLarge)
+
+	// Make sure that we didn't allocate significantly more memory than the max
+	// message size.
+	bytesAllocatedDuringDecompression := afterDecompressionStats.TotalAlloc - beforeDecompressionStats.TotalAlloc
+	require.Less(bytesAllocatedDuringDecompression, uint64(10*maxMessageSize))
+}
+
+func TestZstdDictInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		zstdDict []byte
+	}{
+		{
+			name:     "empty",
+			zstdDict: nil,
+		},
+		{
+			name:     "raw content",
+			zstdDict: utils.RandomBytes(units.KiB),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewZstdDictCompressor(maxMessageSize, tt.zstdDict)
+			require.ErrorIs(t, err, ErrInvalidZstdDict)
+		})
+	}
+}
+
+func TestTrainZstdDictNoSamples(t *testing.T) {
+	_, err := TrainZstdDict(nil, units.KiB)
+	require.ErrorIs(t, err, errNoSamples)
+}
+
+func FuzzZstdDictCompressor(f *testing.F) {
+	compressor, err := NewZstdDictCompressor(maxMessageSize, trainTestZstdDict(f))
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		require := require.New(t)
+
+		if len(data) > maxMessageSize {
+			_, err := compressor.Compress(data)
+			require.ErrorIs(err, ErrMsgTooLarge)
+			return
+		}
+
+		compressed, err := compressor.Compress(data)
+		require.NoError(err)
+
+		decompressed, err := compressor.Decompress(compressed)
+		require.NoError(err)
+
+		require.Equal(data, decompressed)
+	})
+}
@@ -34,6 +34,10 @@ type ClaimedIPPort struct {
 	Signature []byte
 	// The txID that added this peer into the validator set
 	TxID ids.ID
+	// The port the peer accepts QUIC connections on, or 0 if it is unknown.
+	// This isn't covered by [Signature], so it is only ever learned from the
+	// peer itself and is never gossiped.
+	QUICPort uint16
 }
 
 // Returns the length of the byte representation of this ClaimedIPPort.
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/cache"
 )
 
 func TestExecutionConfigUnmarshal(t *testing.T) {
@@ -46,6 +48,7 @@ func TestExecutionConfigUnmarshal(t *testing.T) {
 			"chain-cache-size": 6,
 			"chain-db-cache-size": 7,
 			"block-id-cache-size": 8,
+			"cache-policy": "arc",
 			"checksums-enabled": true
 		}`)
 		ec, err := GetExecutionConfig(b)
@@ -58,8 +61,16 @@ func TestExecutionConfigUnmarshal(t *testing.T) {
 			ChainCacheSize:               6,
 			ChainDBCacheSize:             7,
 			BlockIDCacheSize:             8,
+			CachePolicy:                  cache.ARCPolicy,
 			ChecksumsEnabled:             true,
 		}
 		require.Equal(expected, ec)
 	})
+
+	t.Run("unknown cache policy", func(t *testing.T) {
+		require := require.New(t)
+		b := []byte(`{"cache-policy":"lfu"}`)
+		_, err := GetExecutionConfig(b)
+		require.ErrorIs(err, cache.ErrUnknownPolicy)
+	})
 }
@@ -497,6 +497,37 @@ func New(
 	return s, nil
 }
 
+// newCache returns a metered cache that holds at most [size] elements and
+// evicts them based on [policy].
+func newCache[K comparable, V any](
+	namespace string,
+	registerer prometheus.Registerer,
+	policy cache.Policy,
+	size int,
+) (cache.Cacher[K, V], error) {
+	c, err := cache.New[K, V](policy, size)
+	if err != nil {
+		return nil, err
+	}
+	return metercacher.New(namespace, registerer, c)
+}
+
+// newSizedCache returns a metered cache that holds elements with a total size
+// of at most [maxSize] and evicts them based on [policy].
+func newSizedCache[K comparable, V any](
+	namespace string,
+	registerer prometheus.Registerer,
+	policy cache.Policy,
+	maxSize int,
+	size func(K, V) int,
+) (cache.Cacher[K, V], error) {
+	c, err := cache.NewSized[K, V](policy, maxSize, size)
+	if err != nil {
+		return nil, err
+	}
+	return metercacher.New(namespace, registerer, c)
+}
+
 func newState(
 	db database.Database,
 	metrics metrics.Metrics,
@@ -507,19 +538,22 @@ func newState(
 	rewards reward.Calculator,
 	bootstrapped *utils.Atomic[bool],
 ) (*state, error) {
-	blockIDCache, err := metercacher.New[uint64, ids.ID](
+	blockIDCache, err := newCache[uint64, ids.ID](
 		"block_id_cache",
 		metricsReg,
-		&cache.LRU[uint64, ids.ID]{Size: execCfg.BlockIDCacheSize},
+		execCfg.CachePolicy,
+		execCfg.BlockIDCacheSize,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	blockCache, err := metercacher.New[ids.ID, block.Block](
+	blockCache, err := newSizedCache[ids.ID, block.Block](
 		"block_cache",
 		metricsReg,
-		cache.NewSizedLRU[ids.ID, block.Block](execCfg.BlockCacheSize, blockSize),
+		execCfg.CachePolicy,
+		execCfg.BlockCacheSize,
+		blockSize,
 	)
 	if err != nil {
 		return nil, err
@@ -546,20 +580,23 @@ func newState(
 	flatValidatorWeightDiffsDB := prefixdb.New(flatValidatorWeightDiffsPrefix, validatorsDB)
 	flatValidatorPublicKeyDiffsDB := prefixdb.New(flatValidatorPublicKeyDiffsPrefix, validatorsDB)
 
-	txCache, err := metercacher.New(
+	txCache, err := newSizedCache[ids.ID, *txAndStatus](
 		"tx_cache",
 		metricsReg,
-		cache.NewSizedLRU[ids.ID, *txAndStatus](execCfg.TxCacheSize, txAndStatusSize),
+		execCfg.CachePolicy,
+		execCfg.TxCacheSize,
+		txAndStatusSize,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	rewardUTXODB := prefixdb.New(rewardUTXOsPrefix, baseDB)
-	rewardUTXOsCache, err := metercacher.New[ids.ID, []*avax.UTXO](
+	rewardUTXOsCache, err := newCache[ids.ID, []*avax.UTXO](
 		"reward_utxos_cache",
 		metricsReg,
-		&cache.LRU[ids.ID, []*avax.UTXO]{Size: execCfg.RewardUTXOsCacheSize},
+		execCfg.CachePolicy,
+		execCfg.RewardUTXOsCacheSize,
 	)
 	if err != nil {
 		return nil, err
@@ -573,37 +610,42 @@ func newState(
 
 	subnetBaseDB := prefixdb.New(subnetPrefix, baseDB)
 
-	transformedSubnetCache, err := metercacher.New(
+	transformedSubnetCache, err := newSizedCache[ids.ID, *txs.Tx](
 		"transformed_subnet_cache",
 		metricsReg,
-		cache.NewSizedLRU[ids.ID, *txs.Tx](execCfg.TransformedSubnetTxCacheSize, txSize),
+		execCfg.CachePolicy,
+		execCfg.TransformedSubnetTxCacheSize,
+		txSize,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	supplyCache, err := metercacher.New[ids.ID, *uint64](
+	supplyCache, err := newCache[ids.ID, *uint64](
 		"supply_cache",
 		metricsReg,
-		&cache.LRU[ids.ID, *uint64]{Size: execCfg.ChainCacheSize},
+		execCfg.CachePolicy,
+		execCfg.ChainCacheSize,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	chainCache, err := metercacher.New[ids.ID, []*txs.Tx](
+	chainCache, err := newCache[ids.ID, []*txs.Tx](
 		"chain_cache",
 		metricsReg,
-		&cache.LRU[ids.ID, []*txs.Tx]{Size: execCfg.ChainCacheSize},
+		execCfg.CachePolicy,
+		execCfg.ChainCacheSize,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	chainDBCache, err := metercacher.New[ids.ID, linkeddb.LinkedDB](
+	chainDBCache, err := newCache[ids.ID, linkeddb.LinkedDB](
 		"chain_db_cache",
 		metricsReg,
-		&cache.LRU[ids.ID, linkeddb.LinkedDB]{Size: execCfg.ChainDBCacheSize},
+		execCfg.CachePolicy,
+		execCfg.ChainDBCacheSize,
 	)
 	if err != nil {
 		return nil, err
@@ -665,6 +665,7 @@ func TestOptionTimestampValidity(t *testing.T) {
 		0,
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -77,6 +77,7 @@ func helperBuildStateSyncTestObjects(t *testing.T) (*fullVM, *VM) {
 		0,
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -49,6 +49,8 @@ const (
 	// DefaultNumHistoricalBlocks as 0 results in never deleting any historical
 	// blocks.
 	DefaultNumHistoricalBlocks uint64 = 0
+	// DefaultInnerBlkCachePolicy evicts the least recently used inner blocks.
+	DefaultInnerBlkCachePolicy = cache.LRUPolicy
 
 	checkIndexedFrequency = 10 * time.Second
 	innerBlkCacheSize     = 64 * units.MiB
@@ -95,6 +97,7 @@ type VM struct {
 	minimumPChainHeight uint64
 	minBlkDelay         time.Duration
 	numHistoricalBlocks uint64
+	innerBlkCachePolicy cache.Policy
 	// block signer
 	stakingLeafSigner crypto.Signer
 	// block certificate
@@ -143,6 +146,7 @@ func New(
 	minimumPChainHeight uint64,
 	minBlkDelay time.Duration,
 	numHistoricalBlocks uint64,
+	innerBlkCachePolicy cache.Policy,
 	stakingLeafSigner crypto.Signer,
 	stakingCertLeaf *staking.Certificate,
 ) *VM {
@@ -159,6 +163,7 @@ func New(
 		minimumPChainHeight: minimumPChainHeight,
 		minBlkDelay:         minBlkDelay,
 		numHistoricalBlocks: numHistoricalBlocks,
+		innerBlkCachePolicy: innerBlkCachePolicy,
 		stakingLeafSigner:   stakingLeafSigner,
 		stakingCertLeaf:     stakingCertLeaf,
 	}
@@ -203,13 +208,18 @@ func (vm *VM) Initialize(
 	vm.State = baseState
 	vm.Windower = proposer.New(chainCtx.ValidatorState, chainCtx.SubnetID, chainCtx.ChainID)
 	vm.Tree = tree.New()
-	innerBlkCache, err := metercacher.New[ids.ID, snowman.Block](
+	baseInnerBlkCache, err := cache.NewSized[ids.ID, snowman.Block](
+		vm.innerBlkCachePolicy,
+		innerBlkCacheSize,
+		cachedBlockSize,
+	)
+	if err != nil {
+		return err
+	}
+	innerBlkCache, err := metercacher.New(
 		"inner_block_cache",
 		registerer,
-		cache.NewSizedLRU[ids.ID, snowman.Block](
-			innerBlkCacheSize,
-			cachedBlockSize,
-		),
+		baseInnerBlkCache,
 	)
 	if err != nil {
 		return err
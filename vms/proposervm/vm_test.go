@@ -138,6 +138,7 @@ func initTestProposerVM(
 		minPChainHeight,
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -877,6 +878,7 @@ func TestExpiredBuildBlock(t *testing.T) {
 		0,
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -1220,6 +1222,7 @@ func TestInnerVMRollback(t *testing.T) {
 		0,
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -1307,6 +1310,7 @@ func TestInnerVMRollback(t *testing.T) {
 		0,
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -1799,6 +1803,7 @@ func TestRejectedHeightNotIndexed(t *testing.T) {
 		0,
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -2003,6 +2008,7 @@ func TestRejectedOptionHeightNotIndexed(t *testing.T) {
 		0,
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -2163,6 +2169,7 @@ func TestVMInnerBlkCache(t *testing.T) {
 		0,           // minimum P-Chain height
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -2396,6 +2403,7 @@ func TestVM_VerifyBlockWithContext(t *testing.T) {
 		0,           // minimum P-Chain height
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -2609,6 +2617,7 @@ func TestHistoricalBlockDeletion(t *testing.T) {
 		0,
 		DefaultMinBlockDelay,
 		DefaultNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -2707,6 +2716,7 @@ func TestHistoricalBlockDeletion(t *testing.T) {
 		0,
 		DefaultMinBlockDelay,
 		numHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -2749,6 +2759,7 @@ func TestHistoricalBlockDeletion(t *testing.T) {
 		0,
 		DefaultMinBlockDelay,
 		newNumHistoricalBlocks,
+		DefaultInnerBlkCachePolicy,
 		pTestSigner,
 		pTestCert,
 	)
@@ -4,17 +4,26 @@
 package archivedb
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
+	"sync"
+
+	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanchego/api/health"
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/units"
 )
 
+// The approximate size of the batches that pruned versions are deleted in.
+const pruneBatchSize = 4 * units.MiB
+
 var (
 	ErrNotImplemented = errors.New("feature not implemented")
 	ErrInvalidValue   = errors.New("invalid data value")
+	ErrPruned         = errors.New("height has been pruned")
 
 	_ database.Compacter = (*Database)(nil)
 	_ health.Checker     = (*Database)(nil)
@@ -57,8 +66,22 @@ var (
 // foo was deleted at height 1000. When calling `reader.GetHeight(foo)` at
 // height 99 it will return a tuple `("foo's value is bar", 10)` returning the
 // value of `foo` at height 99 (which was set at height 10).
+//
+// Old versions that are no longer needed can be removed with Prune. After
+// calling `Prune(100)`, the value of foo set at height 10 is removed and
+// readers opened below height 100 return ErrPruned.
 type Database struct {
 	db database.Database
+
+	// Ensures only one call to Prune runs at a time.
+	pruneLock sync.Mutex
+
+	// Protects [prunedHeight] and [prunedHeightLoaded].
+	lock sync.RWMutex
+	// The height readers must be opened at or above.
+	// Loaded from [db] the first time it's needed.
+	prunedHeight       uint64
+	prunedHeightLoaded bool
 }
 
 func New(db database.Database) *Database {
@@ -92,6 +115,116 @@ func (db *Database) NewBatch(height uint64) *batch {
 	}
 }
 
+// PrunedHeight returns the height the database was last pruned below. Readers
+// opened below this height return ErrPruned.
+func (db *Database) PrunedHeight() (uint64, error) {
+	db.lock.RLock()
+	prunedHeight, loaded := db.prunedHeight, db.prunedHeightLoaded
+	db.lock.RUnlock()
+	if loaded {
+		return prunedHeight, nil
+	}
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.prunedHeightLoaded {
+		return db.prunedHeight, nil
+	}
+	prunedHeight, err := database.GetUInt64(db.db, prunedHeightKey)
+	switch {
+	case err == database.ErrNotFound:
+		prunedHeight = 0
+	case err != nil:
+		return 0, err
+	}
+	db.prunedHeight = prunedHeight
+	db.prunedHeightLoaded = true
+	return prunedHeight, nil
+}
+
+// Prune removes the versions of keys that aren't needed to read the state at
+// heights >= [belowHeight]. For each key, the latest version at or below
+// [belowHeight] is kept, unless it's a deletion, and all older versions are
+// removed.
+//
+// Callers that want to keep a retention window of [window] heights should
+// call Prune(height - window) as the database grows.
+//
+// Pruning below a height the database was already pruned below is a no-op.
+func (db *Database) Prune(belowHeight uint64) error {
+	db.pruneLock.Lock()
+	defer db.pruneLock.Unlock()
+
+	prunedHeight, err := db.PrunedHeight()
+	if err != nil {
+		return err
+	}
+	if belowHeight <= prunedHeight {
+		return nil
+	}
+
+	// Record the new pruned height before removing anything, so readers never
+	// see a partially pruned state without an error. If we crash while
+	// pruning, the remaining versions are removed by the next call to Prune.
+	if err := database.PutUInt64(db.db, prunedHeightKey, belowHeight); err != nil {
+		return err
+	}
+	db.lock.Lock()
+	db.prunedHeight = belowHeight
+	db.prunedHeightLoaded = true
+	db.lock.Unlock()
+
+	it := db.db.NewIteratorWithPrefix(entryPrefix)
+	defer it.Release()
+
+	var (
+		batch = db.db.NewBatch()
+		// The user key whose versions are being iterated over.
+		lastKey    []byte
+		hasLastKey bool
+		// True if the latest version of [lastKey] at [belowHeight] was found.
+		foundLatest bool
+	)
+	for it.Next() {
+		key, height, err := parseDBKey(it.Key())
+		if err != nil {
+			return err
+		}
+		if !hasLastKey || !bytes.Equal(key, lastKey) {
+			lastKey = slices.Clone(key)
+			hasLastKey = true
+			foundLatest = false
+		}
+		if height > belowHeight {
+			// Needed to read the state above [belowHeight].
+			continue
+		}
+		if !foundLatest {
+			foundLatest = true
+			if _, exists := parseDBValue(it.Value()); exists {
+				// Needed to read the state at [belowHeight].
+				continue
+			}
+		}
+
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+		if batch.Size() < pruneBatchSize {
+			continue
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
 func (db *Database) Compact(start []byte, limit []byte) error {
 	return db.db.Compact(start, limit)
 }
@@ -4,12 +4,17 @@
 package archivedb
 
 import (
+	"bytes"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/maybe"
 )
 
 func TestDBEntries(t *testing.T) {
@@ -225,3 +230,240 @@ func TestSkipHeight(t *testing.T) {
 	require.NoError(err)
 	require.Equal(uint64(10), height)
 }
+
+// Returns the keys and values [it] iterates over and releases it.
+func iterate(require *require.Assertions, it database.Iterator) ([][]byte, [][]byte) {
+	defer it.Release()
+
+	var keys, values [][]byte
+	for it.Next() {
+		keys = append(keys, it.Key())
+		values = append(values, it.Value())
+	}
+	require.NoError(it.Error())
+	return keys, values
+}
+
+func TestReaderIterator(t *testing.T) {
+	require := require.New(t)
+
+	db := New(memdb.New())
+
+	batch := db.NewBatch(1)
+	require.NoError(batch.Put([]byte("b"), []byte("b@1")))
+	require.NoError(batch.Put([]byte("ab"), []byte("ab@1")))
+	require.NoError(batch.Put([]byte("abc"), []byte("abc@1")))
+	require.NoError(batch.Put([]byte{}, []byte("empty@1")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(2)
+	require.NoError(batch.Put([]byte("a"), []byte("a@2")))
+	require.NoError(batch.Put([]byte("ab"), []byte("ab@2")))
+	require.NoError(batch.Delete([]byte("b")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(3)
+	require.NoError(batch.Put([]byte("b"), []byte("b@3")))
+	require.NoError(batch.Delete([]byte("abc")))
+	require.NoError(batch.Write())
+
+	keys, values := iterate(require, db.Open(0).NewIterator())
+	require.Empty(keys)
+	require.Empty(values)
+
+	keys, values = iterate(require, db.Open(1).NewIterator())
+	require.Equal([][]byte{{}, []byte("ab"), []byte("abc"), []byte("b")}, keys)
+	require.Equal([][]byte{[]byte("empty@1"), []byte("ab@1"), []byte("abc@1"), []byte("b@1")}, values)
+
+	keys, values = iterate(require, db.Open(2).NewIterator())
+	require.Equal([][]byte{{}, []byte("a"), []byte("ab"), []byte("abc")}, keys)
+	require.Equal([][]byte{[]byte("empty@1"), []byte("a@2"), []byte("ab@2"), []byte("abc@1")}, values)
+
+	keys, values = iterate(require, db.Open(3).NewIterator())
+	require.Equal([][]byte{{}, []byte("a"), []byte("ab"), []byte("b")}, keys)
+	require.Equal([][]byte{[]byte("empty@1"), []byte("a@2"), []byte("ab@2"), []byte("b@3")}, values)
+
+	keys, _ = iterate(require, db.Open(2).NewIteratorWithPrefix([]byte("ab")))
+	require.Equal([][]byte{[]byte("ab"), []byte("abc")}, keys)
+
+	keys, _ = iterate(require, db.Open(2).NewIteratorWithStart([]byte("aa")))
+	require.Equal([][]byte{[]byte("ab"), []byte("abc")}, keys)
+
+	keys, _ = iterate(require, db.Open(2).NewIteratorWithStart([]byte("abb")))
+	require.Equal([][]byte{[]byte("abc")}, keys)
+
+	keys, _ = iterate(require, db.Open(3).NewIteratorWithStartAndPrefix([]byte("abb"), []byte("a")))
+	require.Empty(keys)
+}
+
+// Test that iterating at a height returns the same keys and values as reading
+// each key at that height, before and after pruning.
+func TestReaderIteratorRandom(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		r          = rand.New(rand.NewSource(0)) // #nosec G404
+		db         = New(memdb.New())
+		numHeights = 50
+		// height -> key -> value, Nothing if deleted
+		history = make([]map[string]maybe.Maybe[[]byte], numHeights+1)
+	)
+	history[0] = map[string]maybe.Maybe[[]byte]{}
+	for height := 1; height <= numHeights; height++ {
+		state := make(map[string]maybe.Maybe[[]byte], len(history[height-1]))
+		for key, value := range history[height-1] {
+			state[key] = value
+		}
+
+		batch := db.NewBatch(uint64(height))
+		for i := 0; i < 5; i++ {
+			key := make([]byte, r.Intn(3))
+			_, _ = r.Read(key)
+			if r.Intn(4) == 0 {
+				require.NoError(batch.Delete(key))
+				state[string(key)] = maybe.Nothing[[]byte]()
+				continue
+			}
+			value := make([]byte, r.Intn(4))
+			_, _ = r.Read(value)
+			require.NoError(batch.Put(key, value))
+			state[string(key)] = maybe.Some(value)
+		}
+		require.NoError(batch.Write())
+		history[height] = state
+	}
+
+	checkHeight := func(height int) {
+		state := history[height]
+		prefix := make([]byte, r.Intn(2))
+		_, _ = r.Read(prefix)
+		start := make([]byte, r.Intn(3))
+		_, _ = r.Read(start)
+
+		var expectedKeys, expectedValues [][]byte
+		for key, value := range state {
+			if value.IsNothing() || !bytes.HasPrefix([]byte(key), prefix) || key < string(start) {
+				continue
+			}
+			expectedKeys = append(expectedKeys, []byte(key))
+		}
+		slices.SortFunc(expectedKeys, func(a, b []byte) bool {
+			return bytes.Compare(a, b) < 0
+		})
+		for _, key := range expectedKeys {
+			expectedValues = append(expectedValues, state[string(key)].Value())
+		}
+
+		keys, values := iterate(require, db.Open(uint64(height)).NewIteratorWithStartAndPrefix(start, prefix))
+		require.Equal(expectedKeys, keys)
+		require.Equal(expectedValues, values)
+	}
+
+	for height := range history {
+		checkHeight(height)
+	}
+
+	prunedHeight := numHeights / 2
+	require.NoError(db.Prune(uint64(prunedHeight)))
+	for height := prunedHeight; height <= numHeights; height++ {
+		checkHeight(height)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db := New(baseDB)
+
+	batch := db.NewBatch(1)
+	require.NoError(batch.Put([]byte("key1"), []byte("value1@1")))
+	require.NoError(batch.Put([]byte("key2"), []byte("value2@1")))
+	require.NoError(batch.Put([]byte("key3"), []byte("value3@1")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(2)
+	require.NoError(batch.Put([]byte("key1"), []byte("value1@2")))
+	require.NoError(batch.Delete([]byte("key2")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(3)
+	require.NoError(batch.Put([]byte("key1"), []byte("value1@3")))
+	require.NoError(batch.Put([]byte("key3"), []byte("value3@3")))
+	require.NoError(batch.Write())
+
+	prunedHeight, err := db.PrunedHeight()
+	require.NoError(err)
+	require.Zero(prunedHeight)
+
+	require.NoError(db.Prune(2))
+
+	prunedHeight, err = db.PrunedHeight()
+	require.NoError(err)
+	require.Equal(uint64(2), prunedHeight)
+
+	// Reading below the pruned height fails.
+	_, err = db.Open(1).Get([]byte("key1"))
+	require.ErrorIs(err, ErrPruned)
+	it := db.Open(1).NewIterator()
+	require.False(it.Next())
+	require.ErrorIs(it.Error(), ErrPruned)
+	it.Release()
+
+	// Reading at or above the pruned height is unchanged.
+	value, height, exists, err := db.Open(2).GetEntry([]byte("key1"))
+	require.NoError(err)
+	require.True(exists)
+	require.Equal(uint64(2), height)
+	require.Equal([]byte("value1@2"), value)
+
+	_, err = db.Open(2).Get([]byte("key2"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	value, err = db.Open(2).Get([]byte("key3"))
+	require.NoError(err)
+	require.Equal([]byte("value3@1"), value)
+
+	keys, values := iterate(require, db.Open(3).NewIterator())
+	require.Equal([][]byte{[]byte("key1"), []byte("key3")}, keys)
+	require.Equal([][]byte{[]byte("value1@3"), []byte("value3@3")}, values)
+
+	// Only the versions needed at heights >= 2 remain.
+	var remaining [][]byte
+	for _, entry := range []struct {
+		key    string
+		height uint64
+	}{
+		{key: "key1", height: 1},
+		{key: "key1", height: 2},
+		{key: "key1", height: 3},
+		{key: "key2", height: 1},
+		{key: "key2", height: 2},
+		{key: "key3", height: 1},
+		{key: "key3", height: 3},
+	} {
+		dbKey, _ := newDBKey([]byte(entry.key), entry.height)
+		has, err := baseDB.Has(dbKey)
+		require.NoError(err)
+		if has {
+			remaining = append(remaining, dbKey)
+		}
+	}
+	key1At2, _ := newDBKey([]byte("key1"), 2)
+	key1At3, _ := newDBKey([]byte("key1"), 3)
+	key3At1, _ := newDBKey([]byte("key3"), 1)
+	key3At3, _ := newDBKey([]byte("key3"), 3)
+	require.Equal([][]byte{key1At2, key1At3, key3At1, key3At3}, remaining)
+
+	// Pruning below the pruned height is a no-op.
+	require.NoError(db.Prune(1))
+	prunedHeight, err = db.PrunedHeight()
+	require.NoError(err)
+	require.Equal(uint64(2), prunedHeight)
+
+	// The pruned height is persisted.
+	db = New(baseDB)
+	prunedHeight, err = db.PrunedHeight()
+	require.NoError(err)
+	require.Equal(uint64(2), prunedHeight)
+}
@@ -0,0 +1,208 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package archivedb
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+var _ database.Iterator = (*iterator)(nil)
+
+// iterator iterates over the user keys as they were at a height.
+//
+// Database keys are sorted by the length of their user key before the user key
+// itself, so the user keys of each length are iterated over separately and
+// merged to return them in lexicographic order.
+type iterator struct {
+	// Iterators over the user keys of each length that haven't been
+	// exhausted.
+	iterators []*keyLengthIterator
+
+	key   []byte
+	value []byte
+	err   error
+}
+
+func newIterator(r *Reader, start, prefix []byte) (*iterator, error) {
+	keyLens, err := r.db.keyLengths()
+	if err != nil {
+		return nil, err
+	}
+
+	it := &iterator{}
+	for _, keyLen := range keyLens {
+		if keyLen < uint64(len(prefix)) {
+			// No keys of this length can have [prefix].
+			continue
+		}
+		keyLenPrefix := newKeyLengthPrefix(keyLen)
+		dbStart := start
+		if uint64(len(dbStart)) > keyLen {
+			dbStart = dbStart[:keyLen]
+		}
+		keyIt := &keyLengthIterator{
+			it: r.db.db.NewIteratorWithStartAndPrefix(
+				append(slices.Clone(keyLenPrefix), dbStart...),
+				append(keyLenPrefix, prefix...),
+			),
+			height: r.height,
+			start:  start,
+		}
+		if keyIt.Next() {
+			it.iterators = append(it.iterators, keyIt)
+			continue
+		}
+		keyIt.Release()
+		if keyIt.err != nil {
+			it.Release()
+			return nil, keyIt.err
+		}
+	}
+	return it, nil
+}
+
+func (it *iterator) Next() bool {
+	if it.err != nil || len(it.iterators) == 0 {
+		it.key = nil
+		it.value = nil
+		return false
+	}
+
+	// User keys of different lengths are never equal, so there's a unique
+	// smallest key.
+	minIndex := 0
+	for i, keyIt := range it.iterators[1:] {
+		if bytes.Compare(keyIt.key, it.iterators[minIndex].key) < 0 {
+			minIndex = i + 1
+		}
+	}
+
+	minIt := it.iterators[minIndex]
+	it.key = minIt.key
+	it.value = minIt.value
+	if !minIt.Next() {
+		minIt.Release()
+		it.iterators = slices.Delete(it.iterators, minIndex, minIndex+1)
+		if minIt.err != nil {
+			it.err = minIt.err
+		}
+	}
+	return true
+}
+
+func (it *iterator) Error() error {
+	return it.err
+}
+
+func (it *iterator) Key() []byte {
+	return it.key
+}
+
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+func (it *iterator) Release() {
+	for _, keyIt := range it.iterators {
+		keyIt.Release()
+	}
+	it.iterators = nil
+}
+
+// keyLengthIterator iterates over the user keys of a single length as they were
+// at [height].
+type keyLengthIterator struct {
+	it     database.Iterator
+	height uint64
+	// User keys less than [start] are skipped.
+	start []byte
+
+	// The last user key whose latest version at [height] was handled.
+	lastKey    []byte
+	hasLastKey bool
+
+	// The current user key and its value at [height].
+	key   []byte
+	value []byte
+	err   error
+}
+
+// Next moves to the next user key that had a value at [it.height].
+func (it *keyLengthIterator) Next() bool {
+	for it.it.Next() {
+		key, height, err := parseDBKey(it.it.Key())
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if height > it.height ||
+			(it.hasLastKey && bytes.Equal(key, it.lastKey)) ||
+			bytes.Compare(key, it.start) < 0 {
+			// This version was written after [it.height], it's older than
+			// the version of the key we already handled, or the key is
+			// before [it.start].
+			continue
+		}
+
+		// This is the latest version of [key] at [it.height].
+		it.lastKey = slices.Clone(key)
+		it.hasLastKey = true
+		value, exists := parseDBValue(it.it.Value())
+		if !exists {
+			// [key] was deleted at [it.height].
+			continue
+		}
+		it.key = it.lastKey
+		it.value = slices.Clone(value)
+		return true
+	}
+	it.err = it.it.Error()
+	return false
+}
+
+func (it *keyLengthIterator) Release() {
+	it.it.Release()
+}
+
+// keyLengths returns the lengths of all the user keys in the database, in the
+// order their database keys are sorted.
+func (db *Database) keyLengths() ([]uint64, error) {
+	var (
+		keyLens []uint64
+		start   = entryPrefix
+	)
+	for {
+		keyLen, ok, err := db.nextKeyLength(start)
+		if err != nil || !ok {
+			return keyLens, err
+		}
+		keyLens = append(keyLens, keyLen)
+
+		// Skip past the database keys of all user keys of this length. The
+		// last byte of a uvarint is less than 0x80, so this can't overflow.
+		start = newKeyLengthPrefix(keyLen)
+		start[len(start)-1]++
+	}
+}
+
+// nextKeyLength returns the length of the user key of the first database key
+// that is >= [start].
+func (db *Database) nextKeyLength(start []byte) (uint64, bool, error) {
+	it := db.db.NewIteratorWithStartAndPrefix(start, entryPrefix)
+	defer it.Release()
+
+	if !it.Next() {
+		return 0, false, it.Error()
+	}
+	keyLen, offset := binary.Uvarint(it.Key()[len(entryPrefix):])
+	if offset <= 0 {
+		return 0, false, ErrParsingKeyLength
+	}
+	return keyLen, true, nil
+}
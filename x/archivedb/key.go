@@ -15,7 +15,11 @@ var (
 	ErrParsingKeyLength   = errors.New("failed reading key length")
 	ErrIncorrectKeyLength = errors.New("incorrect key length")
 
-	heightKey = []byte{1}
+	heightKey       = []byte{1}
+	prunedHeightKey = []byte{2}
+
+	// All database keys of user keys start with [entryPrefix].
+	entryPrefix = []byte{0}
 )
 
 // newDBKey converts a user formatted key and a height into a database formatted
@@ -58,6 +62,16 @@ func newDBKey(key []byte, height uint64) ([]byte, []byte) {
 	return dbKey[:offset], dbKey[:prefixOffset]
 }
 
+// newKeyLengthPrefix returns the prefix shared by the database keys of all user
+// keys of length [keyLen]. Since uvarints are prefix free, no other database
+// key has this prefix.
+func newKeyLengthPrefix(keyLen uint64) []byte {
+	prefix := make([]byte, 1+binary.MaxVarintLen64)
+	offset := 1
+	offset += binary.PutUvarint(prefix[offset:], keyLen)
+	return prefix[:offset]
+}
+
 // parseDBKey takes a database formatted key and returns the user formatted key
 // along with its height.
 //
@@ -5,7 +5,10 @@ package archivedb
 
 import "github.com/ava-labs/avalanchego/database"
 
-var _ database.KeyValueReader = (*Reader)(nil)
+var (
+	_ database.KeyValueReader = (*Reader)(nil)
+	_ database.Iteratee       = (*Reader)(nil)
+)
 
 type Reader struct {
 	db     *Database
@@ -35,6 +38,10 @@ func (r *Reader) Get(key []byte) ([]byte, error) {
 // modified at, and a boolean to indicate if the last modification was an
 // insertion. If the key has never been modified, ErrNotFound will be returned.
 func (r *Reader) GetEntry(key []byte) ([]byte, uint64, bool, error) {
+	if err := r.checkPruned(); err != nil {
+		return nil, 0, false, err
+	}
+
 	it := r.db.db.NewIteratorWithStartAndPrefix(newDBKey(key, r.height))
 	defer it.Release()
 
@@ -59,3 +66,47 @@ func (r *Reader) GetEntry(key []byte) ([]byte, uint64, bool, error) {
 	}
 	return value, height, true, nil
 }
+
+func (r *Reader) NewIterator() database.Iterator {
+	return r.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (r *Reader) NewIteratorWithStart(start []byte) database.Iterator {
+	return r.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (r *Reader) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return r.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix returns an iterator over the keys >= [start]
+// with [prefix], in lexicographic order, as they were at the reader's height.
+// Each key's value is the latest one written at or below the reader's height.
+// Keys that were deleted at or below the reader's height are skipped.
+func (r *Reader) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	if err := r.checkPruned(); err != nil {
+		return &database.IteratorError{
+			Err: err,
+		}
+	}
+
+	it, err := newIterator(r, start, prefix)
+	if err != nil {
+		return &database.IteratorError{
+			Err: err,
+		}
+	}
+	return it
+}
+
+// Returns ErrPruned if the state at the reader's height has been pruned.
+func (r *Reader) checkPruned() error {
+	prunedHeight, err := r.db.PrunedHeight()
+	if err != nil {
+		return err
+	}
+	if r.height < prunedHeight {
+		return ErrPruned
+	}
+	return nil
+}
@@ -7,12 +7,14 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
-	"fmt"
 	"io"
 	"math"
 	"sync"
 
+	"golang.org/x/exp/maps"
+
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 )
 
@@ -26,6 +28,11 @@ const (
 	minByteSliceLen      = minVarIntLen
 	minDBNodeLen         = minMaybeByteSliceLen + minVarIntLen
 	minChildLen          = minVarIntLen + minSerializedPathLen + ids.IDLen + boolLen
+	minHistoryRecordLen  = ids.IDLen + minVarIntLen
+	minValueChangeLen    = minSerializedPathLen + 2*minMaybeByteSliceLen
+	minProofNodeLen      = minSerializedPathLen + minMaybeByteSliceLen + minVarIntLen
+	minMultiProofLen     = 4 * minVarIntLen
+	minMultiProofKeyLen  = minByteSliceLen + minMaybeByteSliceLen
 
 	estimatedKeyLen            = 64
 	estimatedValueLen          = 64
@@ -42,13 +49,14 @@ var (
 	trueBytes  = []byte{trueByte}
 	falseBytes = []byte{falseByte}
 
-	errTooManyChildren      = fmt.Errorf("length of children list is larger than branching factor of %d", NodeBranchFactor)
-	errChildIndexTooLarge   = fmt.Errorf("invalid child index. Must be less than branching factor of %d", NodeBranchFactor)
+	errTooManyChildren      = errors.New("length of children list is larger than branching factor")
+	errChildIndexTooLarge   = errors.New("invalid child index. Must be less than branching factor")
 	errLeadingZeroes        = errors.New("varint has leading zeroes")
 	errInvalidBool          = errors.New("decoded bool is neither true nor false")
 	errNonZeroNibblePadding = errors.New("nibbles should be padded with 0s")
 	errExtraSpace           = errors.New("trailing buffer space")
 	errIntOverflow          = errors.New("value overflows int")
+	errNonIncreasingKeys    = errors.New("keys aren't in strictly increasing order")
 )
 
 // encoderDecoder defines the interface needed by merkleDB to marshal
@@ -59,15 +67,23 @@ type encoderDecoder interface {
 }
 
 type encoder interface {
-	// Assumes [n] is non-nil.
-	encodeDBNode(n *dbNode) []byte
-	// Assumes [hv] is non-nil.
-	encodeHashValues(hv *hashValues) []byte
+	// Assumes [n] is non-nil and [bf] is valid.
+	encodeDBNode(n *dbNode, bf BranchFactor) []byte
+	// Assumes [hv] is non-nil and [bf] is valid.
+	encodeHashValues(hv *hashValues, bf BranchFactor) []byte
+	// Assumes [r] is non-nil and [bf] is valid.
+	encodeHistoryRecord(r *historyRecord, bf BranchFactor) []byte
+	// Assumes [p] is non-nil.
+	encodeMultiProof(p *MultiProof) []byte
 }
 
 type decoder interface {
-	// Assumes [n] is non-nil.
-	decodeDBNode(bytes []byte, n *dbNode) error
+	// Assumes [n] is non-nil and [bf] is valid.
+	decodeDBNode(bytes []byte, n *dbNode, bf BranchFactor) error
+	// Assumes [r] is non-nil and [bf] is valid.
+	decodeHistoryRecord(bytes []byte, r *historyRecord, bf BranchFactor) error
+	// Assumes [p] is non-nil.
+	decodeMultiProof(bytes []byte, p *MultiProof) error
 }
 
 func newCodec() encoderDecoder {
@@ -88,7 +104,7 @@ type codecImpl struct {
 	varIntPool sync.Pool
 }
 
-func (c *codecImpl) encodeDBNode(n *dbNode) []byte {
+func (c *codecImpl) encodeDBNode(n *dbNode, bf BranchFactor) []byte {
 	var (
 		numChildren = len(n.children)
 		// Estimate size of [n] to prevent memory allocations
@@ -100,10 +116,10 @@ func (c *codecImpl) encodeDBNode(n *dbNode) []byte {
 	c.encodeUint(buf, uint64(numChildren))
 	// Note we insert children in order of increasing index
 	// for determinism.
-	for index := byte(0); index < NodeBranchFactor; index++ {
-		if entry, ok := n.children[index]; ok {
+	for index := 0; index < int(bf); index++ {
+		if entry, ok := n.children[byte(index)]; ok {
 			c.encodeUint(buf, uint64(index))
-			path := entry.compressedPath.Serialize()
+			path := bf.serialize(entry.compressedPath)
 			c.encodeSerializedPath(buf, path)
 			_, _ = buf.Write(entry.id[:])
 			c.encodeBool(buf, entry.hasValue)
@@ -112,7 +128,7 @@ func (c *codecImpl) encodeDBNode(n *dbNode) []byte {
 	return buf.Bytes()
 }
 
-func (c *codecImpl) encodeHashValues(hv *hashValues) []byte {
+func (c *codecImpl) encodeHashValues(hv *hashValues, bf BranchFactor) []byte {
 	var (
 		numChildren = len(hv.Children)
 		// Estimate size [hv] to prevent memory allocations
@@ -123,8 +139,8 @@ func (c *codecImpl) encodeHashValues(hv *hashValues) []byte {
 	c.encodeUint(buf, uint64(numChildren))
 
 	// ensure that the order of entries is consistent
-	for index := byte(0); index < NodeBranchFactor; index++ {
-		if entry, ok := hv.Children[index]; ok {
+	for index := 0; index < int(bf); index++ {
+		if entry, ok := hv.Children[byte(index)]; ok {
 			c.encodeUint(buf, uint64(index))
 			_, _ = buf.Write(entry.id[:])
 		}
@@ -135,7 +151,7 @@ func (c *codecImpl) encodeHashValues(hv *hashValues) []byte {
 	return buf.Bytes()
 }
 
-func (c *codecImpl) decodeDBNode(b []byte, n *dbNode) error {
+func (c *codecImpl) decodeDBNode(b []byte, n *dbNode, bf BranchFactor) error {
 	if minDBNodeLen > len(b) {
 		return io.ErrUnexpectedEOF
 	}
@@ -152,25 +168,25 @@ func (c *codecImpl) decodeDBNode(b []byte, n *dbNode) error {
 	switch {
 	case err != nil:
 		return err
-	case numChildren > NodeBranchFactor:
+	case numChildren > uint64(bf):
 		return errTooManyChildren
 	case numChildren > uint64(src.Len()/minChildLen):
 		return io.ErrUnexpectedEOF
 	}
 
-	n.children = make(map[byte]child, NodeBranchFactor)
+	n.children = make(map[byte]child, bf)
 	var previousChild uint64
 	for i := uint64(0); i < numChildren; i++ {
 		index, err := c.decodeUint(src)
 		if err != nil {
 			return err
 		}
-		if index >= NodeBranchFactor || (i != 0 && index <= previousChild) {
+		if index >= uint64(bf) || (i != 0 && index <= previousChild) {
 			return errChildIndexTooLarge
 		}
 		previousChild = index
 
-		compressedPath, err := c.decodeSerializedPath(src)
+		compressedPath, err := c.decodeSerializedPath(src, bf)
 		if err != nil {
 			return err
 		}
@@ -183,7 +199,7 @@ func (c *codecImpl) decodeDBNode(b []byte, n *dbNode) error {
 			return err
 		}
 		n.children[byte(index)] = child{
-			compressedPath: compressedPath.deserialize(),
+			compressedPath: bf.deserialize(compressedPath),
 			id:             childID,
 			hasValue:       hasValue,
 		}
@@ -194,6 +210,254 @@ func (c *codecImpl) decodeDBNode(b []byte, n *dbNode) error {
 	return nil
 }
 
+func (c *codecImpl) encodeHistoryRecord(r *historyRecord, bf BranchFactor) []byte {
+	var (
+		numValues = len(r.values)
+		// Estimate size of [r] to prevent memory allocations
+		estimatedLen = ids.IDLen + minVarIntLen + numValues*(estimatedKeyLen+2*estimatedValueLen)
+		buf          = bytes.NewBuffer(make([]byte, 0, estimatedLen))
+	)
+
+	_, _ = buf.Write(r.rootID[:])
+	c.encodeUint(buf, uint64(numValues))
+
+	// Note we insert values in order of increasing key for determinism.
+	keys := maps.Keys(r.values)
+	utils.Sort(keys)
+	for _, key := range keys {
+		valueChange := r.values[key]
+		c.encodeSerializedPath(buf, bf.serialize(key))
+		c.encodeMaybeByteSlice(buf, valueChange.before)
+		c.encodeMaybeByteSlice(buf, valueChange.after)
+	}
+	return buf.Bytes()
+}
+
+func (c *codecImpl) decodeHistoryRecord(b []byte, r *historyRecord, bf BranchFactor) error {
+	if minHistoryRecordLen > len(b) {
+		return io.ErrUnexpectedEOF
+	}
+
+	src := bytes.NewReader(b)
+
+	rootID, err := c.decodeID(src)
+	if err != nil {
+		return err
+	}
+	r.rootID = rootID
+
+	numValues, err := c.decodeUint(src)
+	switch {
+	case err != nil:
+		return err
+	case numValues > uint64(src.Len()/minValueChangeLen):
+		return io.ErrUnexpectedEOF
+	}
+
+	r.values = make(map[path]*change[maybe.Maybe[[]byte]], numValues)
+	var previousKey path
+	for i := uint64(0); i < numValues; i++ {
+		serializedKey, err := c.decodeSerializedPath(src, bf)
+		if err != nil {
+			return err
+		}
+		key := bf.deserialize(serializedKey)
+		if i != 0 && key.Compare(previousKey) <= 0 {
+			return errNonIncreasingKeys
+		}
+		previousKey = key
+
+		before, err := c.decodeMaybeByteSlice(src)
+		if err != nil {
+			return err
+		}
+		after, err := c.decodeMaybeByteSlice(src)
+		if err != nil {
+			return err
+		}
+		r.values[key] = &change[maybe.Maybe[[]byte]]{
+			before: before,
+			after:  after,
+		}
+	}
+	if src.Len() != 0 {
+		return errExtraSpace
+	}
+	return nil
+}
+
+func (c *codecImpl) encodeMultiProof(p *MultiProof) []byte {
+	var (
+		bf       = p.BranchFactor.orDefault()
+		numNodes = len(p.Nodes)
+		numKeys  = len(p.Keys)
+		// Estimate size of [p] to prevent memory allocations
+		estimatedLen = minMultiProofLen +
+			numNodes*(estimatedKeyLen+estimatedValueLen+minVarIntLen+int(bf)*hashValuesChildLen) +
+			numKeys*(estimatedKeyLen+estimatedValueLen)
+		buf = bytes.NewBuffer(make([]byte, 0, estimatedLen))
+	)
+
+	c.encodeUint(buf, uint64(bf))
+	c.encodeUint(buf, uint64(hasherID(p.Hasher)))
+
+	c.encodeUint(buf, uint64(numNodes))
+	for i := range p.Nodes {
+		c.encodeProofNode(buf, &p.Nodes[i], bf)
+	}
+
+	c.encodeUint(buf, uint64(numKeys))
+	for i, key := range p.Keys {
+		c.encodeByteSlice(buf, key)
+		c.encodeMaybeByteSlice(buf, p.Values[i])
+	}
+	return buf.Bytes()
+}
+
+func (c *codecImpl) decodeMultiProof(b []byte, p *MultiProof) error {
+	if minMultiProofLen > len(b) {
+		return io.ErrUnexpectedEOF
+	}
+
+	src := bytes.NewReader(b)
+
+	branchFactor, err := c.decodeUint(src)
+	if err != nil {
+		return err
+	}
+	if branchFactor > math.MaxInt {
+		return errIntOverflow
+	}
+	bf := BranchFactor(branchFactor)
+	if err := bf.Valid(); err != nil {
+		return err
+	}
+	p.BranchFactor = bf
+
+	rawHasherID, err := c.decodeUint(src)
+	if err != nil {
+		return err
+	}
+	if rawHasherID > math.MaxUint32 {
+		return errIntOverflow
+	}
+	hasher, err := hasherFromID(HasherID(rawHasherID))
+	if err != nil {
+		return err
+	}
+	p.Hasher = hasher
+
+	numNodes, err := c.decodeUint(src)
+	switch {
+	case err != nil:
+		return err
+	case numNodes > uint64(src.Len()/minProofNodeLen):
+		return io.ErrUnexpectedEOF
+	}
+
+	p.Nodes = make([]ProofNode, numNodes)
+	for i := range p.Nodes {
+		if err := c.decodeProofNode(src, &p.Nodes[i], bf); err != nil {
+			return err
+		}
+	}
+
+	numKeys, err := c.decodeUint(src)
+	switch {
+	case err != nil:
+		return err
+	case numKeys > uint64(src.Len()/minMultiProofKeyLen):
+		return io.ErrUnexpectedEOF
+	}
+
+	p.Keys = make([][]byte, numKeys)
+	p.Values = make([]maybe.Maybe[[]byte], numKeys)
+	for i := range p.Keys {
+		key, err := c.decodeByteSlice(src)
+		if err != nil {
+			return err
+		}
+		if i != 0 && bytes.Compare(key, p.Keys[i-1]) <= 0 {
+			return errNonIncreasingKeys
+		}
+		p.Keys[i] = key
+
+		value, err := c.decodeMaybeByteSlice(src)
+		if err != nil {
+			return err
+		}
+		p.Values[i] = value
+	}
+	if src.Len() != 0 {
+		return errExtraSpace
+	}
+	return nil
+}
+
+// Assumes [n] is non-nil and [bf] is valid.
+func (c *codecImpl) encodeProofNode(dst *bytes.Buffer, n *ProofNode, bf BranchFactor) {
+	c.encodeSerializedPath(dst, n.KeyPath)
+	c.encodeMaybeByteSlice(dst, n.ValueOrHash)
+	c.encodeUint(dst, uint64(len(n.Children)))
+
+	// ensure that the order of entries is consistent
+	for index := 0; index < int(bf); index++ {
+		if childID, ok := n.Children[byte(index)]; ok {
+			c.encodeUint(dst, uint64(index))
+			_, _ = dst.Write(childID[:])
+		}
+	}
+}
+
+// Assumes [n] is non-nil and [bf] is valid.
+func (c *codecImpl) decodeProofNode(src *bytes.Reader, n *ProofNode, bf BranchFactor) error {
+	if minProofNodeLen > src.Len() {
+		return io.ErrUnexpectedEOF
+	}
+
+	keyPath, err := c.decodeSerializedPath(src, bf)
+	if err != nil {
+		return err
+	}
+	n.KeyPath = keyPath
+
+	valueOrHash, err := c.decodeMaybeByteSlice(src)
+	if err != nil {
+		return err
+	}
+	n.ValueOrHash = valueOrHash
+
+	numChildren, err := c.decodeUint(src)
+	switch {
+	case err != nil:
+		return err
+	case numChildren > uint64(bf):
+		return errTooManyChildren
+	case numChildren > uint64(src.Len()/hashValuesChildLen):
+		return io.ErrUnexpectedEOF
+	}
+
+	n.Children = make(map[byte]ids.ID, numChildren)
+	var previousChild uint64
+	for i := uint64(0); i < numChildren; i++ {
+		index, err := c.decodeUint(src)
+		if err != nil {
+			return err
+		}
+		if index >= uint64(bf) || (i != 0 && index <= previousChild) {
+			return errChildIndexTooLarge
+		}
+		previousChild = index
+
+		childID, err := c.decodeID(src)
+		if err != nil {
+			return err
+		}
+		n.Children[byte(index)] = childID
+	}
+	return nil
+}
+
 func (*codecImpl) encodeBool(dst *bytes.Buffer, value bool) {
 	bytesValue := falseBytes
 	if value {
@@ -333,7 +597,8 @@ func (c *codecImpl) encodeSerializedPath(dst *bytes.Buffer, s SerializedPath) {
 	_, _ = dst.Write(s.Value)
 }
 
-func (c *codecImpl) decodeSerializedPath(src *bytes.Reader) (SerializedPath, error) {
+// Assumes [bf] is valid.
+func (c *codecImpl) decodeSerializedPath(src *bytes.Reader, bf BranchFactor) (SerializedPath, error) {
 	if minSerializedPathLen > src.Len() {
 		return SerializedPath{}, io.ErrUnexpectedEOF
 	}
@@ -349,9 +614,10 @@ func (c *codecImpl) decodeSerializedPath(src *bytes.Reader) (SerializedPath, err
 	result := SerializedPath{
 		NibbleLength: int(nibbleLength),
 	}
-	pathBytesLen := result.NibbleLength >> 1
-	hasOddLen := result.hasOddLength()
-	if hasOddLen {
+	tokensPerByte := bf.tokensPerByte()
+	pathBytesLen := result.NibbleLength / tokensPerByte
+	hasPartialByte := bf.hasPartialByte(result.NibbleLength)
+	if hasPartialByte {
 		pathBytesLen++
 	}
 	if pathBytesLen > src.Len() {
@@ -364,9 +630,10 @@ func (c *codecImpl) decodeSerializedPath(src *bytes.Reader) (SerializedPath, err
 		}
 		return SerializedPath{}, err
 	}
-	if hasOddLen {
-		paddedNibble := result.Value[pathBytesLen-1] & 0x0F
-		if paddedNibble != 0 {
+	if hasPartialByte {
+		paddingBits := bf.tokenBitSize() * (tokensPerByte - result.NibbleLength%tokensPerByte)
+		paddingMask := byte(1<<paddingBits - 1)
+		if result.Value[pathBytesLen-1]&paddingMask != 0 {
 			return SerializedPath{}, errNonZeroNibblePadding
 		}
 	}
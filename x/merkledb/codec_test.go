@@ -82,7 +82,7 @@ func FuzzCodecSerializedPath(f *testing.F) {
 			codec := codec.(*codecImpl)
 			reader := bytes.NewReader(b)
 			startLen := reader.Len()
-			got, err := codec.decodeSerializedPath(reader)
+			got, err := codec.decodeSerializedPath(reader, BranchFactor16)
 			if err != nil {
 				t.SkipNow()
 			}
@@ -112,12 +112,12 @@ func FuzzCodecDBNodeCanonical(f *testing.F) {
 
 			codec := codec.(*codecImpl)
 			node := &dbNode{}
-			if err := codec.decodeDBNode(b, node); err != nil {
+			if err := codec.decodeDBNode(b, node, BranchFactor16); err != nil {
 				t.SkipNow()
 			}
 
 			// Encoding [node] should be the same as [b].
-			buf := codec.encodeDBNode(node)
+			buf := codec.encodeDBNode(node, BranchFactor16)
 			require.Equal(b, buf)
 		},
 	)
@@ -167,13 +167,13 @@ func FuzzCodecDBNodeDeterministic(f *testing.F) {
 				children: children,
 			}
 
-			nodeBytes := codec.encodeDBNode(&node)
+			nodeBytes := codec.encodeDBNode(&node, BranchFactor16)
 
 			var gotNode dbNode
-			require.NoError(codec.decodeDBNode(nodeBytes, &gotNode))
+			require.NoError(codec.decodeDBNode(nodeBytes, &gotNode, BranchFactor16))
 			require.Equal(node, gotNode)
 
-			nodeBytes2 := codec.encodeDBNode(&gotNode)
+			nodeBytes2 := codec.encodeDBNode(&gotNode, BranchFactor16)
 			require.Equal(nodeBytes, nodeBytes2)
 		},
 	)
@@ -186,7 +186,7 @@ func TestCodecDecodeDBNode(t *testing.T) {
 		parsedDBNode  dbNode
 		tooShortBytes = make([]byte, minDBNodeLen-1)
 	)
-	err := codec.decodeDBNode(tooShortBytes, &parsedDBNode)
+	err := codec.decodeDBNode(tooShortBytes, &parsedDBNode, BranchFactor16)
 	require.ErrorIs(err, io.ErrUnexpectedEOF)
 
 	proof := dbNode{
@@ -194,7 +194,7 @@ func TestCodecDecodeDBNode(t *testing.T) {
 		children: map[byte]child{},
 	}
 
-	nodeBytes := codec.encodeDBNode(&proof)
+	nodeBytes := codec.encodeDBNode(&proof, BranchFactor16)
 	// Remove num children (0) from end
 	nodeBytes = nodeBytes[:len(nodeBytes)-minVarIntLen]
 	proofBytesBuf := bytes.NewBuffer(nodeBytes)
@@ -202,7 +202,7 @@ func TestCodecDecodeDBNode(t *testing.T) {
 	// Put num children NodeBranchFactor+1 at end
 	codec.(*codecImpl).encodeUint(proofBytesBuf, NodeBranchFactor+1)
 
-	err = codec.decodeDBNode(proofBytesBuf.Bytes(), &parsedDBNode)
+	err = codec.decodeDBNode(proofBytesBuf.Bytes(), &parsedDBNode, BranchFactor16)
 	require.ErrorIs(err, errTooManyChildren)
 }
 
@@ -253,11 +253,87 @@ func FuzzEncodeHashValues(f *testing.F) {
 			}
 
 			// Serialize the *hashValues with both codecs
-			hvBytes1 := codec1.encodeHashValues(hv)
-			hvBytes2 := codec2.encodeHashValues(hv)
+			hvBytes1 := codec1.encodeHashValues(hv, BranchFactor16)
+			hvBytes2 := codec2.encodeHashValues(hv, BranchFactor16)
 
 			// Make sure they're the same
 			require.Equal(hvBytes1, hvBytes2)
 		},
 	)
 }
+
+func TestCodecHistoryRecord(t *testing.T) {
+	require := require.New(t)
+
+	record := &historyRecord{
+		rootID: ids.GenerateTestID(),
+		values: map[path]*change[maybe.Maybe[[]byte]]{
+			newPath([]byte{1}): {
+				before: maybe.Nothing[[]byte](),
+				after:  maybe.Some([]byte{2}),
+			},
+			newPath([]byte{0, 1}): {
+				before: maybe.Some([]byte{}),
+				after:  maybe.Nothing[[]byte](),
+			},
+		},
+	}
+
+	recordBytes := codec.encodeHistoryRecord(record, BranchFactor16)
+
+	var got historyRecord
+	require.NoError(codec.decodeHistoryRecord(recordBytes, &got, BranchFactor16))
+	require.Equal(record.rootID, got.rootID)
+	require.Len(got.values, len(record.values))
+	for key, valueChange := range record.values {
+		gotChange, ok := got.values[key]
+		require.True(ok)
+		require.Equal(valueChange.before.HasValue(), gotChange.before.HasValue())
+		require.Equal(valueChange.after.HasValue(), gotChange.after.HasValue())
+		require.Equal(len(valueChange.after.Value()), len(gotChange.after.Value()))
+	}
+
+	// Trailing bytes aren't allowed.
+	err := codec.decodeHistoryRecord(append(recordBytes, 0), &got, BranchFactor16)
+	require.ErrorIs(err, errExtraSpace)
+}
+
+func TestCodecMultiProof(t *testing.T) {
+	require := require.New(t)
+
+	proof := &MultiProof{
+		Nodes: []ProofNode{
+			{
+				KeyPath:     newPath(nil).Serialize(),
+				ValueOrHash: maybe.Nothing[[]byte](),
+				Children: map[byte]ids.ID{
+					0:  ids.GenerateTestID(),
+					15: ids.GenerateTestID(),
+				},
+			},
+			{
+				KeyPath:     newPath([]byte{1}).Serialize(),
+				ValueOrHash: maybe.Some([]byte{2}),
+				Children:    map[byte]ids.ID{},
+			},
+		},
+		Keys:         [][]byte{{1}, {1, 2}},
+		Values:       []maybe.Maybe[[]byte]{maybe.Some([]byte{2}), maybe.Nothing[[]byte]()},
+		BranchFactor: BranchFactor16,
+	}
+
+	proofBytes := codec.encodeMultiProof(proof)
+
+	var got MultiProof
+	require.NoError(codec.decodeMultiProof(proofBytes, &got))
+	require.Equal(proof, &got)
+
+	// Trailing bytes aren't allowed.
+	err := codec.decodeMultiProof(append(proofBytes, 0), &got)
+	require.ErrorIs(err, errExtraSpace)
+
+	// Keys must be sorted and unique.
+	proof.Keys[0], proof.Keys[1] = proof.Keys[1], proof.Keys[0]
+	err = codec.decodeMultiProof(codec.encodeMultiProof(proof), &got)
+	require.ErrorIs(err, errNonIncreasingKeys)
+}
@@ -49,10 +49,17 @@ var (
 	metadataPrefix         = []byte{0}
 	valueNodePrefix        = []byte{1}
 	intermediateNodePrefix = []byte{2}
+	historyPrefix          = []byte{3}
 
 	cleanShutdownKey        = []byte(string(metadataPrefix) + "cleanShutdown")
 	hadCleanShutdown        = []byte{1}
 	didNotHaveCleanShutdown = []byte{0}
+	branchFactorKey         = []byte(string(metadataPrefix) + "branchFactor")
+	hasherIDKey             = []byte(string(metadataPrefix) + "hasherID")
+
+	ErrBranchFactorChanged = errors.New("branch factor doesn't match the branch factor the database was created with")
+	ErrHasherChanged       = errors.New("hasher doesn't match the hasher the database was created with")
+	ErrPersistedHistory    = errors.New("database has persisted history but history persistence is disabled")
 
 	errSameRoot  = errors.New("start and end root are the same")
 	errNoNewRoot = errors.New("there was no updated root in change list")
@@ -121,6 +128,23 @@ type MerkleDB interface {
 	ProofGetter
 	ChangeProofer
 	RangeProofer
+
+	// NewViewAtRoot returns a new view on top of the trie as it was when it
+	// had root [rootID], where the passed changes have been applied.
+	// Returns [ErrInsufficientHistory] if this node has insufficient history
+	// to recreate the trie at [rootID].
+	//
+	// Committing the returned view reverts the database to the trie at
+	// [rootID] with the passed changes applied.
+	NewViewAtRoot(
+		ctx context.Context,
+		rootID ids.ID,
+		changes ViewChanges,
+	) (TrieView, error)
+
+	// Prune removes the intermediate nodes stored on disk that are no longer
+	// reachable from the root. It may be called while the database is in use.
+	Prune(ctx context.Context) error
 }
 
 type Config struct {
@@ -135,6 +159,30 @@ type Config struct {
 	// The number of changes to the database that we store in memory in order to
 	// serve change proofs.
 	HistoryLength uint
+	// The maximum number of children of each node in the trie.
+	// If 0, [DefaultBranchFactor] is used.
+	// Must not be changed once the database has been created.
+	BranchFactor BranchFactor
+	// The hasher used to calculate the IDs of nodes.
+	// If nil, [DefaultHasher] is used.
+	// Must not be changed once the database has been created.
+	Hasher Hasher
+	// If true, the changes made by every commit at or after
+	// [PersistHistoryStartHeight] are also stored on disk. This allows proofs
+	// and views to be generated for any root committed since then, even after
+	// the change has been evicted from the in-memory history or the database
+	// has been restarted.
+	// The height of the database is the number of commits made to it while
+	// [PersistHistory] was set. If [PersistHistory] isn't set and history was
+	// previously persisted, opening the database fails with
+	// [ErrPersistedHistory] unless [DeletePersistedHistory] is set.
+	PersistHistory bool
+	// The height of the first commit whose changes are stored on disk.
+	// Ignored if [PersistHistory] is false.
+	PersistHistoryStartHeight uint64
+	// If true, any previously persisted history is deleted when the database
+	// is opened. Ignored if [PersistHistory] is true.
+	DeletePersistedHistory bool
 	// The number of bytes to cache nodes with values.
 	ValueNodeCacheSize uint
 	// The number of bytes to cache nodes without values.
@@ -165,10 +213,19 @@ type merkleDB struct {
 	valueNodeDB        *valueNodeDB
 	intermediateNodeDB *intermediateNodeDB
 
+	// The branch factor and hasher of the trie.
+	branchFactor BranchFactor
+	hasher       Hasher
+
 	// Stores change lists. Used to serve change proofs and construct
 	// historical views of the trie.
 	history *trieHistory
 
+	// Stores the value changes of every commit on disk.
+	// Used to serve requests for roots not in [history].
+	// Nil if [Config.PersistHistory] is false.
+	diskHistory *diskHistory
+
 	// True iff the db has been closed.
 	closed bool
 
@@ -208,6 +265,12 @@ func newDatabase(
 		rootGenConcurrency = config.RootGenConcurrency
 	}
 
+	branchFactor := config.BranchFactor.orDefault()
+	if err := branchFactor.Valid(); err != nil {
+		return nil, err
+	}
+	hasher := hasherOrDefault(config.Hasher)
+
 	// Share a sync.Pool of []byte between the intermediateNodeDB and valueNodeDB
 	// to reduce memory allocations.
 	bufferPool := &sync.Pool{
@@ -218,15 +281,33 @@ func newDatabase(
 	trieDB := &merkleDB{
 		metrics:              metrics,
 		baseDB:               db,
-		valueNodeDB:          newValueNodeDB(db, bufferPool, metrics, int(config.ValueNodeCacheSize)),
-		intermediateNodeDB:   newIntermediateNodeDB(db, bufferPool, metrics, int(config.IntermediateNodeCacheSize), int(config.EvictionBatchSize)),
-		history:              newTrieHistory(int(config.HistoryLength)),
+		valueNodeDB:          newValueNodeDB(db, bufferPool, metrics, int(config.ValueNodeCacheSize), branchFactor, hasher),
+		intermediateNodeDB:   newIntermediateNodeDB(db, bufferPool, metrics, int(config.IntermediateNodeCacheSize), int(config.EvictionBatchSize), branchFactor, hasher),
+		branchFactor:         branchFactor,
+		hasher:               hasher,
+		history:              newTrieHistory(int(config.HistoryLength), branchFactor),
 		debugTracer:          getTracerIfEnabled(config.TraceLevel, DebugTrace, config.Tracer),
 		infoTracer:           getTracerIfEnabled(config.TraceLevel, InfoTrace, config.Tracer),
 		childViews:           make([]*trieView, 0, defaultPreallocationSize),
 		calculateNodeIDsSema: semaphore.NewWeighted(int64(rootGenConcurrency)),
 	}
 
+	if err := trieDB.verifyMetadata(); err != nil {
+		return nil, err
+	}
+
+	// Persisted history is only deleted when explicitly requested, so that
+	// opening the database with the wrong config doesn't lose it.
+	if !config.PersistHistory && !config.DeletePersistedHistory {
+		hasHistory, err := hasPersistedHistory(db)
+		if err != nil {
+			return nil, err
+		}
+		if hasHistory {
+			return nil, ErrPersistedHistory
+		}
+	}
+
 	root, err := trieDB.initializeRootIfNeeded()
 	if err != nil {
 		return nil, err
@@ -254,11 +335,71 @@ func newDatabase(
 		return nil, err
 	}
 
+	if config.PersistHistory {
+		trieDB.diskHistory, err = newDiskHistory(db, config.PersistHistoryStartHeight, branchFactor, trieDB.getMerkleRoot())
+		if err != nil {
+			return nil, err
+		}
+	} else if config.DeletePersistedHistory {
+		if err := database.ClearPrefix(db, historyPrefix, rebuildIntermediateDeletionWriteSize); err != nil {
+			return nil, err
+		}
+	}
+
 	// mark that the db has not yet been cleanly closed
 	err = trieDB.baseDB.Put(cleanShutdownKey, didNotHaveCleanShutdown)
 	return trieDB, err
 }
 
+// Returns true if [db] contains any history persisted by [diskHistory].
+func hasPersistedHistory(db database.Database) (bool, error) {
+	it := db.NewIteratorWithPrefix(historyPrefix)
+	defer it.Release()
+
+	return it.Next(), it.Error()
+}
+
+// Returns an error if the database was created with a different branch factor
+// or hasher than [db.branchFactor] and [db.hasher]. If the database is being
+// created, they are recorded.
+func (db *merkleDB) verifyMetadata() error {
+	// Databases created before the branch factor and hasher were recorded
+	// always used the defaults.
+	defaultBranchFactor, defaultHasherID := uint64(DefaultBranchFactor), uint64(DefaultHasher.ID())
+	if _, err := db.baseDB.Get(cleanShutdownKey); err == database.ErrNotFound {
+		defaultBranchFactor, defaultHasherID = uint64(db.branchFactor), uint64(db.hasher.ID())
+	} else if err != nil {
+		return err
+	}
+
+	branchFactor, err := getOrPutUInt64(db.baseDB, branchFactorKey, defaultBranchFactor)
+	if err != nil {
+		return err
+	}
+	if branchFactor != uint64(db.branchFactor) {
+		return fmt.Errorf("%w: %d != %d", ErrBranchFactorChanged, db.branchFactor, branchFactor)
+	}
+
+	hasherID, err := getOrPutUInt64(db.baseDB, hasherIDKey, defaultHasherID)
+	if err != nil {
+		return err
+	}
+	if hasherID != uint64(db.hasher.ID()) {
+		return fmt.Errorf("%w: %d != %d", ErrHasherChanged, db.hasher.ID(), hasherID)
+	}
+	return nil
+}
+
+// Returns the value of [key] in [db]. If [key] isn't in [db], [defaultValue]
+// is put and returned.
+func getOrPutUInt64(db database.KeyValueReaderWriter, key []byte, defaultValue uint64) (uint64, error) {
+	value, err := database.GetUInt64(db, key)
+	if err != database.ErrNotFound {
+		return value, err
+	}
+	return defaultValue, database.PutUInt64(db, key, defaultValue)
+}
+
 // Deletes every intermediate node and rebuilds them by re-adding every key/value.
 // TODO: make this more efficient by only clearing out the stale portions of the trie.
 func (db *merkleDB) rebuild(ctx context.Context, cacheSize int) error {
@@ -425,7 +566,7 @@ func (db *merkleDB) GetValues(ctx context.Context, keys [][]byte) ([][]byte, []e
 	values := make([][]byte, len(keys))
 	errors := make([]error, len(keys))
 	for i, key := range keys {
-		values[i], errors[i] = db.getValueCopy(newPath(key))
+		values[i], errors[i] = db.getValueCopy(db.branchFactor.newPath(key))
 	}
 	return values, errors
 }
@@ -439,7 +580,7 @@ func (db *merkleDB) GetValue(ctx context.Context, key []byte) ([]byte, error) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
-	return db.getValueCopy(newPath(key))
+	return db.getValueCopy(db.branchFactor.newPath(key))
 }
 
 // getValueCopy returns a copy of the value for the given [key].
@@ -522,6 +663,22 @@ func (db *merkleDB) getProof(ctx context.Context, key []byte) (*Proof, error) {
 	return view.getProof(ctx, key)
 }
 
+func (db *merkleDB) GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	view, err := newTrieView(db, db, ViewChanges{})
+	if err != nil {
+		return nil, err
+	}
+	// Don't need to lock [view] because nobody else has a reference to it.
+	return view.getMultiProof(ctx, keys)
+}
+
 func (db *merkleDB) GetRangeProof(
 	ctx context.Context,
 	start maybe.Maybe[[]byte],
@@ -563,7 +720,7 @@ func (db *merkleDB) getRangeProofAtRoot(
 		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxLength)
 	}
 
-	historicalView, err := db.getHistoricalViewForRange(rootID, start, end)
+	historicalView, err := db.getHistoricalViewForRange(ctx, rootID, start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -593,6 +750,9 @@ func (db *merkleDB) GetChangeProof(
 	}
 
 	changes, err := db.history.getValueChanges(startRootID, endRootID, start, end, maxLength)
+	if errors.Is(err, ErrInsufficientHistory) && db.diskHistory != nil {
+		changes, err = db.diskHistory.getValueChanges(startRootID, endRootID, start, end, maxLength)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -604,12 +764,14 @@ func (db *merkleDB) GetChangeProof(
 	utils.Sort(changedKeys)
 
 	result := &ChangeProof{
-		KeyChanges: make([]KeyChange, 0, len(changedKeys)),
+		KeyChanges:   make([]KeyChange, 0, len(changedKeys)),
+		BranchFactor: db.branchFactor,
+		Hasher:       db.hasher,
 	}
 
 	for _, key := range changedKeys {
 		change := changes.values[key]
-		serializedKey := key.Serialize().Value
+		serializedKey := db.branchFactor.serialize(key).Value
 
 		result.KeyChanges = append(result.KeyChanges, KeyChange{
 			Key: serializedKey,
@@ -625,7 +787,7 @@ func (db *merkleDB) GetChangeProof(
 
 	// Since we hold [db.commitlock] we must still have sufficient
 	// history to recreate the trie at [endRootID].
-	historicalView, err := db.getHistoricalViewForRange(endRootID, start, largestKey)
+	historicalView, err := db.getHistoricalViewForRange(ctx, endRootID, start, largestKey)
 	if err != nil {
 		return nil, err
 	}
@@ -698,6 +860,74 @@ func (db *merkleDB) NewView(
 	return newView, nil
 }
 
+// NewViewAtRoot returns a new view on top of the trie as it was when it had
+// root [rootID], where the passed changes have been applied.
+//
+// Assumes [db.commitLock] and [db.lock] aren't held.
+func (db *merkleDB) NewViewAtRoot(
+	_ context.Context,
+	rootID ids.ID,
+	changes ViewChanges,
+) (TrieView, error) {
+	// ensure the db doesn't change while creating the new view
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	var ops []database.BatchOp
+	if rootID != db.getMerkleRoot() {
+		revertOps, err := db.getRevertOps(rootID)
+		if err != nil {
+			return nil, err
+		}
+		// [changes] are applied after reverting to [rootID].
+		ops = append(revertOps, changes.BatchOps...)
+	} else {
+		ops = changes.BatchOps
+	}
+
+	newView, err := newTrieView(db, db, ViewChanges{
+		BatchOps:     ops,
+		MapOps:       changes.MapOps,
+		ConsumeBytes: changes.ConsumeBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ensure access to childViews is protected
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.childViews = append(db.childViews, newView)
+	return newView, nil
+}
+
+// Returns the operations that revert the current trie to the trie with root
+// [rootID]. The returned operations may be modified by the caller.
+// Returns [ErrInsufficientHistory] if there is insufficient history to
+// recreate the trie at [rootID].
+// Assumes [db.commitLock] is read locked.
+func (db *merkleDB) getRevertOps(rootID ids.ID) ([]database.BatchOp, error) {
+	changes, err := db.history.getChangesToGetToRoot(rootID, maybe.Nothing[[]byte](), maybe.Nothing[[]byte]())
+	if errors.Is(err, ErrInsufficientHistory) && db.diskHistory != nil {
+		return db.diskHistory.getRevertOps(rootID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[path]maybe.Maybe[[]byte], len(changes.values))
+	for key, valueChange := range changes.values {
+		// create a copy so edits of the []byte don't affect the history
+		values[key] = maybe.Bind(valueChange.after, slices.Clone[[]byte])
+	}
+	return valuesToBatchOps(values, db.branchFactor), nil
+}
+
 func (db *merkleDB) Has(k []byte) (bool, error) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -706,7 +936,7 @@ func (db *merkleDB) Has(k []byte) (bool, error) {
 		return false, database.ErrClosed
 	}
 
-	_, err := db.getValueWithoutLock(newPath(k))
+	_, err := db.getValueWithoutLock(db.branchFactor.newPath(k))
 	if err == database.ErrNotFound {
 		return false, nil
 	}
@@ -849,6 +1079,14 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 		return errNoNewRoot
 	}
 
+	// The changes must be recorded on disk before they are committed so that
+	// every commit has a corresponding record.
+	if db.diskHistory != nil {
+		if err := db.diskHistory.record(changes); err != nil {
+			return err
+		}
+	}
+
 	currentValueNodeBatch := db.valueNodeDB.NewBatch()
 
 	_, nodesSpan := db.infoTracer.Start(ctx, "MerkleDB.commitChanges.writeNodes")
@@ -927,6 +1165,10 @@ func (db *merkleDB) VerifyChangeProof(
 		return ErrStartAfterEnd
 	case proof.Empty():
 		return ErrNoMerkleProof
+	case proof.BranchFactor.orDefault() != db.branchFactor:
+		return fmt.Errorf("%w: %d != %d", ErrBranchFactorMismatch, proof.BranchFactor.orDefault(), db.branchFactor)
+	case hasherOrDefault(proof.Hasher).ID() != db.hasher.ID():
+		return fmt.Errorf("%w: %d != %d", ErrHasherMismatch, hasherOrDefault(proof.Hasher).ID(), db.hasher.ID())
 	case end.HasValue() && len(proof.KeyChanges) == 0 && len(proof.EndProof) == 0:
 		// We requested an end proof but didn't get one.
 		return ErrNoEndProof
@@ -944,32 +1186,32 @@ func (db *merkleDB) VerifyChangeProof(
 	}
 
 	// Note that if [start] is Nothing, smallestPath is the empty path.
-	smallestPath := newPath(start.Value())
+	smallestPath := db.branchFactor.newPath(start.Value())
 
 	// Make sure the start proof, if given, is well-formed.
-	if err := verifyProofPath(proof.StartProof, smallestPath); err != nil {
+	if err := verifyProofPath(proof.StartProof, smallestPath, db.branchFactor); err != nil {
 		return err
 	}
 
 	// Find the greatest key in [proof.KeyChanges]
 	// Note that [proof.EndProof] is a proof for this key.
 	// [largestPath] is also used when we add children of proof nodes to [trie] below.
-	largestPath := maybe.Bind(end, newPath)
+	largestPath := maybe.Bind(end, db.branchFactor.newPath)
 	if len(proof.KeyChanges) > 0 {
 		// If [proof] has key-value pairs, we should insert children
 		// greater than [end] to ancestors of the node containing [end]
 		// so that we get the expected root ID.
-		largestPath = maybe.Some(newPath(proof.KeyChanges[len(proof.KeyChanges)-1].Key))
+		largestPath = maybe.Some(db.branchFactor.newPath(proof.KeyChanges[len(proof.KeyChanges)-1].Key))
 	}
 
 	// Make sure the end proof, if given, is well-formed.
-	if err := verifyProofPath(proof.EndProof, largestPath.Value()); err != nil {
+	if err := verifyProofPath(proof.EndProof, largestPath.Value(), db.branchFactor); err != nil {
 		return err
 	}
 
 	keyValues := make(map[path]maybe.Maybe[[]byte], len(proof.KeyChanges))
 	for _, keyValue := range proof.KeyChanges {
-		keyValues[newPath(keyValue.Key)] = keyValue.Value
+		keyValues[db.branchFactor.newPath(keyValue.Key)] = keyValue.Value
 	}
 
 	// want to prevent commit writes to DB, but not prevent DB reads
@@ -1083,7 +1325,7 @@ func (db *merkleDB) initializeRootIfNeeded() (ids.ID, error) {
 	}
 	if err == nil {
 		// Root already exists, so calculate its id
-		db.root.calculateID(db.metrics)
+		db.root.calculateID(db.branchFactor, db.hasher, db.metrics)
 		return db.root.id, nil
 	}
 	if err != database.ErrNotFound {
@@ -1094,7 +1336,7 @@ func (db *merkleDB) initializeRootIfNeeded() (ids.ID, error) {
 	db.root = newNode(nil, RootPath)
 
 	// update its ID
-	db.root.calculateID(db.metrics)
+	db.root.calculateID(db.branchFactor, db.hasher, db.metrics)
 
 	if err := db.intermediateNodeDB.Put(RootPath, db.root); err != nil {
 		return ids.Empty, err
@@ -1109,6 +1351,7 @@ func (db *merkleDB) initializeRootIfNeeded() (ids.ID, error) {
 // Assumes [db.commitLock] is read locked.
 // Assumes [db.lock] isn't held.
 func (db *merkleDB) getHistoricalViewForRange(
+	ctx context.Context,
 	rootID ids.ID,
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
@@ -1122,10 +1365,25 @@ func (db *merkleDB) getHistoricalViewForRange(
 	}
 
 	changeHistory, err := db.history.getChangesToGetToRoot(rootID, start, end)
+	if err == nil {
+		return newHistoricalTrieView(db, changeHistory)
+	}
+	if !errors.Is(err, ErrInsufficientHistory) || db.diskHistory == nil {
+		return nil, err
+	}
+
+	// The root isn't in the in-memory history, so recreate the trie by
+	// reverting the changes recorded on disk.
+	ops, err := db.diskHistory.getRevertOps(rootID)
+	if err != nil {
+		return nil, err
+	}
+	view, err := newTrieView(db, db, ViewChanges{BatchOps: ops, ConsumeBytes: true})
 	if err != nil {
 		return nil, err
 	}
-	return newHistoricalTrieView(db, changeHistory)
+	// Callers expect the node IDs of historical views to be calculated.
+	return view, view.calculateNodeIDs(ctx)
 }
 
 // Returns all keys in range [start, end] that aren't in [keySet].
@@ -1213,10 +1471,11 @@ func getBufferFromPool(bufferPool *sync.Pool, size int) []byte {
 }
 
 // cacheEntrySize returns a rough approximation of the memory consumed by storing the path and node
-func cacheEntrySize(p path, n *node) int {
+// of a trie with branch factor [bf]
+func cacheEntrySize(p path, n *node, bf BranchFactor) int {
 	if n == nil {
 		return len(p)
 	}
 	// nodes cache their bytes representation so the total memory consumed is roughly twice that
-	return len(p) + 2*len(n.bytes())
+	return len(p) + 2*len(n.bytes(bf))
 }
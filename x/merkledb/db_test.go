@@ -6,6 +6,7 @@ package merkledb
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"math/rand"
 	"strconv"
 	"testing"
@@ -1169,3 +1170,190 @@ func insertRandomKeyValues(
 		}
 	}
 }
+
+func Test_MerkleDB_BranchFactorsAndHashers(t *testing.T) {
+	ctx := context.Background()
+
+	keys := [][]byte{{}, {0}, {0, 1}, {1}, {0xFF}, []byte("key"), []byte("key1"), []byte("key2")}
+	largeValue := make([]byte, 2*HashLength)
+
+	roots := set.Set[ids.ID]{}
+	for _, bf := range []BranchFactor{BranchFactor2, BranchFactor4, BranchFactor16, BranchFactor256} {
+		for hasherName, hasher := range map[string]Hasher{
+			"sha256":    SHA256Hasher,
+			"keccak256": Keccak256Hasher,
+		} {
+			bf, hasher := bf, hasher
+			t.Run(fmt.Sprintf("%d %s", bf, hasherName), func(t *testing.T) {
+				require := require.New(t)
+
+				baseDB := memdb.New()
+				config := newDefaultConfig()
+				config.BranchFactor = bf
+				config.Hasher = hasher
+				db, err := newDB(ctx, baseDB, config)
+				require.NoError(err)
+
+				startRoot, err := db.GetMerkleRoot(ctx)
+				require.NoError(err)
+
+				batch := db.NewBatch()
+				for i, key := range keys {
+					value := []byte{byte(i)}
+					if i%2 == 0 {
+						value = largeValue
+					}
+					require.NoError(batch.Put(key, value))
+				}
+				require.NoError(batch.Write())
+
+				root, err := db.GetMerkleRoot(ctx)
+				require.NoError(err)
+				require.False(roots.Contains(root))
+				roots.Add(root)
+
+				proof, err := db.GetProof(ctx, []byte("key1"))
+				require.NoError(err)
+				require.Equal(bf, proof.BranchFactor)
+				require.NoError(proof.Verify(ctx, root))
+
+				proof, err = db.GetProof(ctx, []byte("missing"))
+				require.NoError(err)
+				require.NoError(proof.Verify(ctx, root))
+
+				rangeProof, err := db.GetRangeProof(ctx, maybe.Some([]byte{0}), maybe.Some([]byte("key")), 3)
+				require.NoError(err)
+				require.NoError(rangeProof.Verify(ctx, maybe.Some([]byte{0}), maybe.Some([]byte("key")), root))
+
+				multiProof, err := db.GetMultiProof(ctx, [][]byte{{0, 1}, []byte("key2"), []byte("missing")})
+				require.NoError(err)
+				require.NoError(multiProof.Verify(ctx, root))
+
+				var decodedMultiProof MultiProof
+				require.NoError(decodedMultiProof.Unmarshal(multiProof.Marshal()))
+				require.Equal(hasher, decodedMultiProof.Hasher)
+				require.NoError(decodedMultiProof.Verify(ctx, root))
+
+				changeProof, err := db.GetChangeProof(ctx, startRoot, root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), len(keys))
+				require.NoError(err)
+
+				// The branch factor and hasher are part of the proofs sent over
+				// the network.
+				var decodedProof Proof
+				require.NoError(decodedProof.UnmarshalProto(proof.ToProto()))
+				require.Equal(bf, decodedProof.BranchFactor)
+				require.Equal(hasher, decodedProof.Hasher)
+				require.NoError(decodedProof.Verify(ctx, root))
+
+				var decodedRangeProof RangeProof
+				require.NoError(decodedRangeProof.UnmarshalProto(rangeProof.ToProto()))
+				require.Equal(bf, decodedRangeProof.BranchFactor)
+				require.Equal(hasher, decodedRangeProof.Hasher)
+				require.NoError(decodedRangeProof.Verify(ctx, maybe.Some([]byte{0}), maybe.Some([]byte("key")), root))
+
+				var decodedChangeProof ChangeProof
+				require.NoError(decodedChangeProof.UnmarshalProto(changeProof.ToProto()))
+				require.Equal(bf, decodedChangeProof.BranchFactor)
+				require.Equal(hasher, decodedChangeProof.Hasher)
+
+				config.Reg = prometheus.NewRegistry()
+				syncedDB, err := newDB(ctx, memdb.New(), config)
+				require.NoError(err)
+				require.NoError(syncedDB.VerifyChangeProof(ctx, changeProof, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), root))
+				require.NoError(syncedDB.CommitChangeProof(ctx, changeProof))
+				syncedRoot, err := syncedDB.GetMerkleRoot(ctx)
+				require.NoError(err)
+				require.Equal(root, syncedRoot)
+
+				// The proofs only verify with the branch factor and hasher of the trie.
+				otherBF := BranchFactor16
+				if bf == BranchFactor16 {
+					otherBF = BranchFactor2
+				}
+				proof.BranchFactor = otherBF
+				require.Error(proof.Verify(ctx, root)) //nolint:forbidigo // the error depends on the branch factor
+				changeProof.BranchFactor = otherBF
+				err = syncedDB.VerifyChangeProof(ctx, changeProof, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), root)
+				require.ErrorIs(err, ErrBranchFactorMismatch)
+				changeProof.BranchFactor = bf
+				changeProof.Hasher = SHA256Hasher
+				if hasher == SHA256Hasher {
+					changeProof.Hasher = Keccak256Hasher
+				}
+				err = syncedDB.VerifyChangeProof(ctx, changeProof, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), root)
+				require.ErrorIs(err, ErrHasherMismatch)
+
+				rangeProof.Hasher = SHA256Hasher
+				if hasher == SHA256Hasher {
+					rangeProof.Hasher = Keccak256Hasher
+				}
+				err = rangeProof.Verify(ctx, maybe.Some([]byte{0}), maybe.Some([]byte("key")), root)
+				require.ErrorIs(err, ErrInvalidProof)
+
+				// The trie can be reloaded from disk.
+				require.NoError(db.Close())
+				config.Reg = prometheus.NewRegistry()
+				db, err = newDB(ctx, baseDB, config)
+				require.NoError(err)
+				reloadedRoot, err := db.GetMerkleRoot(ctx)
+				require.NoError(err)
+				require.Equal(root, reloadedRoot)
+				value, err := db.GetValue(ctx, []byte("key1"))
+				require.NoError(err)
+				require.Equal(largeValue, value)
+			})
+		}
+	}
+}
+
+func Test_MerkleDB_BranchFactorAndHasherPersisted(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	config.BranchFactor = BranchFactor256
+	config.Hasher = Keccak256Hasher
+	db, err := newDB(ctx, baseDB, config)
+	require.NoError(err)
+	require.NoError(db.Close())
+
+	config.Reg = prometheus.NewRegistry()
+	config.BranchFactor = BranchFactor16
+	_, err = newDB(ctx, baseDB, config)
+	require.ErrorIs(err, ErrBranchFactorChanged)
+
+	config.Reg = prometheus.NewRegistry()
+	config.BranchFactor = BranchFactor256
+	config.Hasher = SHA256Hasher
+	_, err = newDB(ctx, baseDB, config)
+	require.ErrorIs(err, ErrHasherChanged)
+
+	config.Reg = prometheus.NewRegistry()
+	config.Hasher = Keccak256Hasher
+	db, err = newDB(ctx, baseDB, config)
+	require.NoError(err)
+	require.NoError(db.Close())
+
+	// Databases created before the branch factor and hasher were persisted
+	// used the defaults.
+	require.NoError(baseDB.Delete(branchFactorKey))
+	require.NoError(baseDB.Delete(hasherIDKey))
+	config.Reg = prometheus.NewRegistry()
+	_, err = newDB(ctx, baseDB, config)
+	require.ErrorIs(err, ErrBranchFactorChanged)
+
+	config.Reg = prometheus.NewRegistry()
+	config.BranchFactor = 0
+	config.Hasher = nil
+	db, err = newDB(ctx, baseDB, config)
+	require.NoError(err)
+	require.NoError(db.Close())
+}
+
+func Test_MerkleDB_InvalidBranchFactor(t *testing.T) {
+	config := newDefaultConfig()
+	config.BranchFactor = 3
+	_, err := New(context.Background(), memdb.New(), config)
+	require.ErrorIs(t, err, ErrInvalidBranchFactor)
+}
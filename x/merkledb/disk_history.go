@@ -0,0 +1,394 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	errInvalidHistoryKey = errors.New("invalid history key")
+
+	// The keys written by [diskHistory] are prefixed with [historyPrefix]
+	// followed by one of the below prefixes.
+	historyHeightKey     = []byte(string(historyPrefix) + "\x00")
+	historyRecordPrefix  = []byte(string(historyPrefix) + "\x01")
+	historyRootPrefix    = []byte(string(historyPrefix) + "\x02")
+	historyRecordKeySize = len(historyRecordPrefix) + wrappers.LongLen
+)
+
+// The value changes made by a single commit and the root they resulted in.
+type historyRecord struct {
+	rootID ids.ID
+	values map[path]*change[maybe.Maybe[[]byte]]
+}
+
+// diskHistory persists the value changes made by every commit so that
+// historical roots can be served after they are evicted from [trieHistory]
+// or the database is restarted.
+//
+// Every commit to the database increments its height. The changes made at
+// heights >= [startHeight] are stored on disk, along with an index from each
+// root to the most recent height it was committed at.
+//
+// Since the trie is fully determined by its key-value pairs, the trie at a
+// historical height can be recreated by reverting the value changes made
+// after that height.
+//
+// Writes are serialized by [merkleDB.commitLock] and [merkleDB.lock].
+// Reads assume [merkleDB.commitLock] is read locked.
+type diskHistory struct {
+	db database.Database
+
+	// Commits before this height aren't persisted.
+	startHeight uint64
+
+	// The height of the last commit.
+	height uint64
+
+	// The branch factor of the trie the changes are to.
+	branchFactor BranchFactor
+}
+
+// Returns a new diskHistory backed by [db].
+// [rootID] is the current root of the trie, which has branch factor
+// [branchFactor].
+func newDiskHistory(
+	db database.Database,
+	startHeight uint64,
+	branchFactor BranchFactor,
+	rootID ids.ID,
+) (*diskHistory, error) {
+	height, err := database.GetUInt64(db, historyHeightKey)
+	if err != nil && err != database.ErrNotFound {
+		return nil, err
+	}
+
+	dh := &diskHistory{
+		db:           db,
+		startHeight:  startHeight,
+		height:       height,
+		branchFactor: branchFactor,
+	}
+
+	// If the last record was written but the shutdown happened before the
+	// commit it describes was written, the record must be removed.
+	record, err := dh.getRecord(height)
+	switch err {
+	case nil:
+		if record.rootID != rootID {
+			if err := dh.removeLastRecord(record.rootID); err != nil {
+				return nil, err
+			}
+		}
+	case database.ErrNotFound:
+	default:
+		return nil, err
+	}
+
+	if dh.height < dh.startHeight {
+		return dh, nil
+	}
+	// Make sure the current root can be used as the base of future requests.
+	return dh, dh.db.Put(historyRootKey(rootID), database.PackUInt64(dh.height))
+}
+
+// Removes the record at the current height, which resulted in [rootID].
+func (dh *diskHistory) removeLastRecord(rootID ids.ID) error {
+	batch := dh.db.NewBatch()
+	if err := batch.Delete(historyRecordKey(dh.height)); err != nil {
+		return err
+	}
+	rootHeight, err := database.GetUInt64(dh.db, historyRootKey(rootID))
+	switch {
+	case err == database.ErrNotFound:
+	case err != nil:
+		return err
+	case rootHeight == dh.height:
+		if err := batch.Delete(historyRootKey(rootID)); err != nil {
+			return err
+		}
+	}
+	if err := database.PutUInt64(batch, historyHeightKey, dh.height-1); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	dh.height--
+	return nil
+}
+
+// record the value changes in [changes] at the next height.
+//
+// This must be called before [changes] are written to disk, so that a
+// record exists for every commit that was made.
+func (dh *diskHistory) record(changes *changeSummary) error {
+	height := dh.height + 1
+
+	batch := dh.db.NewBatch()
+	if err := database.PutUInt64(batch, historyHeightKey, height); err != nil {
+		return err
+	}
+	if height >= dh.startHeight {
+		recordBytes := codec.encodeHistoryRecord(&historyRecord{
+			rootID: changes.rootID,
+			values: changes.values,
+		}, dh.branchFactor)
+		if err := batch.Put(historyRecordKey(height), recordBytes); err != nil {
+			return err
+		}
+		if err := database.PutUInt64(batch, historyRootKey(changes.rootID), height); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	dh.height = height
+	return nil
+}
+
+// Returns the most recent height that [rootID] was committed at.
+// Returns [ErrInsufficientHistory] if [rootID] isn't in the history.
+func (dh *diskHistory) getRootHeight(rootID ids.ID) (uint64, error) {
+	height, err := database.GetUInt64(dh.db, historyRootKey(rootID))
+	if err == database.ErrNotFound {
+		return 0, fmt.Errorf("%w: root %s not found on disk", ErrInsufficientHistory, rootID)
+	}
+	return height, err
+}
+
+// Returns the record written at [height].
+// Returns [database.ErrNotFound] if there is no record at [height].
+func (dh *diskHistory) getRecord(height uint64) (*historyRecord, error) {
+	recordBytes, err := dh.db.Get(historyRecordKey(height))
+	if err != nil {
+		return nil, err
+	}
+	record := &historyRecord{}
+	return record, codec.decodeHistoryRecord(recordBytes, record, dh.branchFactor)
+}
+
+// Calls [onRecord] with every record at a height in (after, through],
+// in order of increasing height.
+func (dh *diskHistory) iterate(after, through uint64, onRecord func(*historyRecord)) error {
+	it := dh.db.NewIteratorWithStartAndPrefix(historyRecordKey(after+1), historyRecordPrefix)
+	defer it.Release()
+
+	expectedHeight := after + 1
+	for expectedHeight <= through && it.Next() {
+		key := it.Key()
+		if len(key) != historyRecordKeySize {
+			return fmt.Errorf("%w: unexpected key length %d", errInvalidHistoryKey, len(key))
+		}
+		if height := binary.BigEndian.Uint64(key[len(historyRecordPrefix):]); height != expectedHeight {
+			return fmt.Errorf("%w: missing history at height %d", ErrInsufficientHistory, expectedHeight)
+		}
+
+		record := &historyRecord{}
+		if err := codec.decodeHistoryRecord(it.Value(), record, dh.branchFactor); err != nil {
+			return err
+		}
+		onRecord(record)
+		expectedHeight++
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if expectedHeight <= through {
+		return fmt.Errorf("%w: missing history at height %d", ErrInsufficientHistory, expectedHeight)
+	}
+	return nil
+}
+
+// Returns up to [maxLength] key-value pair changes with keys in
+// [start, end] that occurred between [startRoot] and [endRoot].
+// If [start] is Nothing, there's no lower bound on the range.
+// If [end] is Nothing, there's no upper bound on the range.
+// Returns [ErrInsufficientHistory] if the history is insufficient
+// to generate the proof.
+func (dh *diskHistory) getValueChanges(
+	startRoot ids.ID,
+	endRoot ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+) (*changeSummary, error) {
+	if maxLength <= 0 {
+		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxLength)
+	}
+
+	if startRoot == endRoot {
+		return newChangeSummary(maxLength), nil
+	}
+
+	endHeight, err := dh.getRootHeight(endRoot)
+	if err != nil {
+		return nil, err
+	}
+	startHeight, err := dh.getRootHeight(startRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if startHeight > endHeight {
+		// [startHeight] is just the *latest* height resulting in [startRoot].
+		// Attempt to find a height resulting in [startRoot] before [endHeight].
+		startHeight, err = dh.findRootBefore(startRoot, endHeight)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		// Keep track of changed keys so the largest can be removed
+		// in order to stay within the [maxLength] limit if necessary.
+		changedKeys = set.Set[path]{}
+
+		startPath = maybe.Bind(start, dh.branchFactor.newPath)
+		endPath   = maybe.Bind(end, dh.branchFactor.newPath)
+
+		combinedChanges = newChangeSummary(maxLength)
+	)
+
+	err = dh.iterate(startHeight, endHeight, func(record *historyRecord) {
+		for key, valueChange := range record.values {
+			// The key is outside the range [start, end].
+			if (startPath.HasValue() && key.Compare(startPath.Value()) < 0) ||
+				(endPath.HasValue() && key.Compare(endPath.Value()) > 0) {
+				continue
+			}
+
+			// A change to this key already exists in [combinedChanges]
+			// so update its after value with the later after value
+			if existing, ok := combinedChanges.values[key]; ok {
+				existing.after = valueChange.after
+				continue
+			}
+			combinedChanges.values[key] = &change[maybe.Maybe[[]byte]]{
+				before: valueChange.before,
+				after:  valueChange.after,
+			}
+			changedKeys.Add(key)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the changes which are no-ops over the whole range.
+	for key, valueChange := range combinedChanges.values {
+		if valueChange.before.HasValue() == valueChange.after.HasValue() &&
+			bytes.Equal(valueChange.before.Value(), valueChange.after.Value()) {
+			delete(combinedChanges.values, key)
+			changedKeys.Remove(key)
+		}
+	}
+
+	// If we have <= [maxLength] elements, we're done.
+	if changedKeys.Len() <= maxLength {
+		return combinedChanges, nil
+	}
+
+	// Keep only the smallest [maxLength] items in [combinedChanges.values].
+	sortedChangedKeys := changedKeys.List()
+	utils.Sort(sortedChangedKeys)
+	for _, key := range sortedChangedKeys[maxLength:] {
+		delete(combinedChanges.values, key)
+	}
+	return combinedChanges, nil
+}
+
+// Returns the greatest height < [before] that resulted in [rootID].
+// Returns [ErrInsufficientHistory] if no such height is on disk.
+func (dh *diskHistory) findRootBefore(rootID ids.ID, before uint64) (uint64, error) {
+	for height := before; height > dh.startHeight; {
+		height--
+
+		record, err := dh.getRecord(height)
+		if err == database.ErrNotFound {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if record.rootID == rootID {
+			return height, nil
+		}
+	}
+	return 0, fmt.Errorf(
+		"%w: root %s not found on disk before height %d",
+		ErrInsufficientHistory, rootID, before,
+	)
+}
+
+// Returns the operations that revert the current trie to the trie
+// with root [rootID].
+// Returns [ErrInsufficientHistory] if [rootID] isn't in the history.
+func (dh *diskHistory) getRevertOps(rootID ids.ID) ([]database.BatchOp, error) {
+	rootHeight, err := dh.getRootHeight(rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The value of each key at [rootHeight] is the value before the first
+	// change made to it after [rootHeight].
+	values := make(map[path]maybe.Maybe[[]byte])
+	err = dh.iterate(rootHeight, dh.height, func(record *historyRecord) {
+		for key, valueChange := range record.values {
+			if _, ok := values[key]; !ok {
+				values[key] = valueChange.before
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return valuesToBatchOps(values, dh.branchFactor), nil
+}
+
+// Returns the operations that set each key in [values], which are paths in a
+// trie with branch factor [bf], to its value.
+// The returned operations reference the values in [values].
+func valuesToBatchOps(values map[path]maybe.Maybe[[]byte], bf BranchFactor) []database.BatchOp {
+	ops := make([]database.BatchOp, 0, len(values))
+	for key, value := range values {
+		ops = append(ops, database.BatchOp{
+			Key:    bf.serialize(key).Value,
+			Value:  value.Value(),
+			Delete: value.IsNothing(),
+		})
+	}
+	slices.SortFunc(ops, func(a, b database.BatchOp) bool {
+		return bytes.Compare(a.Key, b.Key) < 0
+	})
+	return ops
+}
+
+func historyRecordKey(height uint64) []byte {
+	key := make([]byte, historyRecordKeySize)
+	copy(key, historyRecordPrefix)
+	binary.BigEndian.PutUint64(key[len(historyRecordPrefix):], height)
+	return key
+}
+
+func historyRootKey(rootID ids.ID) []byte {
+	key := make([]byte, len(historyRootPrefix)+ids.IDLen)
+	copy(key, historyRootPrefix)
+	copy(key[len(historyRootPrefix):], rootID[:])
+	return key
+}
@@ -0,0 +1,267 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+func newPersistentHistoryConfig(startHeight uint64) Config {
+	config := newDefaultConfig()
+	config.HistoryLength = 2
+	config.PersistHistory = true
+	config.PersistHistoryStartHeight = startHeight
+	return config
+}
+
+// Writes [numCommits] commits to [db], each of which puts a new key, changes
+// the value of an existing key and deletes a key.
+// Returns the root after each commit and the key-values at each root.
+func writeCommits(require *require.Assertions, db MerkleDB, numCommits int) ([]ids.ID, []map[string][]byte) {
+	var (
+		roots  = make([]ids.ID, 0, numCommits)
+		states = make([]map[string][]byte, 0, numCommits)
+		state  = map[string][]byte{}
+	)
+	for i := 0; i < numCommits; i++ {
+		batch := db.NewBatch()
+		newKey := "key" + strconv.Itoa(i)
+		require.NoError(batch.Put([]byte(newKey), []byte("value"+strconv.Itoa(i))))
+		state[newKey] = []byte("value" + strconv.Itoa(i))
+		if i > 0 {
+			changedKey := "key" + strconv.Itoa(i-1)
+			require.NoError(batch.Put([]byte(changedKey), []byte("changed"+strconv.Itoa(i))))
+			state[changedKey] = []byte("changed" + strconv.Itoa(i))
+		}
+		if i > 2 {
+			deletedKey := "key" + strconv.Itoa(i-3)
+			require.NoError(batch.Delete([]byte(deletedKey)))
+			delete(state, deletedKey)
+		}
+		require.NoError(batch.Write())
+
+		root, err := db.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		roots = append(roots, root)
+
+		stateCopy := make(map[string][]byte, len(state))
+		for k, v := range state {
+			stateCopy[k] = v
+		}
+		states = append(states, stateCopy)
+	}
+	return roots, states
+}
+
+func TestDiskHistoryRangeProofAtEvictedRoot(t *testing.T) {
+	require := require.New(t)
+
+	db, err := newDB(context.Background(), memdb.New(), newPersistentHistoryConfig(0))
+	require.NoError(err)
+
+	roots, _ := writeCommits(require, db, 10)
+
+	// The in-memory history only contains the most recent changes.
+	_, err = db.history.getChangesToGetToRoot(roots[0], maybe.Nothing[[]byte](), maybe.Nothing[[]byte]())
+	require.ErrorIs(err, ErrInsufficientHistory)
+
+	for _, root := range roots {
+		proof, err := db.GetRangeProofAtRoot(context.Background(), root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+		require.NoError(err)
+		require.NoError(proof.Verify(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), root))
+	}
+}
+
+func TestDiskHistoryChangeProofAtEvictedRoot(t *testing.T) {
+	require := require.New(t)
+
+	db, err := newDB(context.Background(), memdb.New(), newPersistentHistoryConfig(0))
+	require.NoError(err)
+
+	roots, states := writeCommits(require, db, 10)
+
+	// Create a db with the state at roots[1].
+	dbClone, err := getBasicDB()
+	require.NoError(err)
+	for k, v := range states[1] {
+		require.NoError(dbClone.Put([]byte(k), v))
+	}
+	cloneRoot, err := dbClone.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(roots[1], cloneRoot)
+
+	proof, err := db.GetChangeProof(context.Background(), roots[1], roots[5], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+	require.NoError(err)
+	require.NoError(dbClone.VerifyChangeProof(context.Background(), proof, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), roots[5]))
+	require.NoError(dbClone.CommitChangeProof(context.Background(), proof))
+
+	cloneRoot, err = dbClone.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(roots[5], cloneRoot)
+
+	// The length of the change proof is bounded by [maxLength].
+	proof, err = db.GetChangeProof(context.Background(), roots[1], roots[5], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 2)
+	require.NoError(err)
+	require.Len(proof.KeyChanges, 2)
+}
+
+func TestDiskHistoryNewViewAtRoot(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := New(context.Background(), baseDB, newPersistentHistoryConfig(0))
+	require.NoError(err)
+
+	roots, states := writeCommits(require, db, 10)
+
+	// The history must survive restarts.
+	require.NoError(db.Close())
+	config := newPersistentHistoryConfig(0)
+	config.Reg = prometheus.NewRegistry()
+	db, err = New(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	for i, root := range roots {
+		view, err := db.NewViewAtRoot(context.Background(), root, ViewChanges{})
+		require.NoError(err)
+
+		viewRoot, err := view.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		require.Equal(root, viewRoot)
+
+		for k, v := range states[i] {
+			got, err := view.GetValue(context.Background(), []byte(k))
+			require.NoError(err)
+			require.Equal(v, got)
+		}
+	}
+
+	// Changes are applied on top of the historical trie.
+	view, err := db.NewViewAtRoot(context.Background(), roots[0], ViewChanges{
+		BatchOps: []database.BatchOp{{Key: []byte("key0"), Delete: true}},
+	})
+	require.NoError(err)
+	_, err = view.GetValue(context.Background(), []byte("key0"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	emptyDB, err := getBasicDB()
+	require.NoError(err)
+	emptyRoot, err := emptyDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	viewRoot, err := view.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(emptyRoot, viewRoot)
+
+	// Committing the view reverts the database.
+	require.NoError(view.CommitToDB(context.Background()))
+	dbRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(emptyRoot, dbRoot)
+}
+
+func TestDiskHistoryStartHeight(t *testing.T) {
+	require := require.New(t)
+
+	db, err := newDB(context.Background(), memdb.New(), newPersistentHistoryConfig(5))
+	require.NoError(err)
+
+	roots, _ := writeCommits(require, db, 10)
+
+	// Heights start at 1, so roots[i] was committed at height i+1.
+	for i, root := range roots {
+		_, err := db.GetRangeProofAtRoot(context.Background(), root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+		if i+1 < 5 {
+			require.ErrorIs(err, ErrInsufficientHistory)
+			continue
+		}
+		require.NoError(err)
+	}
+}
+
+func TestDiskHistoryRemovesUncommittedRecord(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newPersistentHistoryConfig(0))
+	require.NoError(err)
+
+	roots, _ := writeCommits(require, db, 3)
+
+	// Simulate a shutdown after the record of a commit was written but before
+	// the commit was.
+	changes := newChangeSummary(1)
+	changes.rootID = ids.GenerateTestID()
+	require.NoError(db.diskHistory.record(changes))
+	require.Equal(uint64(4), db.diskHistory.height)
+
+	dh, err := newDiskHistory(baseDB, 0, BranchFactor16, roots[2])
+	require.NoError(err)
+	require.Equal(uint64(3), dh.height)
+
+	_, err = dh.getRootHeight(changes.rootID)
+	require.ErrorIs(err, ErrInsufficientHistory)
+	_, err = dh.getRecord(4)
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestDiskHistoryDisabledKeepsHistory(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := New(context.Background(), baseDB, newPersistentHistoryConfig(0))
+	require.NoError(err)
+
+	roots, _ := writeCommits(require, db, 5)
+	require.NoError(db.Close())
+
+	config := newDefaultConfig()
+	config.HistoryLength = 2
+	_, err = New(context.Background(), baseDB, config)
+	require.ErrorIs(err, ErrPersistedHistory)
+
+	config.Reg = nil
+	require.ErrorIs(Prune(context.Background(), baseDB, config), ErrPersistedHistory)
+
+	// The history is still available once persistence is re-enabled.
+	db, err = New(context.Background(), baseDB, newPersistentHistoryConfig(0))
+	require.NoError(err)
+
+	_, err = db.GetRangeProofAtRoot(context.Background(), roots[0], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+	require.NoError(err)
+}
+
+func TestDiskHistoryDisabledDeletesHistory(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := New(context.Background(), baseDB, newPersistentHistoryConfig(0))
+	require.NoError(err)
+
+	roots, _ := writeCommits(require, db, 5)
+	require.NoError(db.Close())
+
+	config := newDefaultConfig()
+	config.HistoryLength = 2
+	config.DeletePersistedHistory = true
+	db, err = New(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	_, err = db.GetRangeProofAtRoot(context.Background(), roots[0], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+	require.ErrorIs(err, ErrInsufficientHistory)
+
+	it := baseDB.NewIteratorWithPrefix(historyPrefix)
+	defer it.Release()
+	require.False(it.Next())
+	require.NoError(it.Error())
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+var (
+	_ Hasher = sha256Hasher{}
+	_ Hasher = keccak256Hasher{}
+
+	// SHA256Hasher hashes with SHA-256.
+	SHA256Hasher Hasher = sha256Hasher{}
+	// Keccak256Hasher hashes with the legacy Keccak-256 used by Ethereum.
+	Keccak256Hasher Hasher = keccak256Hasher{}
+
+	// The hasher used if none is specified.
+	DefaultHasher = SHA256Hasher
+)
+
+// HasherID identifies the hasher of a trie in its proofs and in the metadata
+// of a database. The zero value identifies [DefaultHasher].
+type HasherID uint32
+
+const (
+	SHA256HasherID HasherID = iota + 1
+	Keccak256HasherID
+)
+
+var ErrUnknownHasher = errors.New("unknown hasher")
+
+// Hasher calculates the IDs of the nodes in a trie and the digests of the
+// values that are at least [HashLength] bytes long.
+type Hasher interface {
+	// Hash returns the hash of [b].
+	// Hashes must be computed deterministically.
+	Hash(b []byte) ids.ID
+	// ID returns the ID of the hasher, which must be unique among hashers.
+	// Only proofs from tries that use the hashers of this package can be
+	// unmarshalled.
+	ID() HasherID
+}
+
+type sha256Hasher struct{}
+
+func (sha256Hasher) Hash(b []byte) ids.ID {
+	return hashing.ComputeHash256Array(b)
+}
+
+func (sha256Hasher) ID() HasherID {
+	return SHA256HasherID
+}
+
+type keccak256Hasher struct{}
+
+func (keccak256Hasher) Hash(b []byte) ids.ID {
+	hasher := sha3.NewLegacyKeccak256()
+	_, _ = hasher.Write(b)
+
+	var result ids.ID
+	hasher.Sum(result[:0])
+	return result
+}
+
+func (keccak256Hasher) ID() HasherID {
+	return Keccak256HasherID
+}
+
+// Returns [h], or [DefaultHasher] if [h] is unspecified.
+func hasherOrDefault(h Hasher) Hasher {
+	if h == nil {
+		return DefaultHasher
+	}
+	return h
+}
+
+// Returns the ID of [h], or 0 if [h] is unspecified.
+func hasherID(h Hasher) HasherID {
+	if h == nil {
+		return 0
+	}
+	return h.ID()
+}
+
+// Returns the hasher of this package with ID [id], or nil if [id] is 0.
+func hasherFromID(id HasherID) (Hasher, error) {
+	switch id {
+	case 0:
+		return nil, nil
+	case SHA256HasherID:
+		return SHA256Hasher, nil
+	case Keccak256HasherID:
+		return Keccak256Hasher, nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownHasher, id)
+	}
+}
+
+// Returns the digest of [value] that is included in the ID of the node
+// with [value]. Values shorter than [HashLength] are their own digest.
+func getValueDigest(hasher Hasher, value maybe.Maybe[[]byte]) maybe.Maybe[[]byte] {
+	if value.IsNothing() || len(value.Value()) < HashLength {
+		return value
+	}
+	valueHash := hasher.Hash(value.Value())
+	return maybe.Some(valueHash[:])
+}
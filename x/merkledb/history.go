@@ -32,6 +32,9 @@ type trieHistory struct {
 
 	// Each change is tagged with this monotonic increasing number.
 	nextInsertNumber uint64
+
+	// The branch factor of the trie the changes are to.
+	branchFactor BranchFactor
 }
 
 // Tracks the beginning and ending state of a value.
@@ -63,9 +66,10 @@ func newChangeSummary(estimatedSize int) *changeSummary {
 	}
 }
 
-func newTrieHistory(maxHistoryLookback int) *trieHistory {
+func newTrieHistory(maxHistoryLookback int, branchFactor BranchFactor) *trieHistory {
 	return &trieHistory{
 		maxHistoryLen: maxHistoryLookback,
+		branchFactor:  branchFactor,
 		history:       buffer.NewUnboundedDeque[*changeSummaryAndInsertNumber](maxHistoryLookback),
 		lastChanges:   make(map[ids.ID]*changeSummaryAndInsertNumber),
 	}
@@ -155,8 +159,8 @@ func (th *trieHistory) getValueChanges(
 		// in order to stay within the [maxLength] limit if necessary.
 		changedKeys = set.Set[path]{}
 
-		startPath = maybe.Bind(start, newPath)
-		endPath   = maybe.Bind(end, newPath)
+		startPath = maybe.Bind(start, th.branchFactor.newPath)
+		endPath   = maybe.Bind(end, th.branchFactor.newPath)
 
 		// For each element in the history in the range between [startRoot]'s
 		// last appearance (exclusive) and [endRoot]'s last appearance (inclusive),
@@ -234,8 +238,8 @@ func (th *trieHistory) getChangesToGetToRoot(rootID ids.ID, start maybe.Maybe[[]
 	}
 
 	var (
-		startPath                    = maybe.Bind(start, newPath)
-		endPath                      = maybe.Bind(end, newPath)
+		startPath                    = maybe.Bind(start, th.branchFactor.newPath)
+		endPath                      = maybe.Bind(end, th.branchFactor.newPath)
 		combinedChanges              = newChangeSummary(defaultPreallocationSize)
 		mostRecentChangeInsertNumber = th.nextInsertNumber - 1
 		mostRecentChangeIndex        = th.history.Len() - 1
@@ -4,13 +4,19 @@
 package merkledb
 
 import (
+	"errors"
+	"math/bits"
 	"sync"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/ava-labs/avalanchego/database"
 )
 
 const defaultBufferLength = 256
 
+var errInvalidIntermediateNodeKey = errors.New("invalid intermediate node key")
+
 // Holds intermediate nodes. That is, those without values.
 // Changes to this database aren't written to [baseDB] until
 // they're evicted from the [nodeCache] or Flush is called..
@@ -31,6 +37,10 @@ type intermediateNodeDB struct {
 	// the number of bytes to evict during an eviction batch
 	evictionBatchSize int
 	metrics           merkleMetrics
+
+	// The branch factor and hasher of the trie.
+	branchFactor BranchFactor
+	hasher       Hasher
 }
 
 func newIntermediateNodeDB(
@@ -39,26 +49,34 @@ func newIntermediateNodeDB(
 	metrics merkleMetrics,
 	size int,
 	evictionBatchSize int,
+	branchFactor BranchFactor,
+	hasher Hasher,
 ) *intermediateNodeDB {
 	result := &intermediateNodeDB{
 		metrics:           metrics,
 		baseDB:            db,
 		bufferPool:        bufferPool,
 		evictionBatchSize: evictionBatchSize,
+		branchFactor:      branchFactor,
+		hasher:            hasher,
 	}
 	result.nodeCache = newOnEvictCache(
 		size,
-		cacheEntrySize,
+		result.cacheEntrySize,
 		result.onEviction,
 	)
 	return result
 }
 
+func (db *intermediateNodeDB) cacheEntrySize(key path, n *node) int {
+	return cacheEntrySize(key, n, db.branchFactor)
+}
+
 // A non-nil error is considered fatal and closes [db.baseDB].
 func (db *intermediateNodeDB) onEviction(key path, n *node) error {
 	writeBatch := db.baseDB.NewBatch()
 
-	totalSize := cacheEntrySize(key, n)
+	totalSize := db.cacheEntrySize(key, n)
 	if err := db.addToBatch(writeBatch, key, n); err != nil {
 		_ = db.baseDB.Close()
 		return err
@@ -75,7 +93,7 @@ func (db *intermediateNodeDB) onEviction(key path, n *node) error {
 			// The cache is empty.
 			break
 		}
-		totalSize += cacheEntrySize(key, n)
+		totalSize += db.cacheEntrySize(key, n)
 		if err := db.addToBatch(writeBatch, key, n); err != nil {
 			_ = db.baseDB.Close()
 			return err
@@ -95,7 +113,7 @@ func (db *intermediateNodeDB) addToBatch(b database.Batch, key path, n *node) er
 	if n == nil {
 		return b.Delete(dbKey)
 	}
-	return b.Put(dbKey, n.bytes())
+	return b.Put(dbKey, n.bytes(db.branchFactor))
 }
 
 func (db *intermediateNodeDB) Get(key path) (*node, error) {
@@ -116,7 +134,7 @@ func (db *intermediateNodeDB) Get(key path) (*node, error) {
 	}
 	db.bufferPool.Put(dbKey)
 
-	return parseNode(key, nodeBytes)
+	return parseNode(key, nodeBytes, db.branchFactor, db.hasher)
 }
 
 // constructDBKey returns a key that can be used in [db.baseDB].
@@ -124,11 +142,11 @@ func (db *intermediateNodeDB) Get(key path) (*node, error) {
 // byte length but different token length so we add padding to differentiate.
 // Additionally, we add a prefix indicating it is part of the intermediateNodeDB.
 func (db *intermediateNodeDB) constructDBKey(key path) []byte {
-	compressedKey := key.Serialize()
+	compressedKey := db.branchFactor.serialize(key)
 
 	// add one additional byte to store padding when the path
 	// has a length that fits into a whole number of bytes
-	remainder := compressedKey.NibbleLength % 2
+	remainder := compressedKey.NibbleLength % db.branchFactor.tokensPerByte()
 	keyLen := len(compressedKey.Value)
 	if remainder == 0 {
 		keyLen++
@@ -136,16 +154,56 @@ func (db *intermediateNodeDB) constructDBKey(key path) []byte {
 	dbKey := getBufferFromPool(db.bufferPool, keyLen)
 	defer db.bufferPool.Put(dbKey)
 
+	// the padding is a 1 bit directly after the last token
 	copy(dbKey, compressedKey.Value)
 	if remainder == 0 {
 		dbKey[keyLen-1] = 0b1000_0000
 	} else {
-		dbKey[keyLen-1] |= 0b0000_1000
+		dbKey[keyLen-1] |= 0b1000_0000 >> (remainder * db.branchFactor.tokenBitSize())
 	}
 
 	return addPrefixToKey(db.bufferPool, intermediateNodePrefix, dbKey)
 }
 
+// parseIntermediateNodeDBKey returns the path of the intermediate node stored
+// at [dbKey] in a trie with branch factor [bf].
+// This is the inverse of [constructDBKey].
+// Assumes [bf] is valid.
+func parseIntermediateNodeDBKey(dbKey []byte, bf BranchFactor) (path, error) {
+	if len(dbKey) <= len(intermediateNodePrefix) {
+		return EmptyPath, errInvalidIntermediateNodeKey
+	}
+	dbKey = dbKey[len(intermediateNodePrefix):]
+
+	lastIndex := len(dbKey) - 1
+	lastByte := dbKey[lastIndex]
+	if lastByte == 0 {
+		return EmptyPath, errInvalidIntermediateNodeKey
+	}
+	if lastByte == 0b1000_0000 {
+		// The path fits into a whole number of bytes and was padded with an
+		// additional byte.
+		return bf.deserialize(SerializedPath{
+			NibbleLength: bf.tokensPerByte() * lastIndex,
+			Value:        dbKey[:lastIndex],
+		}), nil
+	}
+
+	// The padding was added to the last byte directly after the last token.
+	paddingBit := bits.TrailingZeros8(lastByte)
+	tokenBitSize := bf.tokenBitSize()
+	remainderBits := 7 - paddingBit
+	if remainderBits%tokenBitSize != 0 {
+		return EmptyPath, errInvalidIntermediateNodeKey
+	}
+	value := slices.Clone(dbKey)
+	value[lastIndex] &^= 1 << paddingBit
+	return bf.deserialize(SerializedPath{
+		NibbleLength: bf.tokensPerByte()*lastIndex + remainderBits/tokenBitSize,
+		Value:        value,
+	}), nil
+}
+
 func (db *intermediateNodeDB) Put(key path, n *node) error {
 	return db.nodeCache.Put(key, n)
 }
@@ -35,12 +35,14 @@ func Test_IntermediateNodeDB(t *testing.T) {
 		&mockMetrics{},
 		cacheSize,
 		evictionBatchSize,
+		BranchFactor16,
+		DefaultHasher,
 	)
 
 	// Put a key-node pair
 	node1Key := newPath([]byte{0x01})
 	node1 := newNode(nil, node1Key)
-	node1.setValue(maybe.Some([]byte{byte(0x01)}))
+	node1.setValue(maybe.Some([]byte{byte(0x01)}), DefaultHasher)
 	require.NoError(db.Put(node1Key, node1))
 
 	// Get the key-node pair from cache
@@ -50,7 +52,7 @@ func Test_IntermediateNodeDB(t *testing.T) {
 
 	// Overwrite the key-node pair
 	node1Updated := newNode(nil, node1Key)
-	node1Updated.setValue(maybe.Some([]byte{byte(0x02)}))
+	node1Updated.setValue(maybe.Some([]byte{byte(0x02)}), DefaultHasher)
 	require.NoError(db.Put(node1Key, node1Updated))
 
 	// Assert the key-node pair was overwritten
@@ -71,8 +73,8 @@ func Test_IntermediateNodeDB(t *testing.T) {
 	for {
 		key := newPath([]byte{byte(added)})
 		node := newNode(nil, key)
-		node.setValue(maybe.Some([]byte{byte(added)}))
-		newExpectedSize := expectedSize + cacheEntrySize(key, node)
+		node.setValue(maybe.Some([]byte{byte(added)}), DefaultHasher)
+		newExpectedSize := expectedSize + db.cacheEntrySize(key, node)
 		if newExpectedSize > cacheSize {
 			// Don't trigger eviction.
 			break
@@ -91,7 +93,7 @@ func Test_IntermediateNodeDB(t *testing.T) {
 	// the added key prefix increasing the size tracked by the batch.
 	key := newPath([]byte{byte(added)})
 	node := newNode(nil, key)
-	node.setValue(maybe.Some([]byte{byte(added)}))
+	node.setValue(maybe.Some([]byte{byte(added)}), DefaultHasher)
 	require.NoError(db.Put(key, node))
 
 	// Assert cache has expected number of elements
@@ -138,6 +140,8 @@ func FuzzIntermediateNodeDBConstructDBKey(f *testing.F) {
 		&mockMetrics{},
 		cacheSize,
 		evictionBatchSize,
+		BranchFactor16,
+		DefaultHasher,
 	)
 
 	f.Fuzz(func(
@@ -174,6 +178,8 @@ func Test_IntermediateNodeDB_ConstructDBKey_DirtyBuffer(t *testing.T) {
 		&mockMetrics{},
 		cacheSize,
 		evictionBatchSize,
+		BranchFactor16,
+		DefaultHasher,
 	)
 
 	db.bufferPool.Put([]byte{0xFF, 0xFF, 0xFF})
@@ -194,3 +200,66 @@ func Test_IntermediateNodeDB_ConstructDBKey_DirtyBuffer(t *testing.T) {
 	require.Equal(intermediateNodePrefix, constructedKey[:len(intermediateNodePrefix)])
 	require.Equal(p.Append(0b0000_1000).Serialize().Value, constructedKey[len(intermediateNodePrefix):])
 }
+
+func Test_IntermediateNodeDB_ParseDBKey(t *testing.T) {
+	require := require.New(t)
+
+	db := newIntermediateNodeDB(
+		memdb.New(),
+		&sync.Pool{
+			New: func() interface{} { return make([]byte, 0, defaultBufferLength) },
+		},
+		&mockMetrics{},
+		200,
+		200,
+		BranchFactor16,
+		DefaultHasher,
+	)
+
+	for _, p := range []path{
+		EmptyPath,
+		path([]byte{0}),
+		path([]byte{8}),
+		path([]byte{15, 8}),
+		path([]byte{1, 2, 3}),
+		newPath([]byte{0x80}),
+		newPath([]byte("key")),
+	} {
+		dbKey := db.constructDBKey(p)
+		parsed, err := parseIntermediateNodeDBKey(dbKey, BranchFactor16)
+		require.NoError(err)
+		require.Equal(p, parsed)
+	}
+
+	_, err := parseIntermediateNodeDBKey(intermediateNodePrefix, BranchFactor16)
+	require.ErrorIs(err, errInvalidIntermediateNodeKey)
+
+	_, err = parseIntermediateNodeDBKey(append(intermediateNodePrefix, 0b0000_0001), BranchFactor16)
+	require.ErrorIs(err, errInvalidIntermediateNodeKey)
+}
+
+func Test_IntermediateNodeDB_ParseDBKey_BranchFactors(t *testing.T) {
+	require := require.New(t)
+
+	for _, bf := range []BranchFactor{BranchFactor2, BranchFactor4, BranchFactor16, BranchFactor256} {
+		db := newIntermediateNodeDB(
+			memdb.New(),
+			&sync.Pool{
+				New: func() interface{} { return make([]byte, 0, defaultBufferLength) },
+			},
+			&mockMetrics{},
+			200,
+			200,
+			bf,
+			DefaultHasher,
+		)
+
+		key := bf.newPath([]byte{0xFF, 0x00, 0x81})
+		for i := 0; i <= len(key); i++ {
+			dbKey := db.constructDBKey(key[:i])
+			parsed, err := parseIntermediateNodeDBKey(dbKey, bf)
+			require.NoError(err)
+			require.Equal(key[:i], parsed)
+		}
+	}
+}
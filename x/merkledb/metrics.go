@@ -28,6 +28,10 @@ type merkleMetrics interface {
 	ViewNodeCacheMiss()
 	ViewValueCacheHit()
 	ViewValueCacheMiss()
+	PruningStarted()
+	PruningFinished()
+	PrunedNodeScanned()
+	PrunedNodeDeleted()
 }
 
 type mockMetrics struct {
@@ -43,6 +47,9 @@ type mockMetrics struct {
 	viewNodeCacheMiss         int64
 	viewValueCacheHit         int64
 	viewValueCacheMiss        int64
+	pruningInProgress         bool
+	prunedNodeScanned         int64
+	prunedNodeDeleted         int64
 }
 
 func (m *mockMetrics) HashCalculated() {
@@ -122,6 +129,34 @@ func (m *mockMetrics) IntermediateNodeCacheMiss() {
 	m.intermediateNodeCacheMiss++
 }
 
+func (m *mockMetrics) PruningStarted() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.pruningInProgress = true
+}
+
+func (m *mockMetrics) PruningFinished() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.pruningInProgress = false
+}
+
+func (m *mockMetrics) PrunedNodeScanned() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.prunedNodeScanned++
+}
+
+func (m *mockMetrics) PrunedNodeDeleted() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.prunedNodeDeleted++
+}
+
 type metrics struct {
 	ioKeyWrite                prometheus.Counter
 	ioKeyRead                 prometheus.Counter
@@ -134,6 +169,9 @@ type metrics struct {
 	viewNodeCacheMiss         prometheus.Counter
 	viewValueCacheHit         prometheus.Counter
 	viewValueCacheMiss        prometheus.Counter
+	pruningInProgress         prometheus.Gauge
+	prunedNodeScanned         prometheus.Counter
+	prunedNodeDeleted         prometheus.Counter
 }
 
 func newMetrics(namespace string, reg prometheus.Registerer) (merkleMetrics, error) {
@@ -197,6 +235,21 @@ func newMetrics(namespace string, reg prometheus.Registerer) (merkleMetrics, err
 			Name:      "view_value_cache_miss",
 			Help:      "cumulative amount of misses on the view value cache",
 		}),
+		pruningInProgress: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pruning_in_progress",
+			Help:      "1 if intermediate nodes are currently being pruned, 0 otherwise",
+		}),
+		prunedNodeScanned: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pruning_nodes_scanned",
+			Help:      "cumulative number of intermediate nodes checked for reachability while pruning",
+		}),
+		prunedNodeDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pruning_nodes_deleted",
+			Help:      "cumulative number of unreachable intermediate nodes deleted while pruning",
+		}),
 	}
 	errs := wrappers.Errs{}
 	errs.Add(
@@ -211,6 +264,9 @@ func newMetrics(namespace string, reg prometheus.Registerer) (merkleMetrics, err
 		reg.Register(m.viewNodeCacheMiss),
 		reg.Register(m.viewValueCacheHit),
 		reg.Register(m.viewValueCacheMiss),
+		reg.Register(m.pruningInProgress),
+		reg.Register(m.prunedNodeScanned),
+		reg.Register(m.prunedNodeDeleted),
 	)
 	return &m, errs.Err
 }
@@ -258,3 +314,19 @@ func (m *metrics) ValueNodeCacheHit() {
 func (m *metrics) ValueNodeCacheMiss() {
 	m.valueNodeCacheMiss.Inc()
 }
+
+func (m *metrics) PruningStarted() {
+	m.pruningInProgress.Set(1)
+}
+
+func (m *metrics) PruningFinished() {
+	m.pruningInProgress.Set(0)
+}
+
+func (m *metrics) PrunedNodeScanned() {
+	m.prunedNodeScanned.Inc()
+}
+
+func (m *metrics) PrunedNodeDeleted() {
+	m.prunedNodeDeleted.Inc()
+}
@@ -155,6 +155,21 @@ func (mr *MockMerkleDBMockRecorder) GetMerkleRoot(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerkleRoot", reflect.TypeOf((*MockMerkleDB)(nil).GetMerkleRoot), arg0)
 }
 
+// GetMultiProof mocks base method.
+func (m *MockMerkleDB) GetMultiProof(arg0 context.Context, arg1 [][]byte) (*MultiProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMultiProof", arg0, arg1)
+	ret0, _ := ret[0].(*MultiProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMultiProof indicates an expected call of GetMultiProof.
+func (mr *MockMerkleDBMockRecorder) GetMultiProof(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMultiProof", reflect.TypeOf((*MockMerkleDB)(nil).GetMultiProof), arg0, arg1)
+}
+
 // GetProof mocks base method.
 func (m *MockMerkleDB) GetProof(arg0 context.Context, arg1 []byte) (*Proof, error) {
 	m.ctrl.T.Helper()
@@ -345,6 +360,35 @@ func (mr *MockMerkleDBMockRecorder) NewView(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewView", reflect.TypeOf((*MockMerkleDB)(nil).NewView), arg0, arg1)
 }
 
+// NewViewAtRoot mocks base method.
+func (m *MockMerkleDB) NewViewAtRoot(arg0 context.Context, arg1 ids.ID, arg2 ViewChanges) (TrieView, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewViewAtRoot", arg0, arg1, arg2)
+	ret0, _ := ret[0].(TrieView)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewViewAtRoot indicates an expected call of NewViewAtRoot.
+func (mr *MockMerkleDBMockRecorder) NewViewAtRoot(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewViewAtRoot", reflect.TypeOf((*MockMerkleDB)(nil).NewViewAtRoot), arg0, arg1, arg2)
+}
+
+// Prune mocks base method.
+func (m *MockMerkleDB) Prune(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prune", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Prune indicates an expected call of Prune.
+func (mr *MockMerkleDBMockRecorder) Prune(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockMerkleDB)(nil).Prune), arg0)
+}
+
 // Put mocks base method.
 func (m *MockMerkleDB) Put(arg0, arg1 []byte) error {
 	m.ctrl.T.Helper()
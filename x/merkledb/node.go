@@ -8,11 +8,12 @@ import (
 	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanchego/ids"
-	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 )
 
 const (
+	// NodeBranchFactor is the branch factor of a trie with
+	// [DefaultBranchFactor].
 	NodeBranchFactor = 16
 	HashLength       = 32
 )
@@ -61,9 +62,10 @@ func newNode(parent *node, key path) *node {
 }
 
 // Parse [nodeBytes] to a node and set its key to [key].
-func parseNode(key path, nodeBytes []byte) (*node, error) {
+// [bf] and [hasher] are the branch factor and hasher of the trie.
+func parseNode(key path, nodeBytes []byte, bf BranchFactor, hasher Hasher) (*node, error) {
 	n := dbNode{}
-	if err := codec.decodeDBNode(nodeBytes, &n); err != nil {
+	if err := codec.decodeDBNode(nodeBytes, &n, bf); err != nil {
 		return nil, err
 	}
 	result := &node{
@@ -72,7 +74,7 @@ func parseNode(key path, nodeBytes []byte) (*node, error) {
 		nodeBytes: nodeBytes,
 	}
 
-	result.setValueDigest()
+	result.setValueDigest(hasher)
 	return result, nil
 }
 
@@ -82,9 +84,10 @@ func (n *node) hasValue() bool {
 }
 
 // Returns the byte representation of this node.
-func (n *node) bytes() []byte {
+// [bf] is the branch factor of the trie.
+func (n *node) bytes(bf BranchFactor) []byte {
 	if n.nodeBytes == nil {
-		n.nodeBytes = codec.encodeDBNode(&n.dbNode)
+		n.nodeBytes = codec.encodeDBNode(&n.dbNode, bf)
 	}
 
 	return n.nodeBytes
@@ -98,7 +101,8 @@ func (n *node) onNodeChanged() {
 }
 
 // Returns and caches the ID of this node.
-func (n *node) calculateID(metrics merkleMetrics) {
+// [bf] and [hasher] are the branch factor and hasher of the trie.
+func (n *node) calculateID(bf BranchFactor, hasher Hasher, metrics merkleMetrics) {
 	if n.id != ids.Empty {
 		return
 	}
@@ -107,24 +111,21 @@ func (n *node) calculateID(metrics merkleMetrics) {
 	bytes := codec.encodeHashValues(&hashValues{
 		Children: n.children,
 		Value:    n.valueDigest,
-		Key:      n.key.Serialize(),
-	})
-	n.id = hashing.ComputeHash256Array(bytes)
+		Key:      bf.serialize(n.key),
+	}, bf)
+	n.id = hasher.Hash(bytes)
 }
 
 // Set [n]'s value to [val].
-func (n *node) setValue(val maybe.Maybe[[]byte]) {
+// [hasher] is the hasher of the trie.
+func (n *node) setValue(val maybe.Maybe[[]byte], hasher Hasher) {
 	n.onNodeChanged()
 	n.value = val
-	n.setValueDigest()
+	n.setValueDigest(hasher)
 }
 
-func (n *node) setValueDigest() {
-	if n.value.IsNothing() || len(n.value.Value()) < HashLength {
-		n.valueDigest = n.value
-	} else {
-		n.valueDigest = maybe.Some(hashing.ComputeHash256(n.value.Value()))
-	}
+func (n *node) setValueDigest(hasher Hasher) {
+	n.valueDigest = getValueDigest(hasher, n.value)
 }
 
 // Adds [child] as a child of [n].
@@ -173,9 +174,10 @@ func (n *node) clone() *node {
 }
 
 // Returns the ProofNode representation of this node.
-func (n *node) asProofNode() ProofNode {
+// [bf] is the branch factor of the trie.
+func (n *node) asProofNode(bf BranchFactor) ProofNode {
 	pn := ProofNode{
-		KeyPath:     n.key.Serialize(),
+		KeyPath:     bf.serialize(n.key),
 		Children:    make(map[byte]ids.ID, len(n.children)),
 		ValueOrHash: maybe.Bind(n.valueDigest, slices.Clone[[]byte]),
 	}
@@ -18,14 +18,14 @@ func Test_Node_Marshal(t *testing.T) {
 
 	fullpath := newPath([]byte("key"))
 	childNode := newNode(root, fullpath)
-	childNode.setValue(maybe.Some([]byte("value")))
+	childNode.setValue(maybe.Some([]byte("value")), DefaultHasher)
 	require.NotNil(t, childNode)
 
-	childNode.calculateID(&mockMetrics{})
+	childNode.calculateID(BranchFactor16, DefaultHasher, &mockMetrics{})
 	root.addChild(childNode)
 
-	data := root.bytes()
-	rootParsed, err := parseNode(newPath([]byte("")), data)
+	data := root.bytes(BranchFactor16)
+	rootParsed, err := parseNode(newPath([]byte("")), data, BranchFactor16, DefaultHasher)
 	require.NoError(t, err)
 	require.Len(t, rootParsed.children, 1)
 
@@ -42,25 +42,25 @@ func Test_Node_Marshal_Errors(t *testing.T) {
 
 	fullpath := newPath([]byte{255})
 	childNode1 := newNode(root, fullpath)
-	childNode1.setValue(maybe.Some([]byte("value1")))
+	childNode1.setValue(maybe.Some([]byte("value1")), DefaultHasher)
 	require.NotNil(t, childNode1)
 
-	childNode1.calculateID(&mockMetrics{})
+	childNode1.calculateID(BranchFactor16, DefaultHasher, &mockMetrics{})
 	root.addChild(childNode1)
 
 	fullpath = newPath([]byte{237})
 	childNode2 := newNode(root, fullpath)
-	childNode2.setValue(maybe.Some([]byte("value2")))
+	childNode2.setValue(maybe.Some([]byte("value2")), DefaultHasher)
 	require.NotNil(t, childNode2)
 
-	childNode2.calculateID(&mockMetrics{})
+	childNode2.calculateID(BranchFactor16, DefaultHasher, &mockMetrics{})
 	root.addChild(childNode2)
 
-	data := root.bytes()
+	data := root.bytes(BranchFactor16)
 
 	for i := 1; i < len(data); i++ {
 		broken := data[:i]
-		_, err := parseNode(newPath([]byte("")), broken)
+		_, err := parseNode(newPath([]byte("")), broken, BranchFactor16, DefaultHasher)
 		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
 	}
 }
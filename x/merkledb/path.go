@@ -5,6 +5,8 @@ package merkledb
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"unsafe"
@@ -12,10 +14,216 @@ import (
 
 const EmptyPath path = ""
 
+var ErrInvalidBranchFactor = errors.New("branch factor must be 2, 4, 16 or 256")
+
+// BranchFactor is the maximum number of children of each node in a trie.
+// Keys are split into tokens of log2(BranchFactor) bits. The tokens of a
+// key are the path from the root to the key's node.
+type BranchFactor int
+
+const (
+	BranchFactor2   BranchFactor = 2
+	BranchFactor4   BranchFactor = 4
+	BranchFactor16  BranchFactor = NodeBranchFactor
+	BranchFactor256 BranchFactor = 256
+
+	// The branch factor used if none is specified.
+	DefaultBranchFactor = BranchFactor16
+)
+
+// Valid returns nil iff [b] is a supported branch factor.
+func (b BranchFactor) Valid() error {
+	switch b {
+	case BranchFactor2, BranchFactor4, BranchFactor16, BranchFactor256:
+		return nil
+	default:
+		return fmt.Errorf("%w but was %d", ErrInvalidBranchFactor, b)
+	}
+}
+
+// Returns [b], or [DefaultBranchFactor] if [b] is unspecified.
+func (b BranchFactor) orDefault() BranchFactor {
+	if b == 0 {
+		return DefaultBranchFactor
+	}
+	return b
+}
+
+// Returns the number of bits in each token.
+// Assumes [b] is valid.
+func (b BranchFactor) tokenBitSize() int {
+	switch b {
+	case BranchFactor2:
+		return 1
+	case BranchFactor4:
+		return 2
+	case BranchFactor16:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// Returns the number of tokens in each byte of a key.
+// Assumes [b] is valid.
+func (b BranchFactor) tokensPerByte() int {
+	return 8 / b.tokenBitSize()
+}
+
+// Returns true iff a path of [tokenLength] tokens doesn't fit into a whole
+// number of bytes. Such a path can't be the path of a key.
+// Assumes [b] is valid.
+func (b BranchFactor) hasPartialByte(tokenLength int) bool {
+	return tokenLength%b.tokensPerByte() != 0
+}
+
+// Returns the path of [key] in a trie with branch factor [b].
+// Assumes [b] is valid.
+func (b BranchFactor) newPath(key []byte) path {
+	var (
+		tokenBitSize  = b.tokenBitSize()
+		tokensPerByte = b.tokensPerByte()
+		mask          = byte(b - 1)
+		buffer        = make([]byte, tokensPerByte*len(key))
+	)
+	for i, currentByte := range key {
+		for j := 0; j < tokensPerByte; j++ {
+			shift := 8 - tokenBitSize*(j+1)
+			buffer[i*tokensPerByte+j] = currentByte >> shift & mask
+		}
+	}
+
+	// avoid copying during the conversion
+	return *(*path)(unsafe.Pointer(&buffer))
+}
+
+// Returns the serialized representation of [p], which is a path in a trie
+// with branch factor [b].
+// Assumes [b] is valid.
+func (b BranchFactor) serialize(p path) SerializedPath {
+	var (
+		tokenBitSize  = b.tokenBitSize()
+		tokensPerByte = b.tokensPerByte()
+		// round up so there is a byte for the trailing tokens if they exist
+		byteLength = (len(p) + tokensPerByte - 1) / tokensPerByte
+	)
+
+	result := SerializedPath{
+		NibbleLength: len(p),
+		Value:        make([]byte, byteLength),
+	}
+	for i := 0; i < len(p); i++ {
+		shift := 8 - tokenBitSize*(i%tokensPerByte+1)
+		result.Value[i/tokensPerByte] |= p[i] << shift
+	}
+	return result
+}
+
+// Returns the path represented by [s], which is a path in a trie with
+// branch factor [b].
+// Assumes [b] is valid.
+func (b BranchFactor) deserialize(s SerializedPath) path {
+	result := b.newPath(s.Value)
+	// trim the padding tokens of the last byte if the path doesn't fit into
+	// a whole number of bytes
+	tokensPerByte := b.tokensPerByte()
+	if remainder := s.NibbleLength % tokensPerByte; remainder != 0 {
+		return result[:len(result)-tokensPerByte+remainder]
+	}
+	return result
+}
+
+// Returns true iff the number of tokens of [s] fits into exactly the bytes of
+// [s] in a trie with branch factor [b].
+// Assumes [b] is valid.
+func (b BranchFactor) validSerializedPath(s SerializedPath) bool {
+	maxLength := len(s.Value) * b.tokensPerByte()
+	return s.NibbleLength >= 0 &&
+		s.NibbleLength <= maxLength &&
+		s.NibbleLength > maxLength-b.tokensPerByte()
+}
+
+// KeyPath returns the serialized path of [key] in a trie with branch factor
+// [b].
+// Assumes [b] is valid.
+func (b BranchFactor) KeyPath(key []byte) SerializedPath {
+	return SerializedPath{
+		NibbleLength: len(key) * b.tokensPerByte(),
+		Value:        key,
+	}
+}
+
+// HasPrefix returns true iff [prefix] is a prefix of [s] or equal to it,
+// where [s] and [prefix] are paths in a trie with branch factor [b].
+// Assumes [b] is valid.
+func (b BranchFactor) HasPrefix(s SerializedPath, prefix SerializedPath) bool {
+	if s.NibbleLength < prefix.NibbleLength {
+		return false
+	}
+
+	tokensPerByte := b.tokensPerByte()
+	wholeBytes := prefix.NibbleLength / tokensPerByte
+	remainder := prefix.NibbleLength % tokensPerByte
+	prefixByteLength := wholeBytes
+	if remainder != 0 {
+		prefixByteLength++
+	}
+
+	// the input was invalid so just return false
+	if len(prefix.Value) < prefixByteLength || len(s.Value) < prefixByteLength {
+		return false
+	}
+	if !bytes.HasPrefix(s.Value, prefix.Value[:wholeBytes]) {
+		return false
+	}
+	if remainder == 0 {
+		return true
+	}
+
+	// only the first [remainder] tokens of the last byte of [prefix] are
+	// compared, as the rest of its bits are padding
+	shift := 8 - b.tokenBitSize()*remainder
+	return s.Value[wholeBytes]>>shift == prefix.Value[wholeBytes]>>shift
+}
+
+// Token returns the token at [index] of [s], which is a path in a trie with
+// branch factor [b].
+// Assumes [b] is valid and [index] < [s.NibbleLength].
+func (b BranchFactor) Token(s SerializedPath, index int) byte {
+	tokensPerByte := b.tokensPerByte()
+	shift := 8 - b.tokenBitSize()*(index%tokensPerByte+1)
+	return s.Value[index/tokensPerByte] >> shift & byte(b-1)
+}
+
+// AppendToken returns [s] with [token] appended, where [s] is a path in a trie
+// with branch factor [b].
+// Assumes [b] is valid and [token] < [b].
+func (b BranchFactor) AppendToken(s SerializedPath, token byte) SerializedPath {
+	var (
+		tokenBitSize  = b.tokenBitSize()
+		tokensPerByte = b.tokensPerByte()
+		// round up so there is a byte for the appended token
+		byteLength = s.NibbleLength/tokensPerByte + 1
+		shift      = 8 - tokenBitSize*(s.NibbleLength%tokensPerByte+1)
+	)
+	value := make([]byte, byteLength)
+	copy(value, s.Value)
+
+	// clear the padding bits of the last byte before setting the token
+	lastByte := value[byteLength-1] & (0xFF << (shift + tokenBitSize))
+	value[byteLength-1] = lastByte | token<<shift
+	return SerializedPath{Value: value, NibbleLength: s.NibbleLength + 1}
+}
+
 // SerializedPath contains a path from the trie.
-// The trie branch factor is 16, so the path may contain an odd number of nibbles.
-// If it did contain an odd number of nibbles, the last 4 bits of the last byte should be discarded.
+// Each token of the path is log2(BranchFactor) bits, so the path may not fit
+// into a whole number of bytes. If it doesn't, the unused bits of the last
+// byte should be discarded.
+//
+// The methods of SerializedPath assume the default branch factor of 16, in
+// which case each token is a nibble.
 type SerializedPath struct {
+	// The number of tokens in the path.
 	NibbleLength int
 	Value        []byte
 }
@@ -28,10 +236,9 @@ func (s SerializedPath) Equal(other SerializedPath) bool {
 	return s.NibbleLength == other.NibbleLength && bytes.Equal(s.Value, other.Value)
 }
 
+// Returns the path represented by [s] in a trie with [BranchFactor16].
 func (s SerializedPath) deserialize() path {
-	result := newPath(s.Value)
-	// trim the last nibble if the path has an odd length
-	return result[:len(result)-s.NibbleLength&1]
+	return BranchFactor16.deserialize(s)
 }
 
 // HasPrefix returns true iff [prefix] is a prefix of [s] or equal to it.
@@ -113,51 +320,16 @@ func (p path) HasPrefix(prefix path) bool {
 
 // Append [val] to [p].
 func (p path) Append(val byte) path {
-	return p + path(val)
+	return p + path([]byte{val})
 }
 
-// Returns the serialized representation of [p].
+// Returns the serialized representation of [p] in a trie with
+// [BranchFactor16].
 func (p path) Serialize() SerializedPath {
-	// need half the number of bytes as nibbles
-	// add one so there is a byte for the odd nibble if it exists
-	// the extra nibble gets rounded down if even length
-	byteLength := (len(p) + 1) / 2
-
-	result := SerializedPath{
-		NibbleLength: len(p),
-		Value:        make([]byte, byteLength),
-	}
-
-	// loop over the path's bytes
-	// if the length is odd, subtract 1 so we don't overflow on the p[pathIndex+1]
-	keyIndex := 0
-	lastIndex := len(p) - len(p)&1
-	for pathIndex := 0; pathIndex < lastIndex; pathIndex += 2 {
-		result.Value[keyIndex] = p[pathIndex]<<4 + p[pathIndex+1]
-		keyIndex++
-	}
-
-	// if there is an odd number of nibbles, grab the last one
-	if result.hasOddLength() {
-		result.Value[keyIndex] = p[keyIndex<<1] << 4
-	}
-
-	return result
+	return BranchFactor16.serialize(p)
 }
 
+// Returns the path of [p] in a trie with [BranchFactor16].
 func newPath(p []byte) path {
-	// create new buffer with double the length of the input since each byte gets split into two nibbles
-	buffer := make([]byte, 2*len(p))
-
-	// first nibble gets shifted right 4 (divided by 16) to isolate the first nibble
-	// second nibble gets bitwise anded with 0x0F (1111) to isolate the second nibble
-	bufferIndex := 0
-	for _, currentByte := range p {
-		buffer[bufferIndex] = currentByte >> 4
-		buffer[bufferIndex+1] = currentByte & 0x0F
-		bufferIndex += 2
-	}
-
-	// avoid copying during the conversion
-	return *(*path)(unsafe.Pointer(&buffer))
+	return BranchFactor16.newPath(p)
 }
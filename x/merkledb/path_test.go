@@ -117,3 +117,88 @@ func FuzzPath(f *testing.F) {
 		require.Equal(serializedPath, reserializedPath)
 	})
 }
+
+func Test_BranchFactor_Valid(t *testing.T) {
+	require := require.New(t)
+
+	for _, bf := range []BranchFactor{BranchFactor2, BranchFactor4, BranchFactor16, BranchFactor256} {
+		require.NoError(bf.Valid())
+	}
+	for _, bf := range []BranchFactor{0, 1, 3, 8, 32, 257} {
+		require.ErrorIs(bf.Valid(), ErrInvalidBranchFactor)
+	}
+}
+
+func Test_BranchFactor_NewPath(t *testing.T) {
+	require := require.New(t)
+
+	key := []byte{0b1011_0001}
+	require.Equal(path([]byte{1, 0, 1, 1, 0, 0, 0, 1}), BranchFactor2.newPath(key))
+	require.Equal(path([]byte{0b10, 0b11, 0b00, 0b01}), BranchFactor4.newPath(key))
+	require.Equal(path([]byte{0b1011, 0b0001}), BranchFactor16.newPath(key))
+	require.Equal(path(key), BranchFactor256.newPath(key))
+}
+
+func Test_Path_Append(t *testing.T) {
+	require := require.New(t)
+
+	p := EmptyPath.Append(0).Append(255)
+	require.Equal(path([]byte{0, 255}), p)
+	require.Equal([]byte{0, 255}, BranchFactor256.serialize(p).Value)
+}
+
+func FuzzBranchFactorPath(f *testing.F) {
+	f.Fuzz(func(t *testing.T, pathBytes []byte, trim uint) {
+		require := require.New(t)
+
+		for _, bf := range []BranchFactor{BranchFactor2, BranchFactor4, BranchFactor16, BranchFactor256} {
+			path := bf.newPath(pathBytes)
+			require.Len(path, bf.tokensPerByte()*len(pathBytes))
+
+			// remove some tokens so the path may have a partial byte
+			if len(path) > 0 {
+				path = path[:len(path)-int(trim%uint(bf.tokensPerByte()))]
+			}
+
+			serializedPath := bf.serialize(path)
+			require.Equal(len(path), serializedPath.NibbleLength)
+			require.Equal(path, bf.deserialize(serializedPath))
+		}
+	})
+}
+
+func Test_BranchFactor_SerializedPathHelpers(t *testing.T) {
+	require := require.New(t)
+
+	key := []byte{0b1011_0001, 0b0110_1110}
+	for _, bf := range []BranchFactor{BranchFactor2, BranchFactor4, BranchFactor16, BranchFactor256} {
+		fullPath := bf.newPath(key)
+		serializedKey := bf.KeyPath(key)
+		require.Equal(bf.serialize(fullPath), serializedKey)
+
+		for i := range fullPath {
+			require.Equal(fullPath[i], bf.Token(serializedKey, i))
+		}
+
+		for length := 0; length <= len(fullPath); length++ {
+			prefix := bf.serialize(fullPath[:length])
+			require.True(bf.HasPrefix(serializedKey, prefix))
+			require.Equal(length == len(fullPath), bf.HasPrefix(prefix, serializedKey))
+
+			for token := 0; token < int(bf); token++ {
+				appended := bf.AppendToken(prefix, byte(token))
+				require.Equal(bf.serialize(fullPath[:length].Append(byte(token))), appended)
+				if length < len(fullPath) {
+					require.Equal(byte(token) == fullPath[length], bf.HasPrefix(serializedKey, appended))
+				}
+			}
+		}
+
+		// The padding bits of a partial byte are ignored.
+		if bf != BranchFactor256 {
+			padded := SerializedPath{NibbleLength: 1, Value: []byte{key[0] ^ 0x01}}
+			require.True(bf.HasPrefix(serializedKey, padded))
+			require.Equal(bf.serialize(fullPath[:2]), bf.AppendToken(padded, fullPath[1]))
+		}
+	}
+}
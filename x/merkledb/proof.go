@@ -9,12 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
-	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 
 	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
@@ -42,7 +42,8 @@ var (
 	ErrProofValueDoesntMatch       = errors.New("the provided value does not match the proof node for the provided key's value")
 	ErrProofNodeHasUnincludedValue = errors.New("the provided proof has a value for a key within the range that is not present in the provided key/values")
 	ErrInvalidMaybe                = errors.New("maybe is nothing but has value")
-	ErrInvalidChildIndex           = fmt.Errorf("child index must be less than %d", NodeBranchFactor)
+	ErrInvalidChildIndex           = errors.New("child index must be less than the branch factor")
+	ErrInvalidKeyLength            = errors.New("proof node key length doesn't match its number of tokens")
 	ErrNilProofNode                = errors.New("proof node is nil")
 	ErrNilValueOrHash              = errors.New("proof node's valueOrHash field is nil")
 	ErrNilSerializedPath           = errors.New("serialized path is nil")
@@ -52,6 +53,13 @@ var (
 	ErrNilProof                    = errors.New("proof is nil")
 	ErrNilValue                    = errors.New("value is nil")
 	ErrUnexpectedEndProof          = errors.New("end proof should be empty")
+	ErrNoKeys                      = errors.New("no keys to prove")
+	ErrNilMultiProof               = errors.New("multi proof is nil")
+	ErrKeysValuesLengthMismatch    = errors.New("number of keys doesn't match number of values")
+	ErrNonIncreasingProofNodeKeys  = errors.New("proof node keys are not in increasing order")
+	ErrIncompleteMultiProof        = errors.New("proof doesn't include the node a key would be under")
+	ErrBranchFactorMismatch        = errors.New("proof branch factor doesn't match the trie's branch factor")
+	ErrHasherMismatch              = errors.New("proof hasher doesn't match the trie's hasher")
 )
 
 type ProofNode struct {
@@ -102,7 +110,9 @@ func (node *ProofNode) UnmarshalProto(pbNode *pb.ProofNode) error {
 
 	node.Children = make(map[byte]ids.ID, len(pbNode.Children))
 	for childIndex, childIDBytes := range pbNode.Children {
-		if childIndex >= NodeBranchFactor {
+		// The child indices are verified against the branch factor of the
+		// trie by the proof the node is in.
+		if childIndex >= uint32(BranchFactor256) {
 			return ErrInvalidChildIndex
 		}
 		childID, err := ids.ToID(childIDBytes)
@@ -131,6 +141,13 @@ type Proof struct {
 	// Nothing if [Key] isn't in the trie.
 	// Otherwise the value corresponding to [Key].
 	Value maybe.Maybe[[]byte]
+
+	// The branch factor of the trie.
+	// If 0, the trie is assumed to have [DefaultBranchFactor].
+	BranchFactor BranchFactor
+	// The hasher of the trie.
+	// If nil, the trie is assumed to use [DefaultHasher].
+	Hasher Hasher
 }
 
 // Returns nil if the trie given in [proof] has root [expectedRootID].
@@ -141,34 +158,35 @@ func (proof *Proof) Verify(ctx context.Context, expectedRootID ids.ID) error {
 	if len(proof.Path) == 0 {
 		return ErrNoProof
 	}
-	if err := verifyProofPath(proof.Path, newPath(proof.Key)); err != nil {
+	bf := proof.BranchFactor.orDefault()
+	if err := bf.Valid(); err != nil {
+		return err
+	}
+	hasher := hasherOrDefault(proof.Hasher)
+
+	keyPath := bf.newPath(proof.Key)
+	if err := verifyProofPath(proof.Path, keyPath, bf); err != nil {
 		return err
 	}
 
 	// Confirm that the last proof node's value matches the claimed proof value
 	lastNode := proof.Path[len(proof.Path)-1]
+	lastPath := bf.deserialize(lastNode.KeyPath)
 
 	// If the last proof node's key is [proof.Key] (i.e. this is an inclusion proof)
 	// then the value of the last proof node must match [proof.Value].
-	// Note odd length keys can never match the [proof.Key] since it's bytes,
-	// and thus an even number of nibbles.
-	if !lastNode.KeyPath.hasOddLength() &&
-		bytes.Equal(proof.Key, lastNode.KeyPath.Value) &&
-		!valueOrHashMatches(proof.Value, lastNode.ValueOrHash) {
+	if lastPath == keyPath && !valueOrHashMatches(hasher, proof.Value, lastNode.ValueOrHash) {
 		return ErrProofValueDoesntMatch
 	}
 
-	// If the last proof node has an odd length or a different key than [proof.Key]
-	// then this is an exclusion proof and should prove that [proof.Key] isn't in the trie..
-	// Note odd length keys can never match the [proof.Key] since it's bytes,
-	// and thus an even number of nibbles.
-	if (lastNode.KeyPath.hasOddLength() || !bytes.Equal(proof.Key, lastNode.KeyPath.Value)) &&
-		proof.Value.HasValue() {
+	// If the last proof node has a different key than [proof.Key] then this
+	// is an exclusion proof and should prove that [proof.Key] isn't in the trie.
+	if lastPath != keyPath && proof.Value.HasValue() {
 		return ErrProofValueDoesntMatch
 	}
 
 	// Don't bother locking [view] -- nobody else has a reference to it.
-	view, err := getStandaloneTrieView(ctx, nil)
+	view, err := getStandaloneTrieView(ctx, nil, bf, hasher)
 	if err != nil {
 		return err
 	}
@@ -176,7 +194,7 @@ func (proof *Proof) Verify(ctx context.Context, expectedRootID ids.ID) error {
 	// Insert all proof nodes.
 	// [provenPath] is the path that we are proving exists, or the path
 	// that is where the path we are proving doesn't exist should be.
-	provenPath := maybe.Some(proof.Path[len(proof.Path)-1].KeyPath.deserialize())
+	provenPath := maybe.Some(lastPath)
 
 	if err = addPathInfo(view, proof.Path, provenPath, provenPath); err != nil {
 		return err
@@ -199,8 +217,10 @@ func (proof *Proof) ToProto() *pb.Proof {
 	}
 
 	pbProof := &pb.Proof{
-		Key:   proof.Key,
-		Value: value,
+		Key:          proof.Key,
+		Value:        value,
+		BranchFactor: uint32(proof.BranchFactor),
+		HasherId:     uint32(hasherID(proof.Hasher)),
 	}
 
 	pbProof.Proof = make([]*pb.ProofNode, len(proof.Path))
@@ -221,19 +241,195 @@ func (proof *Proof) UnmarshalProto(pbProof *pb.Proof) error {
 		return ErrInvalidMaybe
 	}
 
+	bf, hasher, err := unmarshalTrieConfigProto(pbProof.BranchFactor, pbProof.HasherId)
+	if err != nil {
+		return err
+	}
+	proof.BranchFactor = bf
+	proof.Hasher = hasher
+
 	proof.Key = pbProof.Key
 
 	if !pbProof.Value.IsNothing {
 		proof.Value = maybe.Some(pbProof.Value.Value)
 	}
 
-	proof.Path = make([]ProofNode, len(pbProof.Proof))
-	for i, pbNode := range pbProof.Proof {
-		if err := proof.Path[i].UnmarshalProto(pbNode); err != nil {
+	proof.Path, err = unmarshalProofNodesProto(pbProof.Proof, bf)
+	return err
+}
+
+// A proof of the values of a set of keys.
+// Nodes shared by the paths to multiple keys are only included once.
+type MultiProof struct {
+	// The union of the nodes in the proofs of each key in [Keys].
+	// Sorted by increasing key path. Contains no duplicates.
+	// Must always be non-empty (i.e. have the root node).
+	Nodes []ProofNode
+	// The keys this is a proof of.
+	// Sorted by increasing key. Contains no duplicates.
+	Keys [][]byte
+	// [Values][i] is Nothing if [Keys][i] isn't in the trie.
+	// Otherwise it's the value corresponding to [Keys][i].
+	Values []maybe.Maybe[[]byte]
+
+	// The branch factor and hasher of the trie. See [Proof].
+	BranchFactor BranchFactor
+	Hasher       Hasher
+}
+
+// Returns nil if the trie given in [proof] has root [expectedRootID].
+// That is, this is a valid proof that each key in [proof.Keys] has its
+// value in [proof.Values] in the trie with root [expectedRootID].
+func (proof *MultiProof) Verify(ctx context.Context, expectedRootID ids.ID) error {
+	// Make sure the proof is well-formed.
+	switch {
+	case proof == nil:
+		return ErrNilMultiProof
+	case len(proof.Keys) != len(proof.Values):
+		return ErrKeysValuesLengthMismatch
+	case len(proof.Nodes) == 0:
+		return ErrNoProof
+	}
+	bf := proof.BranchFactor.orDefault()
+	if err := bf.Valid(); err != nil {
+		return err
+	}
+	hasher := hasherOrDefault(proof.Hasher)
+
+	for i := 1; i < len(proof.Keys); i++ {
+		if bytes.Compare(proof.Keys[i-1], proof.Keys[i]) >= 0 {
+			return ErrNonIncreasingValues
+		}
+	}
+
+	nodePaths := make([]path, len(proof.Nodes))
+	nodeIndices := make(map[path]int, len(proof.Nodes))
+	for i, node := range proof.Nodes {
+		// intermediate nodes (nodes with odd nibble length) should never have a value associated with them
+		if bf.hasPartialByte(node.KeyPath.NibbleLength) && !node.ValueOrHash.IsNothing() {
+			return ErrOddLengthWithValue
+		}
+		if err := verifyChildIndices(node, bf); err != nil {
+			return err
+		}
+		nodePath := bf.deserialize(node.KeyPath)
+		if i != 0 && nodePath.Compare(nodePaths[i-1]) <= 0 {
+			return ErrNonIncreasingProofNodeKeys
+		}
+		nodePaths[i] = nodePath
+		nodeIndices[nodePath] = i
+	}
+
+	for i, key := range proof.Keys {
+		if err := verifyMultiProofKey(hasher, proof.Nodes, nodePaths, nodeIndices, bf.newPath(key), proof.Values[i]); err != nil {
 			return err
 		}
 	}
 
+	// Don't bother locking [view] -- nobody else has a reference to it.
+	view, err := getStandaloneTrieView(ctx, nil, bf, hasher)
+	if err != nil {
+		return err
+	}
+
+	// Insert the proof nodes in order of decreasing key path so that every
+	// node is inserted after the nodes below it. The children of each node
+	// that aren't in the proof are added using only their IDs.
+	for i := len(proof.Nodes) - 1; i >= 0; i-- {
+		proofNode := proof.Nodes[i]
+
+		// pass nothing because we are going to overwrite the value digest below
+		n, err := view.insert(nodePaths[i], maybe.Nothing[[]byte]())
+		if err != nil {
+			return err
+		}
+		// We overwrite the valueDigest to be the hash provided in the proof
+		// node because we may not know the pre-image of the valueDigest.
+		n.valueDigest = proofNode.ValueOrHash
+
+		for index, childID := range proofNode.Children {
+			if _, ok := n.children[index]; ok {
+				// This child was inserted from the proof.
+				continue
+			}
+			// We don't know if the child had a value or not, but it doesn't matter.
+			// We only need the IDs to be correct so that the calculated hash is correct.
+			n.addChildWithoutNode(index, EmptyPath, childID, false /*hasValue*/)
+		}
+	}
+
+	gotRootID, err := view.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if expectedRootID != gotRootID {
+		return fmt.Errorf("%w:[%s], expected:[%s]", ErrInvalidProof, gotRootID, expectedRootID)
+	}
+	return nil
+}
+
+// Marshal returns the canonical byte representation of [proof].
+func (proof *MultiProof) Marshal() []byte {
+	return codec.encodeMultiProof(proof)
+}
+
+// Unmarshal sets [proof] to the proof represented by [b], which must be the
+// output of [Marshal].
+func (proof *MultiProof) Unmarshal(b []byte) error {
+	return codec.decodeMultiProof(b, proof)
+}
+
+// Returns nil iff the proof nodes [nodes] prove that the value of [keyPath]
+// is [value], assuming the trie given by [nodes] is valid and uses [hasher].
+// [nodePaths][i] is the key path of [nodes][i] and [nodeIndices] maps each
+// key path in [nodePaths] to its index.
+func verifyMultiProofKey(
+	hasher Hasher,
+	nodes []ProofNode,
+	nodePaths []path,
+	nodeIndices map[path]int,
+	keyPath path,
+	value maybe.Maybe[[]byte],
+) error {
+	// Find the deepest proof node whose key path is a prefix of [keyPath].
+	closestIndex := -1
+	for prefixLen := len(keyPath); prefixLen >= 0; prefixLen-- {
+		if index, ok := nodeIndices[keyPath[:prefixLen]]; ok {
+			closestIndex = index
+			break
+		}
+	}
+	if closestIndex == -1 {
+		return ErrProofNodeNotForKey
+	}
+	closestNode := nodes[closestIndex]
+	closestPath := nodePaths[closestIndex]
+
+	if closestPath == keyPath {
+		// This is an inclusion proof.
+		if !valueOrHashMatches(hasher, value, closestNode.ValueOrHash) {
+			return ErrProofValueDoesntMatch
+		}
+		return nil
+	}
+
+	// This is an exclusion proof.
+	if value.HasValue() {
+		return ErrProofValueDoesntMatch
+	}
+
+	// If [keyPath] would be under a child of the closest node, the proof must
+	// show that the child's key path isn't a prefix of [keyPath].
+	childPath := closestPath.Append(keyPath[len(closestPath)])
+	if _, ok := closestNode.Children[childPath[len(childPath)-1]]; !ok {
+		return nil
+	}
+	childIndex := sort.Search(len(nodePaths), func(i int) bool {
+		return nodePaths[i].Compare(childPath) >= 0
+	})
+	if childIndex == len(nodePaths) || !nodePaths[childIndex].HasPrefix(childPath) {
+		return ErrIncompleteMultiProof
+	}
 	return nil
 }
 
@@ -266,6 +462,10 @@ type RangeProof struct {
 	// This proof proves that the key-value pairs in [KeyValues] are in the trie.
 	// Sorted by increasing key.
 	KeyValues []KeyValue
+
+	// The branch factor and hasher of the trie. See [Proof].
+	BranchFactor BranchFactor
+	Hasher       Hasher
 }
 
 // Returns nil iff all the following hold:
@@ -294,6 +494,11 @@ func (proof *RangeProof) Verify(
 	case len(proof.EndProof) == 0 && (end.HasValue() || len(proof.KeyValues) > 0):
 		return ErrNoEndProof
 	}
+	bf := proof.BranchFactor.orDefault()
+	if err := bf.Valid(); err != nil {
+		return err
+	}
+	hasher := hasherOrDefault(proof.Hasher)
 
 	// Make sure the key-value pairs are sorted and in [start, end].
 	if err := verifyKeyValues(proof.KeyValues, start, end); err != nil {
@@ -307,28 +512,30 @@ func (proof *RangeProof) Verify(
 	// If [largestProvenPath] is Nothing, [proof] should
 	// provide and prove all keys > [smallestProvenPath].
 	// If both are Nothing, [proof] should prove the entire trie.
-	smallestProvenPath := maybe.Bind(start, newPath)
+	smallestProvenPath := maybe.Bind(start, bf.newPath)
 
-	largestProvenPath := maybe.Bind(end, newPath)
+	largestProvenPath := maybe.Bind(end, bf.newPath)
 	if len(proof.KeyValues) > 0 {
 		// If [proof] has key-value pairs, we should insert children
 		// greater than [largestProvenPath] to ancestors of the node containing
 		// [largestProvenPath] so that we get the expected root ID.
-		largestProvenPath = maybe.Some(newPath(proof.KeyValues[len(proof.KeyValues)-1].Key))
+		largestProvenPath = maybe.Some(bf.newPath(proof.KeyValues[len(proof.KeyValues)-1].Key))
 	}
 
 	// The key-value pairs (allegedly) proven by [proof].
 	keyValues := make(map[path][]byte, len(proof.KeyValues))
 	for _, keyValue := range proof.KeyValues {
-		keyValues[newPath(keyValue.Key)] = keyValue.Value
+		keyValues[bf.newPath(keyValue.Key)] = keyValue.Value
 	}
 
 	// Ensure that the start proof is valid and contains values that
 	// match the key/values that were sent.
-	if err := verifyProofPath(proof.StartProof, smallestProvenPath.Value()); err != nil {
+	if err := verifyProofPath(proof.StartProof, smallestProvenPath.Value(), bf); err != nil {
 		return err
 	}
 	if err := verifyAllRangeProofKeyValuesPresent(
+		bf,
+		hasher,
 		proof.StartProof,
 		smallestProvenPath.Value(),
 		largestProvenPath,
@@ -339,10 +546,12 @@ func (proof *RangeProof) Verify(
 
 	// Ensure that the end proof is valid and contains values that
 	// match the key/values that were sent.
-	if err := verifyProofPath(proof.EndProof, largestProvenPath.Value()); err != nil {
+	if err := verifyProofPath(proof.EndProof, largestProvenPath.Value(), bf); err != nil {
 		return err
 	}
 	if err := verifyAllRangeProofKeyValuesPresent(
+		bf,
+		hasher,
 		proof.EndProof,
 		smallestProvenPath.Value(),
 		largestProvenPath,
@@ -361,7 +570,7 @@ func (proof *RangeProof) Verify(
 	}
 
 	// Don't need to lock [view] because nobody else has a reference to it.
-	view, err := getStandaloneTrieView(ctx, ops)
+	view, err := getStandaloneTrieView(ctx, ops, bf, hasher)
 	if err != nil {
 		return err
 	}
@@ -419,9 +628,11 @@ func (proof *RangeProof) ToProto() *pb.RangeProof {
 	}
 
 	return &pb.RangeProof{
-		StartProof: startProof,
-		EndProof:   endProof,
-		KeyValues:  keyValues,
+		StartProof:   startProof,
+		EndProof:     endProof,
+		KeyValues:    keyValues,
+		BranchFactor: uint32(proof.BranchFactor),
+		HasherId:     uint32(hasherID(proof.Hasher)),
 	}
 }
 
@@ -430,18 +641,21 @@ func (proof *RangeProof) UnmarshalProto(pbProof *pb.RangeProof) error {
 		return ErrNilRangeProof
 	}
 
-	proof.StartProof = make([]ProofNode, len(pbProof.StartProof))
-	for i, protoNode := range pbProof.StartProof {
-		if err := proof.StartProof[i].UnmarshalProto(protoNode); err != nil {
-			return err
-		}
+	bf, hasher, err := unmarshalTrieConfigProto(pbProof.BranchFactor, pbProof.HasherId)
+	if err != nil {
+		return err
 	}
+	proof.BranchFactor = bf
+	proof.Hasher = hasher
 
-	proof.EndProof = make([]ProofNode, len(pbProof.EndProof))
-	for i, protoNode := range pbProof.EndProof {
-		if err := proof.EndProof[i].UnmarshalProto(protoNode); err != nil {
-			return err
-		}
+	proof.StartProof, err = unmarshalProofNodesProto(pbProof.StartProof, bf)
+	if err != nil {
+		return err
+	}
+
+	proof.EndProof, err = unmarshalProofNodesProto(pbProof.EndProof, bf)
+	if err != nil {
+		return err
 	}
 
 	proof.KeyValues = make([]KeyValue, len(pbProof.KeyValues))
@@ -457,22 +671,30 @@ func (proof *RangeProof) UnmarshalProto(pbProof *pb.RangeProof) error {
 
 // Verify that all non-intermediate nodes in [proof] which have keys
 // in [[start], [end]] have the value given for that key in [keysValues].
-func verifyAllRangeProofKeyValuesPresent(proof []ProofNode, start path, end maybe.Maybe[path], keysValues map[path][]byte) error {
+// [bf] and [hasher] are the branch factor and hasher of the trie.
+func verifyAllRangeProofKeyValuesPresent(
+	bf BranchFactor,
+	hasher Hasher,
+	proof []ProofNode,
+	start path,
+	end maybe.Maybe[path],
+	keysValues map[path][]byte,
+) error {
 	for i := 0; i < len(proof); i++ {
 		var (
 			node     = proof[i]
 			nodeKey  = node.KeyPath
-			nodePath = nodeKey.deserialize()
+			nodePath = bf.deserialize(nodeKey)
 		)
 
 		// Skip odd length keys since they cannot have a value (enforced by [verifyProofPath]).
-		if !nodeKey.hasOddLength() && nodePath.Compare(start) >= 0 && (end.IsNothing() || nodePath.Compare(end.Value()) <= 0) {
+		if !bf.hasPartialByte(nodeKey.NibbleLength) && nodePath.Compare(start) >= 0 && (end.IsNothing() || nodePath.Compare(end.Value()) <= 0) {
 			value, ok := keysValues[nodePath]
 			if !ok && node.ValueOrHash.HasValue() {
 				// We didn't get a key-value pair for this key, but the proof node has a value.
 				return ErrProofNodeHasUnincludedValue
 			}
-			if ok && !valueOrHashMatches(maybe.Some(value), node.ValueOrHash) {
+			if ok && !valueOrHashMatches(hasher, maybe.Some(value), node.ValueOrHash) {
 				// We got a key-value pair for this key, but the value in the proof
 				// node doesn't match the value we got for this key.
 				return ErrProofValueDoesntMatch
@@ -547,6 +769,10 @@ type ChangeProof struct {
 	// [kv0, kv1] (For some kv1 < start)
 	// [kv1, kv2, kv3, kv4, kv5, kv6] (For some kv6 > end)
 	KeyChanges []KeyChange
+
+	// The branch factor and hasher of the trie. See [Proof].
+	BranchFactor BranchFactor
+	Hasher       Hasher
 }
 
 func (proof *ChangeProof) ToProto() *pb.ChangeProof {
@@ -572,9 +798,11 @@ func (proof *ChangeProof) ToProto() *pb.ChangeProof {
 	}
 
 	return &pb.ChangeProof{
-		StartProof: startProof,
-		EndProof:   endProof,
-		KeyChanges: keyChanges,
+		StartProof:   startProof,
+		EndProof:     endProof,
+		KeyChanges:   keyChanges,
+		BranchFactor: uint32(proof.BranchFactor),
+		HasherId:     uint32(hasherID(proof.Hasher)),
 	}
 }
 
@@ -583,18 +811,21 @@ func (proof *ChangeProof) UnmarshalProto(pbProof *pb.ChangeProof) error {
 		return ErrNilChangeProof
 	}
 
-	proof.StartProof = make([]ProofNode, len(pbProof.StartProof))
-	for i, protoNode := range pbProof.StartProof {
-		if err := proof.StartProof[i].UnmarshalProto(protoNode); err != nil {
-			return err
-		}
+	bf, hasher, err := unmarshalTrieConfigProto(pbProof.BranchFactor, pbProof.HasherId)
+	if err != nil {
+		return err
 	}
+	proof.BranchFactor = bf
+	proof.Hasher = hasher
 
-	proof.EndProof = make([]ProofNode, len(pbProof.EndProof))
-	for i, protoNode := range pbProof.EndProof {
-		if err := proof.EndProof[i].UnmarshalProto(protoNode); err != nil {
-			return err
-		}
+	proof.StartProof, err = unmarshalProofNodesProto(pbProof.StartProof, bf)
+	if err != nil {
+		return err
+	}
+
+	proof.EndProof, err = unmarshalProofNodesProto(pbProof.EndProof, bf)
+	if err != nil {
+		return err
 	}
 
 	proof.KeyChanges = make([]KeyChange, len(pbProof.KeyChanges))
@@ -625,7 +856,7 @@ func (proof *ChangeProof) UnmarshalProto(pbProof *pb.ChangeProof) error {
 // - if the node's path is within the key range, that has a value that matches the value passed in the change list or in the db
 func verifyAllChangeProofKeyValuesPresent(
 	ctx context.Context,
-	db MerkleDB,
+	db *merkleDB,
 	proof []ProofNode,
 	start path,
 	end maybe.Maybe[path],
@@ -635,12 +866,12 @@ func verifyAllChangeProofKeyValuesPresent(
 		var (
 			node     = proof[i]
 			nodeKey  = node.KeyPath
-			nodePath = nodeKey.deserialize()
+			nodePath = db.branchFactor.deserialize(nodeKey)
 		)
 
 		// Check the value of any node with a key that is within the range.
 		// Skip odd length keys since they cannot have a value (enforced by [verifyProofPath]).
-		if !nodeKey.hasOddLength() && nodePath.Compare(start) >= 0 && (end.IsNothing() || nodePath.Compare(end.Value()) <= 0) {
+		if !db.branchFactor.hasPartialByte(nodeKey.NibbleLength) && nodePath.Compare(start) >= 0 && (end.IsNothing() || nodePath.Compare(end.Value()) <= 0) {
 			value, ok := keysValues[nodePath]
 			if !ok {
 				// This value isn't in the list of key-value pairs we got.
@@ -656,7 +887,7 @@ func verifyAllChangeProofKeyValuesPresent(
 					value = maybe.Some(dbValue)
 				}
 			}
-			if !valueOrHashMatches(value, node.ValueOrHash) {
+			if !valueOrHashMatches(db.hasher, value, node.ValueOrHash) {
 				return ErrProofValueDoesntMatch
 			}
 		}
@@ -724,53 +955,103 @@ func verifyKeyValues(kvs []KeyValue, start maybe.Maybe[[]byte], end maybe.Maybe[
 }
 
 // Returns nil iff all the following hold:
-//   - Any node with an odd nibble length, should not have a value associated with it
-//     since all keys with values are written in bytes, so have even nibble length.
+//   - Any node with a partial byte key, should not have a value associated with it
+//     since all keys with values are written in bytes, so have whole byte length.
+//   - Each node in [proof] has no child index >= [bf].
 //   - Each key in [proof] is a strict prefix of the following key.
 //   - Each key in [proof] is a strict prefix of [keyBytes], except possibly the last.
 //   - If the last element in [proof] is [keyPath], this is an inclusion proof.
 //     Otherwise, this is an exclusion proof and [keyBytes] must not be in [proof].
-func verifyProofPath(proof []ProofNode, keyPath path) error {
+func verifyProofPath(proof []ProofNode, keyPath path, bf BranchFactor) error {
 	if len(proof) == 0 {
 		return nil
 	}
 
-	provenKey := keyPath.Serialize()
 	// loop over all but the last node since it will not have the prefix in exclusion proofs
 	for i := 0; i < len(proof)-1; i++ {
 		nodeKey := proof[i].KeyPath
 
-		// intermediate nodes (nodes with odd nibble length) should never have a value associated with them
-		if nodeKey.hasOddLength() && !proof[i].ValueOrHash.IsNothing() {
+		// intermediate nodes (nodes with a partial byte key) should never have a value associated with them
+		if bf.hasPartialByte(nodeKey.NibbleLength) && !proof[i].ValueOrHash.IsNothing() {
 			return ErrOddLengthWithValue
 		}
+		if err := verifyChildIndices(proof[i], bf); err != nil {
+			return err
+		}
 
 		// each node should have a key that has the proven key as a prefix
-		if !provenKey.HasStrictPrefix(nodeKey) {
+		nodePath := bf.deserialize(nodeKey)
+		if !hasStrictPrefix(keyPath, nodePath) {
 			return ErrProofNodeNotForKey
 		}
 
 		// each node should have a key that is a prefix of the next node's key
-		nextKey := proof[i+1].KeyPath
-		if !nextKey.HasStrictPrefix(nodeKey) {
+		nextPath := bf.deserialize(proof[i+1].KeyPath)
+		if !hasStrictPrefix(nextPath, nodePath) {
 			return ErrNonIncreasingProofNodes
 		}
 	}
 
 	// check the last node for a value since the above loop doesn't check the last node
-	if len(proof) > 0 {
-		lastNode := proof[len(proof)-1]
-		if lastNode.KeyPath.hasOddLength() && !lastNode.ValueOrHash.IsNothing() {
-			return ErrOddLengthWithValue
+	lastNode := proof[len(proof)-1]
+	if bf.hasPartialByte(lastNode.KeyPath.NibbleLength) && !lastNode.ValueOrHash.IsNothing() {
+		return ErrOddLengthWithValue
+	}
+	return verifyChildIndices(lastNode, bf)
+}
+
+// Returns the branch factor and hasher of a trie from the fields of a proof
+// that [pbBranchFactor] and [pbHasherID] were unmarshalled from.
+// Returns 0 and nil, respectively, if they are unspecified.
+func unmarshalTrieConfigProto(pbBranchFactor uint32, pbHasherID uint32) (BranchFactor, Hasher, error) {
+	bf := BranchFactor(pbBranchFactor)
+	if bf != 0 {
+		if err := bf.Valid(); err != nil {
+			return 0, nil, err
+		}
+	}
+	hasher, err := hasherFromID(HasherID(pbHasherID))
+	return bf, hasher, err
+}
+
+// Returns the proof nodes represented by [pbNodes], which are from a trie with
+// branch factor [bf].
+func unmarshalProofNodesProto(pbNodes []*pb.ProofNode, bf BranchFactor) ([]ProofNode, error) {
+	bf = bf.orDefault()
+	nodes := make([]ProofNode, len(pbNodes))
+	for i, pbNode := range pbNodes {
+		if err := nodes[i].UnmarshalProto(pbNode); err != nil {
+			return nil, err
+		}
+		if !bf.validSerializedPath(nodes[i].KeyPath) {
+			return nil, ErrInvalidKeyLength
+		}
+		if err := verifyChildIndices(nodes[i], bf); err != nil {
+			return nil, err
 		}
 	}
+	return nodes, nil
+}
+
+// Returns true iff [prefix] is a prefix of [p] and isn't equal to [p].
+func hasStrictPrefix(p path, prefix path) bool {
+	return len(p) > len(prefix) && p.HasPrefix(prefix)
+}
 
+// Returns nil iff every child index of [node] is less than [bf].
+func verifyChildIndices(node ProofNode, bf BranchFactor) error {
+	for index := range node.Children {
+		if int(index) >= int(bf) {
+			return ErrInvalidChildIndex
+		}
+	}
 	return nil
 }
 
 // Returns true if [value] and [valueDigest] match.
 // [valueOrHash] should be the [ValueOrHash] field of a [ProofNode].
-func valueOrHashMatches(value maybe.Maybe[[]byte], valueOrHash maybe.Maybe[[]byte]) bool {
+// [hasher] is the hasher of the trie the proof node is from.
+func valueOrHashMatches(hasher Hasher, value maybe.Maybe[[]byte], valueOrHash maybe.Maybe[[]byte]) bool {
 	var (
 		valueIsNothing  = value.IsNothing()
 		digestIsNothing = valueOrHash.IsNothing()
@@ -783,11 +1064,8 @@ func valueOrHashMatches(value maybe.Maybe[[]byte], valueOrHash maybe.Maybe[[]byt
 	case valueIsNothing:
 		// Both are nothing -- match.
 		return true
-	case len(value.Value()) < HashLength:
-		return bytes.Equal(value.Value(), valueOrHash.Value())
 	default:
-		valueHash := hashing.ComputeHash256(value.Value())
-		return bytes.Equal(valueHash, valueOrHash.Value())
+		return bytes.Equal(getValueDigest(hasher, value).Value(), valueOrHash.Value())
 	}
 }
 
@@ -810,10 +1088,10 @@ func addPathInfo(
 
 	for i := len(proofPath) - 1; i >= 0; i-- {
 		proofNode := proofPath[i]
-		keyPath := proofNode.KeyPath.deserialize()
+		keyPath := t.db.branchFactor.deserialize(proofNode.KeyPath)
 
-		if len(keyPath)&1 == 1 && !proofNode.ValueOrHash.IsNothing() {
-			// a value cannot have an odd number of nibbles in its key
+		if t.db.branchFactor.hasPartialByte(len(keyPath)) && !proofNode.ValueOrHash.IsNothing() {
+			// a value cannot have a partial byte in its key
 			return ErrOddLengthWithValue
 		}
 
@@ -853,8 +1131,14 @@ func addPathInfo(
 	return nil
 }
 
-// getStandaloneTrieView returns a new view that has nothing in it besides the changes due to [ops]
-func getStandaloneTrieView(ctx context.Context, ops []database.BatchOp) (*trieView, error) {
+// getStandaloneTrieView returns a new view that has nothing in it besides the changes due to [ops].
+// The view uses branch factor [bf] and [hasher].
+func getStandaloneTrieView(
+	ctx context.Context,
+	ops []database.BatchOp,
+	bf BranchFactor,
+	hasher Hasher,
+) (*trieView, error) {
 	db, err := newDatabase(
 		ctx,
 		memdb.New(),
@@ -863,6 +1147,8 @@ func getStandaloneTrieView(ctx context.Context, ops []database.BatchOp) (*trieVi
 			Tracer:                    trace.Noop,
 			ValueNodeCacheSize:        verificationCacheSize,
 			IntermediateNodeCacheSize: verificationCacheSize,
+			BranchFactor:              bf,
+			Hasher:                    hasher,
 		},
 		&mockMetrics{},
 	)
@@ -0,0 +1,222 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+var (
+	ErrPagesExhausted = errors.New("all pages have already been verified")
+	ErrPageTooLarge   = errors.New("page has more key-value pairs than the page size")
+)
+
+// RangeProofIterator iterates over the key-value pairs of a trie with a fixed
+// root in range [start, end], one page of at most [pageSize] key-value pairs
+// at a time. Each page is returned as a RangeProof of the page against the
+// root.
+//
+// The pages are the ones expected by a RangeProofPageVerifier with the same
+// root, range and page size. The last page is either empty or contains
+// [end].
+type RangeProofIterator struct {
+	getRangeProof func(
+		ctx context.Context,
+		start maybe.Maybe[[]byte],
+		end maybe.Maybe[[]byte],
+		maxLength int,
+	) (*RangeProof, error)
+
+	end      maybe.Maybe[[]byte]
+	pageSize int
+
+	// The lower bound of the current page.
+	pageStart maybe.Maybe[[]byte]
+	// The lower bound of the next page.
+	nextPageStart maybe.Maybe[[]byte]
+
+	proof *RangeProof
+	err   error
+	done  bool
+}
+
+// NewRangeProofIterator returns an iterator over the pages of [trie] in
+// range [start, end].
+// If [start] is Nothing, there's no lower bound on the range.
+// If [end] is Nothing, there's no upper bound on the range.
+//
+// The pages are proven against the root of [trie], so [trie] must not be
+// modified while the iterator is in use.
+func NewRangeProofIterator(
+	trie ReadOnlyTrie,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	pageSize int,
+) *RangeProofIterator {
+	return newRangeProofIterator(trie.GetRangeProof, start, end, pageSize)
+}
+
+// NewRangeProofIteratorAtRoot returns an iterator over the pages of [db] in
+// range [start, end] when its root was [rootID].
+// If [start] is Nothing, there's no lower bound on the range.
+// If [end] is Nothing, there's no upper bound on the range.
+//
+// The iterator fails with [ErrInsufficientHistory] if [db] no longer has
+// sufficient history to recreate the trie at [rootID].
+func NewRangeProofIteratorAtRoot(
+	db RangeProofer,
+	rootID ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	pageSize int,
+) *RangeProofIterator {
+	return newRangeProofIterator(
+		func(ctx context.Context, start, end maybe.Maybe[[]byte], maxLength int) (*RangeProof, error) {
+			return db.GetRangeProofAtRoot(ctx, rootID, start, end, maxLength)
+		},
+		start,
+		end,
+		pageSize,
+	)
+}
+
+func newRangeProofIterator(
+	getRangeProof func(context.Context, maybe.Maybe[[]byte], maybe.Maybe[[]byte], int) (*RangeProof, error),
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	pageSize int,
+) *RangeProofIterator {
+	it := &RangeProofIterator{
+		getRangeProof: getRangeProof,
+		end:           end,
+		pageSize:      pageSize,
+		nextPageStart: start,
+	}
+	switch {
+	case pageSize <= 0:
+		it.err = fmt.Errorf("%w but was %d", ErrInvalidMaxLength, pageSize)
+	case start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) > 0:
+		it.err = ErrStartAfterEnd
+	}
+	return it
+}
+
+// Next fetches the next page. Returns false if there are no more pages or
+// an error occurred.
+func (it *RangeProofIterator) Next(ctx context.Context) bool {
+	if it.done || it.err != nil {
+		it.proof = nil
+		return false
+	}
+
+	proof, err := it.getRangeProof(ctx, it.nextPageStart, it.end, it.pageSize)
+	if err != nil {
+		it.proof = nil
+		it.err = err
+		return false
+	}
+
+	it.proof = proof
+	it.pageStart = it.nextPageStart
+	it.nextPageStart, it.done = nextPageStart(proof, it.end)
+	return true
+}
+
+// Proof returns the proof of the current page.
+func (it *RangeProofIterator) Proof() *RangeProof {
+	return it.proof
+}
+
+// PageStart returns the lower bound of the range the current page proves.
+// The upper bound is always the [end] the iterator was created with.
+func (it *RangeProofIterator) PageStart() maybe.Maybe[[]byte] {
+	return it.pageStart
+}
+
+// Error returns the error that stopped the iteration, if any.
+func (it *RangeProofIterator) Error() error {
+	return it.err
+}
+
+// RangeProofPageVerifier verifies the pages of a trie with a fixed root in
+// range [start, end], as returned by a RangeProofIterator.
+//
+// Each page is verified against the range that directly follows the previous
+// page, so once the last page has been verified, all of the key-value pairs
+// in [start, end] have been verified.
+type RangeProofPageVerifier struct {
+	rootID   ids.ID
+	end      maybe.Maybe[[]byte]
+	pageSize int
+
+	nextPageStart maybe.Maybe[[]byte]
+	done          bool
+}
+
+// NewRangeProofPageVerifier returns a verifier of the pages of the trie with
+// root [rootID] in range [start, end].
+// If [start] is Nothing, there's no lower bound on the range.
+// If [end] is Nothing, there's no upper bound on the range.
+func NewRangeProofPageVerifier(
+	rootID ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	pageSize int,
+) *RangeProofPageVerifier {
+	return &RangeProofPageVerifier{
+		rootID:        rootID,
+		end:           end,
+		pageSize:      pageSize,
+		nextPageStart: start,
+	}
+}
+
+// Verify returns nil iff [proof] is a valid proof of the next page.
+// Returns true if [proof] is the last page.
+func (v *RangeProofPageVerifier) Verify(ctx context.Context, proof *RangeProof) (bool, error) {
+	switch {
+	case v.done:
+		return false, ErrPagesExhausted
+	case v.pageSize <= 0:
+		return false, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, v.pageSize)
+	case proof == nil:
+		return false, ErrNilRangeProof
+	case len(proof.KeyValues) > v.pageSize:
+		return false, fmt.Errorf("%w: %d > %d", ErrPageTooLarge, len(proof.KeyValues), v.pageSize)
+	}
+
+	if err := proof.Verify(ctx, v.nextPageStart, v.end, v.rootID); err != nil {
+		return false, err
+	}
+
+	v.nextPageStart, v.done = nextPageStart(proof, v.end)
+	return v.done, nil
+}
+
+// Returns the lower bound of the page after [proof], which is a page with
+// upper bound [end].
+// Returns true if [proof] is the last page.
+//
+// A page is only considered the last page if it proves there are no more
+// key-value pairs in the range. That is, if the page is empty or contains
+// [end].
+func nextPageStart(proof *RangeProof, end maybe.Maybe[[]byte]) (maybe.Maybe[[]byte], bool) {
+	if len(proof.KeyValues) == 0 {
+		return maybe.Nothing[[]byte](), true
+	}
+	lastKey := proof.KeyValues[len(proof.KeyValues)-1].Key
+	if end.HasValue() && bytes.Compare(lastKey, end.Value()) >= 0 {
+		return maybe.Nothing[[]byte](), true
+	}
+	// The smallest key greater than [lastKey].
+	nextKey := make([]byte, len(lastKey), len(lastKey)+1)
+	copy(nextKey, lastKey)
+	return maybe.Some(append(nextKey, 0)), false
+}
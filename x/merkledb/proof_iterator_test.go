@@ -0,0 +1,214 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+func TestRangeProofIterator(t *testing.T) {
+	type test struct {
+		name     string
+		numKeys  int
+		start    maybe.Maybe[[]byte]
+		end      maybe.Maybe[[]byte]
+		pageSize int
+		// The keys expected to be iterated over.
+		expectedKeys []int
+	}
+
+	tests := []test{
+		{
+			name:         "empty trie",
+			numKeys:      0,
+			start:        maybe.Nothing[[]byte](),
+			end:          maybe.Nothing[[]byte](),
+			pageSize:     3,
+			expectedKeys: []int{},
+		},
+		{
+			name:         "no bounds",
+			numKeys:      10,
+			start:        maybe.Nothing[[]byte](),
+			end:          maybe.Nothing[[]byte](),
+			pageSize:     3,
+			expectedKeys: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:         "page size divides keys",
+			numKeys:      10,
+			start:        maybe.Nothing[[]byte](),
+			end:          maybe.Nothing[[]byte](),
+			pageSize:     5,
+			expectedKeys: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:         "bounded",
+			numKeys:      10,
+			start:        maybe.Some([]byte("key2")),
+			end:          maybe.Some([]byte("key7")),
+			pageSize:     2,
+			expectedKeys: []int{2, 3, 4, 5, 6, 7},
+		},
+		{
+			name:         "end not in trie",
+			numKeys:      10,
+			start:        maybe.Some([]byte("key2")),
+			end:          maybe.Some([]byte("key7a")),
+			pageSize:     3,
+			expectedKeys: []int{2, 3, 4, 5, 6, 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db, err := getBasicDB()
+			require.NoError(err)
+			for i := 0; i < tt.numKeys; i++ {
+				require.NoError(db.Put([]byte("key"+strconv.Itoa(i)), []byte("value"+strconv.Itoa(i))))
+			}
+			root, err := db.GetMerkleRoot(context.Background())
+			require.NoError(err)
+
+			it := NewRangeProofIterator(db, tt.start, tt.end, tt.pageSize)
+			verifier := NewRangeProofPageVerifier(root, tt.start, tt.end, tt.pageSize)
+
+			keys := []int{}
+			done := false
+			for it.Next(context.Background()) {
+				require.False(done)
+
+				proof := it.Proof()
+				require.LessOrEqual(len(proof.KeyValues), tt.pageSize)
+				for _, kv := range proof.KeyValues {
+					i, err := strconv.Atoi(string(kv.Key[len("key"):]))
+					require.NoError(err)
+					require.Equal([]byte("value"+strconv.Itoa(i)), kv.Value)
+					keys = append(keys, i)
+				}
+
+				done, err = verifier.Verify(context.Background(), proof)
+				require.NoError(err)
+			}
+			require.NoError(it.Error())
+			require.True(done)
+			require.Equal(tt.expectedKeys, keys)
+
+			_, err = verifier.Verify(context.Background(), &RangeProof{})
+			require.ErrorIs(err, ErrPagesExhausted)
+		})
+	}
+}
+
+func TestRangeProofIteratorAtRoot(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	for i := 0; i < 10; i++ {
+		require.NoError(db.Put([]byte("key"+strconv.Itoa(i)), []byte("value")))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	it := NewRangeProofIteratorAtRoot(db, root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 4)
+	verifier := NewRangeProofPageVerifier(root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 4)
+
+	// Modifying the db between pages doesn't affect the iteration.
+	numKeys := 0
+	for it.Next(context.Background()) {
+		require.NoError(db.Put([]byte("key"+strconv.Itoa(numKeys)), []byte("changed")))
+
+		proof := it.Proof()
+		for _, kv := range proof.KeyValues {
+			require.Equal([]byte("value"), kv.Value)
+		}
+		numKeys += len(proof.KeyValues)
+
+		_, err := verifier.Verify(context.Background(), proof)
+		require.NoError(err)
+	}
+	require.NoError(it.Error())
+	require.Equal(10, numKeys)
+
+	it = NewRangeProofIteratorAtRoot(db, ids.GenerateTestID(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 4)
+	require.False(it.Next(context.Background()))
+	require.ErrorIs(it.Error(), ErrInsufficientHistory)
+}
+
+func TestRangeProofIteratorInvalidInput(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	it := NewRangeProofIterator(db, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 0)
+	require.False(it.Next(context.Background()))
+	require.ErrorIs(it.Error(), ErrInvalidMaxLength)
+
+	it = NewRangeProofIterator(db, maybe.Some([]byte{1}), maybe.Some([]byte{0}), 1)
+	require.False(it.Next(context.Background()))
+	require.ErrorIs(it.Error(), ErrStartAfterEnd)
+}
+
+func TestRangeProofPageVerifierRejectsSkippedKeys(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	ops := make([]database.BatchOp, 0, 10)
+	for i := 0; i < 10; i++ {
+		ops = append(ops, database.BatchOp{
+			Key:   []byte("key" + strconv.Itoa(i)),
+			Value: []byte("value"),
+		})
+	}
+	require.NoError(db.commitBatch(ops))
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	verifier := NewRangeProofPageVerifier(root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 2)
+
+	firstPage, err := db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 2)
+	require.NoError(err)
+	done, err := verifier.Verify(context.Background(), firstPage)
+	require.NoError(err)
+	require.False(done)
+
+	// A page that skips the keys directly after the first page is invalid.
+	skippingPage, err := db.GetRangeProof(context.Background(), maybe.Some([]byte("key5")), maybe.Nothing[[]byte](), 2)
+	require.NoError(err)
+	_, err = verifier.Verify(context.Background(), skippingPage)
+	require.ErrorIs(err, ErrInvalidProof)
+
+	// A page claiming there are no more keys is invalid.
+	nextPage, err := db.GetRangeProof(context.Background(), maybe.Some([]byte("key1\x00")), maybe.Nothing[[]byte](), 2)
+	require.NoError(err)
+	emptyPage := &RangeProof{
+		StartProof: nextPage.StartProof,
+		EndProof:   nextPage.EndProof,
+	}
+	_, err = verifier.Verify(context.Background(), emptyPage)
+	require.ErrorIs(err, ErrUnexpectedEndProof)
+
+	// A page with more than the page size is invalid.
+	largePage, err := db.GetRangeProof(context.Background(), maybe.Some([]byte("key1\x00")), maybe.Nothing[[]byte](), 3)
+	require.NoError(err)
+	_, err = verifier.Verify(context.Background(), largePage)
+	require.ErrorIs(err, ErrPageTooLarge)
+
+	done, err = verifier.Verify(context.Background(), nextPage)
+	require.NoError(err)
+	require.False(done)
+}
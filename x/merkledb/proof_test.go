@@ -121,14 +121,14 @@ func Test_Proof_Verify_Bad_Data(t *testing.T) {
 func Test_Proof_ValueOrHashMatches(t *testing.T) {
 	require := require.New(t)
 
-	require.True(valueOrHashMatches(maybe.Some([]byte{0}), maybe.Some([]byte{0})))
-	require.False(valueOrHashMatches(maybe.Nothing[[]byte](), maybe.Some(hashing.ComputeHash256([]byte{0}))))
-	require.True(valueOrHashMatches(maybe.Nothing[[]byte](), maybe.Nothing[[]byte]()))
-
-	require.False(valueOrHashMatches(maybe.Some([]byte{0}), maybe.Nothing[[]byte]()))
-	require.False(valueOrHashMatches(maybe.Nothing[[]byte](), maybe.Some([]byte{0})))
-	require.False(valueOrHashMatches(maybe.Nothing[[]byte](), maybe.Some(hashing.ComputeHash256([]byte{1}))))
-	require.False(valueOrHashMatches(maybe.Some(hashing.ComputeHash256([]byte{0})), maybe.Nothing[[]byte]()))
+	require.True(valueOrHashMatches(SHA256Hasher, maybe.Some([]byte{0}), maybe.Some([]byte{0})))
+	require.False(valueOrHashMatches(SHA256Hasher, maybe.Nothing[[]byte](), maybe.Some(hashing.ComputeHash256([]byte{0}))))
+	require.True(valueOrHashMatches(SHA256Hasher, maybe.Nothing[[]byte](), maybe.Nothing[[]byte]()))
+
+	require.False(valueOrHashMatches(SHA256Hasher, maybe.Some([]byte{0}), maybe.Nothing[[]byte]()))
+	require.False(valueOrHashMatches(SHA256Hasher, maybe.Nothing[[]byte](), maybe.Some([]byte{0})))
+	require.False(valueOrHashMatches(SHA256Hasher, maybe.Nothing[[]byte](), maybe.Some(hashing.ComputeHash256([]byte{1}))))
+	require.False(valueOrHashMatches(SHA256Hasher, maybe.Some(hashing.ComputeHash256([]byte{0})), maybe.Nothing[[]byte]()))
 }
 
 func Test_RangeProof_Extra_Value(t *testing.T) {
@@ -1188,7 +1188,7 @@ func TestVerifyProofPath(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := verifyProofPath(tt.path, newPath(tt.proofKey))
+			err := verifyProofPath(tt.path, newPath(tt.proofKey), BranchFactor16)
 			require.ErrorIs(t, err, tt.expectedErr)
 		})
 	}
@@ -1239,13 +1239,128 @@ func TestProofNodeUnmarshalProtoInvalidChildIndex(t *testing.T) {
 	protoNode := node.ToProto()
 
 	childID := ids.GenerateTestID()
-	protoNode.Children[NodeBranchFactor] = childID[:]
+	protoNode.Children[uint32(BranchFactor256)] = childID[:]
 
 	var unmarshaledNode ProofNode
 	err := unmarshaledNode.UnmarshalProto(protoNode)
 	require.ErrorIs(t, err, ErrInvalidChildIndex)
 }
 
+func TestProofsUnmarshalProtoBranchFactor(t *testing.T) {
+	childID := ids.GenerateTestID()
+	newProtoNode := func(childIndex uint32) *pb.ProofNode {
+		return &pb.ProofNode{
+			Key:         &pb.SerializedPath{},
+			ValueOrHash: &pb.MaybeBytes{IsNothing: true},
+			Children: map[uint32][]byte{
+				childIndex: childID[:],
+			},
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		branchFactor         BranchFactor
+		hasherID             HasherID
+		childIndex           uint32
+		key                  *pb.SerializedPath
+		expectedBranchFactor BranchFactor
+		expectedHasher       Hasher
+		expectedErr          error
+	}{
+		{
+			name:       "default branch factor",
+			childIndex: uint32(BranchFactor16) - 1,
+		},
+		{
+			name:        "child index too large for default branch factor",
+			childIndex:  uint32(BranchFactor16),
+			expectedErr: ErrInvalidChildIndex,
+		},
+		{
+			name:                 "branch factor 256",
+			branchFactor:         BranchFactor256,
+			hasherID:             Keccak256HasherID,
+			childIndex:           uint32(BranchFactor256) - 1,
+			expectedBranchFactor: BranchFactor256,
+			expectedHasher:       Keccak256Hasher,
+		},
+		{
+			name:         "child index too large for branch factor",
+			branchFactor: BranchFactor2,
+			childIndex:   uint32(BranchFactor2),
+			expectedErr:  ErrInvalidChildIndex,
+		},
+		{
+			name:         "invalid branch factor",
+			branchFactor: 3,
+			expectedErr:  ErrInvalidBranchFactor,
+		},
+		{
+			name:        "unknown hasher",
+			hasherID:    Keccak256HasherID + 1,
+			expectedErr: ErrUnknownHasher,
+		},
+		{
+			name:         "key length doesn't match branch factor",
+			branchFactor: BranchFactor2,
+			key: &pb.SerializedPath{
+				NibbleLength: 9,
+				Value:        []byte{1},
+			},
+			expectedErr: ErrInvalidKeyLength,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			protoNode := newProtoNode(tt.childIndex)
+			if tt.key != nil {
+				protoNode.Key = tt.key
+			}
+			protoNodes := []*pb.ProofNode{protoNode}
+
+			var proof Proof
+			err := proof.UnmarshalProto(&pb.Proof{
+				Value:        &pb.MaybeBytes{IsNothing: true},
+				Proof:        protoNodes,
+				BranchFactor: uint32(tt.branchFactor),
+				HasherId:     uint32(tt.hasherID),
+			})
+			require.ErrorIs(err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				require.Equal(tt.expectedBranchFactor, proof.BranchFactor)
+				require.Equal(tt.expectedHasher, proof.Hasher)
+			}
+
+			var rangeProof RangeProof
+			err = rangeProof.UnmarshalProto(&pb.RangeProof{
+				EndProof:     protoNodes,
+				BranchFactor: uint32(tt.branchFactor),
+				HasherId:     uint32(tt.hasherID),
+			})
+			require.ErrorIs(err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				require.Equal(tt.expectedBranchFactor, rangeProof.BranchFactor)
+				require.Equal(tt.expectedHasher, rangeProof.Hasher)
+			}
+
+			var changeProof ChangeProof
+			err = changeProof.UnmarshalProto(&pb.ChangeProof{
+				StartProof:   protoNodes,
+				BranchFactor: uint32(tt.branchFactor),
+				HasherId:     uint32(tt.hasherID),
+			})
+			require.ErrorIs(err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				require.Equal(tt.expectedBranchFactor, changeProof.BranchFactor)
+				require.Equal(tt.expectedHasher, changeProof.Hasher)
+			}
+		})
+	}
+}
+
 func TestProofNodeUnmarshalProtoMissingFields(t *testing.T) {
 	now := time.Now().UnixNano()
 	t.Logf("seed: %d", now)
@@ -1853,3 +1968,231 @@ func FuzzChangeProofVerification(f *testing.F) {
 		))
 	})
 }
+
+func Test_MultiProof_Empty(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	_, err = db.GetMultiProof(context.Background(), nil)
+	require.ErrorIs(err, ErrNoKeys)
+
+	proof := &MultiProof{}
+	err = proof.Verify(context.Background(), ids.Empty)
+	require.ErrorIs(err, ErrNoProof)
+}
+
+func Test_MultiProof(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	writeBasicBatch(t, db)
+
+	ctx := context.Background()
+	keys := [][]byte{{5}, {2}, {1}, {2}}
+	proof, err := db.GetMultiProof(ctx, keys)
+	require.NoError(err)
+
+	// The keys are sorted and deduplicated.
+	require.Equal([][]byte{{1}, {2}, {5}}, proof.Keys)
+	require.Equal(
+		[]maybe.Maybe[[]byte]{
+			maybe.Some([]byte{1}),
+			maybe.Some([]byte{2}),
+			maybe.Nothing[[]byte](),
+		},
+		proof.Values,
+	)
+	// [keys] isn't modified.
+	require.Equal([][]byte{{5}, {2}, {1}, {2}}, keys)
+
+	// The root and the node at nibble path [0] are shared by the proofs of
+	// every key, but are only included once.
+	require.Len(proof.Nodes, 4)
+
+	rootID, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.NoError(proof.Verify(ctx, rootID))
+
+	// The proof survives a round trip through the codec.
+	var parsedProof MultiProof
+	require.NoError(parsedProof.Unmarshal(proof.Marshal()))
+	require.NoError(parsedProof.Verify(ctx, rootID))
+
+	require.NoError(db.Put([]byte{6}, []byte{6}))
+	rootID, err = db.GetMerkleRoot(ctx)
+	require.NoError(err)
+	err = proof.Verify(ctx, rootID)
+	require.ErrorIs(err, ErrInvalidProof)
+}
+
+func Test_MultiProof_Verify_Bad_Data(t *testing.T) {
+	type test struct {
+		name        string
+		malform     func(proof *MultiProof)
+		expectedErr error
+	}
+
+	tests := []test{
+		{
+			name:        "happyPath",
+			malform:     func(proof *MultiProof) {},
+			expectedErr: nil,
+		},
+		{
+			name: "no nodes",
+			malform: func(proof *MultiProof) {
+				proof.Nodes = nil
+			},
+			expectedErr: ErrNoProof,
+		},
+		{
+			name: "more keys than values",
+			malform: func(proof *MultiProof) {
+				proof.Values = proof.Values[1:]
+			},
+			expectedErr: ErrKeysValuesLengthMismatch,
+		},
+		{
+			name: "keys not increasing",
+			malform: func(proof *MultiProof) {
+				proof.Keys[0], proof.Keys[1] = proof.Keys[1], proof.Keys[0]
+			},
+			expectedErr: ErrNonIncreasingValues,
+		},
+		{
+			name: "nodes not increasing",
+			malform: func(proof *MultiProof) {
+				proof.Nodes[0], proof.Nodes[1] = proof.Nodes[1], proof.Nodes[0]
+			},
+			expectedErr: ErrNonIncreasingProofNodeKeys,
+		},
+		{
+			name: "odd length key path with value",
+			malform: func(proof *MultiProof) {
+				proof.Nodes[1].ValueOrHash = maybe.Some([]byte{1, 2})
+			},
+			expectedErr: ErrOddLengthWithValue,
+		},
+		{
+			name: "mismatched value",
+			malform: func(proof *MultiProof) {
+				proof.Values[0] = maybe.Some([]byte{10})
+			},
+			expectedErr: ErrProofValueDoesntMatch,
+		},
+		{
+			name: "missing value",
+			malform: func(proof *MultiProof) {
+				proof.Values[1] = maybe.Nothing[[]byte]()
+			},
+			expectedErr: ErrProofValueDoesntMatch,
+		},
+		{
+			name: "value of excluded key",
+			malform: func(proof *MultiProof) {
+				proof.Values[2] = maybe.Some([]byte{5})
+			},
+			expectedErr: ErrProofValueDoesntMatch,
+		},
+		{
+			name: "missing node of key",
+			malform: func(proof *MultiProof) {
+				// remove the node of key [2] and claim it isn't in the trie
+				proof.Nodes = append(proof.Nodes[:3], proof.Nodes[4:]...)
+				proof.Values[1] = maybe.Nothing[[]byte]()
+			},
+			expectedErr: ErrIncompleteMultiProof,
+		},
+		{
+			name: "mismatched child ID",
+			malform: func(proof *MultiProof) {
+				// the child at index 0 is the node of key [0], which isn't in the proof
+				proof.Nodes[1].Children[0] = ids.GenerateTestID()
+			},
+			expectedErr: ErrInvalidProof,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db, err := getBasicDB()
+			require.NoError(err)
+
+			writeBasicBatch(t, db)
+
+			proof, err := db.GetMultiProof(context.Background(), [][]byte{{1}, {2}, {5}})
+			require.NoError(err)
+			require.NotNil(proof)
+
+			tt.malform(proof)
+
+			err = proof.Verify(context.Background(), db.getMerkleRoot())
+			require.ErrorIs(err, tt.expectedErr)
+		})
+	}
+}
+
+func FuzzMultiProofVerification(f *testing.F) {
+	const (
+		deletePortion = 0.25
+		maxNumKeys    = 32
+	)
+	f.Fuzz(func(
+		t *testing.T,
+		randSeed int64,
+		numKeyValues uint,
+		numKeys uint,
+	) {
+		rand := rand.New(rand.NewSource(randSeed)) // #nosec G404
+		require := require.New(t)
+		db, err := getBasicDB()
+		require.NoError(err)
+
+		// Insert a bunch of random key values.
+		insertRandomKeyValues(
+			require,
+			rand,
+			[]database.Database{db},
+			numKeyValues,
+			deletePortion,
+		)
+
+		// Prove a mix of keys that are and aren't in the trie.
+		keys := make([][]byte, 0, numKeys%maxNumKeys+1)
+		iter := db.NewIterator()
+		for i := uint(0); i <= numKeys%maxNumKeys; i++ {
+			if rand.Intn(2) == 0 && iter.Next() {
+				keys = append(keys, iter.Key())
+				continue
+			}
+			key := make([]byte, rand.Intn(4)) // #nosec G404
+			_, _ = rand.Read(key)             // #nosec G404
+			keys = append(keys, key)
+		}
+		require.NoError(iter.Error())
+		iter.Release()
+
+		proof, err := db.GetMultiProof(context.Background(), keys)
+		require.NoError(err)
+
+		rootID, err := db.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		require.NoError(proof.Verify(context.Background(), rootID))
+
+		for i, key := range proof.Keys {
+			value, err := db.Get(key)
+			if err == database.ErrNotFound {
+				require.True(proof.Values[i].IsNothing())
+				continue
+			}
+			require.NoError(err)
+			require.True(proof.Values[i].HasValue())
+			require.True(bytes.Equal(value, proof.Values[i].Value()))
+		}
+	})
+}
@@ -0,0 +1,175 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+// The maximum number of intermediate nodes checked while commits are blocked.
+const pruneBatchSize = 1024
+
+// Prune opens the merkle database stored in [db], removes every intermediate
+// node that isn't reachable from its root and closes it.
+//
+// This is intended to be run offline, while no other process is using [db].
+// Like opening the database, this fails with [ErrPersistedHistory] if [db]
+// has persisted history that [config] neither persists nor deletes.
+func Prune(ctx context.Context, db database.Database, config Config) error {
+	metrics, err := newMetrics("merkleDB", config.Reg)
+	if err != nil {
+		return err
+	}
+	trieDB, err := newDatabase(ctx, db, config, metrics)
+	if err != nil {
+		return err
+	}
+	if err := trieDB.Prune(ctx); err != nil {
+		_ = trieDB.Close()
+		return err
+	}
+	return trieDB.Close()
+}
+
+// Prune removes every intermediate node stored on disk that isn't reachable
+// from the root.
+//
+// Nodes are checked in batches. Commits are blocked while a batch is being
+// checked, but may occur between batches, so this can be called while the
+// database is in use.
+func (db *merkleDB) Prune(ctx context.Context) error {
+	_, span := db.infoTracer.Start(ctx, "MerkleDB.Prune")
+	defer span.End()
+
+	db.metrics.PruningStarted()
+	defer db.metrics.PruningFinished()
+
+	var (
+		start []byte
+		done  bool
+	)
+	for !done {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		start, done, err = db.pruneBatch(start)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneBatch deletes the unreachable intermediate nodes among the first
+// [pruneBatchSize] intermediate nodes stored on disk with keys >= [start].
+// Returns the key to start the next batch at and true if there are no more
+// intermediate nodes to check.
+// Assumes [db.commitLock] and [db.lock] aren't held.
+func (db *merkleDB) pruneBatch(start []byte) ([]byte, bool, error) {
+	// Prevent the trie from changing while determining which nodes are
+	// reachable and deleting the unreachable ones.
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, false, database.ErrClosed
+	}
+
+	unreachableKeys, next, err := db.getUnreachableIntermediateNodes(start)
+	if err != nil {
+		return nil, false, err
+	}
+
+	batch := db.baseDB.NewBatch()
+	for _, key := range unreachableKeys {
+		if err := batch.Delete(key); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return nil, false, err
+	}
+	for range unreachableKeys {
+		db.metrics.PrunedNodeDeleted()
+	}
+	return next, next == nil, nil
+}
+
+// getUnreachableIntermediateNodes returns the database keys of the
+// unreachable intermediate nodes among the first [pruneBatchSize]
+// intermediate nodes stored on disk with keys >= [start].
+// Also returns the database key of the next intermediate node, or nil if
+// there isn't one.
+// Assumes [db.lock] is read locked.
+func (db *merkleDB) getUnreachableIntermediateNodes(start []byte) ([][]byte, []byte, error) {
+	it := db.baseDB.NewIteratorWithStartAndPrefix(start, intermediateNodePrefix)
+	defer it.Release()
+
+	var (
+		unreachableKeys [][]byte
+		scanned         = 0
+	)
+	for scanned < pruneBatchSize && it.Next() {
+		scanned++
+		db.metrics.PrunedNodeScanned()
+
+		key, err := parseIntermediateNodeDBKey(it.Key(), db.branchFactor)
+		if err != nil {
+			return nil, nil, err
+		}
+		reachable, err := db.isReachableIntermediateNode(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !reachable {
+			unreachableKeys = append(unreachableKeys, slices.Clone(it.Key()))
+		}
+	}
+
+	var next []byte
+	if scanned == pruneBatchSize && it.Next() {
+		next = slices.Clone(it.Key())
+	}
+	return unreachableKeys, next, it.Error()
+}
+
+// isReachableIntermediateNode returns true iff the node at [key] is reachable
+// from the root and doesn't have a value.
+// Assumes [db.lock] is read locked.
+func (db *merkleDB) isReachableIntermediateNode(key path) (bool, error) {
+	var (
+		// all paths start at the root
+		currentNode     = db.root
+		matchedKeyIndex = 0
+	)
+
+	// while the entire path hasn't been matched
+	for matchedKeyIndex < len(key) {
+		nextChildEntry, hasChild := currentNode.children[key[matchedKeyIndex]]
+		matchedKeyIndex++
+
+		if !hasChild || !key[matchedKeyIndex:].HasPrefix(nextChildEntry.compressedPath) {
+			// there was no child along the path or the child that was there
+			// doesn't match the remaining path
+			return false, nil
+		}
+		matchedKeyIndex += len(nextChildEntry.compressedPath)
+
+		var err error
+		currentNode, err = db.getNode(key[:matchedKeyIndex], nextChildEntry.hasValue)
+		if err != nil {
+			return false, err
+		}
+	}
+	return !currentNode.hasValue(), nil
+}
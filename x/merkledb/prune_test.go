@@ -0,0 +1,164 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// Writes intermediate nodes that aren't reachable from the root directly to
+// [baseDB]. Returns their keys in [baseDB].
+func writeUnreachableIntermediateNodes(require *require.Assertions, db *merkleDB, baseDB database.Database, count int) [][]byte {
+	keys := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		n := newNode(nil, newPath([]byte("unreachable"+strconv.Itoa(i))))
+		n.addChildWithoutNode(0, EmptyPath, ids.GenerateTestID(), true)
+		n.addChildWithoutNode(1, EmptyPath, ids.GenerateTestID(), true)
+
+		key := db.intermediateNodeDB.constructDBKey(n.key)
+		require.NoError(baseDB.Put(key, n.bytes(BranchFactor16)))
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func countIntermediateNodes(require *require.Assertions, baseDB database.Database) int {
+	it := baseDB.NewIteratorWithPrefix(intermediateNodePrefix)
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	require.NoError(it.Error())
+	return count
+}
+
+func TestPrune(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	config.Reg = nil
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	// Write enough keys that there are more intermediate nodes than fit in a
+	// single prune batch.
+	numKeys := 16 * pruneBatchSize
+	ops := make([]database.BatchOp, numKeys)
+	for i := range ops {
+		ops[i] = database.BatchOp{
+			Key:   []byte(strconv.Itoa(i)),
+			Value: []byte{byte(i)},
+		}
+	}
+	view, err := db.NewView(context.Background(), ViewChanges{BatchOps: ops})
+	require.NoError(err)
+	require.NoError(view.CommitToDB(context.Background()))
+	require.NoError(db.intermediateNodeDB.Flush())
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	numReachable := countIntermediateNodes(require, baseDB)
+	require.Greater(numReachable, pruneBatchSize)
+
+	unreachableKeys := writeUnreachableIntermediateNodes(require, db, baseDB, 10)
+	require.Equal(numReachable+len(unreachableKeys), countIntermediateNodes(require, baseDB))
+
+	require.NoError(db.Prune(context.Background()))
+
+	for _, key := range unreachableKeys {
+		has, err := baseDB.Has(key)
+		require.NoError(err)
+		require.False(has)
+	}
+	require.Equal(numReachable, countIntermediateNodes(require, baseDB))
+
+	metrics := db.metrics.(*mockMetrics)
+	require.False(metrics.pruningInProgress)
+	require.Equal(int64(numReachable+len(unreachableKeys)), metrics.prunedNodeScanned)
+	require.Equal(int64(len(unreachableKeys)), metrics.prunedNodeDeleted)
+
+	// The trie is unaffected.
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(root, newRoot)
+
+	require.NoError(db.Close())
+	db, err = newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+
+	newRoot, err = db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(root, newRoot)
+	for i := 0; i < numKeys; i++ {
+		value, err := db.Get([]byte(strconv.Itoa(i)))
+		require.NoError(err)
+		require.Equal([]byte{byte(i)}, value)
+	}
+}
+
+func TestPruneOffline(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(db.Put([]byte(strconv.Itoa(i)), []byte{byte(i)}))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	unreachableKeys := writeUnreachableIntermediateNodes(require, db, baseDB, 10)
+	require.NoError(db.Close())
+
+	require.NoError(Prune(context.Background(), baseDB, newDefaultConfig()))
+
+	for _, key := range unreachableKeys {
+		has, err := baseDB.Has(key)
+		require.NoError(err)
+		require.False(has)
+	}
+
+	db, err = newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(root, newRoot)
+}
+
+func TestPruneCanceled(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = db.Prune(ctx)
+	require.ErrorIs(err, context.Canceled)
+}
+
+func TestPruneClosed(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Close())
+
+	err = db.Prune(context.Background())
+	require.ErrorIs(err, database.ErrClosed)
+}
@@ -34,6 +34,11 @@ type ReadOnlyTrie interface {
 	// database.ErrNotFound if the key is not present
 	GetValues(ctx context.Context, keys [][]byte) ([][]byte, []error)
 
+	// GetMultiProof generates a proof of the values associated with [keys],
+	// or of their absence from the trie.
+	// Nodes shared by the proofs of multiple keys are only included once.
+	GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error)
+
 	// get the value associated with the key in path form
 	// database.ErrNotFound if the key is not present
 	getValue(key path) ([]byte, error)
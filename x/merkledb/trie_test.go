@@ -300,7 +300,7 @@ func Test_Trie_WriteToDB(t *testing.T) {
 	rawBytes, err := dbTrie.baseDB.Get(prefixedKey)
 	require.NoError(err)
 
-	node, err := parseNode(newPath(key), rawBytes)
+	node, err := parseNode(newPath(key), rawBytes, BranchFactor16, DefaultHasher)
 	require.NoError(err)
 	require.Equal([]byte("value"), node.value.Value())
 }
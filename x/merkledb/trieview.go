@@ -14,6 +14,7 @@ import (
 
 	oteltrace "go.opentelemetry.io/otel/trace"
 
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanchego/database"
@@ -169,7 +170,7 @@ func newTrieView(
 			}
 			newVal = maybe.Some(val)
 		}
-		if err := newView.recordValueChange(newPath(op.Key), newVal); err != nil {
+		if err := newView.recordValueChange(db.branchFactor.newPath(op.Key), newVal); err != nil {
 			return nil, err
 		}
 	}
@@ -177,7 +178,7 @@ func newTrieView(
 		if !changes.ConsumeBytes {
 			val = maybe.Bind(val, slices.Clone[[]byte])
 		}
-		if err := newView.recordValueChange(newPath([]byte(key)), val); err != nil {
+		if err := newView.recordValueChange(db.branchFactor.newPath([]byte(key)), val); err != nil {
 			return nil, err
 		}
 	}
@@ -265,7 +266,7 @@ func (t *trieView) calculateNodeIDsHelper(n *node) {
 	)
 
 	for childIndex, child := range n.children {
-		childPath := n.key + path(childIndex) + child.compressedPath
+		childPath := n.key.Append(childIndex) + child.compressedPath
 		childNodeChange, ok := t.changes.nodes[childPath]
 		if !ok {
 			// This child wasn't changed.
@@ -303,7 +304,7 @@ func (t *trieView) calculateNodeIDsHelper(n *node) {
 	}
 
 	// The IDs [n]'s descendants are up to date so we can calculate [n]'s ID.
-	n.calculateID(t.db.metrics)
+	n.calculateID(t.db.branchFactor, t.db.hasher, t.db.metrics)
 }
 
 // GetProof returns a proof that [bytesPath] is in or not in trie [t].
@@ -324,11 +325,13 @@ func (t *trieView) getProof(ctx context.Context, key []byte) (*Proof, error) {
 	defer span.End()
 
 	proof := &Proof{
-		Key: key,
+		Key:          key,
+		BranchFactor: t.db.branchFactor,
+		Hasher:       t.db.hasher,
 	}
 
 	// Get the node at the given path, or the node closest to it.
-	keyPath := newPath(key)
+	keyPath := t.db.branchFactor.newPath(key)
 
 	proofPath, err := t.getPathTo(keyPath)
 	if err != nil {
@@ -338,7 +341,7 @@ func (t *trieView) getProof(ctx context.Context, key []byte) (*Proof, error) {
 	// From root --> node from left --> right.
 	proof.Path = make([]ProofNode, len(proofPath), len(proofPath)+1)
 	for i, node := range proofPath {
-		proof.Path[i] = node.asProofNode()
+		proof.Path[i] = node.asProofNode(t.db.branchFactor)
 	}
 
 	closestNode := proofPath[len(proofPath)-1]
@@ -360,19 +363,76 @@ func (t *trieView) getProof(ctx context.Context, key []byte) (*Proof, error) {
 
 	childNode, err := t.getNodeWithID(
 		child.id,
-		closestNode.key+path(nextIndex)+child.compressedPath,
+		closestNode.key.Append(nextIndex)+child.compressedPath,
 		child.hasValue,
 	)
 	if err != nil {
 		return nil, err
 	}
-	proof.Path = append(proof.Path, childNode.asProofNode())
+	proof.Path = append(proof.Path, childNode.asProofNode(t.db.branchFactor))
 	if t.isInvalid() {
 		return nil, ErrInvalid
 	}
 	return proof, nil
 }
 
+// GetMultiProof returns a proof of the values of [keys] in trie [t].
+func (t *trieView) GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error) {
+	_, span := t.db.infoTracer.Start(ctx, "MerkleDB.trieview.GetMultiProof")
+	defer span.End()
+
+	if err := t.calculateNodeIDs(ctx); err != nil {
+		return nil, err
+	}
+
+	return t.getMultiProof(ctx, keys)
+}
+
+// Returns a proof of the values of [keys] in trie [t].
+// Assumes the node IDs of [t] have been calculated.
+func (t *trieView) getMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error) {
+	_, span := t.db.infoTracer.Start(ctx, "MerkleDB.trieview.getMultiProof")
+	defer span.End()
+
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	// Sort and deduplicate the keys without modifying [keys].
+	sortedKeys := slices.Clone(keys)
+	utils.SortBytes(sortedKeys)
+	sortedKeys = slices.CompactFunc(sortedKeys, bytes.Equal)
+
+	multiProof := &MultiProof{
+		Keys:         make([][]byte, len(sortedKeys)),
+		Values:       make([]maybe.Maybe[[]byte], len(sortedKeys)),
+		BranchFactor: t.db.branchFactor,
+		Hasher:       t.db.hasher,
+	}
+
+	// The paths to the keys share nodes, so only include each node once.
+	nodes := make(map[path]ProofNode)
+	for i, key := range sortedKeys {
+		proof, err := t.getProof(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		multiProof.Keys[i] = slices.Clone(key)
+		multiProof.Values[i] = proof.Value
+		for _, node := range proof.Path {
+			nodes[t.db.branchFactor.deserialize(node.KeyPath)] = node
+		}
+	}
+
+	nodePaths := maps.Keys(nodes)
+	utils.Sort(nodePaths)
+	multiProof.Nodes = make([]ProofNode, len(nodePaths))
+	for i, nodePath := range nodePaths {
+		multiProof.Nodes[i] = nodes[nodePath]
+	}
+	return multiProof, nil
+}
+
 // GetRangeProof returns a range proof for (at least part of) the key range [start, end].
 // The returned proof's [KeyValues] has at most [maxLength] values.
 // [maxLength] must be > 0.
@@ -397,7 +457,10 @@ func (t *trieView) GetRangeProof(
 		return nil, err
 	}
 
-	var result RangeProof
+	result := RangeProof{
+		BranchFactor: t.db.branchFactor,
+		Hasher:       t.db.hasher,
+	}
 
 	result.KeyValues = make([]KeyValue, 0, initKeyValuesSize)
 	it := t.NewIteratorWithStart(start.Value())
@@ -554,7 +617,7 @@ func (t *trieView) GetValues(ctx context.Context, keys [][]byte) ([][]byte, []er
 	valueErrors := make([]error, len(keys))
 
 	for i, key := range keys {
-		results[i], valueErrors[i] = t.getValueCopy(newPath(key))
+		results[i], valueErrors[i] = t.getValueCopy(t.db.branchFactor.newPath(key))
 	}
 	return results, valueErrors
 }
@@ -565,7 +628,7 @@ func (t *trieView) GetValue(ctx context.Context, key []byte) ([]byte, error) {
 	_, span := t.db.debugTracer.Start(ctx, "MerkleDB.trieview.GetValue")
 	defer span.End()
 
-	return t.getValueCopy(newPath(key))
+	return t.getValueCopy(t.db.branchFactor.newPath(key))
 }
 
 // getValueCopy returns a copy of the value for the given [key].
@@ -632,7 +695,7 @@ func (t *trieView) remove(key path) error {
 		}
 	}
 
-	nodeToDelete.setValue(maybe.Nothing[[]byte]())
+	nodeToDelete.setValue(maybe.Nothing[[]byte](), t.db.hasher)
 	if err := t.recordNodeChange(nodeToDelete); err != nil {
 		return err
 	}
@@ -687,7 +750,7 @@ func (t *trieView) compressNodePath(parent, node *node) error {
 		// "Cycle" over the key/values to find the only child.
 		// Note this iteration once because len(node.children) == 1.
 		for index, entry := range node.children {
-			childPath = node.key + path(index) + entry.compressedPath
+			childPath = node.key.Append(index) + entry.compressedPath
 			childEntry = entry
 		}
 
@@ -840,7 +903,7 @@ func (t *trieView) insert(
 
 	// a node with that exact path already exists so update its value
 	if closestNode.key.Compare(key) == 0 {
-		closestNode.setValue(value)
+		closestNode.setValue(value, t.db.hasher)
 		// closestNode was already marked as changed in the ancestry loop above
 		return closestNode, nil
 	}
@@ -859,7 +922,7 @@ func (t *trieView) insert(
 			closestNode,
 			key,
 		)
-		newNode.setValue(value)
+		newNode.setValue(value, t.db.hasher)
 		return newNode, t.recordNewNode(newNode)
 	}
 
@@ -880,14 +943,14 @@ func (t *trieView) insert(
 
 	if len(key)-len(branchNode.key) == 0 {
 		// there was no residual path for the inserted key, so the value goes directly into the new branch node
-		branchNode.setValue(value)
+		branchNode.setValue(value, t.db.hasher)
 	} else {
 		// generate a new node and add it as a child of the branch node
 		newNode := newNode(
 			branchNode,
 			key,
 		)
-		newNode.setValue(value)
+		newNode.setValue(value, t.db.hasher)
 		if err := t.recordNewNode(newNode); err != nil {
 			return nil, err
 		}
@@ -27,15 +27,30 @@ type valueNodeDB struct {
 	nodeCache cache.Cacher[path, *node]
 	metrics   merkleMetrics
 
+	// The branch factor and hasher of the trie.
+	branchFactor BranchFactor
+	hasher       Hasher
+
 	closed utils.Atomic[bool]
 }
 
-func newValueNodeDB(db database.Database, bufferPool *sync.Pool, metrics merkleMetrics, size int) *valueNodeDB {
+func newValueNodeDB(
+	db database.Database,
+	bufferPool *sync.Pool,
+	metrics merkleMetrics,
+	size int,
+	branchFactor BranchFactor,
+	hasher Hasher,
+) *valueNodeDB {
 	return &valueNodeDB{
 		metrics:    metrics,
 		baseDB:     db,
 		bufferPool: bufferPool,
-		nodeCache:  cache.NewSizedLRU(size, cacheEntrySize),
+		nodeCache: cache.NewSizedLRU(size, func(key path, n *node) int {
+			return cacheEntrySize(key, n, branchFactor)
+		}),
+		branchFactor: branchFactor,
+		hasher:       hasher,
 	}
 }
 
@@ -72,7 +87,7 @@ func (db *valueNodeDB) Get(key path) (*node, error) {
 	}
 	db.metrics.ValueNodeCacheMiss()
 
-	prefixedKey := addPrefixToKey(db.bufferPool, valueNodePrefix, key.Serialize().Value)
+	prefixedKey := addPrefixToKey(db.bufferPool, valueNodePrefix, db.branchFactor.serialize(key).Value)
 	defer db.bufferPool.Put(prefixedKey)
 
 	db.metrics.DatabaseNodeRead()
@@ -81,7 +96,7 @@ func (db *valueNodeDB) Get(key path) (*node, error) {
 		return nil, err
 	}
 
-	return parseNode(key, nodeBytes)
+	return parseNode(key, nodeBytes, db.branchFactor, db.hasher)
 }
 
 // Batch of database operations
@@ -104,12 +119,12 @@ func (b *valueNodeBatch) Write() error {
 	for key, n := range b.ops {
 		b.db.metrics.DatabaseNodeWrite()
 		b.db.nodeCache.Put(key, n)
-		prefixedKey := addPrefixToKey(b.db.bufferPool, valueNodePrefix, key.Serialize().Value)
+		prefixedKey := addPrefixToKey(b.db.bufferPool, valueNodePrefix, b.db.branchFactor.serialize(key).Value)
 		if n == nil {
 			if err := dbBatch.Delete(prefixedKey); err != nil {
 				return err
 			}
-		} else if err := dbBatch.Put(prefixedKey, n.bytes()); err != nil {
+		} else if err := dbBatch.Put(prefixedKey, n.bytes(b.db.branchFactor)); err != nil {
 			return err
 		}
 
@@ -140,7 +155,7 @@ func (i *iterator) Key() []byte {
 	if i.current == nil {
 		return nil
 	}
-	return i.current.key.Serialize().Value
+	return i.db.branchFactor.serialize(i.current.key).Value
 }
 
 func (i *iterator) Value() []byte {
@@ -162,7 +177,7 @@ func (i *iterator) Next() bool {
 	i.db.metrics.DatabaseNodeRead()
 	key := i.nodeIter.Key()
 	key = key[valueNodePrefixLen:]
-	n, err := parseNode(newPath(key), i.nodeIter.Value())
+	n, err := parseNode(i.db.branchFactor.newPath(key), i.nodeIter.Value(), i.db.branchFactor, i.db.hasher)
 	if err != nil {
 		i.err = err
 		return false
@@ -28,6 +28,8 @@ func TestValueNodeDB(t *testing.T) {
 		},
 		&mockMetrics{},
 		size,
+		BranchFactor16,
+		DefaultHasher,
 	)
 
 	// Getting a key that doesn't exist should return an error.
@@ -121,6 +123,8 @@ func TestValueNodeDBIterator(t *testing.T) {
 		},
 		&mockMetrics{},
 		cacheSize,
+		BranchFactor16,
+		DefaultHasher,
 	)
 
 	// Put key-node pairs.
@@ -26,7 +26,7 @@ func (t *trieView) NewIteratorWithPrefix(prefix []byte) database.Iterator {
 func (t *trieView) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
 	changes := make([]KeyChange, 0, len(t.changes.values))
 	for path, change := range t.changes.values {
-		key := path.Serialize().Value
+		key := t.db.branchFactor.serialize(path).Value
 		if (len(start) > 0 && bytes.Compare(start, key) > 0) || !bytes.HasPrefix(key, prefix) {
 			continue
 		}
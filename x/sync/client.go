@@ -295,6 +295,10 @@ func getAndParse[T any](
 			if response, err = parseFn(ctx, responseBytes); err == nil {
 				return response, nil
 			}
+			if ctx.Err() == nil {
+				// The peer sent a response we couldn't parse or verify.
+				client.networkClient.TrackInvalidResponse(nodeID)
+			}
 		}
 
 		if errors.Is(err, errAppSendFailed) {
@@ -119,8 +119,8 @@ func sendRangeProofRequest(
 		},
 	).AnyTimes()
 
-	// Handle bandwidth tracking calls from client.
-	networkClient.EXPECT().TrackBandwidth(gomock.Any(), gomock.Any()).AnyTimes()
+	// Handle invalid response tracking calls from client.
+	networkClient.EXPECT().TrackInvalidResponse(gomock.Any()).AnyTimes()
 
 	// The server should expect to "send" a response to the client.
 	sender.EXPECT().SendAppResponse(
@@ -436,6 +436,9 @@ func sendChangeProofRequest(
 		},
 	).AnyTimes()
 
+	// Handle invalid response tracking calls from client.
+	networkClient.EXPECT().TrackInvalidResponse(gomock.Any()).AnyTimes()
+
 	// Expect server (serverDB) to send app response to client (clientDB)
 	sender.EXPECT().SendAppResponse(
 		gomock.Any(), // ctx
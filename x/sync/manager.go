@@ -9,21 +9,29 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 
 	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
+	safemath "github.com/ava-labs/avalanchego/utils/math"
 )
 
 const (
 	defaultRequestKeyLimit      = maxKeyValuesLimit
 	defaultRequestByteSizeLimit = maxByteSizeLimit
+
+	// The minimum amount of time between checkpoints of the progress that
+	// are taken when a work item is completed.
+	progressCheckpointFrequency = time.Second
 )
 
 var (
@@ -76,6 +84,9 @@ type Manager struct {
 	// Namely, the number of goroutines executing [doWork].
 	// [workLock] must be held when accessing [processingWorkItems].
 	processingWorkItems int
+	// The work items currently being processed that haven't been completed.
+	// [workLock] must be held while accessing [processingWork].
+	processingWork set.Set[*workItem]
 	// [workLock] must be held while accessing [unprocessedWork].
 	unprocessedWork *workHeap
 	// Signalled when:
@@ -86,6 +97,20 @@ type Manager struct {
 	unprocessedWorkCond sync.Cond
 	// [workLock] must be held while accessing [processedWork].
 	processedWork *workHeap
+	// The number of checkpoints of the progress that have been taken.
+	// [workLock] must be held while accessing [progressCheckpoints].
+	progressCheckpoints uint64
+	// When the last checkpoint of the progress was taken.
+	// [workLock] must be held while accessing [lastProgressCheckpoint].
+	lastProgressCheckpoint time.Time
+
+	// Must be held when writing a checkpoint to [config.ProgressDB].
+	progressLock sync.Mutex
+	// The number of the last checkpoint written to [config.ProgressDB].
+	// Checkpoints are written outside of [workLock], so an older checkpoint
+	// may be written after a newer one. It's dropped instead.
+	// [progressLock] must be held when accessing [savedProgressCheckpoint].
+	savedProgressCheckpoint uint64
 
 	// When this is closed:
 	// - [closed] is true.
@@ -112,6 +137,20 @@ type ManagerConfig struct {
 	SimultaneousWorkLimit int
 	Log                   logging.Logger
 	TargetRoot            ids.ID
+	// The branch factor of [DB].
+	// If 0, [merkledb.DefaultBranchFactor] is used.
+	BranchFactor merkledb.BranchFactor
+	// If non-nil, the progress of the sync is checkpointed to [ProgressDB]
+	// periodically and when the Manager is closed, so that it can be
+	// resumed by a Manager with the same [DB] and [ProgressDB] after a
+	// restart. If [TargetRoot] differs from the root that was being synced
+	// to, the sync is resumed as if UpdateSyncTarget had been called with
+	// [TargetRoot].
+	//
+	// Checkpoints are written after the changes they describe are committed
+	// to [DB], so [ProgressDB] should be backed by the same database as [DB]
+	// (e.g. a prefixdb of it) to ensure the writes are persisted in order.
+	ProgressDB database.Database
 }
 
 func NewManager(config ManagerConfig) (*Manager, error) {
@@ -126,11 +165,19 @@ func NewManager(config ManagerConfig) (*Manager, error) {
 		return nil, ErrZeroWorkLimit
 	}
 
+	if config.BranchFactor == 0 {
+		config.BranchFactor = merkledb.DefaultBranchFactor
+	}
+	if err := config.BranchFactor.Valid(); err != nil {
+		return nil, err
+	}
+
 	m := &Manager{
 		config:          config,
 		doneChan:        make(chan struct{}),
 		unprocessedWork: newWorkHeap(),
 		processedWork:   newWorkHeap(),
+		processingWork:  set.Set[*workItem]{},
 	}
 	m.unprocessedWorkCond.L = &m.workLock
 
@@ -147,9 +194,18 @@ func (m *Manager) Start(ctx context.Context) error {
 
 	m.config.Log.Info("starting sync", zap.Stringer("target root", m.config.TargetRoot))
 
-	// Add work item to fetch the entire key range.
-	// Note that this will be the first work item to be processed.
-	m.unprocessedWork.Insert(newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority))
+	resumed, err := m.loadProgress()
+	if err != nil {
+		return err
+	}
+	if !resumed {
+		// Add work item to fetch the entire key range.
+		// Note that this will be the first work item to be processed.
+		m.unprocessedWork.Insert(newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority))
+	}
+	if err := m.saveProgress(); err != nil {
+		return err
+	}
 
 	m.syncing = true
 	ctx, m.cancelCtx = context.WithCancel(ctx)
@@ -193,6 +249,7 @@ func (m *Manager) sync(ctx context.Context) {
 		default:
 			m.processingWorkItems++
 			work := m.unprocessedWork.GetWork()
+			m.processingWork.Add(work)
 			go m.doWork(ctx, work)
 		}
 	}
@@ -216,6 +273,16 @@ func (m *Manager) close() {
 			m.cancelCtx()
 		}
 
+		// Checkpoints are only taken periodically while syncing, so the
+		// latest progress is saved before the heaps are closed. If syncing
+		// hasn't started, the saved progress hasn't been loaded into the
+		// heaps, so it must not be overwritten.
+		if m.syncing {
+			if err := m.saveProgress(); err != nil {
+				m.setError(err)
+			}
+		}
+
 		// ensure any goroutines waiting for work from the heaps gets released
 		m.unprocessedWork.Close()
 		m.unprocessedWorkCond.Signal()
@@ -398,16 +465,14 @@ func (m *Manager) findNextKey(
 	// and traversing them from the longest key to the shortest key.
 	// For each node in these proofs, compare if the children of that node exist
 	// or have the same ID in the other proof.
-	proofKeyPath := merkledb.SerializedPath{
-		Value:        lastReceivedKey,
-		NibbleLength: 2 * len(lastReceivedKey),
-	}
+	bf := m.config.BranchFactor
+	proofKeyPath := bf.KeyPath(lastReceivedKey)
 
 	// If the received proof is an exclusion proof, the last node may be for a
 	// key that is after the [lastReceivedKey].
 	// If the last received node's key is after the [lastReceivedKey], it can
 	// be removed to obtain a valid proof for a prefix of the [lastReceivedKey].
-	if !proofKeyPath.HasPrefix(endProof[len(endProof)-1].KeyPath) {
+	if !bf.HasPrefix(proofKeyPath, endProof[len(endProof)-1].KeyPath) {
 		endProof = endProof[:len(endProof)-1]
 		// update the proofKeyPath to be for the prefix
 		proofKeyPath = endProof[len(endProof)-1].KeyPath
@@ -422,7 +487,7 @@ func (m *Manager) findNextKey(
 
 	// The local proof may also be an exclusion proof with an extra node.
 	// Remove this extra node if it exists to get a proof of the same key as the received proof
-	if !proofKeyPath.HasPrefix(localProofNodes[len(localProofNodes)-1].KeyPath) {
+	if !bf.HasPrefix(proofKeyPath, localProofNodes[len(localProofNodes)-1].KeyPath) {
 		localProofNodes = localProofNodes[:len(localProofNodes)-1]
 	}
 
@@ -474,25 +539,25 @@ func (m *Manager) findNextKey(
 
 		// We only want to look at the children with keys greater than the proofKey.
 		// The proof key has the deepest node's key as a prefix,
-		// so only the next nibble of the proof key needs to be considered.
+		// so only the next token of the proof key needs to be considered.
 
 		// If the deepest node has the same key as [proofKeyPath],
 		// then all of its children have keys greater than the proof key,
-		// so we can start at the 0 nibble.
-		startingChildNibble := byte(0)
+		// so we can start at the 0 token.
+		startingChildIndex := 0
 
 		// If the deepest node has a key shorter than the key being proven,
-		// we can look at the next nibble of the proof key to determine which of that
+		// we can look at the next token of the proof key to determine which of that
 		// node's children have keys larger than [proofKeyPath].
-		// Any child with a nibble greater than the [proofKeyPath]'s nibble at that
+		// Any child with a token greater than the [proofKeyPath]'s token at that
 		// index will have a larger key.
 		if deepestNode.KeyPath.NibbleLength < proofKeyPath.NibbleLength {
-			startingChildNibble = proofKeyPath.NibbleVal(deepestNode.KeyPath.NibbleLength) + 1
+			startingChildIndex = int(bf.Token(proofKeyPath, deepestNode.KeyPath.NibbleLength)) + 1
 		}
 
 		// determine if there are any differences in the children for the deepest unhandled node of the two proofs
-		if childIndex, hasDifference := findChildDifference(deepestNode, deepestNodeFromOtherProof, startingChildNibble); hasDifference {
-			nextKey = maybe.Some(deepestNode.KeyPath.AppendNibble(childIndex).Value)
+		if childIndex, hasDifference := findChildDifference(deepestNode, deepestNodeFromOtherProof, startingChildIndex, bf); hasDifference {
+			nextKey = maybe.Some(bf.AppendToken(deepestNode.KeyPath, childIndex).Value)
 			break
 		}
 	}
@@ -575,21 +640,130 @@ func (m *Manager) UpdateSyncTarget(syncTargetRoot ids.ID) error {
 	m.config.Log.Debug("updated sync target", zap.Stringer("target", syncTargetRoot))
 	m.config.TargetRoot = syncTargetRoot
 
-	// move all completed ranges into the work heap with high priority
-	shouldSignal := m.processedWork.Len() > 0
+	// Note that [m.processedWork].Close() hasn't been called because we
+	// have [m.workLock] and we checked that [m.closed] is false.
+	if m.requeueProcessedWork() {
+		// Only signal once because we only have 1 goroutine
+		// waiting on [m.unprocessedWorkCond].
+		m.unprocessedWorkCond.Signal()
+	}
+	if !m.syncing {
+		// The progress is saved once it's loaded when syncing starts.
+		return nil
+	}
+	return m.saveProgress()
+}
+
+// Moves all completed ranges into the work heap with high priority.
+// Returns true if any ranges were moved.
+// Assumes [m.workLock] is held.
+func (m *Manager) requeueProcessedWork() bool {
+	moved := m.processedWork.Len() > 0
 	for m.processedWork.Len() > 0 {
-		// Note that [m.processedWork].Close() hasn't
-		// been called because we have [m.workLock]
-		// and we checked that [m.closed] is false.
 		currentItem := m.processedWork.GetWork()
 		currentItem.priority = highPriority
 		m.unprocessedWork.Insert(currentItem)
 	}
-	if shouldSignal {
-		// Only signal once because we only have 1 goroutine
-		// waiting on [m.unprocessedWorkCond].
-		m.unprocessedWorkCond.Signal()
+	return moved
+}
+
+// Populates the work heaps with the progress stored in [m.config.ProgressDB].
+// Returns true if there was progress to resume.
+// Assumes [m.workLock] is held.
+func (m *Manager) loadProgress() (bool, error) {
+	if m.config.ProgressDB == nil {
+		return false, nil
+	}
+
+	progress, err := getProgress(m.config.ProgressDB)
+	if err == database.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// The ranges being processed when the progress was saved may not have
+	// been completed, so they're treated as unprocessed.
+	for _, item := range progress.processed {
+		m.processedWork.MergeInsert(item)
+	}
+	for _, item := range progress.pending {
+		m.unprocessedWork.Insert(item)
+	}
+
+	m.config.Log.Info("resuming sync",
+		zap.Stringer("previous target root", progress.targetRoot),
+		zap.Int("processed ranges", m.processedWork.Len()),
+		zap.Int("unprocessed ranges", m.unprocessedWork.Len()),
+	)
+
+	if progress.targetRoot != m.config.TargetRoot {
+		// The ranges synced to the previous target are out of date.
+		m.requeueProcessedWork()
+	}
+	return true, nil
+}
+
+// Writes the current progress to [m.config.ProgressDB], if it's set.
+// Assumes [m.workLock] is held.
+func (m *Manager) saveProgress() error {
+	checkpoint, progressBytes, err := m.checkpointProgress()
+	if err != nil {
+		return err
+	}
+	return m.writeProgress(checkpoint, progressBytes)
+}
+
+// Returns the number of a new checkpoint of the current progress and its
+// serialized bytes, which must be written by [writeProgress].
+// Returns nil bytes if [m.config.ProgressDB] isn't set.
+// The work items are serialized immediately because they are modified in
+// place as ranges are merged.
+// Assumes [m.workLock] is held.
+func (m *Manager) checkpointProgress() (uint64, []byte, error) {
+	if m.config.ProgressDB == nil {
+		return 0, nil, nil
+	}
+
+	progress := &syncProgress{
+		// [m.config.TargetRoot] is only modified while [m.workLock] is held.
+		targetRoot: m.config.TargetRoot,
+		processed:  m.processedWork.Items(),
+		pending:    m.unprocessedWork.Items(),
+	}
+	for item := range m.processingWork {
+		progress.pending = append(progress.pending, item)
+	}
+	progressBytes, err := progress.bytes()
+	if err != nil {
+		return 0, nil, err
 	}
+
+	m.progressCheckpoints++
+	m.lastProgressCheckpoint = time.Now()
+	return m.progressCheckpoints, progressBytes, nil
+}
+
+// Writes the checkpoint [checkpoint] of the progress, whose serialized bytes
+// are [progressBytes], to [m.config.ProgressDB], unless a newer checkpoint has
+// already been written.
+// Doesn't need [m.workLock] to be held.
+func (m *Manager) writeProgress(checkpoint uint64, progressBytes []byte) error {
+	if progressBytes == nil {
+		return nil
+	}
+
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+
+	if checkpoint <= m.savedProgressCheckpoint {
+		return nil
+	}
+	if err := m.config.ProgressDB.Put(progressKey, progressBytes); err != nil {
+		return err
+	}
+	m.savedProgressCheckpoint = checkpoint
 	return nil
 }
 
@@ -626,6 +800,7 @@ func (m *Manager) setError(err error) {
 //
 // Assumes [m.workLock] is not held.
 func (m *Manager) completeWorkItem(ctx context.Context, work *workItem, largestHandledKey maybe.Maybe[[]byte], rootID ids.ID, proofOfLargestKey []merkledb.ProofNode) {
+	var remainingWork *workItem
 	if !maybe.Equal(largestHandledKey, work.end, bytes.Equal) {
 		// The largest handled key isn't equal to the end of the work item.
 		// Find the start of the next key range to fetch.
@@ -644,28 +819,65 @@ func (m *Manager) completeWorkItem(ctx context.Context, work *workItem, largestH
 		if nextStartKey.IsNothing() {
 			largestHandledKey = work.end
 		} else {
-			// the full range wasn't completed, so enqueue a new work item for the range [nextStartKey, workItem.end]
-			m.enqueueWork(newWorkItem(work.localRootID, nextStartKey, work.end, work.priority))
+			// the full range wasn't completed, so a new work item for the range [nextStartKey, workItem.end]
+			// is enqueued below
+			remainingWork = newWorkItem(work.localRootID, nextStartKey, work.end, work.priority)
 			largestHandledKey = nextStartKey
 		}
 	}
 
+	checkpoint, progressBytes, err := m.updateWorkHeaps(work, remainingWork, largestHandledKey, rootID)
+	if err != nil {
+		m.setError(err)
+		return
+	}
+
+	// The checkpoint is written after [m.workLock] is released so that
+	// other work items can be completed in the meantime.
+	if err := m.writeProgress(checkpoint, progressBytes); err != nil {
+		m.setError(err)
+	}
+}
+
+// Records that the range [work.start, largestHandledKey] was fetched for the
+// trie with root [rootID], and queues [remainingWork], if it's non-nil.
+// If it's been at least [progressCheckpointFrequency] since the last
+// checkpoint of the progress was taken, returns a new one, which must be
+// written by [writeProgress].
+// Assumes [m.workLock] is not held.
+func (m *Manager) updateWorkHeaps(work *workItem, remainingWork *workItem, largestHandledKey maybe.Maybe[[]byte], rootID ids.ID) (uint64, []byte, error) {
 	// Process [work] while holding [syncTargetLock] to ensure that object
 	// is added to the right queue, even if a target update is triggered
 	m.syncTargetLock.RLock()
 	defer m.syncTargetLock.RUnlock()
 
+	// Update the work heaps and take the checkpoint atomically so that the
+	// saved ranges never overlap.
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	select {
+	case <-m.doneChan:
+		// If we're closed, the work heaps won't be updated, and the final
+		// progress has already been saved.
+		return 0, nil, nil
+	default:
+	}
+
+	if remainingWork != nil {
+		m.enqueueWork(remainingWork)
+	}
+
 	stale := m.config.TargetRoot != rootID
 	if stale {
 		// the root has changed, so reinsert with high priority
 		m.enqueueWork(newWorkItem(rootID, work.start, largestHandledKey, highPriority))
 	} else {
-		m.workLock.Lock()
-		defer m.workLock.Unlock()
-
 		m.processedWork.MergeInsert(newWorkItem(rootID, work.start, largestHandledKey, work.priority))
 	}
 
+	m.processingWork.Remove(work)
+
 	// completed the range [work.start, lastKey], log and record in the completed work heap
 	m.config.Log.Debug("completed range",
 		zap.Stringer("start", work.start),
@@ -673,46 +885,78 @@ func (m *Manager) completeWorkItem(ctx context.Context, work *workItem, largestH
 		zap.Stringer("rootID", rootID),
 		zap.Bool("stale", stale),
 	)
+
+	if time.Since(m.lastProgressCheckpoint) < progressCheckpointFrequency {
+		return 0, nil, nil
+	}
+	return m.checkpointProgress()
 }
 
 // Queue the given key range to be fetched and applied.
 // If there are sufficiently few unprocessed/processing work items,
-// splits the range into two items and queues them both.
-// Assumes [m.workLock] is not held.
+// splits the range into at least two items and queues them all.
+// When work slots are idle, the range is split into enough items to
+// fill them so that it's fetched from several peers concurrently.
+// Assumes [m.workLock] is held.
 func (m *Manager) enqueueWork(work *workItem) {
-	m.workLock.Lock()
-	defer func() {
-		m.workLock.Unlock()
-		m.unprocessedWorkCond.Signal()
-	}()
+	defer m.unprocessedWorkCond.Signal()
 
-	if m.processingWorkItems+m.unprocessedWork.Len() > 2*m.config.SimultaneousWorkLimit {
+	numWorkItems := m.processingWorkItems + m.unprocessedWork.Len()
+	if numWorkItems > 2*m.config.SimultaneousWorkLimit {
 		// There are too many work items already, don't split the range
 		m.unprocessedWork.Insert(work)
 		return
 	}
 
-	// Split the remaining range into to 2.
-	// Find the middle point.
-	mid := midPoint(work.start, work.end)
-
-	if maybe.Equal(work.start, mid, bytes.Equal) || maybe.Equal(mid, work.end, bytes.Equal) {
+	numIdleWorkSlots := m.config.SimultaneousWorkLimit - numWorkItems
+	bounds := splitRange(work.start, work.end, safemath.Max(2, numIdleWorkSlots))
+	if len(bounds) == 2 {
 		// The range is too small to split.
-		// If we didn't have this check we would add work items
-		// [start, start] and [start, end]. Since start <= end, this would
-		// violate the invariant of [m.unprocessedWork] and [m.processedWork]
-		// that there are no overlapping ranges.
 		m.unprocessedWork.Insert(work)
 		return
 	}
 
-	// first item gets higher priority than the second to encourage finished ranges to grow
+	// first item gets higher priority than the others to encourage finished ranges to grow
 	// rather than start a new range that is not contiguous with existing completed ranges
-	first := newWorkItem(work.localRootID, work.start, mid, medPriority)
-	second := newWorkItem(work.localRootID, mid, work.end, lowPriority)
+	m.unprocessedWork.Insert(newWorkItem(work.localRootID, bounds[0], bounds[1], medPriority))
+	for i := 1; i < len(bounds)-1; i++ {
+		m.unprocessedWork.Insert(newWorkItem(work.localRootID, bounds[i], bounds[i+1], lowPriority))
+	}
+}
 
-	m.unprocessedWork.Insert(first)
-	m.unprocessedWork.Insert(second)
+// Splits the range [start, end] into at most [maxRanges] contiguous ranges by
+// repeatedly splitting the ranges at their midpoint.
+// Returns the bounds of the ranges, where range i is [bounds[i], bounds[i+1]].
+// If the range is too small to split, returns [start, end].
+func splitRange(start, end maybe.Maybe[[]byte], maxRanges int) []maybe.Maybe[[]byte] {
+	bounds := []maybe.Maybe[[]byte]{start, end}
+	for {
+		// Split every range we can in this pass, as long as
+		// we don't exceed [maxRanges].
+		split := false
+		newBounds := []maybe.Maybe[[]byte]{bounds[0]}
+		for i := 0; i < len(bounds)-1; i++ {
+			rangeStart, rangeEnd := bounds[i], bounds[i+1]
+			remaining := len(bounds) - 2 - i // ranges after this one
+			if len(newBounds)+remaining < maxRanges {
+				mid := midPoint(rangeStart, rangeEnd)
+				// If we didn't check that the range is big enough to split, we
+				// would add ranges [start, start] and [start, end]. Since
+				// start <= end, this would violate the invariant of
+				// [m.unprocessedWork] and [m.processedWork] that there are no
+				// overlapping ranges.
+				if !maybe.Equal(rangeStart, mid, bytes.Equal) && !maybe.Equal(mid, rangeEnd, bytes.Equal) {
+					newBounds = append(newBounds, mid)
+					split = true
+				}
+			}
+			newBounds = append(newBounds, rangeEnd)
+		}
+		bounds = newBounds
+		if !split {
+			return bounds
+		}
+	}
 }
 
 // find the midpoint between two keys
@@ -790,13 +1034,15 @@ func midPoint(startMaybe, endMaybe maybe.Maybe[[]byte]) maybe.Maybe[[]byte] {
 }
 
 // findChildDifference returns the first child index that is different between node 1 and node 2 if one exists and
-// a bool indicating if any difference was found
-func findChildDifference(node1, node2 *merkledb.ProofNode, startIndex byte) (byte, bool) {
+// a bool indicating if any difference was found.
+// [bf] is the branch factor of the trie the nodes are from.
+func findChildDifference(node1, node2 *merkledb.ProofNode, startIndex int, bf merkledb.BranchFactor) (byte, bool) {
 	var (
 		child1, child2 ids.ID
 		ok1, ok2       bool
 	)
-	for childIndex := startIndex; childIndex < merkledb.NodeBranchFactor; childIndex++ {
+	for index := startIndex; index < int(bf); index++ {
+		childIndex := byte(index)
 		if node1 != nil {
 			child1, ok1 = node1.Children[childIndex]
 		}
@@ -8,9 +8,15 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
+const (
+	nodeIDLabel = "nodeID"
+	reasonLabel = "reason"
+)
+
 var (
 	_ SyncMetrics = (*mockMetrics)(nil)
 	_ SyncMetrics = (*metrics)(nil)
@@ -94,3 +100,66 @@ func (m *metrics) RequestMade() {
 func (m *metrics) RequestSucceeded() {
 	m.requestsSucceeded.Inc()
 }
+
+// peerMetrics reports how each peer we send requests to is performing.
+// Label values for a peer are removed when it disconnects.
+type peerMetrics struct {
+	score     *prometheus.GaugeVec
+	bandwidth *prometheus.GaugeVec
+	latency   *prometheus.GaugeVec
+	failures  *prometheus.CounterVec
+}
+
+func newPeerMetrics(namespace string, reg prometheus.Registerer) (*peerMetrics, error) {
+	m := &peerMetrics{
+		score: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "peer_score",
+			Help:      "score used to rank the peer when choosing who to send a request to",
+		}, []string{nodeIDLabel}),
+		bandwidth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "peer_bandwidth",
+			Help:      "average sync bandwidth of the peer's responses",
+		}, []string{nodeIDLabel}),
+		latency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "peer_latency",
+			Help:      "average time (in seconds) the peer takes to respond",
+		}, []string{nodeIDLabel}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "peer_failures",
+			Help:      "cumulative amount of requests to the peer that failed, by reason",
+		}, []string{nodeIDLabel, reasonLabel}),
+	}
+	errs := wrappers.Errs{}
+	errs.Add(
+		reg.Register(m.score),
+		reg.Register(m.bandwidth),
+		reg.Register(m.latency),
+		reg.Register(m.failures),
+	)
+	return m, errs.Err
+}
+
+// Reports the current state of [peer].
+func (m *peerMetrics) update(nodeID ids.NodeID, peer *peerInfo) {
+	nodeIDStr := nodeID.String()
+	m.score.WithLabelValues(nodeIDStr).Set(peer.score())
+	m.bandwidth.WithLabelValues(nodeIDStr).Set(readAverager(peer.bandwidth))
+	m.latency.WithLabelValues(nodeIDStr).Set(readAverager(peer.latency))
+}
+
+func (m *peerMetrics) failure(nodeID ids.NodeID, reason string) {
+	m.failures.WithLabelValues(nodeID.String(), reason).Inc()
+}
+
+// Stops reporting metrics for [nodeID].
+func (m *peerMetrics) remove(nodeID ids.NodeID) {
+	labels := prometheus.Labels{nodeIDLabel: nodeID.String()}
+	m.score.Delete(labels)
+	m.bandwidth.Delete(labels)
+	m.latency.Delete(labels)
+	m.failures.DeletePartialMatch(labels)
+}
@@ -123,14 +123,14 @@ func (mr *MockNetworkClientMockRecorder) RequestAny(ctx, minVersion, request int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestAny", reflect.TypeOf((*MockNetworkClient)(nil).RequestAny), ctx, minVersion, request)
 }
 
-// TrackBandwidth mocks base method.
-func (m *MockNetworkClient) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
+// TrackInvalidResponse mocks base method.
+func (m *MockNetworkClient) TrackInvalidResponse(arg0 ids.NodeID) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "TrackBandwidth", nodeID, bandwidth)
+	m.ctrl.Call(m, "TrackInvalidResponse", arg0)
 }
 
-// TrackBandwidth indicates an expected call of TrackBandwidth.
-func (mr *MockNetworkClientMockRecorder) TrackBandwidth(nodeID, bandwidth interface{}) *gomock.Call {
+// TrackInvalidResponse indicates an expected call of TrackInvalidResponse.
+func (mr *MockNetworkClientMockRecorder) TrackInvalidResponse(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrackBandwidth", reflect.TypeOf((*MockNetworkClient)(nil).TrackBandwidth), nodeID, bandwidth)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrackInvalidResponse", reflect.TypeOf((*MockNetworkClient)(nil).TrackInvalidResponse), arg0)
 }
@@ -55,6 +55,10 @@ type NetworkClient interface {
 		request []byte,
 	) ([]byte, error)
 
+	// Records that [nodeID] sent a response that couldn't be parsed or
+	// verified so that the peer is less likely to be sent future requests.
+	TrackInvalidResponse(nodeID ids.NodeID)
+
 	// The following declarations allow this interface to be embedded in the VM
 	// to handle incoming responses from peers.
 
@@ -278,17 +282,18 @@ func (c *networkClient) request(
 
 	select {
 	case <-ctx.Done():
+		// We gave up on the request, which isn't necessarily the peer's fault.
 		c.peers.TrackBandwidth(nodeID, 0)
 		return nil, ctx.Err()
 	case response = <-handler.responseChan:
-		elapsedSeconds := time.Since(startTime).Seconds()
-		bandwidth := float64(len(response))/elapsedSeconds + epsilon
-		c.peers.TrackBandwidth(nodeID, bandwidth)
 	}
 	if handler.failed {
-		c.peers.TrackBandwidth(nodeID, 0)
+		// The engine reports a request as failed when the peer
+		// doesn't respond before the request times out.
+		c.peers.TrackFailure(nodeID, failureReasonTimeout)
 		return nil, errRequestFailed
 	}
+	c.peers.TrackResponse(nodeID, len(response), time.Since(startTime))
 
 	c.log.Debug("received response from peer",
 		zap.Stringer("nodeID", nodeID),
@@ -298,6 +303,10 @@ func (c *networkClient) request(
 	return response, nil
 }
 
+func (c *networkClient) TrackInvalidResponse(nodeID ids.NodeID) {
+	c.peers.TrackFailure(nodeID, failureReasonInvalidResponse)
+}
+
 func (c *networkClient) Connected(
 	_ context.Context,
 	nodeID ids.NodeID,
@@ -25,6 +25,14 @@ import (
 const (
 	bandwidthHalflife = 5 * time.Minute
 
+	// Peers that fail more than this fraction of requests aren't considered
+	// responsive, so they're only chosen when better peers are busy.
+	maxResponsiveFailureRate = 0.5
+
+	// Reasons a request to a peer is considered to have failed.
+	failureReasonTimeout         = "timeout"
+	failureReasonInvalidResponse = "invalid_response"
+
 	// controls how eagerly we connect to new peers vs. using
 	// peers with known good response bandwidth.
 	desiredMinResponsivePeers = 20
@@ -39,10 +47,51 @@ const (
 type peerInfo struct {
 	version   *version.Application
 	bandwidth math.Averager
+	// Average time, in seconds, the peer takes to respond.
+	latency math.Averager
+	// Average fraction of requests to the peer that timed out
+	// or were answered with an invalid response.
+	failureRate math.Averager
+}
+
+// Returns the score used to rank this peer. Higher is better.
+// The peer's bandwidth is discounted by how often its requests
+// fail and by how long it takes to respond.
+func (p *peerInfo) score() float64 {
+	successRate := 1 - readAverager(p.failureRate)
+	return readAverager(p.bandwidth) * successRate * successRate / (1 + readAverager(p.latency))
+}
+
+// Records [value] in the averager at [averager], creating it if needed.
+func observe(averager *math.Averager, value float64, now time.Time) {
+	if *averager == nil {
+		*averager = math.NewAverager(value, bandwidthHalflife, now)
+		return
+	}
+	(*averager).Observe(value, now)
+}
+
+// Returns the value of [averager], or 0 if nothing has been observed.
+func readAverager(averager math.Averager) float64 {
+	if averager == nil {
+		return 0
+	}
+	return averager.Read()
+}
+
+// score is a fixed value that can be stored in a [math.AveragerHeap].
+// Peers are re-added to the heap whenever their score changes so
+// that the heap's ordering stays valid.
+type score float64
+
+func (score) Observe(float64, time.Time) {}
+
+func (s score) Read() float64 {
+	return float64(s)
 }
 
-// Tracks the bandwidth of responses coming from peers,
-// preferring to contact peers with known good bandwidth, connecting
+// Tracks the bandwidth, latency and failures of responses coming from peers,
+// preferring to contact peers with a known good score, connecting
 // to new peers with an exponentially decaying probability.
 type peerTracker struct {
 	// Lock to protect concurrent access to the peer tracker
@@ -54,13 +103,16 @@ type peerTracker struct {
 	trackedPeers set.Set[ids.NodeID]
 	// Peers that we're connected to that responded to the last request they were sent.
 	responsivePeers set.Set[ids.NodeID]
-	// Max heap that contains the average bandwidth of peers.
-	bandwidthHeap          math.AveragerHeap
+	// Max heap that contains the score of peers.
+	// A peer is removed while a request is outstanding to it, so that
+	// concurrent requests are spread across the best peers.
+	scoreHeap              math.AveragerHeap
 	averageBandwidth       math.Averager
 	log                    logging.Logger
 	numTrackedPeers        prometheus.Gauge
 	numResponsivePeers     prometheus.Gauge
 	averageBandwidthMetric prometheus.Gauge
+	peerMetrics            *peerMetrics
 }
 
 func newPeerTracker(
@@ -68,11 +120,16 @@ func newPeerTracker(
 	metricsNamespace string,
 	registerer prometheus.Registerer,
 ) (*peerTracker, error) {
+	peerMetrics, err := newPeerMetrics(metricsNamespace, registerer)
+	if err != nil {
+		return nil, err
+	}
+
 	t := &peerTracker{
 		peers:            make(map[ids.NodeID]*peerInfo),
 		trackedPeers:     make(set.Set[ids.NodeID]),
 		responsivePeers:  make(set.Set[ids.NodeID]),
-		bandwidthHeap:    math.NewMaxAveragerHeap(),
+		scoreHeap:        math.NewMaxAveragerHeap(),
 		averageBandwidth: math.NewAverager(0, bandwidthHalflife, time.Now()),
 		log:              log,
 		numTrackedPeers: prometheus.NewGauge(
@@ -96,6 +153,7 @@ func newPeerTracker(
 				Help:      "average sync bandwidth used by peers",
 			},
 		),
+		peerMetrics: peerMetrics,
 	}
 
 	errs := wrappers.Errs{}
@@ -138,7 +196,7 @@ func (p *peerTracker) shouldTrackNewPeer() bool {
 // Returns a peer that we're connected to.
 // If we should track more peers, returns a random peer with version >= [minVersion], if any exist.
 // Otherwise, with probability [randomPeerProbability] returns a random peer from [p.responsivePeers].
-// With probability [1-randomPeerProbability] returns the peer in [p.bandwidthHeap] with the highest bandwidth.
+// With probability [1-randomPeerProbability] returns the peer in [p.scoreHeap] with the highest score.
 func (p *peerTracker) GetAnyPeer(minVersion *version.Application) (ids.NodeID, bool) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
@@ -170,10 +228,10 @@ func (p *peerTracker) GetAnyPeer(minVersion *version.Application) (ids.NodeID, b
 	if useRand {
 		nodeID, ok = p.responsivePeers.Peek()
 	} else {
-		nodeID, _, ok = p.bandwidthHeap.Pop()
+		nodeID, _, ok = p.scoreHeap.Pop()
 	}
 	if !ok {
-		// if no nodes found in the bandwidth heap, return a tracked node at random
+		// if no nodes found in the score heap, return a tracked node at random
 		return p.trackedPeers.Peek()
 	}
 	p.log.Debug(
@@ -194,7 +252,7 @@ func (p *peerTracker) TrackPeer(nodeID ids.NodeID) {
 }
 
 // Record that we observed that [nodeID]'s bandwidth is [bandwidth].
-// Adds the peer's bandwidth averager to the bandwidth heap.
+// Adds the peer's score to the score heap.
 func (p *peerTracker) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
@@ -206,18 +264,65 @@ func (p *peerTracker) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
 		return
 	}
 
+	p.trackBandwidth(nodeID, peer, bandwidth, time.Now())
+	p.updateScore(nodeID, peer)
+}
+
+// Record that [nodeID] responded to a request with [responseLen]
+// bytes after [latency].
+// Adds the peer's score to the score heap.
+func (p *peerTracker) TrackResponse(nodeID ids.NodeID, responseLen int, latency time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	peer := p.peers[nodeID]
+	if peer == nil {
+		// we're not connected to this peer, nothing to do here
+		p.log.Debug("tracking response for untracked peer", zap.Stringer("nodeID", nodeID))
+		return
+	}
+
 	now := time.Now()
-	if peer.bandwidth == nil {
-		peer.bandwidth = math.NewAverager(bandwidth, bandwidthHalflife, now)
-	} else {
-		peer.bandwidth.Observe(bandwidth, now)
+	bandwidth := float64(responseLen) / (latency.Seconds() + epsilon)
+	observe(&peer.latency, latency.Seconds(), now)
+	observe(&peer.failureRate, 0, now)
+	p.trackBandwidth(nodeID, peer, bandwidth, now)
+	p.updateScore(nodeID, peer)
+}
+
+// Record that a request to [nodeID] failed for [reason].
+// Adds the peer's score to the score heap.
+func (p *peerTracker) TrackFailure(nodeID ids.NodeID, reason string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	peer := p.peers[nodeID]
+	if peer == nil {
+		// we're not connected to this peer, nothing to do here
+		p.log.Debug("tracking failure for untracked peer",
+			zap.Stringer("nodeID", nodeID),
+			zap.String("reason", reason),
+		)
+		return
 	}
-	p.bandwidthHeap.Add(nodeID, peer.bandwidth)
 
-	if bandwidth == 0 {
+	now := time.Now()
+	observe(&peer.failureRate, 1, now)
+	p.trackBandwidth(nodeID, peer, 0, now)
+	p.updateScore(nodeID, peer)
+	p.peerMetrics.failure(nodeID, reason)
+}
+
+// Assumes [p.lock] is held.
+func (p *peerTracker) trackBandwidth(nodeID ids.NodeID, peer *peerInfo, bandwidth float64, now time.Time) {
+	observe(&peer.bandwidth, bandwidth, now)
+
+	if bandwidth == 0 || readAverager(peer.failureRate) > maxResponsiveFailureRate {
 		p.responsivePeers.Remove(nodeID)
 	} else {
 		p.responsivePeers.Add(nodeID)
+	}
+	if bandwidth != 0 {
 		// TODO danlaine: shouldn't we add the observation of 0
 		// to the average bandwidth in the if statement?
 		p.averageBandwidth.Observe(bandwidth, now)
@@ -226,6 +331,13 @@ func (p *peerTracker) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
 	p.numResponsivePeers.Set(float64(p.responsivePeers.Len()))
 }
 
+// Adds [peer]'s current score to [p.scoreHeap], replacing its previous score.
+// Assumes [p.lock] is held.
+func (p *peerTracker) updateScore(nodeID ids.NodeID, peer *peerInfo) {
+	p.scoreHeap.Add(nodeID, score(peer.score()))
+	p.peerMetrics.update(nodeID, peer)
+}
+
 // Connected should be called when [nodeID] connects to this node
 func (p *peerTracker) Connected(nodeID ids.NodeID, nodeVersion *version.Application) {
 	p.lock.Lock()
@@ -244,8 +356,10 @@ func (p *peerTracker) Connected(nodeID ids.NodeID, nodeVersion *version.Applicat
 	// that we have already marked as Connected.
 	if nodeVersion.Compare(peer.version) != 0 {
 		p.peers[nodeID] = &peerInfo{
-			version:   nodeVersion,
-			bandwidth: peer.bandwidth,
+			version:     nodeVersion,
+			bandwidth:   peer.bandwidth,
+			latency:     peer.latency,
+			failureRate: peer.failureRate,
 		}
 		p.log.Warn(
 			"updating node version of already connected peer",
@@ -266,12 +380,13 @@ func (p *peerTracker) Disconnected(nodeID ids.NodeID) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	p.bandwidthHeap.Remove(nodeID)
+	p.scoreHeap.Remove(nodeID)
 	p.trackedPeers.Remove(nodeID)
 	p.numTrackedPeers.Set(float64(p.trackedPeers.Len()))
 	p.responsivePeers.Remove(nodeID)
 	p.numResponsivePeers.Set(float64(p.responsivePeers.Len()))
 	delete(p.peers, nodeID)
+	p.peerMetrics.remove(nodeID)
 }
 
 // Returns the number of peers the node is connected to.
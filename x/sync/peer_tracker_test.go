@@ -0,0 +1,110 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+func newTestPeerTracker(t *testing.T) *peerTracker {
+	tracker, err := newPeerTracker(logging.NoLog{}, "", prometheus.NewRegistry())
+	require.NoError(t, err)
+	return tracker
+}
+
+func TestPeerInfoScore(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Now()
+	newPeer := func(bandwidth, latency, failureRate float64) *peerInfo {
+		peer := &peerInfo{}
+		observe(&peer.bandwidth, bandwidth, now)
+		observe(&peer.latency, latency, now)
+		observe(&peer.failureRate, failureRate, now)
+		return peer
+	}
+
+	// A peer we haven't heard from has no score.
+	require.Zero((&peerInfo{}).score())
+
+	good := newPeer(1_000, 0, 0)
+	require.Equal(float64(1_000), good.score())
+
+	// Slow and unreliable peers are ranked below fast, reliable ones
+	// with the same bandwidth.
+	slow := newPeer(1_000, 1, 0)
+	require.Less(slow.score(), good.score())
+
+	unreliable := newPeer(1_000, 0, 0.5)
+	require.Less(unreliable.score(), good.score())
+
+	// A peer that always fails is never preferred.
+	broken := newPeer(1_000_000, 0, 1)
+	require.Zero(broken.score())
+}
+
+func TestPeerTrackerPrefersBestScore(t *testing.T) {
+	require := require.New(t)
+
+	tracker := newTestPeerTracker(t)
+
+	fast, slow, invalid := ids.GenerateTestNodeID(), ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	for _, nodeID := range []ids.NodeID{fast, slow, invalid} {
+		tracker.Connected(nodeID, version.CurrentApp)
+		tracker.TrackPeer(nodeID)
+	}
+	tracker.TrackResponse(fast, 1_000, 10*time.Millisecond)
+	tracker.TrackResponse(slow, 1_000, 20*time.Millisecond)
+	tracker.TrackResponse(invalid, 1_000, 10*time.Millisecond)
+	tracker.TrackFailure(invalid, failureReasonInvalidResponse)
+
+	// Peers that just failed aren't picked at random.
+	require.True(tracker.responsivePeers.Contains(fast))
+	require.True(tracker.responsivePeers.Contains(slow))
+	require.False(tracker.responsivePeers.Contains(invalid))
+
+	// Peers are removed from the heap while they're busy, so
+	// successive peers are ranked by score.
+	nodeID, _, ok := tracker.scoreHeap.Pop()
+	require.True(ok)
+	require.Equal(fast, nodeID)
+	nodeID, _, ok = tracker.scoreHeap.Pop()
+	require.True(ok)
+	require.Equal(slow, nodeID)
+	nodeID, _, ok = tracker.scoreHeap.Pop()
+	require.True(ok)
+	require.Equal(invalid, nodeID)
+}
+
+func TestPeerTrackerMetrics(t *testing.T) {
+	require := require.New(t)
+
+	tracker := newTestPeerTracker(t)
+
+	nodeID := ids.GenerateTestNodeID()
+	tracker.Connected(nodeID, version.CurrentApp)
+	tracker.TrackPeer(nodeID)
+	tracker.TrackFailure(nodeID, failureReasonTimeout)
+	tracker.TrackFailure(nodeID, failureReasonInvalidResponse)
+	tracker.TrackFailure(nodeID, failureReasonInvalidResponse)
+
+	require.Equal(float64(1), testutil.ToFloat64(tracker.peerMetrics.failures.WithLabelValues(nodeID.String(), failureReasonTimeout)))
+	require.Equal(float64(2), testutil.ToFloat64(tracker.peerMetrics.failures.WithLabelValues(nodeID.String(), failureReasonInvalidResponse)))
+	require.Zero(testutil.ToFloat64(tracker.peerMetrics.score.WithLabelValues(nodeID.String())))
+
+	// Metrics for the peer are removed when it disconnects.
+	tracker.Disconnected(nodeID)
+	require.Zero(testutil.CollectAndCount(tracker.peerMetrics.failures))
+	require.Zero(testutil.CollectAndCount(tracker.peerMetrics.score))
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+const progressCodecVersion = 0
+
+var (
+	progressKey = []byte("progress")
+
+	errUnknownProgressVersion = errors.New("unknown sync progress version")
+	errInvalidProgress        = errors.New("invalid sync progress")
+)
+
+// syncProgress is the state of a Manager that is persisted so that syncing
+// can resume after a restart.
+type syncProgress struct {
+	// The root the Manager was syncing to.
+	targetRoot ids.ID
+	// The ranges that were fetched at their [localRootID].
+	processed []*workItem
+	// The ranges that were being fetched or were waiting to be fetched.
+	pending []*workItem
+}
+
+// Returns the sync progress stored in [db].
+// Returns [database.ErrNotFound] if there isn't any.
+func getProgress(db database.KeyValueReader) (*syncProgress, error) {
+	progressBytes, err := db.Get(progressKey)
+	if err != nil {
+		return nil, err
+	}
+	return parseProgress(progressBytes)
+}
+
+// Writes [progress] to [db], replacing the sync progress stored in it.
+func putProgress(db database.KeyValueWriter, progress *syncProgress) error {
+	progressBytes, err := progress.bytes()
+	if err != nil {
+		return err
+	}
+	return db.Put(progressKey, progressBytes)
+}
+
+func (p *syncProgress) bytes() ([]byte, error) {
+	packer := wrappers.Packer{MaxSize: math.MaxInt32}
+	packer.PackShort(progressCodecVersion)
+	packer.PackFixedBytes(p.targetRoot[:])
+	packWorkItems(&packer, p.processed)
+	packWorkItems(&packer, p.pending)
+	if packer.Err != nil {
+		return nil, packer.Err
+	}
+	return packer.Bytes, nil
+}
+
+func parseProgress(b []byte) (*syncProgress, error) {
+	packer := wrappers.Packer{Bytes: b}
+	if version := packer.UnpackShort(); packer.Err == nil && version != progressCodecVersion {
+		return nil, fmt.Errorf("%w: %d", errUnknownProgressVersion, version)
+	}
+
+	p := &syncProgress{}
+	copy(p.targetRoot[:], packer.UnpackFixedBytes(ids.IDLen))
+	p.processed = unpackWorkItems(&packer)
+	p.pending = unpackWorkItems(&packer)
+	switch {
+	case packer.Err != nil:
+		return nil, fmt.Errorf("%w: %w", errInvalidProgress, packer.Err)
+	case packer.Offset != len(b):
+		return nil, fmt.Errorf("%w: %d trailing bytes", errInvalidProgress, len(b)-packer.Offset)
+	}
+	return p, nil
+}
+
+func packWorkItems(packer *wrappers.Packer, items []*workItem) {
+	packer.PackInt(uint32(len(items)))
+	for _, item := range items {
+		packMaybeBytes(packer, item.start)
+		packMaybeBytes(packer, item.end)
+		packer.PackByte(byte(item.priority))
+		packer.PackFixedBytes(item.localRootID[:])
+	}
+}
+
+func unpackWorkItems(packer *wrappers.Packer) []*workItem {
+	numItems := packer.UnpackInt()
+	if packer.Err != nil {
+		return nil
+	}
+
+	// Don't preallocate more items than could possibly be in [packer].
+	items := make([]*workItem, 0, safemath.Min(int(numItems), len(packer.Bytes)-packer.Offset))
+	for i := uint32(0); i < numItems && packer.Err == nil; i++ {
+		item := &workItem{
+			start:    unpackMaybeBytes(packer),
+			end:      unpackMaybeBytes(packer),
+			priority: priority(packer.UnpackByte()),
+		}
+		copy(item.localRootID[:], packer.UnpackFixedBytes(ids.IDLen))
+		items = append(items, item)
+	}
+	return items
+}
+
+func packMaybeBytes(packer *wrappers.Packer, value maybe.Maybe[[]byte]) {
+	packer.PackBool(value.HasValue())
+	if value.HasValue() {
+		packer.PackBytes(value.Value())
+	}
+}
+
+func unpackMaybeBytes(packer *wrappers.Packer) maybe.Maybe[[]byte] {
+	if !packer.UnpackBool() {
+		return maybe.Nothing[[]byte]()
+	}
+	return maybe.Some(packer.UnpackBytes())
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+func TestProgress(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	_, err := getProgress(db)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	progress := &syncProgress{
+		targetRoot: ids.GenerateTestID(),
+		processed: []*workItem{
+			newWorkItem(ids.GenerateTestID(), maybe.Nothing[[]byte](), maybe.Some([]byte{1}), lowPriority),
+			newWorkItem(ids.GenerateTestID(), maybe.Some([]byte{2}), maybe.Some([]byte{}), medPriority),
+		},
+		pending: []*workItem{
+			newWorkItem(ids.Empty, maybe.Some([]byte{1}), maybe.Some([]byte{2}), highPriority),
+			newWorkItem(ids.Empty, maybe.Some([]byte{3}), maybe.Nothing[[]byte](), lowPriority),
+		},
+	}
+	require.NoError(putProgress(db, progress))
+
+	got, err := getProgress(db)
+	require.NoError(err)
+	require.Equal(progress, got)
+
+	progressBytes, err := progress.bytes()
+	require.NoError(err)
+
+	// Trailing bytes aren't allowed.
+	_, err = parseProgress(append(progressBytes, 0))
+	require.ErrorIs(err, errInvalidProgress)
+
+	// Truncated progress isn't allowed.
+	_, err = parseProgress(progressBytes[:len(progressBytes)-1])
+	require.ErrorIs(err, errInvalidProgress)
+	require.ErrorIs(err, wrappers.ErrInsufficientLength)
+
+	// Unknown versions aren't allowed.
+	progressBytes[1]++
+	_, err = parseProgress(progressBytes)
+	require.ErrorIs(err, errUnknownProgressVersion)
+}
+
+func TestWriteProgressDropsOlderCheckpoints(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	m := &Manager{
+		config: ManagerConfig{
+			ProgressDB: db,
+		},
+		unprocessedWork: newWorkHeap(),
+		processedWork:   newWorkHeap(),
+		processingWork:  set.Set[*workItem]{},
+	}
+
+	m.unprocessedWork.Insert(newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority))
+	olderCheckpoint, olderBytes, err := m.checkpointProgress()
+	require.NoError(err)
+
+	m.processedWork.Insert(m.unprocessedWork.GetWork())
+	newerCheckpoint, newerBytes, err := m.checkpointProgress()
+	require.NoError(err)
+
+	// Checkpoints may be written out of order, but only the newest one is
+	// kept.
+	require.NoError(m.writeProgress(newerCheckpoint, newerBytes))
+	require.NoError(m.writeProgress(olderCheckpoint, olderBytes))
+
+	progress, err := getProgress(db)
+	require.NoError(err)
+	require.Len(progress.processed, 1)
+	require.Empty(progress.pending)
+}
@@ -6,10 +6,13 @@ package sync
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"math/rand"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/stretchr/testify/require"
 
 	"go.uber.org/mock/gomock"
@@ -191,6 +194,51 @@ func Test_Midpoint(t *testing.T) {
 	}
 }
 
+func Test_SplitRange(t *testing.T) {
+	require := require.New(t)
+
+	// A range that can't be split is returned as is.
+	bounds := splitRange(maybe.Some([]byte{1}), maybe.Some([]byte{1}), 4)
+	require.Equal([]maybe.Maybe[[]byte]{maybe.Some([]byte{1}), maybe.Some([]byte{1})}, bounds)
+
+	bounds = splitRange(maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 2)
+	require.Equal([]maybe.Maybe[[]byte]{maybe.Nothing[[]byte](), maybe.Some([]byte{127}), maybe.Nothing[[]byte]()}, bounds)
+
+	bounds = splitRange(maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 4)
+	require.Equal(
+		[]maybe.Maybe[[]byte]{
+			maybe.Nothing[[]byte](),
+			maybe.Some([]byte{63, 127}),
+			maybe.Some([]byte{127}),
+			maybe.Some([]byte{191}),
+			maybe.Nothing[[]byte](),
+		},
+		bounds,
+	)
+
+	for i := 0; i < 1000; i++ {
+		r := rand.New(rand.NewSource(int64(i))) // #nosec G404
+
+		start := make([]byte, r.Intn(99)+1)
+		_, _ = r.Read(start)
+		end := make([]byte, r.Intn(99)+1)
+		_, _ = r.Read(end)
+		if bytes.Compare(start, end) > 0 {
+			start, end = end, start
+		}
+		maxRanges := r.Intn(20) + 2
+
+		bounds := splitRange(maybe.Some(start), maybe.Some(end), maxRanges)
+		require.LessOrEqual(len(bounds)-1, maxRanges)
+		require.Equal(maybe.Some(start), bounds[0])
+		require.Equal(maybe.Some(end), bounds[len(bounds)-1])
+		for j := 1; j < len(bounds); j++ {
+			// The ranges are contiguous and don't overlap.
+			require.Negative(bytes.Compare(bounds[j-1].Value(), bounds[j].Value()))
+		}
+	}
+}
+
 func Test_Sync_FindNextKey_InSync(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -836,6 +884,78 @@ func Test_Sync_Result_Correct_Root(t *testing.T) {
 	require.Equal(syncRoot, newRoot)
 }
 
+func Test_Sync_Result_Correct_Root_BranchFactors(t *testing.T) {
+	for _, bf := range []merkledb.BranchFactor{merkledb.BranchFactor2, merkledb.BranchFactor256} {
+		bf := bf
+		t.Run(fmt.Sprintf("%d", bf), func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			now := time.Now().UnixNano()
+			t.Logf("seed: %d", now)
+			r := rand.New(rand.NewSource(now)) // #nosec G404
+
+			config := newDefaultDBConfig()
+			config.BranchFactor = bf
+			config.Hasher = merkledb.Keccak256Hasher
+			dbToSync, err := merkledb.New(context.Background(), memdb.New(), config)
+			require.NoError(err)
+			for i := 0; i < 3*maxKeyValuesLimit; i++ {
+				key := make([]byte, r.Intn(50))
+				_, _ = r.Read(key)
+				val := make([]byte, r.Intn(50))
+				_, _ = r.Read(val)
+				require.NoError(dbToSync.Put(key, val))
+			}
+			syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+			require.NoError(err)
+
+			// The proofs are sent through their protobuf representation, as
+			// they would be over the network.
+			syncClient := NewMockClient(ctrl)
+			syncClient.EXPECT().GetRangeProof(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, request *pb.SyncGetRangeProofRequest) (*merkledb.RangeProof, error) {
+					rangeProof, err := dbToSync.GetRangeProof(
+						context.Background(),
+						maybeBytesToMaybe(request.StartKey),
+						maybeBytesToMaybe(request.EndKey),
+						int(request.KeyLimit),
+					)
+					if err != nil {
+						return nil, err
+					}
+
+					var receivedProof merkledb.RangeProof
+					if err := receivedProof.UnmarshalProto(rangeProof.ToProto()); err != nil {
+						return nil, err
+					}
+					return &receivedProof, nil
+				}).AnyTimes()
+
+			config.Reg = prometheus.NewRegistry()
+			db, err := merkledb.New(context.Background(), memdb.New(), config)
+			require.NoError(err)
+			syncer, err := NewManager(ManagerConfig{
+				DB:                    db,
+				Client:                syncClient,
+				TargetRoot:            syncRoot,
+				SimultaneousWorkLimit: 5,
+				Log:                   logging.NoLog{},
+				BranchFactor:          bf,
+			})
+			require.NoError(err)
+			require.NoError(syncer.Start(context.Background()))
+
+			require.NoError(syncer.Wait(context.Background()))
+			require.NoError(syncer.Error())
+
+			newRoot, err := db.GetMerkleRoot(context.Background())
+			require.NoError(err)
+			require.Equal(syncRoot, newRoot)
+		})
+	}
+}
+
 func Test_Sync_Result_Correct_Root_With_Sync_Restart(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -899,6 +1019,216 @@ func Test_Sync_Result_Correct_Root_With_Sync_Restart(t *testing.T) {
 	require.Equal(syncRoot, newRoot)
 }
 
+func Test_Sync_Resume_From_Progress(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+	syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+	progressDB := memdb.New()
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                newCallthroughSyncClient(ctrl, dbToSync),
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		ProgressDB:            progressDB,
+	})
+	require.NoError(err)
+	require.NoError(syncer.Start(context.Background()))
+
+	// Wait until we've processed some work before stopping.
+	require.Eventually(
+		func() bool {
+			syncer.workLock.Lock()
+			defer syncer.workLock.Unlock()
+
+			return syncer.processedWork.Len() > 0
+		},
+		5*time.Second,
+		5*time.Millisecond,
+	)
+	closeAndWait(require, syncer)
+
+	progress, err := getProgress(progressDB)
+	require.NoError(err)
+	require.Equal(syncRoot, progress.targetRoot)
+	require.NotEmpty(progress.processed)
+
+	// The resumed sync shouldn't fetch any of the ranges that were completed.
+	client := NewMockClient(ctrl)
+	callthroughClient := newCallthroughSyncClient(ctrl, dbToSync)
+	client.EXPECT().GetRangeProof(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.SyncGetRangeProofRequest) (*merkledb.RangeProof, error) {
+			requestedStart := maybeBytesToMaybe(request.StartKey)
+			requestedEnd := maybeBytesToMaybe(request.EndKey)
+			for _, item := range progress.processed {
+				require.False(rangesOverlap(requestedStart, requestedEnd, item.start, item.end))
+			}
+			return callthroughClient.GetRangeProof(ctx, request)
+		},
+	).AnyTimes()
+
+	newSyncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		ProgressDB:            progressDB,
+	})
+	require.NoError(err)
+	require.NoError(newSyncer.Start(context.Background()))
+	require.NoError(newSyncer.Wait(context.Background()))
+
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(syncRoot, newRoot)
+
+	// The completed sync is saved, so resuming it again is a no-op.
+	finishedSyncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                NewMockClient(ctrl), // Not used
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		ProgressDB:            progressDB,
+	})
+	require.NoError(err)
+	require.NoError(finishedSyncer.Start(context.Background()))
+	require.NoError(finishedSyncer.Wait(context.Background()))
+}
+
+func Test_Sync_Resume_From_Progress_With_New_Target(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+	firstSyncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+	progressDB := memdb.New()
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                newCallthroughSyncClient(ctrl, dbToSync),
+		TargetRoot:            firstSyncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		ProgressDB:            progressDB,
+	})
+	require.NoError(err)
+	require.NoError(syncer.Start(context.Background()))
+
+	// Wait until we've processed some work before stopping.
+	require.Eventually(
+		func() bool {
+			syncer.workLock.Lock()
+			defer syncer.workLock.Unlock()
+
+			return syncer.processedWork.Len() > 0
+		},
+		5*time.Second,
+		5*time.Millisecond,
+	)
+	closeAndWait(require, syncer)
+
+	for x := 0; x < 100; x++ {
+		key := make([]byte, r.Intn(50))
+		_, err = r.Read(key)
+		require.NoError(err)
+
+		val := make([]byte, r.Intn(50))
+		_, err = r.Read(val)
+		require.NoError(err)
+
+		require.NoError(dbToSync.Put(key, val))
+	}
+	secondSyncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// The ranges synced to [firstSyncRoot] should be updated with change proofs.
+	client := NewMockClient(ctrl)
+	callthroughClient := newCallthroughSyncClient(ctrl, dbToSync)
+	client.EXPECT().GetRangeProof(gomock.Any(), gomock.Any()).DoAndReturn(callthroughClient.GetRangeProof).AnyTimes()
+	client.EXPECT().GetChangeProof(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.SyncGetChangeProofRequest, db DB) (*merkledb.ChangeOrRangeProof, error) {
+			require.Equal(firstSyncRoot[:], request.StartRootHash)
+			return callthroughClient.GetChangeProof(ctx, request, db)
+		},
+	).MinTimes(1)
+
+	newSyncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		TargetRoot:            secondSyncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		ProgressDB:            progressDB,
+	})
+	require.NoError(err)
+	require.NoError(newSyncer.Start(context.Background()))
+	require.NoError(newSyncer.Wait(context.Background()))
+
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(secondSyncRoot, newRoot)
+
+	progress, err := getProgress(progressDB)
+	require.NoError(err)
+	require.Equal(secondSyncRoot, progress.targetRoot)
+	require.Empty(progress.pending)
+}
+
+// Closes [syncer] and waits until it has stopped processing work items, so
+// that it no longer writes to its database.
+func closeAndWait(require *require.Assertions, syncer *Manager) {
+	syncer.Close()
+	require.Eventually(
+		func() bool {
+			syncer.workLock.Lock()
+			defer syncer.workLock.Unlock()
+
+			return syncer.processingWorkItems == 0
+		},
+		5*time.Second,
+		5*time.Millisecond,
+	)
+}
+
+// Returns true iff the ranges [start1, end1] and [start2, end2] share more
+// than a boundary.
+func rangesOverlap(start1, end1, start2, end2 maybe.Maybe[[]byte]) bool {
+	// Nothing starts are before every key and Nothing ends are after every key.
+	isBefore := func(start, end maybe.Maybe[[]byte]) bool {
+		return start.IsNothing() || end.IsNothing() || bytes.Compare(start.Value(), end.Value()) < 0
+	}
+	return isBefore(start1, end2) && isBefore(start2, end1)
+}
+
 func Test_Sync_Error_During_Sync(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
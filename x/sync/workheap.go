@@ -166,6 +166,15 @@ func (wh *workHeap) remove(item *heapItem) {
 	wh.sortedItems.Delete(item)
 }
 
+// Returns the work items in the heap, in no particular order.
+func (wh *workHeap) Items() []*workItem {
+	items := make([]*workItem, len(wh.innerHeap))
+	for i, item := range wh.innerHeap {
+		items[i] = item.workItem
+	}
+	return items
+}
+
 func (wh *workHeap) Len() int {
 	return wh.innerHeap.Len()
 }